@@ -0,0 +1,64 @@
+package auditlog
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+const (
+	ResourceSQSQueue        = "sqs-queue"
+	ResourceSNSTopic        = "sns-topic"
+	ResourceSNSSubscription = "sns-subscription"
+	ResourceIAMUser         = "iam-user"
+	ResourceIAMAccessKey    = "iam-access-key"
+	ResourceIAMPolicy       = "iam-policy"
+)
+
+// Event records who asked for a change, which AWS resources it created or destroyed, and whether it succeeded.
+// Events are chained by hash, so removing or altering one breaks the chain.
+type Event struct {
+	Sequence            uint64               `json:"sequence"`
+	Time                string               `json:"time"`
+	Action              string               `json:"action"`
+	Outcome             string               `json:"outcome"`
+	Error               string               `json:"error,omitempty"`
+	OriginatingIdentity *OriginatingIdentity `json:"originating_identity,omitempty"`
+	InstanceID          string               `json:"instance_id,omitempty"`
+	BindingID           string               `json:"binding_id,omitempty"`
+	Request             *Request             `json:"request,omitempty"`
+	Resources           []Resource           `json:"resources,omitempty"`
+	PreviousHash        string               `json:"previous_hash"`
+	Hash                string               `json:"hash"`
+}
+
+type OriginatingIdentity struct {
+	Platform string                 `json:"platform"`
+	Value    map[string]interface{} `json:"value"`
+}
+
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Status int    `json:"status"`
+}
+
+type Resource struct {
+	Type   string `json:"type"`
+	Name   string `json:"name,omitempty"`
+	ID     string `json:"id,omitempty"`
+	ARN    string `json:"arn,omitempty"`
+	Region string `json:"region,omitempty"`
+}
+
+type Logger interface {
+	Log(event Event) error
+}
+
+type discardLogger struct{}
+
+func (discardLogger) Log(event Event) error {
+	return nil
+}
+
+// Discard is a Logger for when there is no audit log configured
+var Discard Logger = discardLogger{}
@@ -0,0 +1,18 @@
+package fakes
+
+import (
+	"github.com/cf-platform-eng/sqs-broker/auditlog"
+)
+
+type FakeLogger struct {
+	LogCalled bool
+	LogEvents []auditlog.Event
+	LogError  error
+}
+
+func (f *FakeLogger) Log(event auditlog.Event) error {
+	f.LogCalled = true
+	f.LogEvents = append(f.LogEvents, event)
+
+	return f.LogError
+}
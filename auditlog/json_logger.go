@@ -0,0 +1,194 @@
+package auditlog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+// JSONLogger writes one JSON Event per line, and logs the head of the hash chain after each Event.
+// The head is meant to be shipped off the host with the broker logs: the log file alone can not
+// tell whether its last Events were removed.
+type JSONLogger struct {
+	writer       io.Writer
+	key          []byte
+	logger       lager.Logger
+	mutex        sync.Mutex
+	sequence     uint64
+	previousHash string
+}
+
+func NewJSONLogger(writer io.Writer, key []byte, logger lager.Logger) *JSONLogger {
+	return &JSONLogger{
+		writer: writer,
+		key:    key,
+		logger: logger.Session("audit-log"),
+	}
+}
+
+// NewFileLogger appends Events to a file, continuing the hash chain of the Events already in it
+func NewFileLogger(path string, key []byte, logger lager.Logger) (*JSONLogger, error) {
+	l := NewJSONLogger(nil, key, logger)
+
+	existing, err := os.Open(path)
+	if err == nil {
+		lastEvent, err := readLastEvent(existing)
+		existing.Close()
+		if err != nil {
+			return nil, fmt.Errorf("Reading audit log '%s': %s", path, err)
+		}
+		l.sequence = lastEvent.Sequence
+		l.previousHash = lastEvent.Hash
+		l.logger.Info("reopened", lager.Data{"sequence": l.sequence, "hash": l.previousHash})
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	l.writer = file
+
+	return l, nil
+}
+
+func (l *JSONLogger) Log(event Event) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if event.Time == "" {
+		event.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	event.Sequence = l.sequence + 1
+	event.PreviousHash = l.previousHash
+
+	hash, err := eventHash(event, l.key)
+	if err != nil {
+		return err
+	}
+	event.Hash = hash
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if _, err := l.writer.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	l.sequence = event.Sequence
+	l.previousHash = event.Hash
+
+	l.logger.Info("head", lager.Data{"sequence": event.Sequence, "hash": event.Hash})
+
+	return nil
+}
+
+// Verify checks that the Events read are unaltered and that none is missing between them, by following their
+// sequence and hash chain, and returns the last one. Whether Events were removed from the end can only be told
+// by comparing the last one with the head logged when it was written.
+func Verify(reader io.Reader, key []byte) (Event, error) {
+	var previous Event
+	line := 0
+	err := readLines(reader, func(contents []byte) error {
+		line++
+
+		var event Event
+		if err := json.Unmarshal(contents, &event); err != nil {
+			return fmt.Errorf("line %d: %s", line, err)
+		}
+
+		if event.Sequence != previous.Sequence+1 {
+			return fmt.Errorf("line %d: expected sequence %d, got %d", line, previous.Sequence+1, event.Sequence)
+		}
+
+		if event.PreviousHash != previous.Hash {
+			return fmt.Errorf("line %d: previous hash does not match event %d", line, previous.Sequence)
+		}
+
+		hash, err := eventHash(event, key)
+		if err != nil {
+			return fmt.Errorf("line %d: %s", line, err)
+		}
+		if !hmac.Equal([]byte(event.Hash), []byte(hash)) {
+			return fmt.Errorf("line %d: hash does not match the event contents", line)
+		}
+
+		previous = event
+		return nil
+	})
+
+	return previous, err
+}
+
+// eventHash is an HMAC covering the previous hash, so each Event vouches for the whole chain before it,
+// and the chain can not be recomputed after an edit without the key
+func eventHash(event Event, key []byte) (string, error) {
+	event.Hash = ""
+
+	contents, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(event.PreviousHash))
+	mac.Write(contents)
+
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+func readLastEvent(reader io.Reader) (Event, error) {
+	var lastLine []byte
+	err := readLines(reader, func(contents []byte) error {
+		if len(contents) > 0 {
+			lastLine = append(lastLine[:0], contents...)
+		}
+		return nil
+	})
+	if err != nil {
+		return Event{}, err
+	}
+
+	var event Event
+	if lastLine == nil {
+		return event, nil
+	}
+
+	err = json.Unmarshal(lastLine, &event)
+
+	return event, err
+}
+
+// readLines calls fn with each line read, without its newline
+func readLines(reader io.Reader, fn func(line []byte) error) error {
+	bufferedReader := bufio.NewReader(reader)
+	for {
+		line, err := bufferedReader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		if len(line) > 0 {
+			if fnErr := fn(bytes.TrimSuffix(line, []byte("\n"))); fnErr != nil {
+				return fnErr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package auditlog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/cf-platform-eng/sqs-broker/auditlog"
+)
+
+var _ = Describe("JSONLogger", func() {
+	var (
+		buffer *bytes.Buffer
+		key    []byte
+		logger *JSONLogger
+		event  Event
+
+		testSink     *lagertest.TestSink
+		brokerLogger lager.Logger
+	)
+
+	readEvents := func(contents string) []Event {
+		events := []Event{}
+		for _, line := range strings.Split(strings.TrimSpace(contents), "\n") {
+			var event Event
+			Expect(json.Unmarshal([]byte(line), &event)).To(Succeed())
+			events = append(events, event)
+		}
+		return events
+	}
+
+	BeforeEach(func() {
+		buffer = &bytes.Buffer{}
+		key = []byte("audit-log-key")
+
+		brokerLogger = lager.NewLogger("json_logger_test")
+		testSink = lagertest.NewTestSink()
+		brokerLogger.RegisterSink(testSink)
+
+		logger = NewJSONLogger(buffer, key, brokerLogger)
+
+		event = Event{
+			Action:     "create-queue",
+			Outcome:    OutcomeSuccess,
+			InstanceID: "instance-id",
+			Resources: []Resource{
+				{Type: ResourceSQSQueue, Name: "cf-instance-id", ARN: "arn:aws:sqs:us-east-1:123456789012:cf-instance-id"},
+			},
+		}
+	})
+
+	It("writes one JSON event per line", func() {
+		Expect(logger.Log(event)).To(Succeed())
+		Expect(logger.Log(event)).To(Succeed())
+
+		events := readEvents(buffer.String())
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Action).To(Equal("create-queue"))
+		Expect(events[0].InstanceID).To(Equal("instance-id"))
+		Expect(events[0].Resources).To(Equal(event.Resources))
+		Expect(events[0].Time).ToNot(BeEmpty())
+	})
+
+	It("chains the events", func() {
+		Expect(logger.Log(event)).To(Succeed())
+		Expect(logger.Log(event)).To(Succeed())
+
+		events := readEvents(buffer.String())
+		Expect(events[0].Sequence).To(Equal(uint64(1)))
+		Expect(events[0].PreviousHash).To(BeEmpty())
+		Expect(events[0].Hash).ToNot(BeEmpty())
+		Expect(events[1].Sequence).To(Equal(uint64(2)))
+		Expect(events[1].PreviousHash).To(Equal(events[0].Hash))
+		Expect(events[1].Hash).ToNot(Equal(events[0].Hash))
+	})
+
+	It("logs the head of the chain", func() {
+		Expect(logger.Log(event)).To(Succeed())
+
+		events := readEvents(buffer.String())
+		Expect(testSink.LogMessages()).To(Equal([]string{"json_logger_test.audit-log.head"}))
+		Expect(testSink.Logs()[0].Data).To(HaveKeyWithValue("sequence", float64(1)))
+		Expect(testSink.Logs()[0].Data).To(HaveKeyWithValue("hash", events[0].Hash))
+	})
+
+	Describe("Verify", func() {
+		BeforeEach(func() {
+			Expect(logger.Log(event)).To(Succeed())
+			event.Action = "delete-queue"
+			Expect(logger.Log(event)).To(Succeed())
+			event.Action = "create-binding-user"
+			Expect(logger.Log(event)).To(Succeed())
+		})
+
+		It("accepts an unaltered log and returns its last event", func() {
+			lastEvent, err := Verify(strings.NewReader(buffer.String()), key)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastEvent.Sequence).To(Equal(uint64(3)))
+			Expect(lastEvent.Action).To(Equal("create-binding-user"))
+		})
+
+		It("detects an altered event", func() {
+			altered := strings.Replace(buffer.String(), `"delete-queue"`, `"modify-queue"`, 1)
+
+			_, err := Verify(strings.NewReader(altered), key)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("line 2: hash does not match the event contents"))
+		})
+
+		It("detects a removed event", func() {
+			lines := strings.Split(buffer.String(), "\n")
+			removed := strings.Join(append([]string{lines[0]}, lines[2:]...), "\n")
+
+			_, err := Verify(strings.NewReader(removed), key)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("line 2: expected sequence 2, got 3"))
+		})
+
+		It("detects a chain rewritten without the key", func() {
+			rewritten := &bytes.Buffer{}
+			rewriter := NewJSONLogger(rewritten, []byte("other-key"), brokerLogger)
+			for _, event := range readEvents(buffer.String()) {
+				Expect(rewriter.Log(event)).To(Succeed())
+			}
+
+			_, err := Verify(strings.NewReader(rewritten.String()), key)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("line 1: hash does not match the event contents"))
+		})
+
+		It("can not detect trailing events removed, which the logged head shows", func() {
+			lines := strings.SplitAfter(buffer.String(), "\n")
+			truncated := strings.Join(lines[:2], "")
+
+			lastEvent, err := Verify(strings.NewReader(truncated), key)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastEvent.Sequence).To(Equal(uint64(2)))
+
+			lastHead := testSink.Logs()[len(testSink.Logs())-1]
+			Expect(lastHead.Data).To(HaveKeyWithValue("sequence", float64(3)))
+		})
+	})
+
+	Describe("NewFileLogger", func() {
+		var (
+			tmpDir  string
+			logFile string
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "auditlog")
+			Expect(err).ToNot(HaveOccurred())
+			logFile = filepath.Join(tmpDir, "audit.log")
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+
+		It("continues the chain of an existing log", func() {
+			fileLogger, err := NewFileLogger(logFile, key, brokerLogger)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fileLogger.Log(event)).To(Succeed())
+
+			fileLogger, err = NewFileLogger(logFile, key, brokerLogger)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fileLogger.Log(event)).To(Succeed())
+
+			contents, err := ioutil.ReadFile(logFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(readEvents(string(contents))).To(HaveLen(2))
+			_, err = Verify(bytes.NewReader(contents), key)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testSink.LogMessages()).To(ContainElement("json_logger_test.audit-log.reopened"))
+		})
+
+		It("returns error if the existing log is not valid", func() {
+			Expect(ioutil.WriteFile(logFile, []byte("not json\n"), 0600)).To(Succeed())
+
+			_, err := NewFileLogger(logFile, key, brokerLogger)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("Reading audit log '" + logFile + "'"))
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package awsemulator_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestAWSEmulator(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AWS Emulator Suite")
+}
@@ -0,0 +1,230 @@
+package awsemulator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultRegion    = "us-east-1"
+	DefaultAccountID = "123456789012"
+
+	// CallerUserName is the IAM user owning the credentials of every request
+	CallerUserName = "sqs-broker"
+	CallerUserID   = "AIDACALLERUSER"
+)
+
+// Emulator is an in-process stand-in for the AWS SQS, SNS and IAM Query APIs, covering the actions used by the broker.
+// All services are served from the same endpoint, requests are dispatched by their Action and are not authenticated.
+type Emulator struct {
+	region    string
+	accountID string
+
+	mutex         sync.Mutex
+	queues        map[string]*queue
+	topics        map[string]*topic
+	subscriptions map[string]*subscription
+	users         map[string]*user
+	policies      map[string]*policy
+	deniedActions map[string]bool
+}
+
+type actionHandler func(e *Emulator, req *http.Request, form url.Values) (interface{}, *Error)
+
+var actions = map[string]actionHandler{}
+
+func New(region string, accountID string) *Emulator {
+	return &Emulator{
+		region:        region,
+		accountID:     accountID,
+		queues:        map[string]*queue{},
+		topics:        map[string]*topic{},
+		subscriptions: map[string]*subscription{},
+		users:         map[string]*user{},
+		policies:      map[string]*policy{},
+		deniedActions: map[string]bool{},
+	}
+}
+
+func (e *Emulator) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if err := req.ParseForm(); err != nil {
+		writeError(w, &Error{StatusCode: http.StatusBadRequest, Code: "MalformedQueryString", Message: err.Error()})
+		return
+	}
+
+	action := req.Form.Get("Action")
+	handler, ok := actions[action]
+	if !ok {
+		writeError(w, &Error{StatusCode: http.StatusBadRequest, Code: "InvalidAction", Message: fmt.Sprintf("The action %s is not valid for this endpoint.", action)})
+		return
+	}
+
+	e.mutex.Lock()
+	result, err := handler(e, req, req.Form)
+	e.mutex.Unlock()
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeResult(w, action, result)
+}
+
+// QueueNames returns the names of the existing queues
+func (e *Emulator) QueueNames() []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	names := []string{}
+	for name := range e.queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// QueueAttributes returns the attributes of a queue, including the approximate number of messages
+func (e *Emulator) QueueAttributes(queueName string) (map[string]string, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	q, ok := e.queues[queueName]
+	if !ok {
+		return nil, false
+	}
+
+	return q.allAttributes(time.Now()), true
+}
+
+// TopicNames returns the names of the existing topics
+func (e *Emulator) TopicNames() []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	names := []string{}
+	for name := range e.topics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// TopicSubscriptionEndpoints returns the endpoints, such as queue ARNs, subscribed to a topic
+func (e *Emulator) TopicSubscriptionEndpoints(topicName string) []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	endpoints := []string{}
+	if t, ok := e.topics[topicName]; ok {
+		for _, s := range e.topicSubscriptions(t.arn) {
+			endpoints = append(endpoints, s.endpoint)
+		}
+	}
+	sort.Strings(endpoints)
+
+	return endpoints
+}
+
+// UserNames returns the names of the existing IAM users
+func (e *Emulator) UserNames() []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	names := []string{}
+	for name := range e.users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// UserPolicyDocuments returns the documents of the managed policies attached to an IAM user
+func (e *Emulator) UserPolicyDocuments(userName string) []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	documents := []string{}
+	if u, ok := e.users[userName]; ok {
+		for _, policyArn := range u.attachedPolicies {
+			documents = append(documents, e.policies[policyArn].document)
+		}
+	}
+
+	return documents
+}
+
+// DenyAction makes the IAM policy simulation report an action as denied to the caller
+func (e *Emulator) DenyAction(action string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.deniedActions[action] = true
+}
+
+// PolicyNames returns the names of the existing IAM managed policies
+func (e *Emulator) PolicyNames() []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	names := []string{}
+	for _, p := range e.policies {
+		names = append(names, p.name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// members returns the members of a flattened Query list (<prefix>.1.<key>, <prefix>.2.<key>, ...) keyed by <key>
+func members(form url.Values, prefix string) []url.Values {
+	var result []url.Values
+	for i := 1; ; i++ {
+		memberPrefix := fmt.Sprintf("%s.%d.", prefix, i)
+		member := url.Values{}
+		for key, values := range form {
+			if strings.HasPrefix(key, memberPrefix) {
+				member[strings.TrimPrefix(key, memberPrefix)] = values
+			}
+		}
+		if len(member) == 0 {
+			return result
+		}
+		result = append(result, member)
+	}
+}
+
+// values returns the values of a flattened Query list of scalars (<prefix>.1, <prefix>.2, ...)
+func values(form url.Values, prefix string) []string {
+	var result []string
+	for i := 1; ; i++ {
+		value, ok := form[fmt.Sprintf("%s.%d", prefix, i)]
+		if !ok {
+			return result
+		}
+		result = append(result, value[0])
+	}
+}
+
+func randomID(length int) string {
+	bytes := make([]byte, (length+1)/2)
+	if _, err := rand.Read(bytes); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(bytes)[:length]
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
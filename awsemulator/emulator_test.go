@@ -0,0 +1,258 @@
+package awsemulator_test
+
+import (
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/sqs-broker/awsemulator"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cf-platform-eng/sqs-broker/awsiam"
+	"github.com/cf-platform-eng/sqs-broker/awssns"
+	"github.com/cf-platform-eng/sqs-broker/awssqs"
+)
+
+var _ = Describe("Emulator", func() {
+	var (
+		emulator *Emulator
+		server   *httptest.Server
+
+		queue awssqs.Queue
+		topic awssns.Topic
+		user  awsiam.User
+
+		iamsvc *iam.IAM
+		logger lager.Logger
+	)
+
+	BeforeEach(func() {
+		emulator = New(DefaultRegion, DefaultAccountID)
+		server = httptest.NewServer(emulator)
+
+		awsSession := session.New(aws.NewConfig().
+			WithRegion(DefaultRegion).
+			WithEndpoint(server.URL).
+			WithCredentials(credentials.NewStaticCredentials("access-key-id", "secret-access-key", "")))
+
+		logger = lager.NewLogger("awsemulator_test")
+		logger.RegisterSink(lagertest.NewTestSink())
+
+		queue = awssqs.NewSQSQueue(sqs.New(awsSession), server.URL, logger)
+		topic = awssns.NewSNSTopic(sns.New(awsSession), logger)
+		iamsvc = iam.New(awsSession)
+		user = awsiam.NewIAMUser(iamsvc, logger)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("SQS", func() {
+		It("creates, describes, modifies and deletes a queue", func() {
+			queueURL, err := queue.Create("queue", awssqs.QueueDetails{DelaySeconds: "5"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(queueURL).To(Equal(server.URL + "/" + DefaultAccountID + "/queue"))
+			Expect(emulator.QueueNames()).To(Equal([]string{"queue"}))
+
+			queueDetails, err := queue.Describe("queue")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(queueDetails.QueueURL).To(Equal(queueURL))
+			Expect(queueDetails.QueueArn).To(Equal("arn:aws:sqs:" + DefaultRegion + ":" + DefaultAccountID + ":queue"))
+			Expect(queueDetails.DelaySeconds).To(Equal("5"))
+			Expect(queueDetails.VisibilityTimeout).To(Equal("30"))
+
+			err = queue.Modify("queue", awssqs.QueueDetails{VisibilityTimeout: "60"})
+			Expect(err).ToNot(HaveOccurred())
+			err = queue.SetPolicy("queue", `{"Statement":[]}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			attributes, ok := emulator.QueueAttributes("queue")
+			Expect(ok).To(BeTrue())
+			Expect(attributes["VisibilityTimeout"]).To(Equal("60"))
+			Expect(attributes["Policy"]).To(Equal(`{"Statement":[]}`))
+
+			err = queue.Delete("queue")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(emulator.QueueNames()).To(BeEmpty())
+		})
+
+		It("sends, receives and deletes messages", func() {
+			_, err := queue.Create("queue", awssqs.QueueDetails{})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = queue.SendMessages("queue", []awssqs.Message{
+				{
+					Body: "message-body",
+					MessageAttributes: map[string]awssqs.MessageAttribute{
+						"attribute": {DataType: "String", StringValue: "attribute-value"},
+					},
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			messages, err := queue.ReceiveMessages("queue", 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(messages).To(HaveLen(1))
+			Expect(messages[0].Body).To(Equal("message-body"))
+			Expect(messages[0].MessageAttributes["attribute"].StringValue).To(Equal("attribute-value"))
+
+			attributes, _ := emulator.QueueAttributes("queue")
+			Expect(attributes["ApproximateNumberOfMessagesNotVisible"]).To(Equal("1"))
+
+			err = queue.DeleteMessages("queue", messages)
+			Expect(err).ToNot(HaveOccurred())
+
+			attributes, _ = emulator.QueueAttributes("queue")
+			Expect(attributes["ApproximateNumberOfMessages"]).To(Equal("0"))
+			Expect(attributes["ApproximateNumberOfMessagesNotVisible"]).To(Equal("0"))
+		})
+
+		It("returns the proper error if the queue does not exist", func() {
+			_, err := queue.Describe("unknown")
+			Expect(err).To(Equal(awssqs.ErrQueueDoesNotExist))
+
+			err = queue.Delete("unknown")
+			Expect(err).To(Equal(awssqs.ErrQueueDoesNotExist))
+		})
+
+		It("rejects invalid attribute values", func() {
+			_, err := queue.Create("queue", awssqs.QueueDetails{DelaySeconds: "901"})
+			Expect(err).To(HaveOccurred())
+			Expect(emulator.QueueNames()).To(BeEmpty())
+		})
+	})
+
+	Describe("SNS", func() {
+		It("creates, describes, modifies and deletes a topic", func() {
+			topicArn, err := topic.Create("topic", awssns.TopicDetails{DisplayName: "Topic"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(topicArn).To(Equal("arn:aws:sns:" + DefaultRegion + ":" + DefaultAccountID + ":topic"))
+			Expect(emulator.TopicNames()).To(Equal([]string{"topic"}))
+
+			err = topic.Modify("topic", awssns.TopicDetails{DeliveryPolicy: `{"http":{}}`})
+			Expect(err).ToNot(HaveOccurred())
+
+			topicDetails, err := topic.Describe("topic")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(topicDetails.TopicArn).To(Equal(topicArn))
+			Expect(topicDetails.DisplayName).To(Equal("Topic"))
+			Expect(topicDetails.DeliveryPolicy).To(Equal(`{"http":{}}`))
+
+			err = topic.Delete("topic")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(emulator.TopicNames()).To(BeEmpty())
+		})
+
+		It("subscribes and unsubscribes a queue", func() {
+			_, err := topic.Create("topic", awssns.TopicDetails{})
+			Expect(err).ToNot(HaveOccurred())
+
+			queueArn := "arn:aws:sqs:" + DefaultRegion + ":" + DefaultAccountID + ":queue"
+			subscriptionArn, err := topic.Subscribe("topic", "sqs", queueArn, awssns.SubscriptionDetails{RawMessageDelivery: "true"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(emulator.TopicSubscriptionEndpoints("topic")).To(Equal([]string{queueArn}))
+
+			topicDetails, err := topic.Describe("topic")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(topicDetails.SubscriptionsConfirmed).To(Equal("1"))
+
+			err = topic.Unsubscribe(subscriptionArn)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(emulator.TopicSubscriptionEndpoints("topic")).To(BeEmpty())
+
+			err = topic.Unsubscribe(subscriptionArn)
+			Expect(err).To(Equal(awssns.ErrSubscriptionDoesNotExist))
+		})
+
+		It("returns the proper error if the topic does not exist", func() {
+			_, err := topic.Describe("unknown")
+			Expect(err).To(Equal(awssns.ErrTopicDoesNotExist))
+
+			err = topic.Delete("unknown")
+			Expect(err).To(Equal(awssns.ErrTopicDoesNotExist))
+		})
+	})
+
+	Describe("IAM", func() {
+		It("creates and deletes a user with an access key and a policy", func() {
+			userARN, err := user.Create("user", "/path/")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(userARN).To(Equal("arn:aws:iam::" + DefaultAccountID + ":user/path/user"))
+
+			userDetails, err := user.Describe("user")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(userDetails.UserName).To(Equal("user"))
+			Expect(userDetails.UserARN).To(Equal(userARN))
+
+			accessKeyID, secretAccessKey, err := user.CreateAccessKey("user")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(secretAccessKey).ToNot(BeEmpty())
+
+			accessKeys, err := user.ListAccessKeys("user")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(accessKeys).To(Equal([]string{accessKeyID}))
+
+			policyARN, err := user.CreatePolicy("policy", "Allow", "sqs:*", "arn:aws:sqs:us-east-1:123456789012:queue")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policyARN).To(Equal("arn:aws:iam::" + DefaultAccountID + ":policy/policy"))
+
+			err = user.AttachUserPolicy("user", policyARN)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(emulator.UserPolicyDocuments("user")).To(HaveLen(1))
+			Expect(emulator.UserPolicyDocuments("user")[0]).To(ContainSubstring("sqs:*"))
+
+			attachedPolicies, err := user.ListAttachedUserPolicies("user")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(attachedPolicies).To(Equal([]string{policyARN}))
+
+			err = user.Delete("user")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("DeleteConflict"))
+
+			Expect(user.DetachUserPolicy("user", policyARN)).To(Succeed())
+			Expect(user.DeletePolicy(policyARN)).To(Succeed())
+			Expect(user.DeleteAccessKey("user", accessKeyID)).To(Succeed())
+			Expect(user.Delete("user")).To(Succeed())
+
+			Expect(emulator.UserNames()).To(BeEmpty())
+			Expect(emulator.PolicyNames()).To(BeEmpty())
+		})
+
+		It("returns the proper error if the user does not exist", func() {
+			_, err := user.Describe("unknown")
+			Expect(err).To(Equal(awsiam.ErrUserDoesNotExist))
+		})
+
+		It("simulates the policies of the caller", func() {
+			policySimulator := awsiam.NewIAMPolicySimulator(iamsvc, logger)
+			emulator.DenyAction("iam:AttachUserPolicy")
+
+			callerArn, err := policySimulator.CallerArn()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(callerArn).To(Equal("arn:aws:iam::" + DefaultAccountID + ":user/" + CallerUserName))
+
+			deniedActions, err := policySimulator.DeniedActions(callerArn, []string{"iam:CreateUser", "iam:AttachUserPolicy"}, []string{"user-1", "user-2"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deniedActions).To(Equal([]string{"iam:AttachUserPolicy"}))
+		})
+
+		It("returns an error if the user already exists", func() {
+			_, err := user.Create("user", "/")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = user.Create("user", "/")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("EntityAlreadyExists"))
+		})
+	})
+})
@@ -0,0 +1,431 @@
+package awsemulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type user struct {
+	name             string
+	path             string
+	id               string
+	createDate       time.Time
+	accessKeys       []accessKey
+	attachedPolicies []string
+}
+
+type accessKey struct {
+	id              string
+	secretAccessKey string
+	createDate      time.Time
+}
+
+type policy struct {
+	name        string
+	arn         string
+	id          string
+	path        string
+	document    string
+	createDate  time.Time
+	attachments int
+}
+
+type userEntry struct {
+	Path       string `xml:"Path"`
+	UserName   string `xml:"UserName"`
+	UserID     string `xml:"UserId"`
+	Arn        string `xml:"Arn"`
+	CreateDate string `xml:"CreateDate"`
+}
+
+type userResult struct {
+	User userEntry `xml:"User"`
+}
+
+type accessKeyEntry struct {
+	UserName        string `xml:"UserName"`
+	AccessKeyID     string `xml:"AccessKeyId"`
+	Status          string `xml:"Status"`
+	SecretAccessKey string `xml:"SecretAccessKey,omitempty"`
+	CreateDate      string `xml:"CreateDate"`
+}
+
+type createAccessKeyResult struct {
+	AccessKey accessKeyEntry `xml:"AccessKey"`
+}
+
+type listAccessKeysResult struct {
+	AccessKeyMetadata []accessKeyEntry `xml:"AccessKeyMetadata>member"`
+	IsTruncated       bool             `xml:"IsTruncated"`
+}
+
+type policyEntry struct {
+	PolicyName       string `xml:"PolicyName"`
+	PolicyID         string `xml:"PolicyId"`
+	Arn              string `xml:"Arn"`
+	Path             string `xml:"Path"`
+	DefaultVersionID string `xml:"DefaultVersionId"`
+	AttachmentCount  int    `xml:"AttachmentCount"`
+	IsAttachable     bool   `xml:"IsAttachable"`
+	CreateDate       string `xml:"CreateDate"`
+	UpdateDate       string `xml:"UpdateDate"`
+}
+
+type createPolicyResult struct {
+	Policy policyEntry `xml:"Policy"`
+}
+
+type attachedPolicyEntry struct {
+	PolicyName string `xml:"PolicyName"`
+	PolicyArn  string `xml:"PolicyArn"`
+}
+
+type evaluationResultEntry struct {
+	EvalActionName   string `xml:"EvalActionName"`
+	EvalResourceName string `xml:"EvalResourceName"`
+	EvalDecision     string `xml:"EvalDecision"`
+}
+
+type simulatePolicyResult struct {
+	EvaluationResults []evaluationResultEntry `xml:"EvaluationResults>member"`
+	IsTruncated       bool                    `xml:"IsTruncated"`
+}
+
+type listAttachedUserPoliciesResult struct {
+	AttachedPolicies []attachedPolicyEntry `xml:"AttachedPolicies>member"`
+	IsTruncated      bool                  `xml:"IsTruncated"`
+}
+
+func init() {
+	actions["CreateUser"] = (*Emulator).createUser
+	actions["GetUser"] = (*Emulator).getUser
+	actions["DeleteUser"] = (*Emulator).deleteUser
+	actions["CreateAccessKey"] = (*Emulator).createAccessKey
+	actions["ListAccessKeys"] = (*Emulator).listAccessKeys
+	actions["DeleteAccessKey"] = (*Emulator).deleteAccessKey
+	actions["CreatePolicy"] = (*Emulator).createPolicy
+	actions["DeletePolicy"] = (*Emulator).deletePolicy
+	actions["AttachUserPolicy"] = (*Emulator).attachUserPolicy
+	actions["DetachUserPolicy"] = (*Emulator).detachUserPolicy
+	actions["ListAttachedUserPolicies"] = (*Emulator).listAttachedUserPolicies
+	actions["SimulatePrincipalPolicy"] = (*Emulator).simulatePrincipalPolicy
+}
+
+func (e *Emulator) createUser(req *http.Request, form url.Values) (interface{}, *Error) {
+	name := form.Get("UserName")
+	if name == "" {
+		return nil, validationError("UserName")
+	}
+
+	if _, ok := e.users[name]; ok {
+		return nil, entityAlreadyExists(fmt.Sprintf("User with name %s already exists.", name))
+	}
+
+	path := form.Get("Path")
+	if path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(path, "/") || !strings.HasSuffix(path, "/") {
+		return nil, validationError("Path")
+	}
+
+	u := &user{
+		name:       name,
+		path:       path,
+		id:         "AIDA" + strings.ToUpper(randomID(17)),
+		createDate: time.Now(),
+	}
+	e.users[name] = u
+
+	return userResult{User: e.userEntry(u)}, nil
+}
+
+func (e *Emulator) getUser(req *http.Request, form url.Values) (interface{}, *Error) {
+	// Without a name, IAM returns the user owning the credentials
+	if form.Get("UserName") == "" {
+		return userResult{User: e.userEntry(&user{name: CallerUserName, path: "/", id: CallerUserID})}, nil
+	}
+
+	u, err := e.findUser(form)
+	if err != nil {
+		return nil, err
+	}
+
+	return userResult{User: e.userEntry(u)}, nil
+}
+
+func (e *Emulator) deleteUser(req *http.Request, form url.Values) (interface{}, *Error) {
+	u, err := e.findUser(form)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(u.accessKeys) > 0 || len(u.attachedPolicies) > 0 {
+		return nil, &Error{StatusCode: http.StatusConflict, Code: "DeleteConflict", Message: "Cannot delete entity, must delete access keys and detach policies first."}
+	}
+
+	delete(e.users, u.name)
+
+	return nil, nil
+}
+
+func (e *Emulator) createAccessKey(req *http.Request, form url.Values) (interface{}, *Error) {
+	u, err := e.findUser(form)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(u.accessKeys) >= 2 {
+		return nil, &Error{StatusCode: http.StatusConflict, Code: "LimitExceeded", Message: "Cannot exceed quota for AccessKeysPerUser: 2"}
+	}
+
+	key := accessKey{
+		id:              "AKIA" + strings.ToUpper(randomID(16)),
+		secretAccessKey: randomID(40),
+		createDate:      time.Now(),
+	}
+	u.accessKeys = append(u.accessKeys, key)
+
+	entry := accessKeyEntry{
+		UserName:        u.name,
+		AccessKeyID:     key.id,
+		Status:          "Active",
+		SecretAccessKey: key.secretAccessKey,
+		CreateDate:      timestamp(key.createDate),
+	}
+
+	return createAccessKeyResult{AccessKey: entry}, nil
+}
+
+func (e *Emulator) listAccessKeys(req *http.Request, form url.Values) (interface{}, *Error) {
+	u, err := e.findUser(form)
+	if err != nil {
+		return nil, err
+	}
+
+	result := listAccessKeysResult{}
+	for _, key := range u.accessKeys {
+		result.AccessKeyMetadata = append(result.AccessKeyMetadata, accessKeyEntry{
+			UserName:    u.name,
+			AccessKeyID: key.id,
+			Status:      "Active",
+			CreateDate:  timestamp(key.createDate),
+		})
+	}
+
+	return result, nil
+}
+
+func (e *Emulator) deleteAccessKey(req *http.Request, form url.Values) (interface{}, *Error) {
+	u, err := e.findUser(form)
+	if err != nil {
+		return nil, err
+	}
+
+	accessKeyID := form.Get("AccessKeyId")
+	for i, key := range u.accessKeys {
+		if key.id == accessKeyID {
+			u.accessKeys = append(u.accessKeys[:i], u.accessKeys[i+1:]...)
+			return nil, nil
+		}
+	}
+
+	return nil, noSuchEntity(fmt.Sprintf("The Access Key with id %s cannot be found.", accessKeyID))
+}
+
+func (e *Emulator) createPolicy(req *http.Request, form url.Values) (interface{}, *Error) {
+	name := form.Get("PolicyName")
+	if name == "" {
+		return nil, validationError("PolicyName")
+	}
+
+	var document interface{}
+	if err := json.Unmarshal([]byte(form.Get("PolicyDocument")), &document); err != nil {
+		return nil, &Error{StatusCode: http.StatusBadRequest, Code: "MalformedPolicyDocument", Message: "Syntax errors in policy."}
+	}
+
+	path := form.Get("Path")
+	if path == "" {
+		path = "/"
+	}
+
+	arn := fmt.Sprintf("arn:aws:iam::%s:policy%s%s", e.accountID, path, name)
+	if _, ok := e.policies[arn]; ok {
+		return nil, entityAlreadyExists(fmt.Sprintf("A policy called %s already exists. Duplicate names are not allowed.", name))
+	}
+
+	p := &policy{
+		name:       name,
+		arn:        arn,
+		id:         "ANPA" + strings.ToUpper(randomID(17)),
+		path:       path,
+		document:   form.Get("PolicyDocument"),
+		createDate: time.Now(),
+	}
+	e.policies[arn] = p
+
+	return createPolicyResult{Policy: policyEntry{
+		PolicyName:       p.name,
+		PolicyID:         p.id,
+		Arn:              p.arn,
+		Path:             p.path,
+		DefaultVersionID: "v1",
+		IsAttachable:     true,
+		CreateDate:       timestamp(p.createDate),
+		UpdateDate:       timestamp(p.createDate),
+	}}, nil
+}
+
+func (e *Emulator) deletePolicy(req *http.Request, form url.Values) (interface{}, *Error) {
+	p, err := e.findPolicy(form)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.attachments > 0 {
+		return nil, &Error{StatusCode: http.StatusConflict, Code: "DeleteConflict", Message: "Cannot delete a policy attached to entities."}
+	}
+
+	delete(e.policies, p.arn)
+
+	return nil, nil
+}
+
+func (e *Emulator) attachUserPolicy(req *http.Request, form url.Values) (interface{}, *Error) {
+	u, err := e.findUser(form)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := e.findPolicy(form)
+	if err != nil {
+		return nil, err
+	}
+
+	if !contains(u.attachedPolicies, p.arn) {
+		u.attachedPolicies = append(u.attachedPolicies, p.arn)
+		p.attachments++
+	}
+
+	return nil, nil
+}
+
+func (e *Emulator) detachUserPolicy(req *http.Request, form url.Values) (interface{}, *Error) {
+	u, err := e.findUser(form)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := e.findPolicy(form)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, policyArn := range u.attachedPolicies {
+		if policyArn == p.arn {
+			u.attachedPolicies = append(u.attachedPolicies[:i], u.attachedPolicies[i+1:]...)
+			p.attachments--
+			return nil, nil
+		}
+	}
+
+	return nil, noSuchEntity(fmt.Sprintf("Policy %s was not found.", p.arn))
+}
+
+func (e *Emulator) listAttachedUserPolicies(req *http.Request, form url.Values) (interface{}, *Error) {
+	u, err := e.findUser(form)
+	if err != nil {
+		return nil, err
+	}
+
+	result := listAttachedUserPoliciesResult{}
+	for _, policyArn := range u.attachedPolicies {
+		result.AttachedPolicies = append(result.AttachedPolicies, attachedPolicyEntry{
+			PolicyName: e.policies[policyArn].name,
+			PolicyArn:  policyArn,
+		})
+	}
+
+	return result, nil
+}
+
+// simulatePrincipalPolicy allows every action on every resource, except the actions denied with DenyAction
+func (e *Emulator) simulatePrincipalPolicy(req *http.Request, form url.Values) (interface{}, *Error) {
+	if form.Get("PolicySourceArn") == "" {
+		return nil, validationError("PolicySourceArn")
+	}
+
+	actionNames := values(form, "ActionNames.member")
+	if len(actionNames) == 0 {
+		return nil, validationError("ActionNames")
+	}
+
+	resourceArns := values(form, "ResourceArns.member")
+	if len(resourceArns) == 0 {
+		resourceArns = []string{"*"}
+	}
+
+	result := simulatePolicyResult{}
+	for _, actionName := range actionNames {
+		decision := "allowed"
+		if e.deniedActions[actionName] {
+			decision = "implicitDeny"
+		}
+
+		for _, resourceArn := range resourceArns {
+			result.EvaluationResults = append(result.EvaluationResults, evaluationResultEntry{
+				EvalActionName:   actionName,
+				EvalResourceName: resourceArn,
+				EvalDecision:     decision,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+func (e *Emulator) userEntry(u *user) userEntry {
+	return userEntry{
+		Path:       u.path,
+		UserName:   u.name,
+		UserID:     u.id,
+		Arn:        fmt.Sprintf("arn:aws:iam::%s:user%s%s", e.accountID, u.path, u.name),
+		CreateDate: timestamp(u.createDate),
+	}
+}
+
+func (e *Emulator) findUser(form url.Values) (*user, *Error) {
+	name := form.Get("UserName")
+	u, ok := e.users[name]
+	if !ok {
+		return nil, noSuchEntity(fmt.Sprintf("The user with name %s cannot be found.", name))
+	}
+
+	return u, nil
+}
+
+func (e *Emulator) findPolicy(form url.Values) (*policy, *Error) {
+	arn := form.Get("PolicyArn")
+	p, ok := e.policies[arn]
+	if !ok {
+		return nil, noSuchEntity(fmt.Sprintf("Policy %s was not found.", arn))
+	}
+
+	return p, nil
+}
+
+func noSuchEntity(message string) *Error {
+	return &Error{StatusCode: http.StatusNotFound, Code: "NoSuchEntity", Message: message}
+}
+
+func entityAlreadyExists(message string) *Error {
+	return &Error{StatusCode: http.StatusConflict, Code: "EntityAlreadyExists", Message: message}
+}
+
+func validationError(parameter string) *Error {
+	return &Error{StatusCode: http.StatusBadRequest, Code: "ValidationError", Message: fmt.Sprintf("Invalid value for parameter %s.", parameter)}
+}
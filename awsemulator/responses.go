@@ -0,0 +1,68 @@
+package awsemulator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+)
+
+// Error is an AWS Query API error, returned with its HTTP status code
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+type errorResponse struct {
+	XMLName   xml.Name    `xml:"ErrorResponse"`
+	Error     errorDetail `xml:"Error"`
+	RequestID string      `xml:"RequestId"`
+}
+
+type errorDetail struct {
+	Type    string `xml:"Type"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func writeError(w http.ResponseWriter, err *Error) {
+	errorType := "Sender"
+	if err.StatusCode >= 500 {
+		errorType = "Receiver"
+	}
+
+	body, marshalErr := xml.Marshal(errorResponse{
+		Error: errorDetail{
+			Type:    errorType,
+			Code:    err.Code,
+			Message: err.Message,
+		},
+		RequestID: randomID(32),
+	})
+	if marshalErr != nil {
+		http.Error(w, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(err.StatusCode)
+	w.Write(body)
+}
+
+// writeResult wraps a result in the <Action>Response and <Action>Result elements expected by the AWS SDK
+func writeResult(w http.ResponseWriter, action string, result interface{}) {
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "<%sResponse>", action)
+	if result != nil {
+		encoder := xml.NewEncoder(w)
+		encoder.EncodeElement(result, xml.StartElement{Name: xml.Name{Local: action + "Result"}})
+		encoder.Flush()
+	}
+	fmt.Fprintf(w, "<ResponseMetadata><RequestId>%s</RequestId></ResponseMetadata></%sResponse>", randomID(32), action)
+}
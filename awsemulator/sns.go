@@ -0,0 +1,248 @@
+package awsemulator
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var topicNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,256}$`)
+
+// The attributes that can be set on a topic and a subscription, one per SetTopicAttributes or SetSubscriptionAttributes call
+var (
+	topicAttributeNames        = []string{"DisplayName", "Policy", "DeliveryPolicy"}
+	subscriptionAttributeNames = []string{"RawMessageDelivery", "FilterPolicy"}
+)
+
+type topic struct {
+	name       string
+	arn        string
+	attributes map[string]string
+}
+
+type subscription struct {
+	arn        string
+	topicArn   string
+	protocol   string
+	endpoint   string
+	attributes map[string]string
+}
+
+type createTopicResult struct {
+	TopicArn string `xml:"TopicArn"`
+}
+
+type listTopicsResult struct {
+	Topics []topicEntry `xml:"Topics>member"`
+}
+
+type topicEntry struct {
+	TopicArn string `xml:"TopicArn"`
+}
+
+type getTopicAttributesResult struct {
+	Attributes []mapEntry `xml:"Attributes>entry"`
+}
+
+type mapEntry struct {
+	Key   string `xml:"key"`
+	Value string `xml:"value"`
+}
+
+type subscribeResult struct {
+	SubscriptionArn string `xml:"SubscriptionArn"`
+}
+
+func init() {
+	actions["CreateTopic"] = (*Emulator).createTopic
+	actions["ListTopics"] = (*Emulator).listTopics
+	actions["GetTopicAttributes"] = (*Emulator).getTopicAttributes
+	actions["SetTopicAttributes"] = (*Emulator).setTopicAttributes
+	actions["DeleteTopic"] = (*Emulator).deleteTopic
+	actions["Subscribe"] = (*Emulator).subscribe
+	actions["SetSubscriptionAttributes"] = (*Emulator).setSubscriptionAttributes
+	actions["Unsubscribe"] = (*Emulator).unsubscribe
+}
+
+func (e *Emulator) createTopic(req *http.Request, form url.Values) (interface{}, *Error) {
+	name := form.Get("Name")
+	if !topicNamePattern.MatchString(name) {
+		return nil, &Error{StatusCode: http.StatusBadRequest, Code: "InvalidParameter", Message: "Invalid parameter: Topic Name"}
+	}
+
+	// Creating an existing topic returns its ARN
+	if t, ok := e.topics[name]; ok {
+		return createTopicResult{TopicArn: t.arn}, nil
+	}
+
+	t := &topic{
+		name:       name,
+		arn:        fmt.Sprintf("arn:aws:sns:%s:%s:%s", e.region, e.accountID, name),
+		attributes: map[string]string{},
+	}
+	e.topics[name] = t
+
+	return createTopicResult{TopicArn: t.arn}, nil
+}
+
+func (e *Emulator) listTopics(req *http.Request, form url.Values) (interface{}, *Error) {
+	arns := []string{}
+	for _, t := range e.topics {
+		arns = append(arns, t.arn)
+	}
+	sort.Strings(arns)
+
+	result := listTopicsResult{}
+	for _, arn := range arns {
+		result.Topics = append(result.Topics, topicEntry{TopicArn: arn})
+	}
+
+	return result, nil
+}
+
+func (e *Emulator) getTopicAttributes(req *http.Request, form url.Values) (interface{}, *Error) {
+	t, err := e.findTopic(form.Get("TopicArn"))
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := map[string]string{
+		"TopicArn":                t.arn,
+		"Owner":                   e.accountID,
+		"SubscriptionsConfirmed":  strconv.Itoa(len(e.topicSubscriptions(t.arn))),
+		"SubscriptionsPending":    "0",
+		"SubscriptionsDeleted":    "0",
+		"EffectiveDeliveryPolicy": `{"http":{"defaultHealthyRetryPolicy":{"numRetries":3}}}`,
+	}
+	for name, value := range t.attributes {
+		attributes[name] = value
+	}
+
+	names := []string{}
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := getTopicAttributesResult{}
+	for _, name := range names {
+		result.Attributes = append(result.Attributes, mapEntry{Key: name, Value: attributes[name]})
+	}
+
+	return result, nil
+}
+
+func (e *Emulator) setTopicAttributes(req *http.Request, form url.Values) (interface{}, *Error) {
+	t, err := e.findTopic(form.Get("TopicArn"))
+	if err != nil {
+		return nil, err
+	}
+
+	name := form.Get("AttributeName")
+	if !contains(topicAttributeNames, name) {
+		return nil, &Error{StatusCode: http.StatusBadRequest, Code: "InvalidParameter", Message: "Invalid parameter: AttributeName"}
+	}
+	t.attributes[name] = form.Get("AttributeValue")
+
+	return nil, nil
+}
+
+func (e *Emulator) deleteTopic(req *http.Request, form url.Values) (interface{}, *Error) {
+	t, err := e.findTopic(form.Get("TopicArn"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Deleting a topic also deletes its subscriptions
+	for _, s := range e.topicSubscriptions(t.arn) {
+		delete(e.subscriptions, s.arn)
+	}
+	delete(e.topics, t.name)
+
+	return nil, nil
+}
+
+func (e *Emulator) subscribe(req *http.Request, form url.Values) (interface{}, *Error) {
+	t, err := e.findTopic(form.Get("TopicArn"))
+	if err != nil {
+		return nil, err
+	}
+
+	protocol := form.Get("Protocol")
+	endpoint := form.Get("Endpoint")
+	if protocol != "sqs" || !strings.HasPrefix(endpoint, "arn:aws:sqs:") {
+		return nil, &Error{StatusCode: http.StatusBadRequest, Code: "InvalidParameter", Message: "Invalid parameter: only SQS queue ARN endpoints are supported"}
+	}
+
+	// Subscribing the same endpoint again returns the existing subscription
+	for _, s := range e.topicSubscriptions(t.arn) {
+		if s.protocol == protocol && s.endpoint == endpoint {
+			return subscribeResult{SubscriptionArn: s.arn}, nil
+		}
+	}
+
+	s := &subscription{
+		arn:        fmt.Sprintf("%s:%s", t.arn, randomUUID()),
+		topicArn:   t.arn,
+		protocol:   protocol,
+		endpoint:   endpoint,
+		attributes: map[string]string{},
+	}
+	e.subscriptions[s.arn] = s
+
+	return subscribeResult{SubscriptionArn: s.arn}, nil
+}
+
+func (e *Emulator) setSubscriptionAttributes(req *http.Request, form url.Values) (interface{}, *Error) {
+	s, ok := e.subscriptions[form.Get("SubscriptionArn")]
+	if !ok {
+		return nil, notFound("Subscription does not exist")
+	}
+
+	name := form.Get("AttributeName")
+	if !contains(subscriptionAttributeNames, name) {
+		return nil, &Error{StatusCode: http.StatusBadRequest, Code: "InvalidParameter", Message: "Invalid parameter: AttributeName"}
+	}
+	s.attributes[name] = form.Get("AttributeValue")
+
+	return nil, nil
+}
+
+func (e *Emulator) unsubscribe(req *http.Request, form url.Values) (interface{}, *Error) {
+	subscriptionArn := form.Get("SubscriptionArn")
+	if _, ok := e.subscriptions[subscriptionArn]; !ok {
+		return nil, notFound("Subscription does not exist")
+	}
+
+	delete(e.subscriptions, subscriptionArn)
+
+	return nil, nil
+}
+
+func (e *Emulator) findTopic(topicArn string) (*topic, *Error) {
+	t, ok := e.topics[topicArn[strings.LastIndex(topicArn, ":")+1:]]
+	if !ok || t.arn != topicArn {
+		return nil, notFound("Topic does not exist")
+	}
+
+	return t, nil
+}
+
+func (e *Emulator) topicSubscriptions(topicArn string) []*subscription {
+	subscriptions := []*subscription{}
+	for _, s := range e.subscriptions {
+		if s.topicArn == topicArn {
+			subscriptions = append(subscriptions, s)
+		}
+	}
+
+	return subscriptions
+}
+
+func notFound(message string) *Error {
+	return &Error{StatusCode: http.StatusNotFound, Code: "NotFound", Message: message}
+}
@@ -0,0 +1,500 @@
+package awsemulator
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const errNonExistentQueue = "AWS.SimpleQueueService.NonExistentQueue"
+
+var queueNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,80}$`)
+
+type attributeRange struct {
+	defaultValue string
+	min          int
+	max          int
+}
+
+// The numeric attributes that can be set on a queue, with their AWS defaults and limits
+var queueAttributeRanges = map[string]attributeRange{
+	"DelaySeconds":                  {"0", 0, 900},
+	"MaximumMessageSize":            {"262144", 1024, 262144},
+	"MessageRetentionPeriod":        {"345600", 60, 1209600},
+	"ReceiveMessageWaitTimeSeconds": {"0", 0, 20},
+	"VisibilityTimeout":             {"30", 0, 43200},
+}
+
+type queue struct {
+	name       string
+	attributes map[string]string
+	messages   []*message
+}
+
+type message struct {
+	id            string
+	body          string
+	attributes    []messageAttributeEntry
+	receiptHandle string
+	received      bool
+	visibleAt     time.Time
+}
+
+type createQueueResult struct {
+	QueueURL string `xml:"QueueUrl"`
+}
+
+type getQueueURLResult struct {
+	QueueURL string `xml:"QueueUrl"`
+}
+
+type getQueueAttributesResult struct {
+	Attributes []attributeEntry `xml:"Attribute"`
+}
+
+type attributeEntry struct {
+	Name  string `xml:"Name"`
+	Value string `xml:"Value"`
+}
+
+type sendMessageBatchResult struct {
+	Successful []sendMessageBatchResultEntry `xml:"SendMessageBatchResultEntry"`
+	Failed     []batchResultErrorEntry       `xml:"BatchResultErrorEntry"`
+}
+
+type sendMessageBatchResultEntry struct {
+	ID               string `xml:"Id"`
+	MessageID        string `xml:"MessageId"`
+	MD5OfMessageBody string `xml:"MD5OfMessageBody"`
+}
+
+type deleteMessageBatchResult struct {
+	Successful []deleteMessageBatchResultEntry `xml:"DeleteMessageBatchResultEntry"`
+	Failed     []batchResultErrorEntry         `xml:"BatchResultErrorEntry"`
+}
+
+type deleteMessageBatchResultEntry struct {
+	ID string `xml:"Id"`
+}
+
+type batchResultErrorEntry struct {
+	ID          string `xml:"Id"`
+	SenderFault bool   `xml:"SenderFault"`
+	Code        string `xml:"Code"`
+	Message     string `xml:"Message"`
+}
+
+type receiveMessageResult struct {
+	Messages []messageEntry `xml:"Message"`
+}
+
+type messageEntry struct {
+	MessageID         string                  `xml:"MessageId"`
+	ReceiptHandle     string                  `xml:"ReceiptHandle"`
+	MD5OfBody         string                  `xml:"MD5OfBody"`
+	Body              string                  `xml:"Body"`
+	MessageAttributes []messageAttributeEntry `xml:"MessageAttribute"`
+}
+
+type messageAttributeEntry struct {
+	Name  string                `xml:"Name"`
+	Value messageAttributeValue `xml:"Value"`
+}
+
+type messageAttributeValue struct {
+	DataType    string `xml:"DataType"`
+	StringValue string `xml:"StringValue,omitempty"`
+	BinaryValue string `xml:"BinaryValue,omitempty"`
+}
+
+func init() {
+	actions["CreateQueue"] = (*Emulator).createQueue
+	actions["GetQueueUrl"] = (*Emulator).getQueueURL
+	actions["GetQueueAttributes"] = (*Emulator).getQueueAttributes
+	actions["SetQueueAttributes"] = (*Emulator).setQueueAttributes
+	actions["DeleteQueue"] = (*Emulator).deleteQueue
+	actions["SendMessageBatch"] = (*Emulator).sendMessageBatch
+	actions["ReceiveMessage"] = (*Emulator).receiveMessage
+	actions["DeleteMessageBatch"] = (*Emulator).deleteMessageBatch
+}
+
+func (e *Emulator) createQueue(req *http.Request, form url.Values) (interface{}, *Error) {
+	name := form.Get("QueueName")
+	if !queueNamePattern.MatchString(name) {
+		return nil, invalidParameterValue("Can only include alphanumeric characters, hyphens, or underscores. 1 to 80 in length")
+	}
+
+	attributes, err := queueAttributes(form)
+	if err != nil {
+		return nil, err
+	}
+
+	if q, ok := e.queues[name]; ok {
+		for attributeName, value := range attributes {
+			if q.attributes[attributeName] != value {
+				return nil, &Error{StatusCode: http.StatusBadRequest, Code: "QueueAlreadyExists", Message: fmt.Sprintf("A queue already exists with the same name and a different value for attribute %s", attributeName)}
+			}
+		}
+		return createQueueResult{QueueURL: e.queueURL(req, name)}, nil
+	}
+
+	now := time.Now()
+	q := &queue{
+		name: name,
+		attributes: map[string]string{
+			"QueueArn":              fmt.Sprintf("arn:aws:sqs:%s:%s:%s", e.region, e.accountID, name),
+			"CreatedTimestamp":      strconv.FormatInt(now.Unix(), 10),
+			"LastModifiedTimestamp": strconv.FormatInt(now.Unix(), 10),
+		},
+	}
+	for attributeName, attributeRange := range queueAttributeRanges {
+		q.attributes[attributeName] = attributeRange.defaultValue
+	}
+	q.setAttributes(attributes, now)
+	e.queues[name] = q
+
+	return createQueueResult{QueueURL: e.queueURL(req, name)}, nil
+}
+
+func (e *Emulator) getQueueURL(req *http.Request, form url.Values) (interface{}, *Error) {
+	name := form.Get("QueueName")
+	if _, ok := e.queues[name]; !ok {
+		return nil, nonExistentQueue()
+	}
+
+	return getQueueURLResult{QueueURL: e.queueURL(req, name)}, nil
+}
+
+func (e *Emulator) getQueueAttributes(req *http.Request, form url.Values) (interface{}, *Error) {
+	q, err := e.findQueue(form)
+	if err != nil {
+		return nil, err
+	}
+
+	names := values(form, "AttributeName")
+	all := false
+	for _, name := range names {
+		all = all || name == "All"
+	}
+
+	result := getQueueAttributesResult{}
+	for name, value := range q.allAttributes(time.Now()) {
+		if all || contains(names, name) {
+			result.Attributes = append(result.Attributes, attributeEntry{Name: name, Value: value})
+		}
+	}
+	sort.Sort(attributeEntries(result.Attributes))
+
+	return result, nil
+}
+
+func (e *Emulator) setQueueAttributes(req *http.Request, form url.Values) (interface{}, *Error) {
+	q, err := e.findQueue(form)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := queueAttributes(form)
+	if err != nil {
+		return nil, err
+	}
+	q.setAttributes(attributes, time.Now())
+
+	return nil, nil
+}
+
+func (e *Emulator) deleteQueue(req *http.Request, form url.Values) (interface{}, *Error) {
+	q, err := e.findQueue(form)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(e.queues, q.name)
+
+	return nil, nil
+}
+
+func (e *Emulator) sendMessageBatch(req *http.Request, form url.Values) (interface{}, *Error) {
+	q, err := e.findQueue(form)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := members(form, "SendMessageBatchRequestEntry")
+	if err := checkBatchSize(len(entries)); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	maximumMessageSize, _ := strconv.Atoi(q.attributes["MaximumMessageSize"])
+	delaySeconds, _ := strconv.Atoi(q.attributes["DelaySeconds"])
+
+	result := sendMessageBatchResult{}
+	for _, entry := range entries {
+		body := entry.Get("MessageBody")
+		if len(body) > maximumMessageSize {
+			result.Failed = append(result.Failed, batchResultErrorEntry{
+				ID:          entry.Get("Id"),
+				SenderFault: true,
+				Code:        "InvalidParameterValue",
+				Message:     fmt.Sprintf("Message must be shorter than %d bytes", maximumMessageSize),
+			})
+			continue
+		}
+
+		delay := delaySeconds
+		if entryDelay := entry.Get("DelaySeconds"); entryDelay != "" {
+			delay, _ = strconv.Atoi(entryDelay)
+		}
+
+		m := &message{
+			id:        randomUUID(),
+			body:      body,
+			visibleAt: now.Add(time.Duration(delay) * time.Second),
+		}
+		for _, attribute := range members(entry, "MessageAttribute") {
+			m.attributes = append(m.attributes, messageAttributeEntry{
+				Name: attribute.Get("Name"),
+				Value: messageAttributeValue{
+					DataType:    attribute.Get("Value.DataType"),
+					StringValue: attribute.Get("Value.StringValue"),
+					BinaryValue: attribute.Get("Value.BinaryValue"),
+				},
+			})
+		}
+		q.messages = append(q.messages, m)
+
+		result.Successful = append(result.Successful, sendMessageBatchResultEntry{
+			ID:               entry.Get("Id"),
+			MessageID:        m.id,
+			MD5OfMessageBody: md5Hex(body),
+		})
+	}
+
+	return result, nil
+}
+
+func (e *Emulator) receiveMessage(req *http.Request, form url.Values) (interface{}, *Error) {
+	q, err := e.findQueue(form)
+	if err != nil {
+		return nil, err
+	}
+
+	maxNumberOfMessages := 1
+	if value := form.Get("MaxNumberOfMessages"); value != "" {
+		maxNumberOfMessages, _ = strconv.Atoi(value)
+		if maxNumberOfMessages < 1 || maxNumberOfMessages > 10 {
+			return nil, invalidParameterValue("Value for parameter MaxNumberOfMessages is invalid. Reason: Must be between 1 and 10")
+		}
+	}
+
+	visibilityTimeout, _ := strconv.Atoi(q.attributes["VisibilityTimeout"])
+	if value := form.Get("VisibilityTimeout"); value != "" {
+		visibilityTimeout, _ = strconv.Atoi(value)
+	}
+	attributeNames := values(form, "MessageAttributeName")
+
+	now := time.Now()
+	result := receiveMessageResult{}
+	for _, m := range q.messages {
+		if len(result.Messages) == maxNumberOfMessages {
+			break
+		}
+		if m.visibleAt.After(now) {
+			continue
+		}
+
+		m.received = true
+		m.receiptHandle = randomID(64)
+		m.visibleAt = now.Add(time.Duration(visibilityTimeout) * time.Second)
+
+		entry := messageEntry{
+			MessageID:     m.id,
+			ReceiptHandle: m.receiptHandle,
+			MD5OfBody:     md5Hex(m.body),
+			Body:          m.body,
+		}
+		for _, attribute := range m.attributes {
+			if contains(attributeNames, "All") || contains(attributeNames, ".*") || contains(attributeNames, attribute.Name) {
+				entry.MessageAttributes = append(entry.MessageAttributes, attribute)
+			}
+		}
+		result.Messages = append(result.Messages, entry)
+	}
+
+	return result, nil
+}
+
+func (e *Emulator) deleteMessageBatch(req *http.Request, form url.Values) (interface{}, *Error) {
+	q, err := e.findQueue(form)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := members(form, "DeleteMessageBatchRequestEntry")
+	if err := checkBatchSize(len(entries)); err != nil {
+		return nil, err
+	}
+
+	result := deleteMessageBatchResult{}
+	for _, entry := range entries {
+		if !q.deleteMessage(entry.Get("ReceiptHandle")) {
+			result.Failed = append(result.Failed, batchResultErrorEntry{
+				ID:          entry.Get("Id"),
+				SenderFault: true,
+				Code:        "ReceiptHandleIsInvalid",
+				Message:     fmt.Sprintf("The input receipt handle \"%s\" is not a valid receipt handle.", entry.Get("ReceiptHandle")),
+			})
+			continue
+		}
+
+		result.Successful = append(result.Successful, deleteMessageBatchResultEntry{ID: entry.Get("Id")})
+	}
+
+	return result, nil
+}
+
+func (e *Emulator) queueURL(req *http.Request, name string) string {
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s/%s/%s", scheme, req.Host, e.accountID, name)
+}
+
+// findQueue finds the queue of the QueueUrl parameter by the last segment of its path
+func (e *Emulator) findQueue(form url.Values) (*queue, *Error) {
+	queueURL := form.Get("QueueUrl")
+	q, ok := e.queues[queueURL[strings.LastIndex(queueURL, "/")+1:]]
+	if !ok {
+		return nil, nonExistentQueue()
+	}
+
+	return q, nil
+}
+
+func (q *queue) setAttributes(attributes map[string]string, now time.Time) {
+	for name, value := range attributes {
+		if name == "Policy" && value == "" {
+			delete(q.attributes, name)
+			continue
+		}
+		q.attributes[name] = value
+	}
+	q.attributes["LastModifiedTimestamp"] = strconv.FormatInt(now.Unix(), 10)
+}
+
+func (q *queue) allAttributes(now time.Time) map[string]string {
+	attributes := map[string]string{}
+	for name, value := range q.attributes {
+		attributes[name] = value
+	}
+
+	visible, notVisible, delayed := 0, 0, 0
+	for _, m := range q.messages {
+		switch {
+		case !m.visibleAt.After(now):
+			visible++
+		case m.received:
+			notVisible++
+		default:
+			delayed++
+		}
+	}
+	attributes["ApproximateNumberOfMessages"] = strconv.Itoa(visible)
+	attributes["ApproximateNumberOfMessagesNotVisible"] = strconv.Itoa(notVisible)
+	attributes["ApproximateNumberOfMessagesDelayed"] = strconv.Itoa(delayed)
+
+	return attributes
+}
+
+func (q *queue) deleteMessage(receiptHandle string) bool {
+	for i, m := range q.messages {
+		if m.received && m.receiptHandle == receiptHandle {
+			q.messages = append(q.messages[:i], q.messages[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// queueAttributes reads and validates the Attribute.N.Name/Attribute.N.Value parameters
+func queueAttributes(form url.Values) (map[string]string, *Error) {
+	attributes := map[string]string{}
+	for _, attribute := range members(form, "Attribute") {
+		name := attribute.Get("Name")
+		value := attribute.Get("Value")
+
+		if name == "Policy" {
+			attributes[name] = value
+			continue
+		}
+
+		attributeRange, ok := queueAttributeRanges[name]
+		if !ok {
+			return nil, &Error{StatusCode: http.StatusBadRequest, Code: "InvalidAttributeName", Message: fmt.Sprintf("Unknown Attribute %s.", name)}
+		}
+
+		number, err := strconv.Atoi(value)
+		if err != nil || number < attributeRange.min || number > attributeRange.max {
+			return nil, &Error{StatusCode: http.StatusBadRequest, Code: "InvalidAttributeValue", Message: fmt.Sprintf("Invalid value for the parameter %s.", name)}
+		}
+		attributes[name] = value
+	}
+
+	return attributes, nil
+}
+
+func checkBatchSize(size int) *Error {
+	switch {
+	case size == 0:
+		return &Error{StatusCode: http.StatusBadRequest, Code: "AWS.SimpleQueueService.EmptyBatchRequest", Message: "There should be at least one entry in the request."}
+	case size > 10:
+		return &Error{StatusCode: http.StatusBadRequest, Code: "AWS.SimpleQueueService.TooManyEntriesInBatchRequest", Message: "Maximum number of entries per request are 10."}
+	}
+
+	return nil
+}
+
+func nonExistentQueue() *Error {
+	return &Error{StatusCode: http.StatusBadRequest, Code: errNonExistentQueue, Message: "The specified queue does not exist for this wsdl version."}
+}
+
+func invalidParameterValue(message string) *Error {
+	return &Error{StatusCode: http.StatusBadRequest, Code: "InvalidParameterValue", Message: message}
+}
+
+func md5Hex(body string) string {
+	sum := md5.Sum([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomUUID() string {
+	id := randomID(32)
+	return fmt.Sprintf("%s-%s-%s-%s-%s", id[0:8], id[8:12], id[12:16], id[16:20], id[20:32])
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+type attributeEntries []attributeEntry
+
+func (a attributeEntries) Len() int           { return len(a) }
+func (a attributeEntries) Less(i, j int) bool { return a[i].Name < a[j].Name }
+func (a attributeEntries) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
@@ -0,0 +1,36 @@
+package fakes
+
+type FakePolicySimulator struct {
+	CallerArnCalled bool
+	CallerArnArn    string
+	CallerArnError  error
+
+	DeniedActionsCalled        bool
+	DeniedActionsPrincipalArn  string
+	DeniedActionsActions       [][]string
+	DeniedActionsResourceArns  [][]string
+	DeniedActionsDeniedActions map[string]bool
+	DeniedActionsError         error
+}
+
+func (f *FakePolicySimulator) CallerArn() (string, error) {
+	f.CallerArnCalled = true
+
+	return f.CallerArnArn, f.CallerArnError
+}
+
+func (f *FakePolicySimulator) DeniedActions(principalArn string, actions []string, resourceArns []string) ([]string, error) {
+	f.DeniedActionsCalled = true
+	f.DeniedActionsPrincipalArn = principalArn
+	f.DeniedActionsActions = append(f.DeniedActionsActions, actions)
+	f.DeniedActionsResourceArns = append(f.DeniedActionsResourceArns, resourceArns)
+
+	deniedActions := []string{}
+	for _, action := range actions {
+		if f.DeniedActionsDeniedActions[action] {
+			deniedActions = append(deniedActions, action)
+		}
+	}
+
+	return deniedActions, f.DeniedActionsError
+}
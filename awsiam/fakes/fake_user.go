@@ -12,6 +12,7 @@ type FakeUser struct {
 
 	CreateCalled   bool
 	CreateUserName string
+	CreateUserPath string
 	CreateUserARN  string
 	CreateError    error
 
@@ -70,9 +71,10 @@ func (f *FakeUser) Describe(userName string) (awsiam.UserDetails, error) {
 	return f.DescribeUserDetails, f.DescribeError
 }
 
-func (f *FakeUser) Create(userName string) (string, error) {
+func (f *FakeUser) Create(userName string, userPath string) (string, error) {
 	f.CreateCalled = true
 	f.CreateUserName = userName
+	f.CreateUserPath = userPath
 
 	return f.CreateUserARN, f.CreateError
 }
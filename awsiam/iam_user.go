@@ -8,6 +8,8 @@ import (
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/sqs-broker/brokerlog"
 )
 
 type UserPolicy struct {
@@ -52,6 +54,12 @@ func (i *IAMUser) Describe(userName string) (UserDetails, error) {
 	if err != nil {
 		i.logger.Error("aws-iam-error", err)
 		if awsErr, ok := err.(awserr.Error); ok {
+			if reqErr, ok := err.(awserr.RequestFailure); ok {
+				// AWS IAM returns a 404 if User is not found
+				if reqErr.StatusCode() == 404 {
+					return userDetails, ErrUserDoesNotExist
+				}
+			}
 			return userDetails, errors.New(awsErr.Code() + ": " + awsErr.Message())
 		}
 		return userDetails, err
@@ -64,10 +72,13 @@ func (i *IAMUser) Describe(userName string) (UserDetails, error) {
 	return userDetails, nil
 }
 
-func (i *IAMUser) Create(userName string) (string, error) {
+func (i *IAMUser) Create(userName string, userPath string) (string, error) {
 	createUserInput := &iam.CreateUserInput{
 		UserName: aws.String(userName),
 	}
+	if userPath != "" {
+		createUserInput.Path = aws.String(userPath)
+	}
 	i.logger.Debug("create-user", lager.Data{"input": createUserInput})
 
 	createUserOutput, err := i.iamsvc.CreateUser(createUserInput)
@@ -141,7 +152,7 @@ func (i *IAMUser) CreateAccessKey(userName string) (string, string, error) {
 		}
 		return "", "", err
 	}
-	i.logger.Debug("create-access-key", lager.Data{"output": createAccessKeyOutput})
+	i.logger.Debug("create-access-key", lager.Data{"output": redactCreateAccessKeyOutput(createAccessKeyOutput)})
 
 	return aws.StringValue(createAccessKeyOutput.AccessKey.AccessKeyId), aws.StringValue(createAccessKeyOutput.AccessKey.SecretAccessKey), nil
 }
@@ -296,3 +307,15 @@ func (i *IAMUser) buildUserPolicy(policyID string, effect string, action string,
 
 	return string(policy), nil
 }
+
+// redactCreateAccessKeyOutput returns a copy of the output that can be logged, without the secret access key
+func redactCreateAccessKeyOutput(createAccessKeyOutput *iam.CreateAccessKeyOutput) *iam.CreateAccessKeyOutput {
+	if createAccessKeyOutput.AccessKey == nil {
+		return createAccessKeyOutput
+	}
+
+	accessKey := *createAccessKeyOutput.AccessKey
+	accessKey.SecretAccessKey = aws.String(brokerlog.RedactedValue)
+
+	return &iam.CreateAccessKeyOutput{AccessKey: &accessKey}
+}
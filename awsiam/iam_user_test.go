@@ -116,6 +116,19 @@ var _ = Describe("IAM User", func() {
 					Expect(err.Error()).To(Equal("code: message"))
 				})
 			})
+
+			Context("and it is a 404 error", func() {
+				BeforeEach(func() {
+					awsError := awserr.New("code", "message", errors.New("operation failed"))
+					getUserError = awserr.NewRequestFailure(awsError, 404, "request-id")
+				})
+
+				It("returns the proper error", func() {
+					_, err := user.Describe(userName)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(ErrUserDoesNotExist))
+				})
+			})
 		})
 	})
 
@@ -149,18 +162,30 @@ var _ = Describe("IAM User", func() {
 		})
 
 		It("creates the User", func() {
-			userARN, err := user.Create(userName)
+			userARN, err := user.Create(userName, "")
 			Expect(userARN).To(Equal("user-arn"))
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		Context("when has a User Path", func() {
+			BeforeEach(func() {
+				createUserInput.Path = aws.String("/user/path/")
+			})
+
+			It("creates the User with the Path", func() {
+				userARN, err := user.Create(userName, "/user/path/")
+				Expect(userARN).To(Equal("user-arn"))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
 		Context("when creating the User fails", func() {
 			BeforeEach(func() {
 				createUserError = errors.New("operation failed")
 			})
 
 			It("returns the proper error", func() {
-				_, err := user.Create(userName)
+				_, err := user.Create(userName, "")
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("operation failed"))
 			})
@@ -171,7 +196,7 @@ var _ = Describe("IAM User", func() {
 				})
 
 				It("returns the proper error", func() {
-					_, err := user.Create(userName)
+					_, err := user.Create(userName, "")
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(Equal("code: message"))
 				})
@@ -345,6 +370,13 @@ var _ = Describe("IAM User", func() {
 			Expect(secretAccessKey).To(Equal("secret-access-key"))
 		})
 
+		It("does not log the Secret Access Key", func() {
+			_, _, err := user.CreateAccessKey(userName)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testSink.Buffer().Contents()).ToNot(ContainSubstring("secret-access-key"))
+			Expect(testSink.Buffer().Contents()).To(ContainSubstring("access-key-id"))
+		})
+
 		Context("when creating the Access Key fails", func() {
 			BeforeEach(func() {
 				createAccessKeyError = errors.New("operation failed")
@@ -0,0 +1,92 @@
+package awsiam
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/pivotal-golang/lager"
+)
+
+const allowedDecision = "allowed"
+
+type PolicySimulator interface {
+	CallerArn() (string, error)
+	DeniedActions(principalArn string, actions []string, resourceArns []string) ([]string, error)
+}
+
+type IAMPolicySimulator struct {
+	iamsvc *iam.IAM
+	logger lager.Logger
+}
+
+func NewIAMPolicySimulator(
+	iamsvc *iam.IAM,
+	logger lager.Logger,
+) *IAMPolicySimulator {
+	return &IAMPolicySimulator{
+		iamsvc: iamsvc,
+		logger: logger.Session("iam-policy-simulator"),
+	}
+}
+
+// CallerArn returns the ARN of the IAM user owning the credentials
+func (s *IAMPolicySimulator) CallerArn() (string, error) {
+	getUserInput := &iam.GetUserInput{}
+	s.logger.Debug("get-user", lager.Data{"input": getUserInput})
+
+	getUserOutput, err := s.iamsvc.GetUser(getUserInput)
+	if err != nil {
+		s.logger.Error("aws-iam-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			return "", errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return "", err
+	}
+	s.logger.Debug("get-user", lager.Data{"output": getUserOutput})
+
+	return aws.StringValue(getUserOutput.User.Arn), nil
+}
+
+// DeniedActions simulates the policies of the principal, and returns the actions not allowed on every resource
+func (s *IAMPolicySimulator) DeniedActions(principalArn string, actions []string, resourceArns []string) ([]string, error) {
+	deniedActions := []string{}
+	denied := map[string]bool{}
+
+	simulatePrincipalPolicyInput := &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principalArn),
+		ActionNames:     aws.StringSlice(actions),
+	}
+	if len(resourceArns) > 0 {
+		simulatePrincipalPolicyInput.ResourceArns = aws.StringSlice(resourceArns)
+	}
+
+	for {
+		s.logger.Debug("simulate-principal-policy", lager.Data{"input": simulatePrincipalPolicyInput})
+
+		simulatePolicyResponse, err := s.iamsvc.SimulatePrincipalPolicy(simulatePrincipalPolicyInput)
+		if err != nil {
+			s.logger.Error("aws-iam-error", err)
+			if awsErr, ok := err.(awserr.Error); ok {
+				return deniedActions, errors.New(awsErr.Code() + ": " + awsErr.Message())
+			}
+			return deniedActions, err
+		}
+		s.logger.Debug("simulate-principal-policy", lager.Data{"output": simulatePolicyResponse})
+
+		// Results are returned per action and resource, an action is denied if any of its resources is
+		for _, result := range simulatePolicyResponse.EvaluationResults {
+			action := aws.StringValue(result.EvalActionName)
+			if aws.StringValue(result.EvalDecision) != allowedDecision && !denied[action] {
+				denied[action] = true
+				deniedActions = append(deniedActions, action)
+			}
+		}
+
+		if !aws.BoolValue(simulatePolicyResponse.IsTruncated) {
+			return deniedActions, nil
+		}
+		simulatePrincipalPolicyInput.Marker = simulatePolicyResponse.Marker
+	}
+}
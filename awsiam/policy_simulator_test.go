@@ -0,0 +1,143 @@
+package awsiam_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/sqs-broker/awsiam"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("IAM Policy Simulator", func() {
+	var (
+		awsSession *session.Session
+		iamsvc     *iam.IAM
+
+		policySimulator PolicySimulator
+	)
+
+	JustBeforeEach(func() {
+		awsSession = session.New(nil)
+		iamsvc = iam.New(awsSession)
+
+		logger := lager.NewLogger("iampolicysimulator_test")
+		logger.RegisterSink(lagertest.NewTestSink())
+
+		policySimulator = NewIAMPolicySimulator(iamsvc, logger)
+	})
+
+	var _ = Describe("CallerArn", func() {
+		var (
+			getUserError error
+		)
+
+		BeforeEach(func() {
+			getUserError = nil
+		})
+
+		JustBeforeEach(func() {
+			iamsvc.Handlers.Clear()
+			iamsvc.Handlers.Send.PushBack(func(r *request.Request) {
+				Expect(r.Operation.Name).To(Equal("GetUser"))
+				Expect(r.Params).To(Equal(&iam.GetUserInput{}))
+				data := r.Data.(*iam.GetUserOutput)
+				data.User = &iam.User{Arn: aws.String("user-arn")}
+				r.Error = getUserError
+			})
+		})
+
+		It("returns the ARN of the User owning the credentials", func() {
+			callerArn, err := policySimulator.CallerArn()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(callerArn).To(Equal("user-arn"))
+		})
+
+		Context("when getting the User fails", func() {
+			BeforeEach(func() {
+				getUserError = awserr.New("code", "message", errors.New("operation failed"))
+			})
+
+			It("returns the proper error", func() {
+				_, err := policySimulator.CallerArn()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("code: message"))
+			})
+		})
+	})
+
+	var _ = Describe("DeniedActions", func() {
+		var (
+			simulateInput   *iam.SimulatePrincipalPolicyInput
+			simulateMarkers []string
+			simulateError   error
+		)
+
+		BeforeEach(func() {
+			simulateMarkers = []string{}
+			simulateError = nil
+		})
+
+		JustBeforeEach(func() {
+			iamsvc.Handlers.Clear()
+			iamsvc.Handlers.Send.PushBack(func(r *request.Request) {
+				Expect(r.Operation.Name).To(Equal("SimulatePrincipalPolicy"))
+				input := r.Params.(*iam.SimulatePrincipalPolicyInput)
+				simulateInput = input
+				simulateMarkers = append(simulateMarkers, aws.StringValue(input.Marker))
+
+				data := r.Data.(*iam.SimulatePolicyResponse)
+				if input.Marker == nil {
+					data.EvaluationResults = []*iam.EvaluationResult{
+						{EvalActionName: aws.String("sqs:CreateQueue"), EvalResourceName: aws.String("queue-1"), EvalDecision: aws.String("allowed")},
+						{EvalActionName: aws.String("sqs:CreateQueue"), EvalResourceName: aws.String("queue-2"), EvalDecision: aws.String("explicitDeny")},
+					}
+					data.IsTruncated = aws.Bool(true)
+					data.Marker = aws.String("marker")
+				} else {
+					data.EvaluationResults = []*iam.EvaluationResult{
+						{EvalActionName: aws.String("iam:AttachUserPolicy"), EvalResourceName: aws.String("queue-1"), EvalDecision: aws.String("implicitDeny")},
+						{EvalActionName: aws.String("iam:AttachUserPolicy"), EvalResourceName: aws.String("queue-2"), EvalDecision: aws.String("implicitDeny")},
+						{EvalActionName: aws.String("iam:GetUser"), EvalResourceName: aws.String("queue-1"), EvalDecision: aws.String("allowed")},
+					}
+					data.IsTruncated = aws.Bool(false)
+				}
+				r.Error = simulateError
+			})
+		})
+
+		It("returns the actions denied on any resource, once", func() {
+			deniedActions, err := policySimulator.DeniedActions(
+				"user-arn",
+				[]string{"sqs:CreateQueue", "iam:AttachUserPolicy", "iam:GetUser"},
+				[]string{"queue-1", "queue-2"},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deniedActions).To(Equal([]string{"sqs:CreateQueue", "iam:AttachUserPolicy"}))
+
+			Expect(aws.StringValue(simulateInput.PolicySourceArn)).To(Equal("user-arn"))
+			Expect(aws.StringValueSlice(simulateInput.ResourceArns)).To(Equal([]string{"queue-1", "queue-2"}))
+			Expect(simulateMarkers).To(Equal([]string{"", "marker"}))
+		})
+
+		Context("when simulating the policies fails", func() {
+			BeforeEach(func() {
+				simulateError = awserr.New("code", "message", errors.New("operation failed"))
+			})
+
+			It("returns the proper error", func() {
+				_, err := policySimulator.DeniedActions("user-arn", []string{"iam:GetUser"}, nil)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("code: message"))
+			})
+		})
+	})
+})
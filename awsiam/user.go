@@ -6,7 +6,7 @@ import (
 
 type User interface {
 	Describe(userName string) (UserDetails, error)
-	Create(userName string) (string, error)
+	Create(userName string, userPath string) (string, error)
 	Delete(userName string) error
 	ListAccessKeys(userName string) ([]string, error)
 	CreateAccessKey(userName string) (string, string, error)
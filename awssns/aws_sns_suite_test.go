@@ -0,0 +1,13 @@
+package awssns_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestAWSSNS(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AWS SNS Suite")
+}
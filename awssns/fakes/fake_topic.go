@@ -0,0 +1,99 @@
+package fakes
+
+import (
+	"github.com/cf-platform-eng/sqs-broker/awssns"
+)
+
+type FakeTopic struct {
+	DescribeCalled       bool
+	DescribeTopicName    string
+	DescribeTopicDetails awssns.TopicDetails
+	DescribeError        error
+
+	CreateCalled       bool
+	CreateTopicName    string
+	CreateTopicDetails awssns.TopicDetails
+	CreateTopicArn     string
+	CreateError        error
+
+	ModifyCalled       bool
+	ModifyTopicName    string
+	ModifyTopicDetails awssns.TopicDetails
+	ModifyError        error
+
+	DeleteCalled    bool
+	DeleteTopicName string
+	DeleteError     error
+
+	SubscribeCalled              bool
+	SubscribeTopicName           string
+	SubscribeProtocol            string
+	SubscribeEndpoint            string
+	SubscribeSubscriptionDetails awssns.SubscriptionDetails
+	SubscribeSubscriptionArn     string
+	SubscribeError               error
+
+	ModifySubscriptionCalled              bool
+	ModifySubscriptionSubscriptionArn     string
+	ModifySubscriptionSubscriptionDetails awssns.SubscriptionDetails
+	ModifySubscriptionError               error
+
+	UnsubscribeCalled          bool
+	UnsubscribeSubscriptionArn string
+	UnsubscribeError           error
+}
+
+func (f *FakeTopic) Describe(topicName string) (awssns.TopicDetails, error) {
+	f.DescribeCalled = true
+	f.DescribeTopicName = topicName
+
+	return f.DescribeTopicDetails, f.DescribeError
+}
+
+func (f *FakeTopic) Create(topicName string, topicDetails awssns.TopicDetails) (string, error) {
+	f.CreateCalled = true
+	f.CreateTopicName = topicName
+	f.CreateTopicDetails = topicDetails
+
+	return f.CreateTopicArn, f.CreateError
+}
+
+func (f *FakeTopic) Modify(topicName string, topicDetails awssns.TopicDetails) error {
+	f.ModifyCalled = true
+	f.ModifyTopicName = topicName
+	f.ModifyTopicDetails = topicDetails
+
+	return f.ModifyError
+}
+
+func (f *FakeTopic) Delete(topicName string) error {
+	f.DeleteCalled = true
+	f.DeleteTopicName = topicName
+
+	return f.DeleteError
+}
+
+func (f *FakeTopic) Subscribe(topicName string, protocol string, endpoint string, subscriptionDetails awssns.SubscriptionDetails) (string, error) {
+	f.SubscribeCalled = true
+	f.SubscribeTopicName = topicName
+	f.SubscribeProtocol = protocol
+	f.SubscribeEndpoint = endpoint
+	f.SubscribeSubscriptionDetails = subscriptionDetails
+
+	return f.SubscribeSubscriptionArn, f.SubscribeError
+}
+
+func (f *FakeTopic) ModifySubscription(subscriptionArn string, subscriptionDetails awssns.SubscriptionDetails) error {
+	f.ModifySubscriptionCalled = true
+	f.ModifySubscriptionSubscriptionArn = subscriptionArn
+	f.ModifySubscriptionSubscriptionDetails = subscriptionDetails
+
+	return f.ModifySubscriptionError
+}
+
+func (f *FakeTopic) Unsubscribe(subscriptionArn string) error {
+	f.UnsubscribeCalled = true
+	f.UnsubscribeSubscriptionArn = subscriptionArn
+
+	return f.UnsubscribeError
+}
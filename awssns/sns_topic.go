@@ -0,0 +1,317 @@
+package awssns
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/pivotal-golang/lager"
+)
+
+type SNSTopic struct {
+	snssvc *sns.SNS
+	logger lager.Logger
+}
+
+func NewSNSTopic(
+	snssvc *sns.SNS,
+	logger lager.Logger,
+) *SNSTopic {
+	return &SNSTopic{
+		snssvc: snssvc,
+		logger: logger.Session("sns-topic"),
+	}
+}
+
+func (s *SNSTopic) Describe(topicName string) (TopicDetails, error) {
+	topicDetails := TopicDetails{}
+
+	topicArn, err := s.getTopicArn(topicName)
+	if err != nil {
+		return topicDetails, err
+	}
+
+	getTopicAttributesInput := &sns.GetTopicAttributesInput{
+		TopicArn: aws.String(topicArn),
+	}
+	s.logger.Debug("get-topic-attributes", lager.Data{"input": getTopicAttributesInput})
+
+	getTopicAttributesOutput, err := s.snssvc.GetTopicAttributes(getTopicAttributesInput)
+	if err != nil {
+		s.logger.Error("aws-sns-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			if reqErr, ok := err.(awserr.RequestFailure); ok {
+				// AWS SNS returns a 404 if Topic is not found
+				if reqErr.StatusCode() == 404 {
+					return topicDetails, ErrTopicDoesNotExist
+				}
+			}
+			return topicDetails, errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return topicDetails, err
+	}
+	s.logger.Debug("get-topic-attributes", lager.Data{"output": getTopicAttributesOutput})
+
+	return s.buildTopicDetails(topicArn, aws.StringValueMap(getTopicAttributesOutput.Attributes)), nil
+}
+
+func (s *SNSTopic) Create(topicName string, topicDetails TopicDetails) (string, error) {
+	createTopicInput := &sns.CreateTopicInput{
+		Name: aws.String(topicName),
+	}
+	s.logger.Debug("create-topic", lager.Data{"input": createTopicInput})
+
+	createTopicOutput, err := s.snssvc.CreateTopic(createTopicInput)
+	if err != nil {
+		s.logger.Error("aws-sns-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			return "", errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return "", err
+	}
+	s.logger.Debug("create-topic", lager.Data{"output": createTopicOutput})
+
+	topicArn := aws.StringValue(createTopicOutput.TopicArn)
+	if err = s.setTopicAttributes(topicArn, topicDetails); err != nil {
+		return topicArn, err
+	}
+
+	return topicArn, nil
+}
+
+func (s *SNSTopic) Modify(topicName string, topicDetails TopicDetails) error {
+	topicArn, err := s.getTopicArn(topicName)
+	if err != nil {
+		return err
+	}
+
+	return s.setTopicAttributes(topicArn, topicDetails)
+}
+
+func (s *SNSTopic) Delete(topicName string) error {
+	topicArn, err := s.getTopicArn(topicName)
+	if err != nil {
+		return err
+	}
+
+	deleteTopicInput := &sns.DeleteTopicInput{
+		TopicArn: aws.String(topicArn),
+	}
+	s.logger.Debug("delete-topic", lager.Data{"input": deleteTopicInput})
+
+	deleteTopicOutput, err := s.snssvc.DeleteTopic(deleteTopicInput)
+	if err != nil {
+		s.logger.Error("aws-sns-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			if reqErr, ok := err.(awserr.RequestFailure); ok {
+				// AWS SNS returns a 404 if Topic is not found
+				if reqErr.StatusCode() == 404 {
+					return ErrTopicDoesNotExist
+				}
+			}
+			return errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return err
+	}
+	s.logger.Debug("delete-topic", lager.Data{"output": deleteTopicOutput})
+
+	return nil
+}
+
+func (s *SNSTopic) Subscribe(topicName string, protocol string, endpoint string, subscriptionDetails SubscriptionDetails) (string, error) {
+	topicArn, err := s.getTopicArn(topicName)
+	if err != nil {
+		return "", err
+	}
+
+	subscribeInput := &sns.SubscribeInput{
+		TopicArn: aws.String(topicArn),
+		Protocol: aws.String(protocol),
+		Endpoint: aws.String(endpoint),
+	}
+	s.logger.Debug("subscribe", lager.Data{"input": subscribeInput})
+
+	subscribeOutput, err := s.snssvc.Subscribe(subscribeInput)
+	if err != nil {
+		s.logger.Error("aws-sns-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			if reqErr, ok := err.(awserr.RequestFailure); ok {
+				// AWS SNS returns a 404 if Topic is not found
+				if reqErr.StatusCode() == 404 {
+					return "", ErrTopicDoesNotExist
+				}
+			}
+			return "", errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return "", err
+	}
+	s.logger.Debug("subscribe", lager.Data{"output": subscribeOutput})
+
+	subscriptionArn := aws.StringValue(subscribeOutput.SubscriptionArn)
+	if err = s.setSubscriptionAttributes(subscriptionArn, subscriptionDetails); err != nil {
+		return subscriptionArn, err
+	}
+
+	return subscriptionArn, nil
+}
+
+func (s *SNSTopic) ModifySubscription(subscriptionArn string, subscriptionDetails SubscriptionDetails) error {
+	return s.setSubscriptionAttributes(subscriptionArn, subscriptionDetails)
+}
+
+func (s *SNSTopic) Unsubscribe(subscriptionArn string) error {
+	unsubscribeInput := &sns.UnsubscribeInput{
+		SubscriptionArn: aws.String(subscriptionArn),
+	}
+	s.logger.Debug("unsubscribe", lager.Data{"input": unsubscribeInput})
+
+	unsubscribeOutput, err := s.snssvc.Unsubscribe(unsubscribeInput)
+	if err != nil {
+		s.logger.Error("aws-sns-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			if reqErr, ok := err.(awserr.RequestFailure); ok {
+				// AWS SNS returns a 404 if Subscription is not found
+				if reqErr.StatusCode() == 404 {
+					return ErrSubscriptionDoesNotExist
+				}
+			}
+			return errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return err
+	}
+	s.logger.Debug("unsubscribe", lager.Data{"output": unsubscribeOutput})
+
+	return nil
+}
+
+func (s *SNSTopic) getTopicArn(topicName string) (string, error) {
+	// SNS does not allow to look up Topics by name, so the ARN must be found among all Topics
+	listTopicsInput := &sns.ListTopicsInput{}
+
+	for {
+		s.logger.Debug("list-topics", lager.Data{"input": listTopicsInput})
+
+		listTopicsOutput, err := s.snssvc.ListTopics(listTopicsInput)
+		if err != nil {
+			s.logger.Error("aws-sns-error", err)
+			if awsErr, ok := err.(awserr.Error); ok {
+				return "", errors.New(awsErr.Code() + ": " + awsErr.Message())
+			}
+			return "", err
+		}
+		s.logger.Debug("list-topics", lager.Data{"output": listTopicsOutput})
+
+		for _, topic := range listTopicsOutput.Topics {
+			topicArn := aws.StringValue(topic.TopicArn)
+			if strings.HasSuffix(topicArn, ":"+topicName) {
+				return topicArn, nil
+			}
+		}
+
+		if aws.StringValue(listTopicsOutput.NextToken) == "" {
+			return "", ErrTopicDoesNotExist
+		}
+		listTopicsInput.NextToken = listTopicsOutput.NextToken
+	}
+}
+
+func (s *SNSTopic) setTopicAttributes(topicArn string, topicDetails TopicDetails) error {
+	attributes := map[string]string{}
+
+	if topicDetails.DisplayName != "" {
+		attributes["DisplayName"] = topicDetails.DisplayName
+	}
+
+	if topicDetails.Policy != "" {
+		attributes["Policy"] = topicDetails.Policy
+	}
+
+	if topicDetails.DeliveryPolicy != "" {
+		attributes["DeliveryPolicy"] = topicDetails.DeliveryPolicy
+	}
+
+	// AWS SNS only allows to set one attribute per call
+	for name, value := range attributes {
+		setTopicAttributesInput := &sns.SetTopicAttributesInput{
+			TopicArn:       aws.String(topicArn),
+			AttributeName:  aws.String(name),
+			AttributeValue: aws.String(value),
+		}
+		s.logger.Debug("set-topic-attributes", lager.Data{"input": setTopicAttributesInput})
+
+		setTopicAttributesOutput, err := s.snssvc.SetTopicAttributes(setTopicAttributesInput)
+		if err != nil {
+			s.logger.Error("aws-sns-error", err)
+			if awsErr, ok := err.(awserr.Error); ok {
+				if reqErr, ok := err.(awserr.RequestFailure); ok {
+					// AWS SNS returns a 404 if Topic is not found
+					if reqErr.StatusCode() == 404 {
+						return ErrTopicDoesNotExist
+					}
+				}
+				return errors.New(awsErr.Code() + ": " + awsErr.Message())
+			}
+			return err
+		}
+		s.logger.Debug("set-topic-attributes", lager.Data{"output": setTopicAttributesOutput})
+	}
+
+	return nil
+}
+
+func (s *SNSTopic) setSubscriptionAttributes(subscriptionArn string, subscriptionDetails SubscriptionDetails) error {
+	attributes := map[string]string{}
+
+	if subscriptionDetails.RawMessageDelivery != "" {
+		attributes["RawMessageDelivery"] = subscriptionDetails.RawMessageDelivery
+	}
+
+	if subscriptionDetails.FilterPolicy != "" {
+		attributes["FilterPolicy"] = subscriptionDetails.FilterPolicy
+	}
+
+	// AWS SNS only allows to set one attribute per call
+	for name, value := range attributes {
+		setSubscriptionAttributesInput := &sns.SetSubscriptionAttributesInput{
+			SubscriptionArn: aws.String(subscriptionArn),
+			AttributeName:   aws.String(name),
+			AttributeValue:  aws.String(value),
+		}
+		s.logger.Debug("set-subscription-attributes", lager.Data{"input": setSubscriptionAttributesInput})
+
+		setSubscriptionAttributesOutput, err := s.snssvc.SetSubscriptionAttributes(setSubscriptionAttributesInput)
+		if err != nil {
+			s.logger.Error("aws-sns-error", err)
+			if awsErr, ok := err.(awserr.Error); ok {
+				if reqErr, ok := err.(awserr.RequestFailure); ok {
+					// AWS SNS returns a 404 if Subscription is not found
+					if reqErr.StatusCode() == 404 {
+						return ErrSubscriptionDoesNotExist
+					}
+				}
+				return errors.New(awsErr.Code() + ": " + awsErr.Message())
+			}
+			return err
+		}
+		s.logger.Debug("set-subscription-attributes", lager.Data{"output": setSubscriptionAttributesOutput})
+	}
+
+	return nil
+}
+
+func (s *SNSTopic) buildTopicDetails(topicArn string, attributes map[string]string) TopicDetails {
+	topicDetails := TopicDetails{
+		TopicArn:                topicArn,
+		DisplayName:             attributes["DisplayName"],
+		Policy:                  attributes["Policy"],
+		DeliveryPolicy:          attributes["DeliveryPolicy"],
+		SubscriptionsConfirmed:  attributes["SubscriptionsConfirmed"],
+		SubscriptionsPending:    attributes["SubscriptionsPending"],
+		SubscriptionsDeleted:    attributes["SubscriptionsDeleted"],
+		EffectiveDeliveryPolicy: attributes["EffectiveDeliveryPolicy"],
+	}
+
+	return topicDetails
+}
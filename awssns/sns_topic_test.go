@@ -0,0 +1,431 @@
+package awssns_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/sqs-broker/awssns"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+)
+
+var _ = Describe("SNS Topic", func() {
+	var (
+		topicName string
+		topicArn  string
+
+		listTopicsError error
+
+		awsSession *session.Session
+		snssvc     *sns.SNS
+		snsCall    func(r *request.Request)
+
+		testSink *lagertest.TestSink
+		logger   lager.Logger
+
+		topic Topic
+	)
+
+	BeforeEach(func() {
+		topicName = "sns-topic"
+		topicArn = "arn:aws:sns:us-east-1:123456789012:sns-topic"
+		listTopicsError = nil
+	})
+
+	JustBeforeEach(func() {
+		awsSession = session.New(nil)
+		snssvc = sns.New(awsSession)
+
+		logger = lager.NewLogger("snstopic_test")
+		testSink = lagertest.NewTestSink()
+		logger.RegisterSink(testSink)
+
+		topic = NewSNSTopic(snssvc, logger)
+	})
+
+	listTopics := func(r *request.Request) {
+		Expect(r.Params).To(BeAssignableToTypeOf(&sns.ListTopicsInput{}))
+		data := r.Data.(*sns.ListTopicsOutput)
+		if aws.StringValue(r.Params.(*sns.ListTopicsInput).NextToken) == "" {
+			data.Topics = []*sns.Topic{&sns.Topic{TopicArn: aws.String("arn:aws:sns:us-east-1:123456789012:other-sns-topic")}}
+			data.NextToken = aws.String("next-token")
+		} else {
+			data.Topics = []*sns.Topic{&sns.Topic{TopicArn: aws.String(topicArn)}}
+		}
+		r.Error = listTopicsError
+	}
+
+	var _ = Describe("Describe", func() {
+		var (
+			getTopicAttributesInput *sns.GetTopicAttributesInput
+			getTopicAttributesError error
+		)
+
+		BeforeEach(func() {
+			getTopicAttributesInput = &sns.GetTopicAttributesInput{
+				TopicArn: aws.String(topicArn),
+			}
+			getTopicAttributesError = nil
+		})
+
+		JustBeforeEach(func() {
+			snssvc.Handlers.Clear()
+
+			snsCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(MatchRegexp("ListTopics|GetTopicAttributes"))
+				switch r.Operation.Name {
+				case "ListTopics":
+					listTopics(r)
+				case "GetTopicAttributes":
+					Expect(r.Params).To(Equal(getTopicAttributesInput))
+					data := r.Data.(*sns.GetTopicAttributesOutput)
+					data.Attributes = map[string]*string{
+						"DisplayName":            aws.String("test-display-name"),
+						"Policy":                 aws.String("test-policy"),
+						"SubscriptionsConfirmed": aws.String("1"),
+					}
+					r.Error = getTopicAttributesError
+				}
+			}
+			snssvc.Handlers.Send.PushBack(snsCall)
+		})
+
+		It("gets the Topic Attributes", func() {
+			topicDetails, err := topic.Describe(topicName)
+			Expect(topicDetails).To(Equal(TopicDetails{
+				TopicArn:               topicArn,
+				DisplayName:            "test-display-name",
+				Policy:                 "test-policy",
+				SubscriptionsConfirmed: "1",
+			}))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when the Topic does not exist", func() {
+			BeforeEach(func() {
+				topicName = "unknown"
+			})
+
+			It("returns the proper error", func() {
+				_, err := topic.Describe(topicName)
+				Expect(err).To(Equal(ErrTopicDoesNotExist))
+			})
+		})
+
+		Context("when listing the Topics fails", func() {
+			BeforeEach(func() {
+				listTopicsError = awserr.New("code", "message", errors.New("operation failed"))
+			})
+
+			It("returns the proper error", func() {
+				_, err := topic.Describe(topicName)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("code: message"))
+			})
+		})
+
+		Context("when getting the Topic Attributes fails", func() {
+			BeforeEach(func() {
+				getTopicAttributesError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := topic.Describe(topicName)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("and it is a 404 error", func() {
+				BeforeEach(func() {
+					awsError := awserr.New("code", "message", errors.New("operation failed"))
+					getTopicAttributesError = awserr.NewRequestFailure(awsError, 404, "request-id")
+				})
+
+				It("returns the proper error", func() {
+					_, err := topic.Describe(topicName)
+					Expect(err).To(Equal(ErrTopicDoesNotExist))
+				})
+			})
+		})
+	})
+
+	var _ = Describe("Create", func() {
+		var (
+			topicDetails TopicDetails
+
+			createTopicInput *sns.CreateTopicInput
+			createTopicError error
+
+			setTopicAttributes      map[string]string
+			setTopicAttributesError error
+		)
+
+		BeforeEach(func() {
+			topicDetails = TopicDetails{
+				DisplayName: "test-display-name",
+				Policy:      "test-policy",
+			}
+
+			createTopicInput = &sns.CreateTopicInput{
+				Name: aws.String(topicName),
+			}
+			createTopicError = nil
+
+			setTopicAttributes = map[string]string{}
+			setTopicAttributesError = nil
+		})
+
+		JustBeforeEach(func() {
+			snssvc.Handlers.Clear()
+
+			snsCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(MatchRegexp("CreateTopic|SetTopicAttributes"))
+				switch r.Operation.Name {
+				case "CreateTopic":
+					Expect(r.Params).To(Equal(createTopicInput))
+					data := r.Data.(*sns.CreateTopicOutput)
+					data.TopicArn = aws.String(topicArn)
+					r.Error = createTopicError
+				case "SetTopicAttributes":
+					params := r.Params.(*sns.SetTopicAttributesInput)
+					Expect(aws.StringValue(params.TopicArn)).To(Equal(topicArn))
+					setTopicAttributes[aws.StringValue(params.AttributeName)] = aws.StringValue(params.AttributeValue)
+					r.Error = setTopicAttributesError
+				}
+			}
+			snssvc.Handlers.Send.PushBack(snsCall)
+		})
+
+		It("creates the Topic and sets its Attributes", func() {
+			createdTopicArn, err := topic.Create(topicName, topicDetails)
+			Expect(createdTopicArn).To(Equal(topicArn))
+			Expect(setTopicAttributes).To(Equal(map[string]string{
+				"DisplayName": "test-display-name",
+				"Policy":      "test-policy",
+			}))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when creating the Topic fails", func() {
+			BeforeEach(func() {
+				createTopicError = awserr.New("code", "message", errors.New("operation failed"))
+			})
+
+			It("returns the proper error", func() {
+				_, err := topic.Create(topicName, topicDetails)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("code: message"))
+				Expect(setTopicAttributes).To(BeEmpty())
+			})
+		})
+
+		Context("when setting the Topic Attributes fails", func() {
+			BeforeEach(func() {
+				setTopicAttributesError = awserr.New("code", "message", errors.New("operation failed"))
+			})
+
+			It("returns the proper error", func() {
+				_, err := topic.Create(topicName, topicDetails)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("code: message"))
+			})
+		})
+	})
+
+	var _ = Describe("Delete", func() {
+		var (
+			deleteTopicInput *sns.DeleteTopicInput
+			deleteTopicError error
+		)
+
+		BeforeEach(func() {
+			deleteTopicInput = &sns.DeleteTopicInput{
+				TopicArn: aws.String(topicArn),
+			}
+			deleteTopicError = nil
+		})
+
+		JustBeforeEach(func() {
+			snssvc.Handlers.Clear()
+
+			snsCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(MatchRegexp("ListTopics|DeleteTopic"))
+				switch r.Operation.Name {
+				case "ListTopics":
+					listTopics(r)
+				case "DeleteTopic":
+					Expect(r.Params).To(Equal(deleteTopicInput))
+					r.Error = deleteTopicError
+				}
+			}
+			snssvc.Handlers.Send.PushBack(snsCall)
+		})
+
+		It("deletes the Topic", func() {
+			err := topic.Delete(topicName)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when deleting the Topic fails", func() {
+			BeforeEach(func() {
+				deleteTopicError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				err := topic.Delete(topicName)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("and it is a 404 error", func() {
+				BeforeEach(func() {
+					awsError := awserr.New("code", "message", errors.New("operation failed"))
+					deleteTopicError = awserr.NewRequestFailure(awsError, 404, "request-id")
+				})
+
+				It("returns the proper error", func() {
+					err := topic.Delete(topicName)
+					Expect(err).To(Equal(ErrTopicDoesNotExist))
+				})
+			})
+		})
+	})
+
+	var _ = Describe("Subscribe", func() {
+		var (
+			subscriptionArn     string
+			subscriptionDetails SubscriptionDetails
+
+			subscribeInput *sns.SubscribeInput
+			subscribeError error
+
+			setSubscriptionAttributes      map[string]string
+			setSubscriptionAttributesError error
+		)
+
+		BeforeEach(func() {
+			subscriptionArn = topicArn + ":subscription-id"
+			subscriptionDetails = SubscriptionDetails{
+				RawMessageDelivery: "true",
+				FilterPolicy:       `{"event":["created"]}`,
+			}
+
+			subscribeInput = &sns.SubscribeInput{
+				TopicArn: aws.String(topicArn),
+				Protocol: aws.String("sqs"),
+				Endpoint: aws.String("queue-arn"),
+			}
+			subscribeError = nil
+
+			setSubscriptionAttributes = map[string]string{}
+			setSubscriptionAttributesError = nil
+		})
+
+		JustBeforeEach(func() {
+			snssvc.Handlers.Clear()
+
+			snsCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(MatchRegexp("ListTopics|Subscribe|SetSubscriptionAttributes"))
+				switch r.Operation.Name {
+				case "ListTopics":
+					listTopics(r)
+				case "Subscribe":
+					Expect(r.Params).To(Equal(subscribeInput))
+					data := r.Data.(*sns.SubscribeOutput)
+					data.SubscriptionArn = aws.String(subscriptionArn)
+					r.Error = subscribeError
+				case "SetSubscriptionAttributes":
+					params := r.Params.(*sns.SetSubscriptionAttributesInput)
+					Expect(aws.StringValue(params.SubscriptionArn)).To(Equal(subscriptionArn))
+					setSubscriptionAttributes[aws.StringValue(params.AttributeName)] = aws.StringValue(params.AttributeValue)
+					r.Error = setSubscriptionAttributesError
+				}
+			}
+			snssvc.Handlers.Send.PushBack(snsCall)
+		})
+
+		It("subscribes to the Topic and sets the Subscription Attributes", func() {
+			createdSubscriptionArn, err := topic.Subscribe(topicName, "sqs", "queue-arn", subscriptionDetails)
+			Expect(createdSubscriptionArn).To(Equal(subscriptionArn))
+			Expect(setSubscriptionAttributes).To(Equal(map[string]string{
+				"RawMessageDelivery": "true",
+				"FilterPolicy":       `{"event":["created"]}`,
+			}))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when subscribing fails", func() {
+			BeforeEach(func() {
+				subscribeError = awserr.New("code", "message", errors.New("operation failed"))
+			})
+
+			It("returns the proper error", func() {
+				_, err := topic.Subscribe(topicName, "sqs", "queue-arn", subscriptionDetails)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("code: message"))
+			})
+		})
+
+		Context("when setting the Subscription Attributes fails", func() {
+			BeforeEach(func() {
+				awsError := awserr.New("code", "message", errors.New("operation failed"))
+				setSubscriptionAttributesError = awserr.NewRequestFailure(awsError, 404, "request-id")
+			})
+
+			It("returns the proper error", func() {
+				_, err := topic.Subscribe(topicName, "sqs", "queue-arn", subscriptionDetails)
+				Expect(err).To(Equal(ErrSubscriptionDoesNotExist))
+			})
+		})
+	})
+
+	var _ = Describe("Unsubscribe", func() {
+		var (
+			unsubscribeInput *sns.UnsubscribeInput
+			unsubscribeError error
+		)
+
+		BeforeEach(func() {
+			unsubscribeInput = &sns.UnsubscribeInput{
+				SubscriptionArn: aws.String("subscription-arn"),
+			}
+			unsubscribeError = nil
+		})
+
+		JustBeforeEach(func() {
+			snssvc.Handlers.Clear()
+
+			snsCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(Equal("Unsubscribe"))
+				Expect(r.Params).To(Equal(unsubscribeInput))
+				r.Error = unsubscribeError
+			}
+			snssvc.Handlers.Send.PushBack(snsCall)
+		})
+
+		It("deletes the Subscription", func() {
+			err := topic.Unsubscribe("subscription-arn")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when the Subscription does not exist", func() {
+			BeforeEach(func() {
+				awsError := awserr.New("code", "message", errors.New("operation failed"))
+				unsubscribeError = awserr.NewRequestFailure(awsError, 404, "request-id")
+			})
+
+			It("returns the proper error", func() {
+				err := topic.Unsubscribe("subscription-arn")
+				Expect(err).To(Equal(ErrSubscriptionDoesNotExist))
+			})
+		})
+	})
+})
@@ -0,0 +1,36 @@
+package awssns
+
+import (
+	"errors"
+)
+
+type Topic interface {
+	Describe(topicName string) (TopicDetails, error)
+	Create(topicName string, topicDetails TopicDetails) (string, error)
+	Modify(topicName string, topicDetails TopicDetails) error
+	Delete(topicName string) error
+	Subscribe(topicName string, protocol string, endpoint string, subscriptionDetails SubscriptionDetails) (string, error)
+	ModifySubscription(subscriptionArn string, subscriptionDetails SubscriptionDetails) error
+	Unsubscribe(subscriptionArn string) error
+}
+
+type TopicDetails struct {
+	TopicArn                string
+	DisplayName             string
+	Policy                  string
+	DeliveryPolicy          string
+	SubscriptionsConfirmed  string
+	SubscriptionsPending    string
+	SubscriptionsDeleted    string
+	EffectiveDeliveryPolicy string
+}
+
+type SubscriptionDetails struct {
+	RawMessageDelivery string
+	FilterPolicy       string
+}
+
+var (
+	ErrTopicDoesNotExist        = errors.New("sns topic does not exist")
+	ErrSubscriptionDoesNotExist = errors.New("sns subscription does not exist")
+)
@@ -9,6 +9,8 @@ type FakeQueue struct {
 	DescribeQueueName    string
 	DescribeQueueDetails awssqs.QueueDetails
 	DescribeError        error
+	// Returned one per call before DescribeQueueDetails
+	DescribeQueueDetailsSequence []awssqs.QueueDetails
 
 	CreateCalled       bool
 	CreateQueueName    string
@@ -21,15 +23,44 @@ type FakeQueue struct {
 	ModifyQueueDetails awssqs.QueueDetails
 	ModifyError        error
 
+	SetPolicyCalled    bool
+	SetPolicyQueueName string
+	SetPolicyPolicy    string
+	SetPolicyError     error
+
 	DeleteCalled    bool
 	DeleteQueueName string
 	DeleteError     error
+
+	ReceiveMessagesCalled              bool
+	ReceiveMessagesQueueName           string
+	ReceiveMessagesMaxNumberOfMessages int64
+	ReceiveMessagesMessages            []awssqs.Message
+	ReceiveMessagesError               error
+	// Handed out one per call before ReceiveMessagesMessages
+	ReceiveMessagesBatches [][]awssqs.Message
+
+	SendMessagesCalled    bool
+	SendMessagesQueueName string
+	SendMessagesMessages  []awssqs.Message
+	SendMessagesError     error
+
+	DeleteMessagesCalled    bool
+	DeleteMessagesQueueName string
+	DeleteMessagesMessages  []awssqs.Message
+	DeleteMessagesError     error
 }
 
 func (f *FakeQueue) Describe(queueName string) (awssqs.QueueDetails, error) {
 	f.DescribeCalled = true
 	f.DescribeQueueName = queueName
 
+	if len(f.DescribeQueueDetailsSequence) > 0 {
+		queueDetails := f.DescribeQueueDetailsSequence[0]
+		f.DescribeQueueDetailsSequence = f.DescribeQueueDetailsSequence[1:]
+		return queueDetails, f.DescribeError
+	}
+
 	return f.DescribeQueueDetails, f.DescribeError
 }
 
@@ -49,9 +80,51 @@ func (f *FakeQueue) Modify(queueName string, queueDetails awssqs.QueueDetails) e
 	return f.ModifyError
 }
 
+func (f *FakeQueue) SetPolicy(queueName string, policy string) error {
+	f.SetPolicyCalled = true
+	f.SetPolicyQueueName = queueName
+	f.SetPolicyPolicy = policy
+
+	return f.SetPolicyError
+}
+
 func (f *FakeQueue) Delete(queueName string) error {
 	f.DeleteCalled = true
 	f.DeleteQueueName = queueName
 
 	return f.DeleteError
 }
+
+func (f *FakeQueue) ReceiveMessages(queueName string, maxNumberOfMessages int64) ([]awssqs.Message, error) {
+	f.ReceiveMessagesCalled = true
+	f.ReceiveMessagesQueueName = queueName
+	f.ReceiveMessagesMaxNumberOfMessages = maxNumberOfMessages
+
+	if len(f.ReceiveMessagesBatches) > 0 {
+		messages := f.ReceiveMessagesBatches[0]
+		f.ReceiveMessagesBatches = f.ReceiveMessagesBatches[1:]
+		return messages, f.ReceiveMessagesError
+	}
+
+	// Messages are handed out only once, so callers draining the queue terminate
+	messages := f.ReceiveMessagesMessages
+	f.ReceiveMessagesMessages = nil
+
+	return messages, f.ReceiveMessagesError
+}
+
+func (f *FakeQueue) SendMessages(queueName string, messages []awssqs.Message) error {
+	f.SendMessagesCalled = true
+	f.SendMessagesQueueName = queueName
+	f.SendMessagesMessages = append(f.SendMessagesMessages, messages...)
+
+	return f.SendMessagesError
+}
+
+func (f *FakeQueue) DeleteMessages(queueName string, messages []awssqs.Message) error {
+	f.DeleteMessagesCalled = true
+	f.DeleteMessagesQueueName = queueName
+	f.DeleteMessagesMessages = append(f.DeleteMessagesMessages, messages...)
+
+	return f.DeleteMessagesError
+}
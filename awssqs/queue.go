@@ -8,18 +8,40 @@ type Queue interface {
 	Describe(queueName string) (QueueDetails, error)
 	Create(queueName string, queueDetails QueueDetails) (string, error)
 	Modify(queueName string, queueDetails QueueDetails) error
+	SetPolicy(queueName string, policy string) error
 	Delete(queueName string) error
+	ReceiveMessages(queueName string, maxNumberOfMessages int64) ([]Message, error)
+	SendMessages(queueName string, messages []Message) error
+	DeleteMessages(queueName string, messages []Message) error
 }
 
 type QueueDetails struct {
-	QueueURL                      string
-	QueueArn                      string
-	DelaySeconds                  string
-	MaximumMessageSize            string
-	MessageRetentionPeriod        string
-	Policy                        string
-	ReceiveMessageWaitTimeSeconds string
-	VisibilityTimeout             string
+	QueueURL                              string
+	QueueArn                              string
+	DelaySeconds                          string
+	MaximumMessageSize                    string
+	MessageRetentionPeriod                string
+	Policy                                string
+	ReceiveMessageWaitTimeSeconds         string
+	VisibilityTimeout                     string
+	ApproximateNumberOfMessages           string
+	ApproximateNumberOfMessagesNotVisible string
+	ApproximateNumberOfMessagesDelayed    string
+	CreatedTimestamp                      string
+	LastModifiedTimestamp                 string
+}
+
+type Message struct {
+	MessageID         string
+	ReceiptHandle     string
+	Body              string
+	MessageAttributes map[string]MessageAttribute
+}
+
+type MessageAttribute struct {
+	DataType    string
+	StringValue string
+	BinaryValue []byte
 }
 
 var (
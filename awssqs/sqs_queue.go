@@ -2,6 +2,10 @@ package awssqs
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -9,18 +13,31 @@ import (
 	"github.com/pivotal-golang/lager"
 )
 
+const nonExistentQueueErrorCode = "AWS.SimpleQueueService.NonExistentQueue"
+
+// A SendMessageBatch request is limited to 10 messages and 256 KiB of payload
+const (
+	maxBatchMessages    = 10
+	maxBatchPayloadSize = 256 * 1024
+)
+
 type SQSQueue struct {
-	sqssvc *sqs.SQS
-	logger lager.Logger
+	sqssvc   *sqs.SQS
+	endpoint string
+	logger   lager.Logger
 }
 
+// NewSQSQueue returns an SQSQueue. When endpoint is not empty, the Queue URLs it returns are rewritten
+// to point to it, as AWS keeps returning its public URLs when called through a VPC or a custom endpoint.
 func NewSQSQueue(
 	sqssvc *sqs.SQS,
+	endpoint string,
 	logger lager.Logger,
 ) *SQSQueue {
 	return &SQSQueue{
-		sqssvc: sqssvc,
-		logger: logger.Session("sqs-queue"),
+		sqssvc:   sqssvc,
+		endpoint: endpoint,
+		logger:   logger.Session("sqs-queue"),
 	}
 }
 
@@ -37,7 +54,7 @@ func (s *SQSQueue) Describe(queueName string) (QueueDetails, error) {
 		return queueDetails, err
 	}
 
-	return s.buildQueueDetails(queueURL, queueAttributes), nil
+	return s.buildQueueDetails(s.endpointQueueURL(queueURL), queueAttributes), nil
 }
 
 func (s *SQSQueue) Create(queueName string, queueDetails QueueDetails) (string, error) {
@@ -54,7 +71,7 @@ func (s *SQSQueue) Create(queueName string, queueDetails QueueDetails) (string,
 	}
 	s.logger.Debug("create-queue", lager.Data{"output": createQueueOutput})
 
-	return aws.StringValue(createQueueOutput.QueueUrl), nil
+	return s.endpointQueueURL(aws.StringValue(createQueueOutput.QueueUrl)), nil
 }
 
 func (s *SQSQueue) Modify(queueName string, queueDetails QueueDetails) error {
@@ -71,6 +88,40 @@ func (s *SQSQueue) Modify(queueName string, queueDetails QueueDetails) error {
 	return nil
 }
 
+func (s *SQSQueue) SetPolicy(queueName string, policy string) error {
+	queueURL, err := s.getQueueURL(queueName)
+	if err != nil {
+		return err
+	}
+
+	// An empty Policy removes the Queue Policy, so it is always sent
+	setQueueAttributesInput := &sqs.SetQueueAttributesInput{
+		QueueUrl: aws.String(queueURL),
+		Attributes: map[string]*string{
+			"Policy": aws.String(policy),
+		},
+	}
+	s.logger.Debug("set-queue-attributes", lager.Data{"input": setQueueAttributesInput})
+
+	setQueueAttributesOutput, err := s.sqssvc.SetQueueAttributes(setQueueAttributesInput)
+	if err != nil {
+		s.logger.Error("aws-sqs-error", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			if reqErr, ok := err.(awserr.RequestFailure); ok {
+				// AWS SQS returns a 400 if Queue is not found
+				if reqErr.StatusCode() == 400 || reqErr.StatusCode() == 404 {
+					return ErrQueueDoesNotExist
+				}
+			}
+			return errors.New(awsErr.Code() + ": " + awsErr.Message())
+		}
+		return err
+	}
+	s.logger.Debug("set-queue-attributes", lager.Data{"output": setQueueAttributesOutput})
+
+	return nil
+}
+
 func (s *SQSQueue) Delete(queueName string) error {
 	queueURL, err := s.getQueueURL(queueName)
 	if err != nil {
@@ -101,6 +152,101 @@ func (s *SQSQueue) Delete(queueName string) error {
 	return nil
 }
 
+func (s *SQSQueue) ReceiveMessages(queueName string, maxNumberOfMessages int64) ([]Message, error) {
+	var messages []Message
+
+	queueURL, err := s.getQueueURL(queueName)
+	if err != nil {
+		return messages, err
+	}
+
+	receiveMessageInput := &sqs.ReceiveMessageInput{
+		QueueUrl:              aws.String(queueURL),
+		MaxNumberOfMessages:   aws.Int64(maxNumberOfMessages),
+		MessageAttributeNames: aws.StringSlice([]string{"All"}),
+		WaitTimeSeconds:       aws.Int64(1),
+	}
+	s.logger.Debug("receive-message", lager.Data{"queue-url": queueURL, "max-number-of-messages": maxNumberOfMessages})
+
+	receiveMessageOutput, err := s.sqssvc.ReceiveMessage(receiveMessageInput)
+	if err != nil {
+		s.logger.Error("aws-sqs-error", err)
+		return messages, s.messagesError(err)
+	}
+
+	// Message bodies are customer data, only their IDs are logged
+	messageIDs := []string{}
+	for _, message := range receiveMessageOutput.Messages {
+		messages = append(messages, s.buildMessage(message))
+		messageIDs = append(messageIDs, aws.StringValue(message.MessageId))
+	}
+	s.logger.Debug("receive-message", lager.Data{"queue-url": queueURL, "message-ids": messageIDs})
+
+	return messages, nil
+}
+
+// SendMessages sends the Messages in as many batches as needed to keep each batch under the SQS payload size limit
+func (s *SQSQueue) SendMessages(queueName string, messages []Message) error {
+	queueURL, err := s.getQueueURL(queueName)
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range splitMessageBatches(messages) {
+		sendMessageBatchInput := s.buildSendMessageBatchInput(queueURL, batch)
+		s.logger.Debug("send-message-batch", lager.Data{"queue-url": queueURL, "message-ids": messageIDs(batch)})
+
+		sendMessageBatchOutput, err := s.sqssvc.SendMessageBatch(sendMessageBatchInput)
+		if err != nil {
+			s.logger.Error("aws-sqs-error", err)
+			return s.messagesError(err)
+		}
+		s.logger.Debug("send-message-batch", lager.Data{"queue-url": queueURL, "sent": len(sendMessageBatchOutput.Successful), "failed": len(sendMessageBatchOutput.Failed)})
+
+		if len(sendMessageBatchOutput.Failed) > 0 {
+			failed := sendMessageBatchOutput.Failed[0]
+			return fmt.Errorf("Failed to send %d messages: %s: %s", len(sendMessageBatchOutput.Failed), aws.StringValue(failed.Code), aws.StringValue(failed.Message))
+		}
+	}
+
+	return nil
+}
+
+func (s *SQSQueue) DeleteMessages(queueName string, messages []Message) error {
+	queueURL, err := s.getQueueURL(queueName)
+	if err != nil {
+		return err
+	}
+
+	deleteMessageBatchInput := s.buildDeleteMessageBatchInput(queueURL, messages)
+	s.logger.Debug("delete-message-batch", lager.Data{"queue-url": queueURL, "message-ids": messageIDs(messages)})
+
+	deleteMessageBatchOutput, err := s.sqssvc.DeleteMessageBatch(deleteMessageBatchInput)
+	if err != nil {
+		s.logger.Error("aws-sqs-error", err)
+		return s.messagesError(err)
+	}
+	s.logger.Debug("delete-message-batch", lager.Data{"queue-url": queueURL, "deleted": len(deleteMessageBatchOutput.Successful), "failed": len(deleteMessageBatchOutput.Failed)})
+
+	if len(deleteMessageBatchOutput.Failed) > 0 {
+		failed := deleteMessageBatchOutput.Failed[0]
+		return fmt.Errorf("Failed to delete %d messages: %s: %s", len(deleteMessageBatchOutput.Failed), aws.StringValue(failed.Code), aws.StringValue(failed.Message))
+	}
+
+	return nil
+}
+
+// messagesError maps only a missing Queue to ErrQueueDoesNotExist, SQS also answers 400 for invalid batches
+func (s *SQSQueue) messagesError(err error) error {
+	if awsErr, ok := err.(awserr.Error); ok {
+		if awsErr.Code() == nonExistentQueueErrorCode {
+			return ErrQueueDoesNotExist
+		}
+		return errors.New(awsErr.Code() + ": " + awsErr.Message())
+	}
+	return err
+}
+
 func (s *SQSQueue) getQueueURL(queueName string) (string, error) {
 	getQueueURLInput := &sqs.GetQueueUrlInput{
 		QueueName: aws.String(queueName),
@@ -126,6 +272,28 @@ func (s *SQSQueue) getQueueURL(queueName string) (string, error) {
 	return aws.StringValue(getQueueURLOutput.QueueUrl), nil
 }
 
+func (s *SQSQueue) endpointQueueURL(queueURL string) string {
+	if s.endpoint == "" {
+		return queueURL
+	}
+
+	endpointURL, err := url.Parse(s.endpoint)
+	if err != nil {
+		return queueURL
+	}
+
+	parsedQueueURL, err := url.Parse(queueURL)
+	if err != nil {
+		return queueURL
+	}
+
+	parsedQueueURL.Scheme = endpointURL.Scheme
+	parsedQueueURL.Host = endpointURL.Host
+	parsedQueueURL.Path = strings.TrimSuffix(endpointURL.Path, "/") + parsedQueueURL.Path
+
+	return parsedQueueURL.String()
+}
+
 func (s *SQSQueue) getQueueAttributes(queueURL string) (map[string]string, error) {
 	getQueueAttributesInput := &sqs.GetQueueAttributesInput{
 		QueueUrl:       aws.String(queueURL),
@@ -177,19 +345,43 @@ func (s *SQSQueue) setQueueAttributes(queueURL string, queueDetails QueueDetails
 
 func (s *SQSQueue) buildQueueDetails(queueURL string, attributes map[string]string) QueueDetails {
 	queueDetails := QueueDetails{
-		QueueURL:               queueURL,
-		QueueArn:               attributes["QueueArn"],
-		DelaySeconds:           attributes["DelaySeconds"],
-		MaximumMessageSize:     attributes["MaximumMessageSize"],
-		MessageRetentionPeriod: attributes["MessageRetentionPeriod"],
-		Policy:                 attributes["Policy"],
-		ReceiveMessageWaitTimeSeconds: attributes["ReceiveMessageWaitTimeSeconds"],
-		VisibilityTimeout:             attributes["VisibilityTimeout"],
+		QueueURL:                              queueURL,
+		QueueArn:                              attributes["QueueArn"],
+		DelaySeconds:                          attributes["DelaySeconds"],
+		MaximumMessageSize:                    attributes["MaximumMessageSize"],
+		MessageRetentionPeriod:                attributes["MessageRetentionPeriod"],
+		Policy:                                attributes["Policy"],
+		ReceiveMessageWaitTimeSeconds:         attributes["ReceiveMessageWaitTimeSeconds"],
+		VisibilityTimeout:                     attributes["VisibilityTimeout"],
+		ApproximateNumberOfMessages:           attributes["ApproximateNumberOfMessages"],
+		ApproximateNumberOfMessagesNotVisible: attributes["ApproximateNumberOfMessagesNotVisible"],
+		ApproximateNumberOfMessagesDelayed:    attributes["ApproximateNumberOfMessagesDelayed"],
+		CreatedTimestamp:                      attributes["CreatedTimestamp"],
+		LastModifiedTimestamp:                 attributes["LastModifiedTimestamp"],
 	}
 
 	return queueDetails
 }
 
+func (s *SQSQueue) buildMessage(sqsMessage *sqs.Message) Message {
+	message := Message{
+		MessageID:         aws.StringValue(sqsMessage.MessageId),
+		ReceiptHandle:     aws.StringValue(sqsMessage.ReceiptHandle),
+		Body:              aws.StringValue(sqsMessage.Body),
+		MessageAttributes: map[string]MessageAttribute{},
+	}
+
+	for name, attribute := range sqsMessage.MessageAttributes {
+		message.MessageAttributes[name] = MessageAttribute{
+			DataType:    aws.StringValue(attribute.DataType),
+			StringValue: aws.StringValue(attribute.StringValue),
+			BinaryValue: attribute.BinaryValue,
+		}
+	}
+
+	return message
+}
+
 func (s *SQSQueue) buildCreateQueueInput(queueName string, queueDetails QueueDetails) *sqs.CreateQueueInput {
 	createQueueInput := &sqs.CreateQueueInput{
 		QueueName:  aws.String(queueName),
@@ -255,3 +447,94 @@ func (s *SQSQueue) buildSetQueueAttributesInput(queueURL string, queueDetails Qu
 
 	return setQueueAttributesInput
 }
+
+func (s *SQSQueue) buildSendMessageBatchInput(queueURL string, messages []Message) *sqs.SendMessageBatchInput {
+	sendMessageBatchInput := &sqs.SendMessageBatchInput{
+		QueueUrl: aws.String(queueURL),
+	}
+
+	for i, message := range messages {
+		entry := &sqs.SendMessageBatchRequestEntry{
+			Id:          aws.String(strconv.Itoa(i)),
+			MessageBody: aws.String(message.Body),
+		}
+
+		if len(message.MessageAttributes) > 0 {
+			entry.MessageAttributes = map[string]*sqs.MessageAttributeValue{}
+			for name, attribute := range message.MessageAttributes {
+				messageAttributeValue := &sqs.MessageAttributeValue{
+					DataType: aws.String(attribute.DataType),
+				}
+				if attribute.StringValue != "" {
+					messageAttributeValue.StringValue = aws.String(attribute.StringValue)
+				}
+				if attribute.BinaryValue != nil {
+					messageAttributeValue.BinaryValue = attribute.BinaryValue
+				}
+				entry.MessageAttributes[name] = messageAttributeValue
+			}
+		}
+
+		sendMessageBatchInput.Entries = append(sendMessageBatchInput.Entries, entry)
+	}
+
+	return sendMessageBatchInput
+}
+
+// splitMessageBatches groups the Messages in batches SendMessageBatch accepts, a Message too large on its own
+// is sent alone and rejected by SQS
+func splitMessageBatches(messages []Message) [][]Message {
+	batches := [][]Message{}
+	batch := []Message{}
+	batchSize := 0
+
+	for _, message := range messages {
+		size := messageSize(message)
+		if len(batch) > 0 && (len(batch) == maxBatchMessages || batchSize+size > maxBatchPayloadSize) {
+			batches = append(batches, batch)
+			batch = []Message{}
+			batchSize = 0
+		}
+		batch = append(batch, message)
+		batchSize += size
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+// messageSize is the payload size SQS counts for a Message: its body, and the name, type and value of its attributes
+func messageSize(message Message) int {
+	size := len(message.Body)
+	for name, attribute := range message.MessageAttributes {
+		size += len(name) + len(attribute.DataType) + len(attribute.StringValue) + len(attribute.BinaryValue)
+	}
+
+	return size
+}
+
+func messageIDs(messages []Message) []string {
+	ids := []string{}
+	for _, message := range messages {
+		ids = append(ids, message.MessageID)
+	}
+
+	return ids
+}
+
+func (s *SQSQueue) buildDeleteMessageBatchInput(queueURL string, messages []Message) *sqs.DeleteMessageBatchInput {
+	deleteMessageBatchInput := &sqs.DeleteMessageBatchInput{
+		QueueUrl: aws.String(queueURL),
+	}
+
+	for i, message := range messages {
+		deleteMessageBatchInput.Entries = append(deleteMessageBatchInput.Entries, &sqs.DeleteMessageBatchRequestEntry{
+			Id:            aws.String(strconv.Itoa(i)),
+			ReceiptHandle: aws.String(message.ReceiptHandle),
+		})
+	}
+
+	return deleteMessageBatchInput
+}
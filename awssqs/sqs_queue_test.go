@@ -2,6 +2,7 @@ package awssqs_test
 
 import (
 	"errors"
+	"strings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -21,6 +22,7 @@ var _ = Describe("SQS Queue", func() {
 	var (
 		queueName string
 		queueURL  string
+		endpoint  string
 
 		awsSession *session.Session
 		sqssvc     *sqs.SQS
@@ -35,6 +37,7 @@ var _ = Describe("SQS Queue", func() {
 	BeforeEach(func() {
 		queueName = "sqs-queue"
 		queueURL = "sqs-queue-url"
+		endpoint = ""
 	})
 
 	JustBeforeEach(func() {
@@ -45,7 +48,7 @@ var _ = Describe("SQS Queue", func() {
 		testSink = lagertest.NewTestSink()
 		logger.RegisterSink(testSink)
 
-		queue = NewSQSQueue(sqssvc, logger)
+		queue = NewSQSQueue(sqssvc, endpoint, logger)
 	})
 
 	var _ = Describe("Describe", func() {
@@ -62,14 +65,19 @@ var _ = Describe("SQS Queue", func() {
 
 		BeforeEach(func() {
 			properQueueDetails = QueueDetails{
-				QueueURL:               queueURL,
-				QueueArn:               "test-queue-arn",
-				DelaySeconds:           "test-delay-seconds",
-				MaximumMessageSize:     "test-maximum-message-size",
-				MessageRetentionPeriod: "test-message-retention-period",
-				Policy:                 "test-policy",
-				ReceiveMessageWaitTimeSeconds: "test-receive-message-wait-time-seconds",
-				VisibilityTimeout:             "test-visibility-timeout",
+				QueueURL:                              queueURL,
+				QueueArn:                              "test-queue-arn",
+				DelaySeconds:                          "test-delay-seconds",
+				MaximumMessageSize:                    "test-maximum-message-size",
+				MessageRetentionPeriod:                "test-message-retention-period",
+				Policy:                                "test-policy",
+				ReceiveMessageWaitTimeSeconds:         "test-receive-message-wait-time-seconds",
+				VisibilityTimeout:                     "test-visibility-timeout",
+				ApproximateNumberOfMessages:           "test-approximate-number-of-messages",
+				ApproximateNumberOfMessagesNotVisible: "test-approximate-number-of-messages-not-visible",
+				ApproximateNumberOfMessagesDelayed:    "test-approximate-number-of-messages-delayed",
+				CreatedTimestamp:                      "test-created-timestamp",
+				LastModifiedTimestamp:                 "test-last-modified-timestamp",
 			}
 
 			getQueueURLInput = &sqs.GetQueueUrlInput{
@@ -78,13 +86,18 @@ var _ = Describe("SQS Queue", func() {
 			getQueueURLError = nil
 
 			getQueueAttributes = map[string]*string{
-				"QueueArn":                      aws.String("test-queue-arn"),
-				"DelaySeconds":                  aws.String("test-delay-seconds"),
-				"MaximumMessageSize":            aws.String("test-maximum-message-size"),
-				"MessageRetentionPeriod":        aws.String("test-message-retention-period"),
-				"Policy":                        aws.String("test-policy"),
-				"ReceiveMessageWaitTimeSeconds": aws.String("test-receive-message-wait-time-seconds"),
-				"VisibilityTimeout":             aws.String("test-visibility-timeout"),
+				"QueueArn":                              aws.String("test-queue-arn"),
+				"DelaySeconds":                          aws.String("test-delay-seconds"),
+				"MaximumMessageSize":                    aws.String("test-maximum-message-size"),
+				"MessageRetentionPeriod":                aws.String("test-message-retention-period"),
+				"Policy":                                aws.String("test-policy"),
+				"ReceiveMessageWaitTimeSeconds":         aws.String("test-receive-message-wait-time-seconds"),
+				"VisibilityTimeout":                     aws.String("test-visibility-timeout"),
+				"ApproximateNumberOfMessages":           aws.String("test-approximate-number-of-messages"),
+				"ApproximateNumberOfMessagesNotVisible": aws.String("test-approximate-number-of-messages-not-visible"),
+				"ApproximateNumberOfMessagesDelayed":    aws.String("test-approximate-number-of-messages-delayed"),
+				"CreatedTimestamp":                      aws.String("test-created-timestamp"),
+				"LastModifiedTimestamp":                 aws.String("test-last-modified-timestamp"),
 			}
 			getQueueAttributesInput = &sqs.GetQueueAttributesInput{
 				QueueUrl:       aws.String(queueURL),
@@ -122,6 +135,20 @@ var _ = Describe("SQS Queue", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		Context("when there is a custom endpoint", func() {
+			BeforeEach(func() {
+				endpoint = "https://vpce-id.sqs.us-east-1.vpce.amazonaws.com/"
+				queueURL = "https://sqs.us-east-1.amazonaws.com/123456789012/sqs-queue"
+				getQueueAttributesInput.QueueUrl = aws.String(queueURL)
+			})
+
+			It("returns the Queue URL on the custom endpoint", func() {
+				queueDetails, err := queue.Describe(queueName)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(queueDetails.QueueURL).To(Equal("https://vpce-id.sqs.us-east-1.vpce.amazonaws.com/123456789012/sqs-queue"))
+			})
+		})
+
 		Context("when getting the Queue URL fails", func() {
 			BeforeEach(func() {
 				getQueueURLError = errors.New("operation failed")
@@ -235,6 +262,19 @@ var _ = Describe("SQS Queue", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		Context("when there is a custom endpoint", func() {
+			BeforeEach(func() {
+				endpoint = "http://localhost:4576"
+				queueURL = "http://localhost:4566/000000000000/sqs-queue"
+			})
+
+			It("returns the Queue URL on the custom endpoint", func() {
+				createdQueueURL, err := queue.Create(queueName, queueDetails)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(createdQueueURL).To(Equal("http://localhost:4576/000000000000/sqs-queue"))
+			})
+		})
+
 		Context("when has DelaySeconds", func() {
 			BeforeEach(func() {
 				queueDetails.DelaySeconds = "test-delay-seconds"
@@ -531,6 +571,75 @@ var _ = Describe("SQS Queue", func() {
 		})
 	})
 
+	var _ = Describe("SetPolicy", func() {
+		var (
+			getQueueURLError error
+
+			setQueueAttributesInput *sqs.SetQueueAttributesInput
+			setQueueAttributesError error
+		)
+
+		BeforeEach(func() {
+			getQueueURLError = nil
+
+			setQueueAttributesInput = &sqs.SetQueueAttributesInput{
+				QueueUrl: aws.String(queueURL),
+				Attributes: map[string]*string{
+					"Policy": aws.String(""),
+				},
+			}
+			setQueueAttributesError = nil
+		})
+
+		JustBeforeEach(func() {
+			sqssvc.Handlers.Clear()
+
+			sqsCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(MatchRegexp("GetQueueUrl|SetQueueAttributes"))
+				switch r.Operation.Name {
+				case "GetQueueUrl":
+					data := r.Data.(*sqs.GetQueueUrlOutput)
+					data.QueueUrl = aws.String(queueURL)
+					r.Error = getQueueURLError
+				case "SetQueueAttributes":
+					Expect(r.Params).To(BeAssignableToTypeOf(&sqs.SetQueueAttributesInput{}))
+					Expect(r.Params).To(Equal(setQueueAttributesInput))
+					r.Error = setQueueAttributesError
+				}
+			}
+			sqssvc.Handlers.Send.PushBack(sqsCall)
+		})
+
+		It("sends the Policy even if it is empty", func() {
+			err := queue.SetPolicy(queueName, "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when getting the Queue URL fails", func() {
+			BeforeEach(func() {
+				awsError := awserr.New("code", "message", errors.New("operation failed"))
+				getQueueURLError = awserr.NewRequestFailure(awsError, 400, "request-id")
+			})
+
+			It("returns the proper error", func() {
+				err := queue.SetPolicy(queueName, "")
+				Expect(err).To(Equal(ErrQueueDoesNotExist))
+			})
+		})
+
+		Context("when setting the Queue Attributes fails", func() {
+			BeforeEach(func() {
+				setQueueAttributesError = awserr.New("code", "message", errors.New("operation failed"))
+			})
+
+			It("returns the proper error", func() {
+				err := queue.SetPolicy(queueName, "")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("code: message"))
+			})
+		})
+	})
+
 	var _ = Describe("Delete", func() {
 		var (
 			getQueueURLInput *sqs.GetQueueUrlInput
@@ -652,4 +761,439 @@ var _ = Describe("SQS Queue", func() {
 			})
 		})
 	})
+
+	var _ = Describe("ReceiveMessages", func() {
+		var (
+			properMessages []Message
+
+			getQueueURLInput *sqs.GetQueueUrlInput
+			getQueueURLError error
+
+			receiveMessageInput    *sqs.ReceiveMessageInput
+			receiveMessageMessages []*sqs.Message
+			receiveMessageError    error
+		)
+
+		BeforeEach(func() {
+			properMessages = []Message{
+				Message{
+					MessageID:     "message-id",
+					ReceiptHandle: "receipt-handle",
+					Body:          "body",
+					MessageAttributes: map[string]MessageAttribute{
+						"attribute": MessageAttribute{
+							DataType:    "String",
+							StringValue: "value",
+						},
+					},
+				},
+			}
+
+			getQueueURLInput = &sqs.GetQueueUrlInput{
+				QueueName: aws.String(queueName),
+			}
+			getQueueURLError = nil
+
+			receiveMessageInput = &sqs.ReceiveMessageInput{
+				QueueUrl:              aws.String(queueURL),
+				MaxNumberOfMessages:   aws.Int64(10),
+				MessageAttributeNames: aws.StringSlice([]string{"All"}),
+				WaitTimeSeconds:       aws.Int64(1),
+			}
+			receiveMessageMessages = []*sqs.Message{
+				&sqs.Message{
+					MessageId:     aws.String("message-id"),
+					ReceiptHandle: aws.String("receipt-handle"),
+					Body:          aws.String("body"),
+					MD5OfBody:     aws.String("841a2d689ad86bd1611447453c22c6fc"),
+					MessageAttributes: map[string]*sqs.MessageAttributeValue{
+						"attribute": &sqs.MessageAttributeValue{
+							DataType:    aws.String("String"),
+							StringValue: aws.String("value"),
+						},
+					},
+				},
+			}
+			receiveMessageError = nil
+		})
+
+		JustBeforeEach(func() {
+			sqssvc.Handlers.Clear()
+
+			sqsCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(MatchRegexp("GetQueueUrl|ReceiveMessage"))
+				switch r.Operation.Name {
+				case "GetQueueUrl":
+					Expect(r.Params).To(BeAssignableToTypeOf(&sqs.GetQueueUrlInput{}))
+					Expect(r.Params).To(Equal(getQueueURLInput))
+					data := r.Data.(*sqs.GetQueueUrlOutput)
+					data.QueueUrl = aws.String(queueURL)
+					r.Error = getQueueURLError
+				case "ReceiveMessage":
+					Expect(r.Params).To(BeAssignableToTypeOf(&sqs.ReceiveMessageInput{}))
+					Expect(r.Params).To(Equal(receiveMessageInput))
+					data := r.Data.(*sqs.ReceiveMessageOutput)
+					data.Messages = receiveMessageMessages
+					r.Error = receiveMessageError
+				}
+			}
+			sqssvc.Handlers.Send.PushBack(sqsCall)
+		})
+
+		It("receives the Messages", func() {
+			messages, err := queue.ReceiveMessages(queueName, 10)
+			Expect(messages).To(Equal(properMessages))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when getting the Queue URL fails", func() {
+			BeforeEach(func() {
+				awsError := awserr.New("code", "message", errors.New("operation failed"))
+				getQueueURLError = awserr.NewRequestFailure(awsError, 400, "request-id")
+			})
+
+			It("returns the proper error", func() {
+				_, err := queue.ReceiveMessages(queueName, 10)
+				Expect(err).To(HaveOccurred())
+				Expect(err).To(Equal(ErrQueueDoesNotExist))
+			})
+		})
+
+		Context("when receiving the Messages fails", func() {
+			BeforeEach(func() {
+				receiveMessageError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := queue.ReceiveMessages(queueName, 10)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("and it is an AWS error", func() {
+				BeforeEach(func() {
+					receiveMessageError = awserr.New("code", "message", errors.New("operation failed"))
+				})
+
+				It("returns the proper error", func() {
+					_, err := queue.ReceiveMessages(queueName, 10)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("code: message"))
+				})
+			})
+
+			Context("and the Queue does not exist", func() {
+				BeforeEach(func() {
+					awsError := awserr.New("AWS.SimpleQueueService.NonExistentQueue", "message", errors.New("operation failed"))
+					receiveMessageError = awserr.NewRequestFailure(awsError, 400, "request-id")
+				})
+
+				It("returns the proper error", func() {
+					_, err := queue.ReceiveMessages(queueName, 10)
+					Expect(err).To(Equal(ErrQueueDoesNotExist))
+				})
+			})
+		})
+
+		It("does not log the Message bodies", func() {
+			_, err := queue.ReceiveMessages(queueName, 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testSink.Buffer().Contents()).ToNot(ContainSubstring("body"))
+			Expect(testSink.Buffer().Contents()).To(ContainSubstring("message-id"))
+		})
+	})
+
+	var _ = Describe("SendMessages", func() {
+		var (
+			messages []Message
+
+			getQueueURLInput *sqs.GetQueueUrlInput
+			getQueueURLError error
+
+			sendMessageBatchInput  *sqs.SendMessageBatchInput
+			sendMessageBatchFailed []*sqs.BatchResultErrorEntry
+			sendMessageBatchError  error
+		)
+
+		BeforeEach(func() {
+			messages = []Message{
+				Message{
+					Body: "body-1",
+					MessageAttributes: map[string]MessageAttribute{
+						"attribute": MessageAttribute{
+							DataType:    "String",
+							StringValue: "value",
+						},
+					},
+				},
+				Message{
+					Body: "body-2",
+				},
+			}
+
+			getQueueURLInput = &sqs.GetQueueUrlInput{
+				QueueName: aws.String(queueName),
+			}
+			getQueueURLError = nil
+
+			sendMessageBatchInput = &sqs.SendMessageBatchInput{
+				QueueUrl: aws.String(queueURL),
+				Entries: []*sqs.SendMessageBatchRequestEntry{
+					&sqs.SendMessageBatchRequestEntry{
+						Id:          aws.String("0"),
+						MessageBody: aws.String("body-1"),
+						MessageAttributes: map[string]*sqs.MessageAttributeValue{
+							"attribute": &sqs.MessageAttributeValue{
+								DataType:    aws.String("String"),
+								StringValue: aws.String("value"),
+							},
+						},
+					},
+					&sqs.SendMessageBatchRequestEntry{
+						Id:          aws.String("1"),
+						MessageBody: aws.String("body-2"),
+					},
+				},
+			}
+			sendMessageBatchFailed = nil
+			sendMessageBatchError = nil
+		})
+
+		JustBeforeEach(func() {
+			sqssvc.Handlers.Clear()
+
+			sqsCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(MatchRegexp("GetQueueUrl|SendMessageBatch"))
+				switch r.Operation.Name {
+				case "GetQueueUrl":
+					Expect(r.Params).To(BeAssignableToTypeOf(&sqs.GetQueueUrlInput{}))
+					Expect(r.Params).To(Equal(getQueueURLInput))
+					data := r.Data.(*sqs.GetQueueUrlOutput)
+					data.QueueUrl = aws.String(queueURL)
+					r.Error = getQueueURLError
+				case "SendMessageBatch":
+					Expect(r.Params).To(BeAssignableToTypeOf(&sqs.SendMessageBatchInput{}))
+					Expect(r.Params).To(Equal(sendMessageBatchInput))
+					data := r.Data.(*sqs.SendMessageBatchOutput)
+					data.Failed = sendMessageBatchFailed
+					r.Error = sendMessageBatchError
+				}
+			}
+			sqssvc.Handlers.Send.PushBack(sqsCall)
+		})
+
+		It("sends the Messages", func() {
+			err := queue.SendMessages(queueName, messages)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when some Messages are not sent", func() {
+			BeforeEach(func() {
+				sendMessageBatchFailed = []*sqs.BatchResultErrorEntry{
+					&sqs.BatchResultErrorEntry{
+						Id:      aws.String("1"),
+						Code:    aws.String("code"),
+						Message: aws.String("message"),
+					},
+				}
+			})
+
+			It("returns the proper error", func() {
+				err := queue.SendMessages(queueName, messages)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Failed to send 1 messages: code: message"))
+			})
+		})
+
+		Context("when sending the Messages fails", func() {
+			BeforeEach(func() {
+				sendMessageBatchError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				err := queue.SendMessages(queueName, messages)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("and the Queue does not exist", func() {
+				BeforeEach(func() {
+					awsError := awserr.New("AWS.SimpleQueueService.NonExistentQueue", "message", errors.New("operation failed"))
+					sendMessageBatchError = awserr.NewRequestFailure(awsError, 400, "request-id")
+				})
+
+				It("returns the proper error", func() {
+					err := queue.SendMessages(queueName, messages)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(ErrQueueDoesNotExist))
+				})
+			})
+
+			Context("and it is another 400 error", func() {
+				BeforeEach(func() {
+					awsError := awserr.New("AWS.SimpleQueueService.BatchRequestTooLong", "message", errors.New("operation failed"))
+					sendMessageBatchError = awserr.NewRequestFailure(awsError, 400, "request-id")
+				})
+
+				It("returns the proper error", func() {
+					err := queue.SendMessages(queueName, messages)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("AWS.SimpleQueueService.BatchRequestTooLong: message"))
+				})
+			})
+		})
+
+		It("does not log the Message bodies", func() {
+			err := queue.SendMessages(queueName, messages)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testSink.Buffer().Contents()).ToNot(ContainSubstring("body-1"))
+		})
+
+		Context("when the Messages exceed the batch payload size", func() {
+			var batchSizes []int
+
+			BeforeEach(func() {
+				largeBody := strings.Repeat("x", 100*1024)
+				messages = []Message{
+					Message{Body: largeBody},
+					Message{Body: largeBody},
+					Message{Body: largeBody},
+					Message{Body: "small"},
+				}
+				batchSizes = []int{}
+			})
+
+			JustBeforeEach(func() {
+				sqssvc.Handlers.Clear()
+
+				sqsCall = func(r *request.Request) {
+					switch r.Operation.Name {
+					case "GetQueueUrl":
+						data := r.Data.(*sqs.GetQueueUrlOutput)
+						data.QueueUrl = aws.String(queueURL)
+					case "SendMessageBatch":
+						batchSizes = append(batchSizes, len(r.Params.(*sqs.SendMessageBatchInput).Entries))
+					}
+				}
+				sqssvc.Handlers.Send.PushBack(sqsCall)
+			})
+
+			It("splits them in several batches", func() {
+				err := queue.SendMessages(queueName, messages)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(batchSizes).To(Equal([]int{2, 2}))
+			})
+		})
+	})
+
+	var _ = Describe("DeleteMessages", func() {
+		var (
+			messages []Message
+
+			getQueueURLInput *sqs.GetQueueUrlInput
+			getQueueURLError error
+
+			deleteMessageBatchInput  *sqs.DeleteMessageBatchInput
+			deleteMessageBatchFailed []*sqs.BatchResultErrorEntry
+			deleteMessageBatchError  error
+		)
+
+		BeforeEach(func() {
+			messages = []Message{
+				Message{ReceiptHandle: "receipt-handle-1"},
+				Message{ReceiptHandle: "receipt-handle-2"},
+			}
+
+			getQueueURLInput = &sqs.GetQueueUrlInput{
+				QueueName: aws.String(queueName),
+			}
+			getQueueURLError = nil
+
+			deleteMessageBatchInput = &sqs.DeleteMessageBatchInput{
+				QueueUrl: aws.String(queueURL),
+				Entries: []*sqs.DeleteMessageBatchRequestEntry{
+					&sqs.DeleteMessageBatchRequestEntry{
+						Id:            aws.String("0"),
+						ReceiptHandle: aws.String("receipt-handle-1"),
+					},
+					&sqs.DeleteMessageBatchRequestEntry{
+						Id:            aws.String("1"),
+						ReceiptHandle: aws.String("receipt-handle-2"),
+					},
+				},
+			}
+			deleteMessageBatchFailed = nil
+			deleteMessageBatchError = nil
+		})
+
+		JustBeforeEach(func() {
+			sqssvc.Handlers.Clear()
+
+			sqsCall = func(r *request.Request) {
+				Expect(r.Operation.Name).To(MatchRegexp("GetQueueUrl|DeleteMessageBatch"))
+				switch r.Operation.Name {
+				case "GetQueueUrl":
+					Expect(r.Params).To(BeAssignableToTypeOf(&sqs.GetQueueUrlInput{}))
+					Expect(r.Params).To(Equal(getQueueURLInput))
+					data := r.Data.(*sqs.GetQueueUrlOutput)
+					data.QueueUrl = aws.String(queueURL)
+					r.Error = getQueueURLError
+				case "DeleteMessageBatch":
+					Expect(r.Params).To(BeAssignableToTypeOf(&sqs.DeleteMessageBatchInput{}))
+					Expect(r.Params).To(Equal(deleteMessageBatchInput))
+					data := r.Data.(*sqs.DeleteMessageBatchOutput)
+					data.Failed = deleteMessageBatchFailed
+					r.Error = deleteMessageBatchError
+				}
+			}
+			sqssvc.Handlers.Send.PushBack(sqsCall)
+		})
+
+		It("deletes the Messages", func() {
+			err := queue.DeleteMessages(queueName, messages)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when some Messages are not deleted", func() {
+			BeforeEach(func() {
+				deleteMessageBatchFailed = []*sqs.BatchResultErrorEntry{
+					&sqs.BatchResultErrorEntry{
+						Id:      aws.String("0"),
+						Code:    aws.String("code"),
+						Message: aws.String("message"),
+					},
+				}
+			})
+
+			It("returns the proper error", func() {
+				err := queue.DeleteMessages(queueName, messages)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Failed to delete 1 messages: code: message"))
+			})
+		})
+
+		Context("when deleting the Messages fails", func() {
+			BeforeEach(func() {
+				deleteMessageBatchError = awserr.New("code", "message", errors.New("operation failed"))
+			})
+
+			It("returns the proper error", func() {
+				err := queue.DeleteMessages(queueName, messages)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("code: message"))
+			})
+
+			Context("and the Queue does not exist", func() {
+				BeforeEach(func() {
+					awsError := awserr.New("AWS.SimpleQueueService.NonExistentQueue", "message", errors.New("operation failed"))
+					deleteMessageBatchError = awserr.NewRequestFailure(awsError, 400, "request-id")
+				})
+
+				It("returns the proper error", func() {
+					err := queue.DeleteMessages(queueName, messages)
+					Expect(err).To(Equal(ErrQueueDoesNotExist))
+				})
+			})
+		})
+	})
 })
@@ -0,0 +1,204 @@
+package main_test
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/sqs-broker"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cf-platform-eng/sqs-broker/auditlog"
+	"github.com/cf-platform-eng/sqs-broker/awsemulator"
+	"github.com/cf-platform-eng/sqs-broker/sqsbroker"
+)
+
+var _ = Describe("NewBroker", func() {
+	var (
+		tmpDir         string
+		emulator       *awsemulator.Emulator
+		emulatorServer *httptest.Server
+		config         *Config
+		logger         lager.Logger
+	)
+
+	provision := func(brokerAPI http.Handler) *httptest.ResponseRecorder {
+		request, err := http.NewRequest("PUT", "/v2/service_instances/instance-id", strings.NewReader(`{"service_id":"service-id","plan_id":"plan-id","organization_guid":"organization-id","space_guid":"space-id"}`))
+		Expect(err).ToNot(HaveOccurred())
+		request.SetBasicAuth("broker-username", "broker-password")
+		request.Header.Set("X-Broker-API-Version", "2.13")
+
+		recorder := httptest.NewRecorder()
+		brokerAPI.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "broker")
+		Expect(err).ToNot(HaveOccurred())
+
+		os.Setenv("AWS_ACCESS_KEY_ID", "access-key-id")
+		os.Setenv("AWS_SECRET_ACCESS_KEY", "secret-access-key")
+
+		emulator = awsemulator.New(awsemulator.DefaultRegion, awsemulator.DefaultAccountID)
+		emulatorServer = httptest.NewTLSServer(emulator)
+
+		config = &Config{
+			Username: "broker-username",
+			Password: "broker-password",
+			SQSConfig: sqsbroker.Config{
+				Region:      awsemulator.DefaultRegion,
+				SQSEndpoint: emulatorServer.URL,
+				IAMEndpoint: emulatorServer.URL,
+				SQSPrefix:   "cf",
+				Catalog: sqsbroker.Catalog{
+					Services: []sqsbroker.Service{
+						{
+							ID:          "service-id",
+							Name:        "sqs",
+							Description: "SQS queues",
+							Plans: []sqsbroker.ServicePlan{
+								{ID: "plan-id", Name: "standard", Description: "Standard queue"},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		logger = lager.NewLogger("broker_test")
+		logger.RegisterSink(lagertest.NewTestSink())
+	})
+
+	AfterEach(func() {
+		emulatorServer.Close()
+		os.Unsetenv("AWS_ACCESS_KEY_ID")
+		os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+		os.RemoveAll(tmpDir)
+	})
+
+	It("does not trust an endpoint with an unknown CA", func() {
+		_, brokerAPI, err := NewBroker(config, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		recorder := provision(brokerAPI)
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(emulator.QueueNames()).To(BeEmpty())
+	})
+
+	It("trusts an endpoint signed by the CA bundle", func() {
+		caBundleFile := filepath.Join(tmpDir, "ca.pem")
+		caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: emulatorServer.TLS.Certificates[0].Certificate[0]})
+		Expect(ioutil.WriteFile(caBundleFile, caBundle, 0600)).To(Succeed())
+		config.SQSConfig.CABundleFile = caBundleFile
+
+		_, brokerAPI, err := NewBroker(config, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		recorder := provision(brokerAPI)
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+		Expect(emulator.QueueNames()).To(Equal([]string{"cf-instance-id"}))
+	})
+
+	It("skips the SSL validation", func() {
+		config.SQSConfig.SkipSSLValidation = true
+
+		_, brokerAPI, err := NewBroker(config, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		recorder := provision(brokerAPI)
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+		Expect(emulator.QueueNames()).To(Equal([]string{"cf-instance-id"}))
+	})
+
+	It("returns error if the CA bundle does not exist", func() {
+		config.SQSConfig.CABundleFile = filepath.Join(tmpDir, "unknown.pem")
+
+		_, _, err := NewBroker(config, logger)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("Loading CA bundle: "))
+	})
+
+	It("returns error if the CA bundle has no certificates", func() {
+		caBundleFile := filepath.Join(tmpDir, "ca.pem")
+		Expect(ioutil.WriteFile(caBundleFile, []byte("not a certificate"), 0600)).To(Succeed())
+		config.SQSConfig.CABundleFile = caBundleFile
+
+		_, _, err := NewBroker(config, logger)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Loading CA bundle: No PEM certificates found in " + caBundleFile))
+	})
+
+	It("writes the audit log", func() {
+		auditLogFile := filepath.Join(tmpDir, "audit.log")
+		config.AuditLogFile = auditLogFile
+		config.AuditLogKey = "audit-log-key"
+		config.SQSConfig.SkipSSLValidation = true
+
+		_, brokerAPI, err := NewBroker(config, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		recorder := provision(brokerAPI)
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+		auditLog, err := ioutil.ReadFile(auditLogFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(auditLog)).To(ContainSubstring(`"action":"create-queue"`))
+		Expect(string(auditLog)).To(ContainSubstring(`"action":"provision"`))
+		_, err = auditlog.Verify(strings.NewReader(string(auditLog)), []byte("audit-log-key"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("serves the admin API when the admin credentials are set", func() {
+		config.AdminUsername = "admin-username"
+		config.AdminPassword = "admin-password"
+		config.SQSConfig.SkipSSLValidation = true
+
+		_, brokerAPI, err := NewBroker(config, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		recorder := provision(brokerAPI)
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+		request, err := http.NewRequest("GET", "/admin/instances", nil)
+		Expect(err).ToNot(HaveOccurred())
+		request.SetBasicAuth("admin-username", "admin-password")
+
+		recorder = httptest.NewRecorder()
+		brokerAPI.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"instance_id":"instance-id"`))
+	})
+
+	It("does not serve the admin API without admin credentials", func() {
+		_, brokerAPI, err := NewBroker(config, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		request, err := http.NewRequest("GET", "/admin/instances", nil)
+		Expect(err).ToNot(HaveOccurred())
+		request.SetBasicAuth("broker-username", "broker-password")
+
+		recorder := httptest.NewRecorder()
+		brokerAPI.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("returns error if the audit log can not be opened", func() {
+		config.AuditLogFile = filepath.Join(tmpDir, "unknown", "audit.log")
+
+		_, _, err := NewBroker(config, logger)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("Opening audit log: "))
+	})
+})
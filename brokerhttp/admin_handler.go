@@ -0,0 +1,193 @@
+package brokerhttp
+
+import (
+	"net/http"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/gorilla/mux"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/sqs-broker/auditlog"
+	"github.com/cf-platform-eng/sqs-broker/sqsbroker"
+)
+
+const adminPlatform = "admin"
+
+type AdminBroker interface {
+	AdminInstances() ([]sqsbroker.AdminInstance, error)
+	AdminBindings() ([]sqsbroker.AdminBinding, error)
+	AdminInstance(instanceID string) (sqsbroker.AdminInstanceResponse, error)
+	ForceDeleteInstance(instanceID string) (bool, error)
+	ForceDeleteBinding(bindingID string) error
+	RotateBindingKeys(bindingID string) (sqsbroker.BindingResponse, error)
+	Reconcile(dryRun bool) (sqsbroker.ReconcileReport, error)
+}
+
+type adminHandler struct {
+	adminBroker AdminBroker
+	auditLogger auditlog.Logger
+	logger      lager.Logger
+}
+
+// NewAdmin serves the operator API under /admin, authenticated with its own credentials
+func NewAdmin(
+	adminBroker AdminBroker,
+	auditLogger auditlog.Logger,
+	logger lager.Logger,
+	adminCredentials brokerapi.BrokerCredentials,
+) http.Handler {
+	h := &adminHandler{
+		adminBroker: adminBroker,
+		auditLogger: auditLogger,
+		logger:      logger.Session("admin-http"),
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/instances", h.listInstances).Methods("GET")
+	router.HandleFunc("/admin/instances/{instance_id}", h.getInstance).Methods("GET")
+	router.HandleFunc("/admin/instances/{instance_id}", h.deleteInstance).Methods("DELETE")
+	router.HandleFunc("/admin/bindings", h.listBindings).Methods("GET")
+	router.HandleFunc("/admin/bindings/{binding_id}", h.deleteBinding).Methods("DELETE")
+	router.HandleFunc("/admin/bindings/{binding_id}/rotate_keys", h.rotateBindingKeys).Methods("POST")
+	router.HandleFunc("/admin/reconcile", h.reconcile).Methods("POST")
+
+	return checkAuth(router, adminCredentials)
+}
+
+func (h *adminHandler) listInstances(w http.ResponseWriter, req *http.Request) {
+	instances, err := h.adminBroker.AdminInstances()
+	if err != nil {
+		h.logger.Error("list-instances-failed", err)
+		h.respondError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, instances)
+}
+
+func (h *adminHandler) getInstance(w http.ResponseWriter, req *http.Request) {
+	instanceID := mux.Vars(req)["instance_id"]
+
+	instance, err := h.adminBroker.AdminInstance(instanceID)
+	if err != nil {
+		h.logger.Error("get-instance-failed", err, lager.Data{instanceIDLogKey: instanceID})
+		h.respondError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, instance)
+}
+
+func (h *adminHandler) deleteInstance(w http.ResponseWriter, req *http.Request) {
+	instanceID := mux.Vars(req)["instance_id"]
+
+	asynch, err := h.adminBroker.ForceDeleteInstance(instanceID)
+	if err != nil {
+		h.logger.Error("delete-instance-failed", err, lager.Data{instanceIDLogKey: instanceID})
+		h.audit(req, "admin-delete-instance", instanceID, "", h.respondError(w, err))
+		return
+	}
+
+	if asynch {
+		respond(w, http.StatusAccepted, EmptyResponse{})
+		h.audit(req, "admin-delete-instance", instanceID, "", http.StatusAccepted)
+		return
+	}
+
+	respond(w, http.StatusOK, EmptyResponse{})
+	h.audit(req, "admin-delete-instance", instanceID, "", http.StatusOK)
+}
+
+func (h *adminHandler) listBindings(w http.ResponseWriter, req *http.Request) {
+	bindings, err := h.adminBroker.AdminBindings()
+	if err != nil {
+		h.logger.Error("list-bindings-failed", err)
+		h.respondError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, bindings)
+}
+
+func (h *adminHandler) deleteBinding(w http.ResponseWriter, req *http.Request) {
+	bindingID := mux.Vars(req)["binding_id"]
+
+	if err := h.adminBroker.ForceDeleteBinding(bindingID); err != nil {
+		h.logger.Error("delete-binding-failed", err, lager.Data{bindingIDLogKey: bindingID})
+		h.audit(req, "admin-delete-binding", "", bindingID, h.respondError(w, err))
+		return
+	}
+
+	respond(w, http.StatusOK, EmptyResponse{})
+	h.audit(req, "admin-delete-binding", "", bindingID, http.StatusOK)
+}
+
+func (h *adminHandler) rotateBindingKeys(w http.ResponseWriter, req *http.Request) {
+	bindingID := mux.Vars(req)["binding_id"]
+
+	bindingResponse, err := h.adminBroker.RotateBindingKeys(bindingID)
+	if err != nil {
+		h.logger.Error("rotate-binding-keys-failed", err, lager.Data{bindingIDLogKey: bindingID})
+		h.audit(req, "admin-rotate-binding-keys", "", bindingID, h.respondError(w, err))
+		return
+	}
+
+	respond(w, http.StatusOK, bindingResponse)
+	h.audit(req, "admin-rotate-binding-keys", "", bindingID, http.StatusOK)
+}
+
+func (h *adminHandler) reconcile(w http.ResponseWriter, req *http.Request) {
+	report, err := h.adminBroker.Reconcile(req.FormValue("dry_run") == "true")
+	if err != nil {
+		h.logger.Error("reconcile-failed", err)
+		h.audit(req, "admin-reconcile", "", "", h.respondError(w, err))
+		return
+	}
+
+	respond(w, http.StatusOK, report)
+	h.audit(req, "admin-reconcile", "", "", http.StatusOK)
+}
+
+func (h *adminHandler) respondError(w http.ResponseWriter, err error) int {
+	if brokerErr, ok := err.(sqsbroker.BrokerError); ok {
+		return respondBrokerError(w, brokerErr)
+	}
+
+	status := http.StatusInternalServerError
+	if err == brokerapi.ErrInstanceDoesNotExist || err == brokerapi.ErrBindingDoesNotExist {
+		status = http.StatusNotFound
+	}
+
+	respond(w, status, ErrorResponse{
+		Description: err.Error(),
+	})
+
+	return status
+}
+
+// audit records the admin requests changing instances and bindings, with the admin username as originating identity
+func (h *adminHandler) audit(req *http.Request, action string, instanceID string, bindingID string, status int) {
+	username, _, _ := req.BasicAuth()
+	event := auditlog.Event{
+		Action:  action,
+		Outcome: auditlog.OutcomeSuccess,
+		OriginatingIdentity: &auditlog.OriginatingIdentity{
+			Platform: adminPlatform,
+			Value:    map[string]interface{}{"username": username},
+		},
+		InstanceID: instanceID,
+		BindingID:  bindingID,
+		Request: &auditlog.Request{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Status: status,
+		},
+	}
+	if status >= http.StatusBadRequest {
+		event.Outcome = auditlog.OutcomeFailure
+	}
+
+	if err := h.auditLogger.Log(event); err != nil {
+		h.logger.Error("audit-log-failed", err, lager.Data{"action": action})
+	}
+}
@@ -0,0 +1,224 @@
+package brokerhttp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/sqs-broker/brokerhttp"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cf-platform-eng/sqs-broker/auditlog"
+	auditfake "github.com/cf-platform-eng/sqs-broker/auditlog/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awsiam"
+	iamfake "github.com/cf-platform-eng/sqs-broker/awsiam/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awssns"
+	snsfake "github.com/cf-platform-eng/sqs-broker/awssns/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awssqs"
+	sqsfake "github.com/cf-platform-eng/sqs-broker/awssqs/fakes"
+	"github.com/cf-platform-eng/sqs-broker/brokerstore"
+	"github.com/cf-platform-eng/sqs-broker/sqsbroker"
+)
+
+var _ = Describe("Admin HTTP Handler", func() {
+	var (
+		queue *sqsfake.FakeQueue
+		topic *snsfake.FakeTopic
+		user  *iamfake.FakeUser
+		store *brokerstore.JSONStore
+
+		auditLogger *auditfake.FakeLogger
+
+		handler http.Handler
+
+		adminCredentials = brokerapi.BrokerCredentials{
+			Username: "admin-username",
+			Password: "admin-password",
+		}
+	)
+
+	BeforeEach(func() {
+		queue = &sqsfake.FakeQueue{}
+		topic = &snsfake.FakeTopic{}
+		user = &iamfake.FakeUser{}
+		store = brokerstore.NewMemoryStore()
+
+		auditLogger = &auditfake.FakeLogger{}
+
+		Expect(store.SaveInstance(brokerstore.Instance{
+			InstanceID: "instance-id",
+			ServiceID:  "Service-1",
+			PlanID:     "Plan-1",
+		})).To(Succeed())
+		Expect(store.SaveBinding(brokerstore.Binding{
+			BindingID:   "binding-id",
+			InstanceID:  "instance-id",
+			ServiceID:   "Service-1",
+			PlanID:      "Plan-1",
+			AccessKeyID: "access-key-id",
+		})).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		config := sqsbroker.Config{
+			Region:    "sqs-region",
+			SQSPrefix: "cf",
+			Catalog: sqsbroker.Catalog{
+				Services: []sqsbroker.Service{
+					sqsbroker.Service{
+						ID:          "Service-1",
+						Name:        "Service 1",
+						Description: "This is the Service 1",
+						Bindable:    true,
+						Plans: []sqsbroker.ServicePlan{
+							sqsbroker.ServicePlan{
+								ID:          "Plan-1",
+								Name:        "Plan 1",
+								Description: "This is the Plan 1",
+							},
+						},
+					},
+				},
+			},
+		}
+
+		logger := lager.NewLogger("admin_handler_test")
+		logger.RegisterSink(lagertest.NewTestSink())
+
+		clientsFactory := func(region string, accountRole sqsbroker.AccountRole) (awssqs.Queue, awssns.Topic, awsiam.User) {
+			return queue, topic, user
+		}
+		serviceBroker := sqsbroker.New(config, clientsFactory, store, nil, auditlog.Discard, logger)
+		handler = NewAdmin(serviceBroker, auditLogger, logger, adminCredentials)
+	})
+
+	doRequest := func(method string, path string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(method, path, nil)
+		Expect(err).ToNot(HaveOccurred())
+		request.SetBasicAuth(adminCredentials.Username, adminCredentials.Password)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	It("rejects requests without the admin credentials", func() {
+		request, err := http.NewRequest("DELETE", "/admin/instances/instance-id", nil)
+		Expect(err).ToNot(HaveOccurred())
+		request.SetBasicAuth("username", "password")
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		Expect(queue.DeleteCalled).To(BeFalse())
+	})
+
+	Describe("GET /admin/instances", func() {
+		It("lists the Instances", func() {
+			queue.DescribeQueueDetails = awssqs.QueueDetails{QueueArn: "queue-arn"}
+
+			recorder := doRequest("GET", "/admin/instances")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var instances []sqsbroker.AdminInstance
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &instances)).To(Succeed())
+			Expect(instances).To(HaveLen(1))
+			Expect(instances[0].InstanceID).To(Equal("instance-id"))
+			Expect(instances[0].Arn).To(Equal("queue-arn"))
+		})
+	})
+
+	Describe("GET /admin/instances/{instance_id}", func() {
+		It("returns 404 when the Queue does not exist", func() {
+			queue.DescribeError = awssqs.ErrQueueDoesNotExist
+
+			recorder := doRequest("GET", "/admin/instances/instance-id")
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("GET /admin/bindings", func() {
+		It("lists the Bindings", func() {
+			recorder := doRequest("GET", "/admin/bindings")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"binding_id":"binding-id"`))
+		})
+	})
+
+	Describe("DELETE /admin/instances/{instance_id}", func() {
+		It("deletes the Instance and audits the request with the admin username", func() {
+			recorder := doRequest("DELETE", "/admin/instances/instance-id")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(queue.DeleteCalled).To(BeTrue())
+
+			Expect(auditLogger.LogEvents).To(HaveLen(1))
+			event := auditLogger.LogEvents[0]
+			Expect(event.Action).To(Equal("admin-delete-instance"))
+			Expect(event.Outcome).To(Equal(auditlog.OutcomeSuccess))
+			Expect(event.InstanceID).To(Equal("instance-id"))
+			Expect(event.OriginatingIdentity).To(Equal(&auditlog.OriginatingIdentity{
+				Platform: "admin",
+				Value:    map[string]interface{}{"username": "admin-username"},
+			}))
+		})
+	})
+
+	Describe("DELETE /admin/bindings/{binding_id}", func() {
+		It("returns 404 and audits the failure when the Binding is unknown", func() {
+			recorder := doRequest("DELETE", "/admin/bindings/unknown-binding-id")
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+
+			Expect(auditLogger.LogEvents).To(HaveLen(1))
+			Expect(auditLogger.LogEvents[0].Outcome).To(Equal(auditlog.OutcomeFailure))
+			Expect(auditLogger.LogEvents[0].BindingID).To(Equal("unknown-binding-id"))
+		})
+	})
+
+	Describe("POST /admin/bindings/{binding_id}/rotate_keys", func() {
+		It("returns the new credentials", func() {
+			user.CreateAccessKeyAccessKeyID = "new-access-key-id"
+			user.CreateAccessKeySecretAccessKey = "new-secret-access-key"
+
+			recorder := doRequest("POST", "/admin/bindings/binding-id/rotate_keys")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"password":"new-secret-access-key"`))
+		})
+
+		It("returns 422 when the Binding is in progress", func() {
+			Expect(store.SaveBinding(brokerstore.Binding{
+				BindingID:          "binding-id",
+				InstanceID:         "instance-id",
+				LastOperationState: brokerapi.LastOperationInProgress,
+			})).To(Succeed())
+
+			recorder := doRequest("POST", "/admin/bindings/binding-id/rotate_keys")
+			Expect(recorder.Code).To(Equal(422))
+			Expect(recorder.Body.String()).To(ContainSubstring("ConcurrencyError"))
+		})
+	})
+
+	Describe("POST /admin/reconcile", func() {
+		It("keeps the records on a dry run", func() {
+			queue.DescribeError = awssqs.ErrQueueDoesNotExist
+
+			recorder := doRequest("POST", "/admin/reconcile?dry_run=true")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var report sqsbroker.ReconcileReport
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &report)).To(Succeed())
+			Expect(report.DryRun).To(BeTrue())
+			Expect(report.MissingInstances).To(Equal([]string{"instance-id"}))
+
+			_, err := store.GetInstance("instance-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(auditLogger.LogEvents[0].Action).To(Equal("admin-reconcile"))
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package brokerhttp_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestBrokerHTTP(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Broker HTTP Suite")
+}
@@ -0,0 +1,564 @@
+package brokerhttp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/gorilla/mux"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/sqs-broker/auditlog"
+	"github.com/cf-platform-eng/sqs-broker/sqsbroker"
+)
+
+const instanceIDLogKey = "instance-id"
+const bindingIDLogKey = "binding-id"
+
+const originatingIdentityHeader = "X-Broker-API-Originating-Identity"
+
+var errInvalidOriginatingIdentity = errors.New("Originating identity must be '<platform> <base64 encoded JSON value>'")
+
+type ServiceBroker interface {
+	brokerapi.ServiceBroker
+	ProvisionWithContext(instanceID string, details brokerapi.ProvisionDetails, requestContext map[string]interface{}, acceptsIncomplete bool) (brokerapi.ProvisioningResponse, bool, error)
+	UpdateWithContext(instanceID string, details brokerapi.UpdateDetails, requestContext map[string]interface{}, acceptsIncomplete bool) (sqsbroker.UpdateResponse, bool, error)
+	ForceDeprovision(instanceID string, details brokerapi.DeprovisionDetails, acceptsIncomplete bool) (bool, error)
+	GetInstance(instanceID string) (sqsbroker.InstanceResponse, error)
+	AsyncBind(instanceID, bindingID string, details brokerapi.BindDetails, requestContext map[string]interface{}, acceptsIncomplete bool) (brokerapi.BindingResponse, bool, error)
+	AsyncUnbind(instanceID, bindingID string, details brokerapi.UnbindDetails, acceptsIncomplete bool) (bool, error)
+	GetBinding(instanceID, bindingID string) (sqsbroker.BindingResponse, error)
+	BindingLastOperation(instanceID, bindingID string) (brokerapi.LastOperationResponse, error)
+}
+
+type ErrorResponse struct {
+	Error       string `json:"error,omitempty"`
+	Description string `json:"description"`
+}
+
+type EmptyResponse struct{}
+
+type ProvisionRequest struct {
+	brokerapi.ProvisionDetails
+	Context map[string]interface{} `json:"context,omitempty"`
+}
+
+type UpdateRequest struct {
+	brokerapi.UpdateDetails
+	Context map[string]interface{} `json:"context,omitempty"`
+}
+
+type BindRequest struct {
+	brokerapi.BindDetails
+	Context map[string]interface{} `json:"context,omitempty"`
+}
+
+type OriginatingIdentity struct {
+	Platform string                 `json:"platform"`
+	Value    map[string]interface{} `json:"value"`
+}
+
+type handler struct {
+	serviceBroker ServiceBroker
+	auditLogger   auditlog.Logger
+	logger        lager.Logger
+}
+
+func New(
+	serviceBroker ServiceBroker,
+	auditLogger auditlog.Logger,
+	logger lager.Logger,
+	brokerCredentials brokerapi.BrokerCredentials,
+) http.Handler {
+	h := &handler{
+		serviceBroker: serviceBroker,
+		auditLogger:   auditLogger,
+		logger:        logger.Session("broker-http"),
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/v2/service_instances/{instance_id}", h.provision).Methods("PUT")
+	router.HandleFunc("/v2/service_instances/{instance_id}", h.update).Methods("PATCH")
+	router.HandleFunc("/v2/service_instances/{instance_id}", h.getInstance).Methods("GET")
+	router.HandleFunc("/v2/service_instances/{instance_id}", h.deprovision).Methods("DELETE")
+	router.HandleFunc("/v2/service_instances/{instance_id}/service_bindings/{binding_id}", h.bind).Methods("PUT")
+	router.HandleFunc("/v2/service_instances/{instance_id}/service_bindings/{binding_id}", h.getBinding).Methods("GET")
+	router.HandleFunc("/v2/service_instances/{instance_id}/service_bindings/{binding_id}", h.unbind).Methods("DELETE")
+	router.HandleFunc("/v2/service_instances/{instance_id}/service_bindings/{binding_id}/last_operation", h.bindingLastOperation).Methods("GET")
+	router.NotFoundHandler = brokerapi.New(serviceBroker, logger, brokerCredentials)
+
+	return checkAuth(h.audit(router), brokerCredentials)
+}
+
+func (h *handler) provision(w http.ResponseWriter, req *http.Request) {
+	instanceID := mux.Vars(req)["instance_id"]
+	logger := h.logger.Session("provision", lager.Data{
+		instanceIDLogKey: instanceID,
+	})
+
+	var provisionRequest ProvisionRequest
+	if err := json.NewDecoder(req.Body).Decode(&provisionRequest); err != nil {
+		logger.Error("invalid-provision-details", err)
+		respond(w, http.StatusBadRequest, ErrorResponse{
+			Description: err.Error(),
+		})
+		return
+	}
+
+	provisioningResponse, asynch, err := h.serviceBroker.ProvisionWithContext(instanceID, provisionRequest.ProvisionDetails, provisionRequest.Context, req.FormValue("accepts_incomplete") == "true")
+	if err != nil {
+		logger.Error("provision-failed", err)
+		if brokerErr, ok := err.(sqsbroker.BrokerError); ok {
+			respondBrokerError(w, brokerErr)
+			return
+		}
+
+		switch err {
+		case brokerapi.ErrInstanceAlreadyExists:
+			respond(w, http.StatusConflict, EmptyResponse{})
+		case brokerapi.ErrAsyncRequired:
+			respond(w, 422, ErrorResponse{
+				Error:       "AsyncRequired",
+				Description: err.Error(),
+			})
+		default:
+			respond(w, http.StatusInternalServerError, ErrorResponse{
+				Description: err.Error(),
+			})
+		}
+		return
+	}
+
+	if asynch {
+		respond(w, http.StatusAccepted, provisioningResponse)
+		return
+	}
+
+	respond(w, http.StatusCreated, provisioningResponse)
+}
+
+func (h *handler) update(w http.ResponseWriter, req *http.Request) {
+	instanceID := mux.Vars(req)["instance_id"]
+	logger := h.logger.Session("update", lager.Data{
+		instanceIDLogKey: instanceID,
+	})
+
+	var updateRequest UpdateRequest
+	if err := json.NewDecoder(req.Body).Decode(&updateRequest); err != nil {
+		logger.Error("invalid-update-details", err)
+		respond(w, http.StatusBadRequest, ErrorResponse{
+			Description: err.Error(),
+		})
+		return
+	}
+
+	updateResponse, asynch, err := h.serviceBroker.UpdateWithContext(instanceID, updateRequest.UpdateDetails, updateRequest.Context, req.FormValue("accepts_incomplete") == "true")
+	if err != nil {
+		logger.Error("update-failed", err)
+		if brokerErr, ok := err.(sqsbroker.BrokerError); ok {
+			respondBrokerError(w, brokerErr)
+			return
+		}
+
+		switch err {
+		case brokerapi.ErrInstanceDoesNotExist:
+			respond(w, http.StatusNotFound, ErrorResponse{
+				Description: err.Error(),
+			})
+		case brokerapi.ErrInstanceNotUpdateable:
+			respond(w, 422, ErrorResponse{
+				Description: err.Error(),
+			})
+		case brokerapi.ErrAsyncRequired:
+			respond(w, 422, ErrorResponse{
+				Error:       "AsyncRequired",
+				Description: err.Error(),
+			})
+		default:
+			respond(w, http.StatusInternalServerError, ErrorResponse{
+				Description: err.Error(),
+			})
+		}
+		return
+	}
+
+	if asynch {
+		respond(w, http.StatusAccepted, updateResponse)
+		return
+	}
+
+	respond(w, http.StatusOK, updateResponse)
+}
+
+func (h *handler) getInstance(w http.ResponseWriter, req *http.Request) {
+	instanceID := mux.Vars(req)["instance_id"]
+	logger := h.logger.Session("get-instance", lager.Data{
+		instanceIDLogKey: instanceID,
+	})
+
+	instanceResponse, err := h.serviceBroker.GetInstance(instanceID)
+	if err != nil {
+		logger.Error("get-instance-failed", err)
+		switch err {
+		case brokerapi.ErrInstanceDoesNotExist:
+			respond(w, http.StatusNotFound, EmptyResponse{})
+		default:
+			respond(w, http.StatusInternalServerError, ErrorResponse{
+				Description: err.Error(),
+			})
+		}
+		return
+	}
+
+	respond(w, http.StatusOK, instanceResponse)
+}
+
+func (h *handler) deprovision(w http.ResponseWriter, req *http.Request) {
+	instanceID := mux.Vars(req)["instance_id"]
+	details := brokerapi.DeprovisionDetails{
+		ServiceID: req.FormValue("service_id"),
+		PlanID:    req.FormValue("plan_id"),
+	}
+	logger := h.logger.Session("deprovision", lager.Data{
+		instanceIDLogKey: instanceID,
+	})
+
+	var asynch bool
+	var err error
+	if req.FormValue("force") == "true" {
+		asynch, err = h.serviceBroker.ForceDeprovision(instanceID, details, req.FormValue("accepts_incomplete") == "true")
+	} else {
+		asynch, err = h.serviceBroker.Deprovision(instanceID, details, req.FormValue("accepts_incomplete") == "true")
+	}
+
+	if err != nil {
+		logger.Error("deprovision-failed", err)
+		if brokerErr, ok := err.(sqsbroker.BrokerError); ok {
+			respondBrokerError(w, brokerErr)
+			return
+		}
+
+		switch err {
+		case brokerapi.ErrInstanceDoesNotExist:
+			respond(w, http.StatusGone, EmptyResponse{})
+		case brokerapi.ErrAsyncRequired:
+			respond(w, 422, ErrorResponse{
+				Error:       "AsyncRequired",
+				Description: err.Error(),
+			})
+		default:
+			respond(w, http.StatusInternalServerError, ErrorResponse{
+				Description: err.Error(),
+			})
+		}
+		return
+	}
+
+	if asynch {
+		respond(w, http.StatusAccepted, EmptyResponse{})
+		return
+	}
+
+	respond(w, http.StatusOK, EmptyResponse{})
+}
+
+func (h *handler) bind(w http.ResponseWriter, req *http.Request) {
+	instanceID := mux.Vars(req)["instance_id"]
+	bindingID := mux.Vars(req)["binding_id"]
+	logger := h.logger.Session("bind", lager.Data{
+		instanceIDLogKey: instanceID,
+		bindingIDLogKey:  bindingID,
+	})
+
+	var bindRequest BindRequest
+	if err := json.NewDecoder(req.Body).Decode(&bindRequest); err != nil {
+		logger.Error("invalid-bind-details", err)
+		respond(w, http.StatusBadRequest, ErrorResponse{
+			Description: err.Error(),
+		})
+		return
+	}
+
+	bindingResponse, asynch, err := h.serviceBroker.AsyncBind(instanceID, bindingID, bindRequest.BindDetails, bindRequest.Context, req.FormValue("accepts_incomplete") == "true")
+	if err != nil {
+		logger.Error("bind-failed", err)
+		if brokerErr, ok := err.(sqsbroker.BrokerError); ok {
+			respondBrokerError(w, brokerErr)
+			return
+		}
+
+		switch err {
+		case brokerapi.ErrInstanceDoesNotExist:
+			respond(w, http.StatusNotFound, ErrorResponse{
+				Description: err.Error(),
+			})
+		case brokerapi.ErrInstanceNotBindable:
+			respond(w, 422, ErrorResponse{
+				Description: err.Error(),
+			})
+		case brokerapi.ErrBindingAlreadyExists:
+			respond(w, http.StatusConflict, ErrorResponse{
+				Description: err.Error(),
+			})
+		case brokerapi.ErrAppGUIDRequired:
+			respond(w, 422, ErrorResponse{
+				Error:       "RequiresApp",
+				Description: err.Error(),
+			})
+		default:
+			respond(w, http.StatusInternalServerError, ErrorResponse{
+				Description: err.Error(),
+			})
+		}
+		return
+	}
+
+	if asynch {
+		respond(w, http.StatusAccepted, EmptyResponse{})
+		return
+	}
+
+	respond(w, http.StatusCreated, bindingResponse)
+}
+
+func (h *handler) unbind(w http.ResponseWriter, req *http.Request) {
+	instanceID := mux.Vars(req)["instance_id"]
+	bindingID := mux.Vars(req)["binding_id"]
+	details := brokerapi.UnbindDetails{
+		ServiceID: req.FormValue("service_id"),
+		PlanID:    req.FormValue("plan_id"),
+	}
+	logger := h.logger.Session("unbind", lager.Data{
+		instanceIDLogKey: instanceID,
+		bindingIDLogKey:  bindingID,
+	})
+
+	asynch, err := h.serviceBroker.AsyncUnbind(instanceID, bindingID, details, req.FormValue("accepts_incomplete") == "true")
+	if err != nil {
+		logger.Error("unbind-failed", err)
+		switch err {
+		case brokerapi.ErrInstanceDoesNotExist:
+			respond(w, http.StatusGone, ErrorResponse{
+				Description: err.Error(),
+			})
+		case brokerapi.ErrBindingDoesNotExist:
+			respond(w, http.StatusGone, EmptyResponse{})
+		default:
+			respond(w, http.StatusInternalServerError, ErrorResponse{
+				Description: err.Error(),
+			})
+		}
+		return
+	}
+
+	if asynch {
+		respond(w, http.StatusAccepted, EmptyResponse{})
+		return
+	}
+
+	respond(w, http.StatusOK, EmptyResponse{})
+}
+
+func (h *handler) bindingLastOperation(w http.ResponseWriter, req *http.Request) {
+	instanceID := mux.Vars(req)["instance_id"]
+	bindingID := mux.Vars(req)["binding_id"]
+	logger := h.logger.Session("binding-last-operation", lager.Data{
+		instanceIDLogKey: instanceID,
+		bindingIDLogKey:  bindingID,
+	})
+
+	lastOperationResponse, err := h.serviceBroker.BindingLastOperation(instanceID, bindingID)
+	if err != nil {
+		logger.Error("binding-last-operation-failed", err)
+		switch err {
+		case brokerapi.ErrBindingDoesNotExist:
+			respond(w, http.StatusGone, EmptyResponse{})
+		default:
+			respond(w, http.StatusInternalServerError, ErrorResponse{
+				Description: err.Error(),
+			})
+		}
+		return
+	}
+
+	respond(w, http.StatusOK, lastOperationResponse)
+}
+
+func (h *handler) getBinding(w http.ResponseWriter, req *http.Request) {
+	instanceID := mux.Vars(req)["instance_id"]
+	bindingID := mux.Vars(req)["binding_id"]
+	logger := h.logger.Session("get-binding", lager.Data{
+		instanceIDLogKey: instanceID,
+		bindingIDLogKey:  bindingID,
+	})
+
+	bindingResponse, err := h.serviceBroker.GetBinding(instanceID, bindingID)
+	if err != nil {
+		logger.Error("get-binding-failed", err)
+		if brokerErr, ok := err.(sqsbroker.BrokerError); ok {
+			respondBrokerError(w, brokerErr)
+			return
+		}
+
+		switch err {
+		case brokerapi.ErrInstanceDoesNotExist, brokerapi.ErrBindingDoesNotExist:
+			respond(w, http.StatusNotFound, EmptyResponse{})
+		default:
+			respond(w, http.StatusInternalServerError, ErrorResponse{
+				Description: err.Error(),
+			})
+		}
+		return
+	}
+
+	respond(w, http.StatusOK, bindingResponse)
+}
+
+func (h *handler) audit(handler http.Handler) http.Handler {
+	logger := h.logger.Session("audit")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler.ServeHTTP(recorder, req)
+
+		var auditIdentity *auditlog.OriginatingIdentity
+		if header := req.Header.Get(originatingIdentityHeader); header != "" {
+			data := lager.Data{
+				"method": req.Method,
+				"path":   req.URL.Path,
+				"status": recorder.status,
+			}
+			identity, err := parseOriginatingIdentity(header)
+			if err != nil {
+				logger.Error("invalid-originating-identity", err, lager.Data{"header": header})
+			} else {
+				data["originating-identity"] = identity
+				auditIdentity = &auditlog.OriginatingIdentity{Platform: identity.Platform, Value: identity.Value}
+			}
+
+			logger.Info("request", data)
+		}
+
+		h.auditRequest(req, recorder.status, auditIdentity)
+	})
+}
+
+// auditRequest records who asked to create, change or destroy an instance or a binding.
+// The events of the resources changed by the broker carry the same instance and binding IDs.
+func (h *handler) auditRequest(req *http.Request, status int, identity *auditlog.OriginatingIdentity) {
+	action, instanceID, bindingID := requestAction(req.Method, req.URL.Path)
+	if action == "" {
+		return
+	}
+
+	event := auditlog.Event{
+		Action:              action,
+		Outcome:             auditlog.OutcomeSuccess,
+		OriginatingIdentity: identity,
+		InstanceID:          instanceID,
+		BindingID:           bindingID,
+		Request: &auditlog.Request{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Status: status,
+		},
+	}
+	if status >= http.StatusBadRequest {
+		event.Outcome = auditlog.OutcomeFailure
+	}
+
+	if err := h.auditLogger.Log(event); err != nil {
+		h.logger.Error("audit-log-failed", err, lager.Data{"action": action})
+	}
+}
+
+// requestAction names the changes requested to /v2/service_instances/<instance-id>[/service_bindings/<binding-id>]
+func requestAction(method string, path string) (string, string, string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "v2" || parts[1] != "service_instances" {
+		return "", "", ""
+	}
+
+	switch {
+	case len(parts) == 3 && method == "PUT":
+		return "provision", parts[2], ""
+	case len(parts) == 3 && method == "PATCH":
+		return "update", parts[2], ""
+	case len(parts) == 3 && method == "DELETE":
+		return "deprovision", parts[2], ""
+	case len(parts) == 5 && parts[3] == "service_bindings" && method == "PUT":
+		return "bind", parts[2], parts[4]
+	case len(parts) == 5 && parts[3] == "service_bindings" && method == "DELETE":
+		return "unbind", parts[2], parts[4]
+	}
+
+	return "", "", ""
+}
+
+func parseOriginatingIdentity(header string) (OriginatingIdentity, error) {
+	identity := OriginatingIdentity{}
+
+	fields := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(fields) != 2 {
+		return identity, errInvalidOriginatingIdentity
+	}
+	identity.Platform = fields[0]
+
+	value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(fields[1]))
+	if err != nil {
+		return identity, err
+	}
+
+	if err = json.Unmarshal(value, &identity.Value); err != nil {
+		return identity, err
+	}
+
+	return identity, nil
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func checkAuth(handler http.Handler, credentials brokerapi.BrokerCredentials) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		username, password, ok := req.BasicAuth()
+		if !ok || username != credentials.Username || password != credentials.Password {
+			http.Error(w, "Not Authorized", http.StatusUnauthorized)
+			return
+		}
+
+		handler.ServeHTTP(w, req)
+	})
+}
+
+func respond(w http.ResponseWriter, status int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// respondBrokerError answers 400 to invalid requests and 422 with the error code to the other errors the broker classifies
+func respondBrokerError(w http.ResponseWriter, err sqsbroker.BrokerError) int {
+	if err.Kind() == sqsbroker.InvalidRequestErrorKind {
+		respond(w, http.StatusBadRequest, ErrorResponse{
+			Description: err.Error(),
+		})
+		return http.StatusBadRequest
+	}
+
+	respond(w, 422, ErrorResponse{
+		Error:       err.Kind(),
+		Description: err.Error(),
+	})
+	return 422
+}
@@ -0,0 +1,617 @@
+package brokerhttp_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/sqs-broker/brokerhttp"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cf-platform-eng/sqs-broker/auditlog"
+	auditfake "github.com/cf-platform-eng/sqs-broker/auditlog/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awsiam"
+	iamfake "github.com/cf-platform-eng/sqs-broker/awsiam/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awssns"
+	snsfake "github.com/cf-platform-eng/sqs-broker/awssns/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awssqs"
+	sqsfake "github.com/cf-platform-eng/sqs-broker/awssqs/fakes"
+	"github.com/cf-platform-eng/sqs-broker/brokerstore"
+	storefake "github.com/cf-platform-eng/sqs-broker/brokerstore/fakes"
+	"github.com/cf-platform-eng/sqs-broker/sqsbroker"
+)
+
+var _ = Describe("Broker HTTP Handler", func() {
+	var (
+		queue *sqsfake.FakeQueue
+		topic *snsfake.FakeTopic
+		user  *iamfake.FakeUser
+		store *storefake.FakeStore
+
+		bindingQueue        *sqsfake.FakeQueue
+		bindingQueueFactory sqsbroker.BindingQueueFactory
+
+		deletionProtection  bool
+		deletionArchiveMode string
+		bindable            bool
+		planUpdateable      bool
+
+		auditLogger *auditfake.FakeLogger
+
+		testSink *lagertest.TestSink
+		logger   lager.Logger
+
+		handler http.Handler
+
+		credentials = brokerapi.BrokerCredentials{
+			Username: "username",
+			Password: "password",
+		}
+	)
+
+	BeforeEach(func() {
+		queue = &sqsfake.FakeQueue{}
+		topic = &snsfake.FakeTopic{}
+		user = &iamfake.FakeUser{}
+		store = &storefake.FakeStore{}
+
+		bindingQueue = &sqsfake.FakeQueue{}
+		bindingQueueFactory = func(region string, accessKeyID string, secretAccessKey string) awssqs.Queue {
+			return bindingQueue
+		}
+
+		deletionProtection = false
+		deletionArchiveMode = ""
+		bindable = true
+		planUpdateable = true
+
+		auditLogger = &auditfake.FakeLogger{}
+	})
+
+	JustBeforeEach(func() {
+		config := sqsbroker.Config{
+			Region:    "sqs-region",
+			SQSPrefix: "cf",
+			Catalog: sqsbroker.Catalog{
+				Services: []sqsbroker.Service{
+					sqsbroker.Service{
+						ID:             "Service-1",
+						Name:           "Service 1",
+						Description:    "This is the Service 1",
+						Bindable:       bindable,
+						PlanUpdateable: planUpdateable,
+						Plans: []sqsbroker.ServicePlan{
+							sqsbroker.ServicePlan{
+								ID:                  "Plan-1",
+								Name:                "Plan 1",
+								Description:         "This is the Plan 1",
+								DeletionProtection:  deletionProtection,
+								DeletionArchiveMode: deletionArchiveMode,
+								Platforms:           []string{"cloudfoundry", "kubernetes"},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		logger = lager.NewLogger("brokerhttp_test")
+		testSink = lagertest.NewTestSink()
+		logger.RegisterSink(testSink)
+
+		clientsFactory := func(region string, accountRole sqsbroker.AccountRole) (awssqs.Queue, awssns.Topic, awsiam.User) {
+			return queue, topic, user
+		}
+		serviceBroker := sqsbroker.New(config, clientsFactory, store, bindingQueueFactory, auditlog.Discard, logger)
+		handler = New(serviceBroker, auditLogger, logger, credentials)
+	})
+
+	doRequest := func(method string, path string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(method, path, nil)
+		Expect(err).ToNot(HaveOccurred())
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	doRequestWithBody := func(method string, path string, body string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(method, path, strings.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	It("rejects requests without the proper credentials", func() {
+		request, err := http.NewRequest("DELETE", "/v2/service_instances/instance-id?service_id=Service-1&plan_id=Plan-1", nil)
+		Expect(err).ToNot(HaveOccurred())
+		request.SetBasicAuth("username", "wrong-password")
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		Expect(queue.DeleteCalled).To(BeFalse())
+	})
+
+	It("serves the remaining routes from brokerapi", func() {
+		recorder := doRequest("GET", "/v2/catalog")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"id":"Service-1"`))
+	})
+
+	It("logs the originating identity of the requests", func() {
+		request, err := http.NewRequest("GET", "/v2/catalog", nil)
+		Expect(err).ToNot(HaveOccurred())
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		// {"user_id": "user-1"}
+		request.Header.Set("X-Broker-API-Originating-Identity", "cloudfoundry eyJ1c2VyX2lkIjogInVzZXItMSJ9")
+
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+
+		var auditLog lager.LogFormat
+		for _, log := range testSink.Logs() {
+			if log.Message == "brokerhttp_test.broker-http.audit.request" {
+				auditLog = log
+			}
+		}
+		Expect(auditLog.Data).To(HaveKeyWithValue("method", "GET"))
+		Expect(auditLog.Data).To(HaveKeyWithValue("path", "/v2/catalog"))
+		Expect(auditLog.Data).To(HaveKeyWithValue("originating-identity", map[string]interface{}{
+			"platform": "cloudfoundry",
+			"value":    map[string]interface{}{"user_id": "user-1"},
+		}))
+	})
+
+	It("audits the requests that change instances and bindings", func() {
+		request, err := http.NewRequest("DELETE", "/v2/service_instances/instance-id/service_bindings/binding-id?service_id=Service-1&plan_id=Plan-1", nil)
+		Expect(err).ToNot(HaveOccurred())
+		request.SetBasicAuth(credentials.Username, credentials.Password)
+		// {"user_id": "user-1"}
+		request.Header.Set("X-Broker-API-Originating-Identity", "cloudfoundry eyJ1c2VyX2lkIjogInVzZXItMSJ9")
+		user.ListAccessKeysError = errors.New("operation failed")
+
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+
+		Expect(auditLogger.LogEvents).To(HaveLen(1))
+		event := auditLogger.LogEvents[0]
+		Expect(event.Action).To(Equal("unbind"))
+		Expect(event.Outcome).To(Equal(auditlog.OutcomeFailure))
+		Expect(event.InstanceID).To(Equal("instance-id"))
+		Expect(event.BindingID).To(Equal("binding-id"))
+		Expect(event.Request).To(Equal(&auditlog.Request{
+			Method: "DELETE",
+			Path:   "/v2/service_instances/instance-id/service_bindings/binding-id",
+			Status: http.StatusInternalServerError,
+		}))
+		Expect(event.OriginatingIdentity).To(Equal(&auditlog.OriginatingIdentity{
+			Platform: "cloudfoundry",
+			Value:    map[string]interface{}{"user_id": "user-1"},
+		}))
+	})
+
+	It("does not audit the requests that do not change instances or bindings", func() {
+		recorder := doRequest("GET", "/v2/catalog")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(auditLogger.LogCalled).To(BeFalse())
+	})
+
+	Describe("Provision", func() {
+		It("creates the Queue and saves the request Context", func() {
+			recorder := doRequestWithBody("PUT", "/v2/service_instances/instance-id", `{"service_id":"Service-1","plan_id":"Plan-1","organization_guid":"organization-id","space_guid":"space-id","context":{"platform":"kubernetes","namespace":"namespace-1"}}`)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+			Expect(queue.CreateCalled).To(BeTrue())
+			Expect(store.SaveInstanceInstance.OrganizationGUID).To(Equal("organization-id"))
+			Expect(store.SaveInstanceInstance.Context).To(Equal(map[string]interface{}{"platform": "kubernetes", "namespace": "namespace-1"}))
+		})
+
+		It("returns 400 when the Service Plan is not available on the platform", func() {
+			recorder := doRequestWithBody("PUT", "/v2/service_instances/instance-id", `{"service_id":"Service-1","plan_id":"Plan-1","context":{"platform":"other"}}`)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(queue.CreateCalled).To(BeFalse())
+		})
+
+		It("returns 400 when the details are not valid", func() {
+			recorder := doRequestWithBody("PUT", "/v2/service_instances/instance-id", `{`)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("Update", func() {
+		It("modifies the Queue and saves the request Context", func() {
+			queue.DescribeQueueDetails = awssqs.QueueDetails{DelaySeconds: "5"}
+
+			recorder := doRequestWithBody("PATCH", "/v2/service_instances/instance-id", `{"service_id":"Service-1","plan_id":"Plan-1","context":{"platform":"cloudfoundry","organization_guid":"organization-id"}}`)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"description":"Queue attributes changed: delay_seconds (5 -> 0)"}`))
+			Expect(queue.ModifyCalled).To(BeTrue())
+			Expect(store.SaveInstanceInstance.Context).To(Equal(map[string]interface{}{"platform": "cloudfoundry", "organization_guid": "organization-id"}))
+		})
+
+		Context("when the Queue does not exist", func() {
+			BeforeEach(func() {
+				queue.DescribeError = awssqs.ErrQueueDoesNotExist
+			})
+
+			It("returns 404", func() {
+				recorder := doRequestWithBody("PATCH", "/v2/service_instances/instance-id", `{"service_id":"Service-1","plan_id":"Plan-1","context":{"platform":"cloudfoundry"}}`)
+				Expect(recorder.Code).To(Equal(http.StatusNotFound))
+				Expect(recorder.Body.String()).To(MatchJSON(`{"description":"instance does not exist"}`))
+			})
+		})
+
+		Context("when the Service is not updateable", func() {
+			BeforeEach(func() {
+				planUpdateable = false
+			})
+
+			It("returns 422", func() {
+				recorder := doRequestWithBody("PATCH", "/v2/service_instances/instance-id", `{"service_id":"Service-1","plan_id":"Plan-1","context":{"platform":"cloudfoundry"}}`)
+				Expect(recorder.Code).To(Equal(422))
+				Expect(recorder.Body.String()).To(MatchJSON(`{"description":"instance is not updateable"}`))
+				Expect(queue.ModifyCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the Queue is being migrated", func() {
+			BeforeEach(func() {
+				store.GetInstanceInstance = brokerstore.Instance{LastOperationState: brokerapi.LastOperationInProgress}
+			})
+
+			It("returns 422", func() {
+				recorder := doRequestWithBody("PATCH", "/v2/service_instances/instance-id", `{"service_id":"Service-1","plan_id":"Plan-1","context":{"platform":"cloudfoundry"}}`)
+				Expect(recorder.Code).To(Equal(422))
+				Expect(recorder.Body.String()).To(ContainSubstring(`"error":"ConcurrencyError"`))
+				Expect(queue.ModifyCalled).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("GetInstance", func() {
+		BeforeEach(func() {
+			store.GetInstanceInstance = brokerstore.Instance{
+				InstanceID: "instance-id",
+				ServiceID:  "Service-1",
+				PlanID:     "Plan-1",
+			}
+			queue.DescribeQueueDetails = awssqs.QueueDetails{
+				QueueURL:                    "queue-url",
+				ApproximateNumberOfMessages: "5",
+			}
+		})
+
+		It("returns the Instance", func() {
+			recorder := doRequest("GET", "/v2/service_instances/instance-id")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"service_id":"Service-1"`))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"plan_id":"Plan-1"`))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"queue_url":"queue-url"`))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"approximate_number_of_messages":"5"`))
+			Expect(queue.DescribeQueueName).To(Equal("cf-instance-id"))
+		})
+
+		Context("when the Queue does not exist", func() {
+			BeforeEach(func() {
+				queue.DescribeError = awssqs.ErrQueueDoesNotExist
+			})
+
+			It("returns 404", func() {
+				recorder := doRequest("GET", "/v2/service_instances/instance-id")
+				Expect(recorder.Code).To(Equal(http.StatusNotFound))
+				Expect(recorder.Body.String()).To(MatchJSON("{}"))
+			})
+		})
+
+		Context("when describing the Queue fails", func() {
+			BeforeEach(func() {
+				queue.DescribeError = errors.New("operation failed")
+			})
+
+			It("returns 500", func() {
+				recorder := doRequest("GET", "/v2/service_instances/instance-id")
+				Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+				Expect(recorder.Body.String()).To(MatchJSON(`{"description":"operation failed"}`))
+			})
+		})
+	})
+
+	Describe("GetBinding", func() {
+		BeforeEach(func() {
+			store.GetBindingBinding = brokerstore.Binding{
+				BindingID:       "binding-id",
+				InstanceID:      "instance-id",
+				AccessKeyID:     "access-key-id",
+				SecretAccessKey: "secret-access-key",
+				Parameters:      map[string]interface{}{"key": "value"},
+			}
+			queue.DescribeQueueDetails = awssqs.QueueDetails{
+				QueueURL: "queue-url",
+			}
+		})
+
+		It("returns the Binding", func() {
+			recorder := doRequest("GET", "/v2/service_instances/instance-id/service_bindings/binding-id")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{
+				"credentials": {
+					"username": "access-key-id",
+					"password": "secret-access-key",
+					"uri": "queue-url"
+				},
+				"parameters": {"key": "value"}
+			}`))
+			Expect(user.DescribeUserName).To(Equal("cf-binding-id"))
+		})
+
+		Context("when the User does not exist", func() {
+			BeforeEach(func() {
+				user.DescribeError = awsiam.ErrUserDoesNotExist
+			})
+
+			It("returns 404", func() {
+				recorder := doRequest("GET", "/v2/service_instances/instance-id/service_bindings/binding-id")
+				Expect(recorder.Code).To(Equal(http.StatusNotFound))
+				Expect(recorder.Body.String()).To(MatchJSON("{}"))
+			})
+		})
+
+		Context("when the secret access key is not available", func() {
+			BeforeEach(func() {
+				store.GetBindingBinding.SecretAccessKey = ""
+			})
+
+			It("returns 422", func() {
+				recorder := doRequest("GET", "/v2/service_instances/instance-id/service_bindings/binding-id")
+				Expect(recorder.Code).To(Equal(422))
+				Expect(recorder.Body.String()).To(MatchJSON(`{
+					"error": "SecretNotAvailable",
+					"description": "The secret access key of Binding 'binding-id' is not available anymore, rotate the binding keys to get new credentials"
+				}`))
+				Expect(user.CreateAccessKeyCalled).To(BeFalse())
+			})
+		})
+
+		Context("when describing the User fails", func() {
+			BeforeEach(func() {
+				user.DescribeError = errors.New("operation failed")
+			})
+
+			It("returns 500", func() {
+				recorder := doRequest("GET", "/v2/service_instances/instance-id/service_bindings/binding-id")
+				Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+				Expect(recorder.Body.String()).To(MatchJSON(`{"description":"operation failed"}`))
+			})
+		})
+	})
+
+	Describe("Bind", func() {
+		BeforeEach(func() {
+			queue.DescribeQueueDetails = awssqs.QueueDetails{
+				QueueURL: "queue-url",
+				QueueArn: "queue-arn",
+			}
+			user.CreateAccessKeyAccessKeyID = "access-key-id"
+			user.CreateAccessKeySecretAccessKey = "secret-access-key"
+		})
+
+		It("returns the credentials", func() {
+			recorder := doRequestWithBody("PUT", "/v2/service_instances/instance-id/service_bindings/binding-id", `{"service_id":"Service-1","plan_id":"Plan-1"}`)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"username":"access-key-id"`))
+			Expect(bindingQueue.DescribeCalled).To(BeFalse())
+		})
+
+		It("returns 202 when accepts_incomplete is set", func() {
+			recorder := doRequestWithBody("PUT", "/v2/service_instances/instance-id/service_bindings/binding-id?accepts_incomplete=true", `{"service_id":"Service-1","plan_id":"Plan-1"}`)
+			Expect(recorder.Code).To(Equal(http.StatusAccepted))
+			Expect(recorder.Body.String()).To(MatchJSON("{}"))
+			Eventually(func() string { return store.SaveBindingBinding.LastOperationState }).Should(Equal("succeeded"))
+		})
+
+		It("saves the request Context", func() {
+			recorder := doRequestWithBody("PUT", "/v2/service_instances/instance-id/service_bindings/binding-id", `{"service_id":"Service-1","plan_id":"Plan-1","context":{"platform":"kubernetes","namespace":"namespace-1"}}`)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+			Expect(store.SaveBindingBinding.Context).To(Equal(map[string]interface{}{"platform": "kubernetes", "namespace": "namespace-1"}))
+			Expect(user.CreateUserPath).To(Equal("/cf/kubernetes/namespace-1/"))
+		})
+
+		It("returns 400 when the details are not valid", func() {
+			recorder := doRequestWithBody("PUT", "/v2/service_instances/instance-id/service_bindings/binding-id", `{`)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		Context("when the Queue does not exist", func() {
+			BeforeEach(func() {
+				queue.DescribeError = awssqs.ErrQueueDoesNotExist
+			})
+
+			It("returns 404", func() {
+				recorder := doRequestWithBody("PUT", "/v2/service_instances/instance-id/service_bindings/binding-id", `{"service_id":"Service-1","plan_id":"Plan-1"}`)
+				Expect(recorder.Code).To(Equal(http.StatusNotFound))
+				Expect(recorder.Body.String()).To(MatchJSON(`{"description":"instance does not exist"}`))
+				Expect(user.CreateCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the Service is not bindable", func() {
+			BeforeEach(func() {
+				bindable = false
+			})
+
+			It("returns 422", func() {
+				recorder := doRequestWithBody("PUT", "/v2/service_instances/instance-id/service_bindings/binding-id", `{"service_id":"Service-1","plan_id":"Plan-1"}`)
+				Expect(recorder.Code).To(Equal(422))
+				Expect(recorder.Body.String()).To(MatchJSON(`{"description":"instance is not bindable"}`))
+				Expect(user.CreateCalled).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("Unbind", func() {
+		It("deletes the User", func() {
+			recorder := doRequest("DELETE", "/v2/service_instances/instance-id/service_bindings/binding-id?service_id=Service-1&plan_id=Plan-1")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON("{}"))
+			Expect(user.DeleteUserName).To(Equal("cf-binding-id"))
+		})
+
+		It("returns 202 when accepts_incomplete is set", func() {
+			recorder := doRequest("DELETE", "/v2/service_instances/instance-id/service_bindings/binding-id?service_id=Service-1&plan_id=Plan-1&accepts_incomplete=true")
+			Expect(recorder.Code).To(Equal(http.StatusAccepted))
+			Expect(recorder.Body.String()).To(MatchJSON("{}"))
+			Eventually(func() bool { return store.DeleteBindingCalled }).Should(BeTrue())
+		})
+
+		Context("when the Instance does not exist", func() {
+			BeforeEach(func() {
+				store.GetInstanceError = brokerapi.ErrInstanceDoesNotExist
+			})
+
+			It("returns 410", func() {
+				recorder := doRequest("DELETE", "/v2/service_instances/instance-id/service_bindings/binding-id?service_id=Service-1&plan_id=Plan-1")
+				Expect(recorder.Code).To(Equal(http.StatusGone))
+				Expect(recorder.Body.String()).To(MatchJSON(`{"description":"instance does not exist"}`))
+			})
+		})
+	})
+
+	Describe("BindingLastOperation", func() {
+		BeforeEach(func() {
+			store.GetBindingBinding = brokerstore.Binding{
+				BindingID:                "binding-id",
+				InstanceID:               "instance-id",
+				LastOperationState:       "in progress",
+				LastOperationDescription: "Creating binding",
+			}
+		})
+
+		It("returns the state of the Binding", func() {
+			recorder := doRequest("GET", "/v2/service_instances/instance-id/service_bindings/binding-id/last_operation")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"state":"in progress","description":"Creating binding"}`))
+		})
+
+		Context("when the Binding does not exist", func() {
+			BeforeEach(func() {
+				store.GetBindingError = brokerstore.ErrBindingDoesNotExist
+			})
+
+			It("returns 410", func() {
+				recorder := doRequest("GET", "/v2/service_instances/instance-id/service_bindings/binding-id/last_operation")
+				Expect(recorder.Code).To(Equal(http.StatusGone))
+				Expect(recorder.Body.String()).To(MatchJSON("{}"))
+			})
+		})
+	})
+
+	Describe("Deprovision", func() {
+		It("deletes the Queue", func() {
+			recorder := doRequest("DELETE", "/v2/service_instances/instance-id?service_id=Service-1&plan_id=Plan-1")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON("{}"))
+			Expect(queue.DeleteCalled).To(BeTrue())
+			Expect(queue.DeleteQueueName).To(Equal("cf-instance-id"))
+		})
+
+		Context("when the Queue does not exist", func() {
+			BeforeEach(func() {
+				queue.DeleteError = awssqs.ErrQueueDoesNotExist
+			})
+
+			It("returns 410", func() {
+				recorder := doRequest("DELETE", "/v2/service_instances/instance-id?service_id=Service-1&plan_id=Plan-1")
+				Expect(recorder.Code).To(Equal(http.StatusGone))
+				Expect(recorder.Body.String()).To(MatchJSON("{}"))
+			})
+		})
+
+		Context("when deleting the Queue fails", func() {
+			BeforeEach(func() {
+				queue.DeleteError = errors.New("operation failed")
+			})
+
+			It("returns 500", func() {
+				recorder := doRequest("DELETE", "/v2/service_instances/instance-id?service_id=Service-1&plan_id=Plan-1")
+				Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+				Expect(recorder.Body.String()).To(MatchJSON(`{"description":"operation failed"}`))
+			})
+		})
+
+		Context("when the Queue is protected and not empty", func() {
+			BeforeEach(func() {
+				deletionProtection = true
+				queue.DescribeQueueDetails = awssqs.QueueDetails{
+					ApproximateNumberOfMessages:           "5",
+					ApproximateNumberOfMessagesNotVisible: "0",
+				}
+			})
+
+			It("returns 422", func() {
+				recorder := doRequest("DELETE", "/v2/service_instances/instance-id?service_id=Service-1&plan_id=Plan-1")
+				Expect(recorder.Code).To(Equal(422))
+				Expect(recorder.Body.String()).To(ContainSubstring(`"error":"QueueNotEmpty"`))
+				Expect(recorder.Body.String()).To(ContainSubstring("still holds 5 messages"))
+				Expect(queue.DeleteCalled).To(BeFalse())
+			})
+
+			Context("and force is set", func() {
+				It("deletes the Queue", func() {
+					recorder := doRequest("DELETE", "/v2/service_instances/instance-id?service_id=Service-1&plan_id=Plan-1&force=true")
+					Expect(recorder.Code).To(Equal(http.StatusOK))
+					Expect(queue.DeleteCalled).To(BeTrue())
+				})
+			})
+		})
+
+		Context("when the Queue is archived on deletion", func() {
+			BeforeEach(func() {
+				deletionArchiveMode = sqsbroker.ArchiveQueueDeletionMode
+				queue.DescribeQueueDetails = awssqs.QueueDetails{
+					ApproximateNumberOfMessages:           "0",
+					ApproximateNumberOfMessagesNotVisible: "0",
+				}
+			})
+
+			It("returns 202 when accepts_incomplete is set", func() {
+				recorder := doRequest("DELETE", "/v2/service_instances/instance-id?service_id=Service-1&plan_id=Plan-1&accepts_incomplete=true")
+				Expect(recorder.Code).To(Equal(http.StatusAccepted))
+				Expect(recorder.Body.String()).To(MatchJSON("{}"))
+				Eventually(func() bool { return store.DeleteInstanceCalled }).Should(BeTrue())
+				Expect(queue.DeleteCalled).To(BeTrue())
+			})
+
+			It("returns 422 when accepts_incomplete is not set", func() {
+				recorder := doRequest("DELETE", "/v2/service_instances/instance-id?service_id=Service-1&plan_id=Plan-1")
+				Expect(recorder.Code).To(Equal(422))
+				Expect(recorder.Body.String()).To(ContainSubstring(`"error":"AsyncRequired"`))
+				Expect(queue.DeleteCalled).To(BeFalse())
+			})
+
+			Context("and the Queue has in flight messages", func() {
+				BeforeEach(func() {
+					queue.DescribeQueueDetails.ApproximateNumberOfMessagesNotVisible = "2"
+				})
+
+				It("returns 422", func() {
+					recorder := doRequest("DELETE", "/v2/service_instances/instance-id?service_id=Service-1&plan_id=Plan-1&accepts_incomplete=true")
+					Expect(recorder.Code).To(Equal(422))
+					Expect(recorder.Body.String()).To(ContainSubstring(`"error":"QueueNotEmpty"`))
+					Expect(recorder.Body.String()).To(ContainSubstring("2 messages in flight"))
+					Expect(queue.DeleteCalled).To(BeFalse())
+				})
+			})
+		})
+	})
+})
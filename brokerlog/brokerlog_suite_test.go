@@ -0,0 +1,13 @@
+package brokerlog_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestBrokerLog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Broker Log Suite")
+}
@@ -0,0 +1,84 @@
+package brokerlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+var levelNames = map[lager.LogLevel]string{
+	lager.DEBUG: "DEBUG",
+	lager.INFO:  "INFO",
+	lager.ERROR: "ERROR",
+	lager.FATAL: "FATAL",
+}
+
+// ConsoleSink rewrites the lager JSON logs as single human readable lines:
+// <time> <level> <message> <key>=<value>...
+type ConsoleSink struct {
+	sink lager.Sink
+}
+
+func NewConsoleSink(sink lager.Sink) *ConsoleSink {
+	return &ConsoleSink{sink: sink}
+}
+
+func (s *ConsoleSink) Log(level lager.LogLevel, payload []byte) {
+	s.sink.Log(level, formatConsole(payload))
+}
+
+func formatConsole(payload []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	var log lager.LogFormat
+	if err := decoder.Decode(&log); err != nil {
+		return payload
+	}
+
+	line := fmt.Sprintf("%s %-5s %s", formatTimestamp(log.Timestamp), levelNames[log.LogLevel], log.Message)
+
+	keys := []string{}
+	for key := range log.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		line += " " + key + "=" + formatValue(log.Data[key])
+	}
+
+	return []byte(line)
+}
+
+// formatTimestamp turns the lager timestamps, in seconds since the epoch, into UTC times
+func formatTimestamp(timestamp string) string {
+	seconds, err := strconv.ParseFloat(timestamp, 64)
+	if err != nil {
+		return timestamp
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))).UTC().Format("2006-01-02T15:04:05.000Z07:00")
+}
+
+func formatValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+			return strconv.Quote(s)
+		}
+		return s
+	}
+
+	contents, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return string(contents)
+}
@@ -0,0 +1,44 @@
+package brokerlog_test
+
+import (
+	"errors"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/sqs-broker/brokerlog"
+)
+
+var _ = Describe("ConsoleSink", func() {
+	var (
+		testSink *lagertest.TestSink
+		logger   lager.Logger
+	)
+
+	BeforeEach(func() {
+		testSink = lagertest.NewTestSink()
+		logger = lager.NewLogger("brokerlog_test")
+		logger.RegisterSink(NewConsoleSink(testSink))
+	})
+
+	It("writes the logs as human readable lines", func() {
+		logger.Session("broker").Error("provision", errors.New("operation failed"), lager.Data{
+			"instance-id": "instance-id",
+			"quota":       3,
+			"parameters":  map[string]interface{}{"delay_seconds": "1"},
+		})
+
+		Expect(string(testSink.Buffer().Contents())).To(MatchRegexp(
+			`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{3}Z ERROR brokerlog_test\.broker\.provision error="operation failed" instance-id=instance-id parameters={"delay_seconds":"1"} quota=3 session=1\n$`,
+		))
+	})
+
+	It("pads the level names", func() {
+		logger.Info("reload")
+
+		Expect(string(testSink.Buffer().Contents())).To(MatchRegexp(`Z INFO  brokerlog_test\.reload\n$`))
+	})
+})
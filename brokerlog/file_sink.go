@@ -0,0 +1,118 @@
+package brokerlog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/pivotal-golang/lager"
+)
+
+// FileSink appends logs to a file, renaming it to <path>.1 (and older files to <path>.2...) once it reaches its max size
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mutex sync.Mutex
+	file  *os.File
+	size  int64
+}
+
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	s := &FileSink{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *FileSink) Log(level lager.LogLevel, payload []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	line := append(append([]byte{}, payload...), '\n')
+	if s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Rotating log file '%s': %s\n", s.path, err)
+			// Rotating is tried again once another max size of logs has been written
+			s.size = 0
+		}
+	}
+
+	n, _ := s.file.Write(line)
+	s.size += int64(n)
+}
+
+func (s *FileSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.file == os.Stderr {
+		return nil
+	}
+
+	return s.file.Close()
+}
+
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	s.file = file
+	s.size = info.Size()
+
+	return nil
+}
+
+// rotate moves the current file aside and opens a new one. The current file is only closed once the new one is open:
+// it keeps receiving the logs if it can not be moved, and they go to stderr if the new file can not be opened.
+func (s *FileSink) rotate() error {
+	// A previous rotation failed to open the file, there is no current file to move
+	if s.file != os.Stderr {
+		if s.maxBackups > 0 {
+			os.Remove(s.backupPath(s.maxBackups))
+			for i := s.maxBackups - 1; i > 0; i-- {
+				os.Rename(s.backupPath(i), s.backupPath(i+1))
+			}
+			if err := os.Rename(s.path, s.backupPath(1)); err != nil {
+				return err
+			}
+		} else if err := os.Remove(s.path); err != nil {
+			return err
+		}
+	}
+
+	previous := s.file
+	if err := s.open(); err != nil {
+		if previous != os.Stderr {
+			previous.Close()
+		}
+		s.file = os.Stderr
+		return err
+	}
+
+	if previous != os.Stderr {
+		previous.Close()
+	}
+
+	return nil
+}
+
+func (s *FileSink) backupPath(index int) string {
+	return fmt.Sprintf("%s.%d", s.path, index)
+}
@@ -0,0 +1,97 @@
+package brokerlog_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/sqs-broker/brokerlog"
+)
+
+var _ = Describe("FileSink", func() {
+	var (
+		tmpDir  string
+		logPath string
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "brokerlog")
+		Expect(err).ToNot(HaveOccurred())
+		logPath = filepath.Join(tmpDir, "broker.log")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	readFile := func(path string) string {
+		contents, err := ioutil.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		return string(contents)
+	}
+
+	It("appends the logs to the file", func() {
+		Expect(ioutil.WriteFile(logPath, []byte("existing\n"), 0600)).To(Succeed())
+
+		sink, err := NewFileSink(logPath, 1024, 2)
+		Expect(err).ToNot(HaveOccurred())
+		defer sink.Close()
+
+		sink.Log(lager.INFO, []byte("log-1"))
+		sink.Log(lager.INFO, []byte("log-2"))
+
+		Expect(readFile(logPath)).To(Equal("existing\nlog-1\nlog-2\n"))
+	})
+
+	It("rotates the file once it reaches its max size, keeping the max backups", func() {
+		sink, err := NewFileSink(logPath, 12, 2)
+		Expect(err).ToNot(HaveOccurred())
+		defer sink.Close()
+
+		for _, log := range []string{"log-1", "log-2", "log-3", "log-4", "log-5", "log-6", "log-7"} {
+			sink.Log(lager.INFO, []byte(log))
+		}
+
+		Expect(readFile(logPath)).To(Equal("log-7\n"))
+		Expect(readFile(logPath + ".1")).To(Equal("log-5\nlog-6\n"))
+		Expect(readFile(logPath + ".2")).To(Equal("log-3\nlog-4\n"))
+		Expect(logPath + ".3").ToNot(BeAnExistingFile())
+	})
+
+	It("truncates the file when no backups are kept", func() {
+		sink, err := NewFileSink(logPath, 6, 0)
+		Expect(err).ToNot(HaveOccurred())
+		defer sink.Close()
+
+		sink.Log(lager.INFO, []byte("log-1"))
+		sink.Log(lager.INFO, []byte("log-2"))
+
+		Expect(readFile(logPath)).To(Equal("log-2\n"))
+		Expect(logPath + ".1").ToNot(BeAnExistingFile())
+	})
+
+	It("keeps writing to the current file if it can not be moved aside", func() {
+		Expect(os.MkdirAll(filepath.Join(logPath+".1", "busy"), 0700)).To(Succeed())
+
+		sink, err := NewFileSink(logPath, 6, 1)
+		Expect(err).ToNot(HaveOccurred())
+		defer sink.Close()
+
+		sink.Log(lager.INFO, []byte("log-1"))
+		sink.Log(lager.INFO, []byte("log-2"))
+		sink.Log(lager.INFO, []byte("log-3"))
+
+		Expect(readFile(logPath)).To(Equal("log-1\nlog-2\nlog-3\n"))
+	})
+
+	It("returns error if the file can not be opened", func() {
+		_, err := NewFileSink(filepath.Join(tmpDir, "unknown", "broker.log"), 1024, 2)
+		Expect(err).To(HaveOccurred())
+	})
+})
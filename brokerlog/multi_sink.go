@@ -0,0 +1,18 @@
+package brokerlog
+
+import (
+	"github.com/pivotal-golang/lager"
+)
+
+// MultiSink hands every log to all its sinks, so they can share the same level, format and redaction
+type MultiSink []lager.Sink
+
+func NewMultiSink(sinks ...lager.Sink) MultiSink {
+	return MultiSink(sinks)
+}
+
+func (s MultiSink) Log(level lager.LogLevel, payload []byte) {
+	for _, sink := range s {
+		sink.Log(level, payload)
+	}
+}
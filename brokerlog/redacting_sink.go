@@ -0,0 +1,133 @@
+package brokerlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const RedactedValue = "[REDACTED]"
+
+// secretKeyFragments are the parts of the data keys whose values are masked, such as admin_password or SecretAccessKey.
+// Keys are compared in lower case without '_' and '-'.
+var secretKeyFragments = []string{"password", "secret", "token", "credentials"}
+
+// secretKeySuffix ends the keys of other secrets, such as audit_log_key. Objects under such keys (an IAM AccessKey)
+// are not masked as a whole, their own secret keys are.
+const secretKeySuffix = "key"
+
+// fileKeySuffix ends the keys of the files holding a secret, such as password_file, which are masked like the secret
+const fileKeySuffix = "file"
+
+// RedactingSink masks the values of secret keys at any depth of the log data before handing the log to its sink
+type RedactingSink struct {
+	sink lager.Sink
+}
+
+func NewRedactingSink(sink lager.Sink) *RedactingSink {
+	return &RedactingSink{sink: sink}
+}
+
+func (s *RedactingSink) Log(level lager.LogLevel, payload []byte) {
+	s.sink.Log(level, Redact(payload))
+}
+
+// NewLogger builds a Logger writing to sinks that never see the secrets of the log data
+func NewLogger(component string, sinks ...lager.Sink) lager.Logger {
+	logger := lager.NewLogger(component)
+	for _, sink := range sinks {
+		logger.RegisterSink(NewRedactingSink(sink))
+	}
+
+	return logger
+}
+
+// Redact returns a lager JSON payload with the values of secret keys masked.
+// Payloads that can not be parsed are masked entirely, as they can not be checked.
+func Redact(payload []byte) []byte {
+	if !mayHaveSecretKeys(payload) {
+		return payload
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	var log lager.LogFormat
+	if err := decoder.Decode(&log); err != nil {
+		return lager.LogFormat{
+			Message: "unparseable-log",
+			Data:    lager.Data{"payload": RedactedValue},
+		}.ToJSON()
+	}
+
+	if !redactValue(map[string]interface{}(log.Data)) {
+		return payload
+	}
+
+	return log.ToJSON()
+}
+
+// redactValue masks the secret keys of the maps found in value, and returns whether any was masked
+func redactValue(value interface{}) bool {
+	redacted := false
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, nestedValue := range value {
+			if isSecretKey(key, nestedValue) {
+				value[key] = RedactedValue
+				redacted = true
+				continue
+			}
+			if redactValue(nestedValue) {
+				redacted = true
+			}
+		}
+	case []interface{}:
+		for _, nestedValue := range value {
+			if redactValue(nestedValue) {
+				redacted = true
+			}
+		}
+	}
+
+	return redacted
+}
+
+// mayHaveSecretKeys tells whether a payload may hold a secret key, so that logs without any are not parsed
+func mayHaveSecretKeys(payload []byte) bool {
+	lowerPayload := bytes.ToLower(payload)
+	if bytes.Contains(lowerPayload, []byte(secretKeySuffix)) {
+		return true
+	}
+
+	for _, fragment := range secretKeyFragments {
+		if bytes.Contains(lowerPayload, []byte(fragment)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isSecretKey(key string, value interface{}) bool {
+	key = strings.ToLower(key)
+	key = strings.Replace(key, "_", "", -1)
+	key = strings.Replace(key, "-", "", -1)
+	key = strings.TrimSuffix(key, fileKeySuffix)
+
+	for _, fragment := range secretKeyFragments {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	}
+
+	return strings.HasSuffix(key, secretKeySuffix)
+}
@@ -0,0 +1,123 @@
+package brokerlog_test
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/sqs-broker/brokerlog"
+)
+
+var _ = Describe("RedactingSink", func() {
+	var (
+		testSink  *lagertest.TestSink
+		otherSink *lagertest.TestSink
+		logger    lager.Logger
+	)
+
+	BeforeEach(func() {
+		testSink = lagertest.NewTestSink()
+		otherSink = lagertest.NewTestSink()
+		logger = NewLogger("brokerlog_test", testSink, otherSink)
+	})
+
+	logs := func(sink *lagertest.TestSink) string {
+		return string(sink.Buffer().Contents())
+	}
+
+	It("masks the Secret Access Key of the AWS responses", func() {
+		logger.Debug("create-access-key", lager.Data{"output": &iam.CreateAccessKeyOutput{
+			AccessKey: &iam.AccessKey{
+				AccessKeyId:     aws.String("access-key-id"),
+				SecretAccessKey: aws.String("secret-access-key"),
+				UserName:        aws.String("user-name"),
+			},
+		}})
+
+		for _, sink := range []*lagertest.TestSink{testSink, otherSink} {
+			Expect(logs(sink)).ToNot(ContainSubstring("secret-access-key"))
+			Expect(logs(sink)).To(ContainSubstring(`"SecretAccessKey":"[REDACTED]"`))
+			Expect(logs(sink)).To(ContainSubstring(`"AccessKeyId":"access-key-id"`))
+		}
+	})
+
+	It("masks the credentials of the binding responses", func() {
+		logger.Info("bind", lager.Data{"response": brokerapi.BindingResponse{
+			Credentials: &brokerapi.CredentialsHash{
+				Username: "access-key-id",
+				Password: "secret-access-key",
+				URI:      "queue-url",
+			},
+		}})
+
+		Expect(logs(testSink)).ToNot(ContainSubstring("secret-access-key"))
+		Expect(logs(testSink)).ToNot(ContainSubstring("queue-url"))
+		Expect(logs(testSink)).To(ContainSubstring(`"credentials":"[REDACTED]"`))
+	})
+
+	It("masks the secret keys whatever their case and separators", func() {
+		logger.Info("config", lager.Data{
+			"password":          "broker-password",
+			"Password":          "other-password",
+			"secret_access_key": "secret-access-key",
+			"list":              []interface{}{map[string]interface{}{"session-token": "session-token"}},
+		})
+
+		Expect(testSink.Logs()).To(HaveLen(1))
+		Expect(testSink.Logs()[0].Data).To(Equal(lager.Data{
+			"password":          "[REDACTED]",
+			"Password":          "[REDACTED]",
+			"secret_access_key": "[REDACTED]",
+			"list":              []interface{}{map[string]interface{}{"session-token": "[REDACTED]"}},
+		}))
+	})
+
+	It("masks the keys holding a secret among other words", func() {
+		logger.Info("config", lager.Data{
+			"admin_password": "admin-password",
+			"audit_log_key":  "audit-log-key",
+			"PasswordFile":   "/var/vcap/password",
+			"sqs_config":     map[string]interface{}{"aws": map[string]interface{}{"SecretAccessKey": "secret-access-key", "AccessKeyId": "access-key-id"}},
+			"NextToken":      "next-token",
+			"instance-id":    "instance-id",
+		})
+
+		Expect(testSink.Logs()).To(HaveLen(1))
+		Expect(testSink.Logs()[0].Data).To(Equal(lager.Data{
+			"admin_password": "[REDACTED]",
+			"audit_log_key":  "[REDACTED]",
+			"PasswordFile":   "[REDACTED]",
+			"sqs_config":     map[string]interface{}{"aws": map[string]interface{}{"SecretAccessKey": "[REDACTED]", "AccessKeyId": "access-key-id"}},
+			"NextToken":      "[REDACTED]",
+			"instance-id":    "instance-id",
+		}))
+	})
+
+	It("masks the secrets of the session data", func() {
+		logger.Session("session", lager.Data{"password": "broker-password"}).Error("failed", errors.New("operation failed"))
+
+		Expect(logs(testSink)).ToNot(ContainSubstring("broker-password"))
+		Expect(testSink.Logs()[0].Data).To(HaveKeyWithValue("error", "operation failed"))
+	})
+
+	It("keeps the logs without secrets as they are", func() {
+		logger.Info("provision", lager.Data{"instance-id": "instance-id", "messages": 12345678901234567})
+
+		Expect(logs(testSink)).To(ContainSubstring(`"data":{"instance-id":"instance-id","messages":12345678901234567}`))
+	})
+})
+
+var _ = Describe("Redact", func() {
+	It("masks the whole payload if it can not be parsed", func() {
+		redacted := Redact([]byte(`{"data":{"password":"broker-password"`))
+		Expect(string(redacted)).ToNot(ContainSubstring("broker-password"))
+		Expect(string(redacted)).To(ContainSubstring("[REDACTED]"))
+	})
+})
@@ -0,0 +1,42 @@
+package brokerlog
+
+import (
+	"log/syslog"
+
+	"github.com/pivotal-golang/lager"
+)
+
+// SyslogSink sends each log as a syslog message, with a severity matching its level
+type SyslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink connects to the syslog server at address over network ("udp" or "tcp"),
+// or to the local syslog daemon if network is empty
+func NewSyslogSink(network string, address string, tag string) (*SyslogSink, error) {
+	writer, err := syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SyslogSink{writer: writer}, nil
+}
+
+func (s *SyslogSink) Log(level lager.LogLevel, payload []byte) {
+	message := string(payload)
+
+	switch level {
+	case lager.DEBUG:
+		s.writer.Debug(message)
+	case lager.INFO:
+		s.writer.Info(message)
+	case lager.ERROR:
+		s.writer.Err(message)
+	default:
+		s.writer.Crit(message)
+	}
+}
+
+func (s *SyslogSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,56 @@
+package brokerlog_test
+
+import (
+	"net"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/sqs-broker/brokerlog"
+)
+
+var _ = Describe("SyslogSink", func() {
+	var (
+		server net.PacketConn
+	)
+
+	BeforeEach(func() {
+		var err error
+		server, err = net.ListenPacket("udp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	receive := func() string {
+		buffer := make([]byte, 1024)
+		server.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := server.ReadFrom(buffer)
+		Expect(err).ToNot(HaveOccurred())
+		return string(buffer[:n])
+	}
+
+	It("sends the logs with the severity of their level", func() {
+		sink, err := NewSyslogSink("udp", server.LocalAddr().String(), "sqs-broker")
+		Expect(err).ToNot(HaveOccurred())
+		defer sink.Close()
+
+		// <facility daemon (3) * 8 + severity>
+		sink.Log(lager.DEBUG, []byte("log-1"))
+		Expect(receive()).To(MatchRegexp(`^<31>.* sqs-broker\[\d+\]: log-1\n?$`))
+
+		sink.Log(lager.INFO, []byte("log-2"))
+		Expect(receive()).To(MatchRegexp(`^<30>.* sqs-broker\[\d+\]: log-2\n?$`))
+
+		sink.Log(lager.ERROR, []byte("log-3"))
+		Expect(receive()).To(MatchRegexp(`^<27>.*: log-3\n?$`))
+
+		sink.Log(lager.FATAL, []byte("log-4"))
+		Expect(receive()).To(MatchRegexp(`^<26>.*: log-4\n?$`))
+	})
+})
@@ -0,0 +1,13 @@
+package brokerstore_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestBrokerStore(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Broker Store Suite")
+}
@@ -0,0 +1,95 @@
+package fakes
+
+import (
+	"github.com/cf-platform-eng/sqs-broker/brokerstore"
+)
+
+type FakeStore struct {
+	ListInstancesCalled    bool
+	ListInstancesInstances []brokerstore.Instance
+	ListInstancesError     error
+
+	GetInstanceCalled     bool
+	GetInstanceInstanceID string
+	GetInstanceInstance   brokerstore.Instance
+	GetInstanceError      error
+
+	SaveInstanceCalled   bool
+	SaveInstanceInstance brokerstore.Instance
+	SaveInstanceError    error
+
+	DeleteInstanceCalled     bool
+	DeleteInstanceInstanceID string
+	DeleteInstanceError      error
+
+	ListBindingsCalled   bool
+	ListBindingsBindings []brokerstore.Binding
+	ListBindingsError    error
+
+	GetBindingCalled    bool
+	GetBindingBindingID string
+	GetBindingBinding   brokerstore.Binding
+	GetBindingError     error
+
+	SaveBindingCalled  bool
+	SaveBindingBinding brokerstore.Binding
+	SaveBindingError   error
+
+	DeleteBindingCalled    bool
+	DeleteBindingBindingID string
+	DeleteBindingError     error
+}
+
+func (f *FakeStore) ListInstances() ([]brokerstore.Instance, error) {
+	f.ListInstancesCalled = true
+
+	return f.ListInstancesInstances, f.ListInstancesError
+}
+
+func (f *FakeStore) GetInstance(instanceID string) (brokerstore.Instance, error) {
+	f.GetInstanceCalled = true
+	f.GetInstanceInstanceID = instanceID
+
+	return f.GetInstanceInstance, f.GetInstanceError
+}
+
+func (f *FakeStore) SaveInstance(instance brokerstore.Instance) error {
+	f.SaveInstanceCalled = true
+	f.SaveInstanceInstance = instance
+
+	return f.SaveInstanceError
+}
+
+func (f *FakeStore) DeleteInstance(instanceID string) error {
+	f.DeleteInstanceCalled = true
+	f.DeleteInstanceInstanceID = instanceID
+
+	return f.DeleteInstanceError
+}
+
+func (f *FakeStore) ListBindings() ([]brokerstore.Binding, error) {
+	f.ListBindingsCalled = true
+
+	return f.ListBindingsBindings, f.ListBindingsError
+}
+
+func (f *FakeStore) GetBinding(bindingID string) (brokerstore.Binding, error) {
+	f.GetBindingCalled = true
+	f.GetBindingBindingID = bindingID
+
+	return f.GetBindingBinding, f.GetBindingError
+}
+
+func (f *FakeStore) SaveBinding(binding brokerstore.Binding) error {
+	f.SaveBindingCalled = true
+	f.SaveBindingBinding = binding
+
+	return f.SaveBindingError
+}
+
+func (f *FakeStore) DeleteBinding(bindingID string) error {
+	f.DeleteBindingCalled = true
+	f.DeleteBindingBindingID = bindingID
+
+	return f.DeleteBindingError
+}
@@ -0,0 +1,214 @@
+package brokerstore
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type JSONStore struct {
+	path  string
+	mutex sync.Mutex
+	state jsonState
+}
+
+type jsonState struct {
+	Instances map[string]Instance `json:"instances"`
+	Bindings  map[string]Binding  `json:"bindings"`
+}
+
+func NewMemoryStore() *JSONStore {
+	return &JSONStore{
+		state: newJSONState(),
+	}
+}
+
+func NewFileStore(path string) (*JSONStore, error) {
+	s := &JSONStore{
+		path:  path,
+		state: newJSONState(),
+	}
+
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+
+	if err = json.Unmarshal(bytes, &s.state); err != nil {
+		return nil, err
+	}
+
+	if s.state.Instances == nil {
+		s.state.Instances = map[string]Instance{}
+	}
+
+	if s.state.Bindings == nil {
+		s.state.Bindings = map[string]Binding{}
+	}
+
+	return s, nil
+}
+
+func (s *JSONStore) ListInstances() ([]Instance, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	instances := []Instance{}
+	for _, instance := range s.state.Instances {
+		instances = append(instances, instance)
+	}
+
+	return instances, nil
+}
+
+func (s *JSONStore) GetInstance(instanceID string) (Instance, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	instance, ok := s.state.Instances[instanceID]
+	if !ok {
+		return Instance{}, ErrInstanceDoesNotExist
+	}
+
+	return instance, nil
+}
+
+func (s *JSONStore) SaveInstance(instance Instance) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, existed := s.state.Instances[instance.InstanceID]
+	s.state.Instances[instance.InstanceID] = instance
+
+	if err := s.persist(); err != nil {
+		if existed {
+			s.state.Instances[instance.InstanceID] = previous
+		} else {
+			delete(s.state.Instances, instance.InstanceID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *JSONStore) DeleteInstance(instanceID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	instance, ok := s.state.Instances[instanceID]
+	if !ok {
+		return ErrInstanceDoesNotExist
+	}
+	delete(s.state.Instances, instanceID)
+
+	if err := s.persist(); err != nil {
+		s.state.Instances[instanceID] = instance
+		return err
+	}
+
+	return nil
+}
+
+func (s *JSONStore) ListBindings() ([]Binding, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	bindings := []Binding{}
+	for _, binding := range s.state.Bindings {
+		bindings = append(bindings, binding)
+	}
+
+	return bindings, nil
+}
+
+func (s *JSONStore) GetBinding(bindingID string) (Binding, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	binding, ok := s.state.Bindings[bindingID]
+	if !ok {
+		return Binding{}, ErrBindingDoesNotExist
+	}
+
+	return binding, nil
+}
+
+func (s *JSONStore) SaveBinding(binding Binding) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, existed := s.state.Bindings[binding.BindingID]
+	s.state.Bindings[binding.BindingID] = binding
+
+	if err := s.persist(); err != nil {
+		if existed {
+			s.state.Bindings[binding.BindingID] = previous
+		} else {
+			delete(s.state.Bindings, binding.BindingID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *JSONStore) DeleteBinding(bindingID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	binding, ok := s.state.Bindings[bindingID]
+	if !ok {
+		return ErrBindingDoesNotExist
+	}
+	delete(s.state.Bindings, bindingID)
+
+	if err := s.persist(); err != nil {
+		s.state.Bindings[bindingID] = binding
+		return err
+	}
+
+	return nil
+}
+
+func (s *JSONStore) persist() error {
+	if s.path == "" {
+		return nil
+	}
+
+	bytes, err := json.Marshal(s.state)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated state file behind
+	tmpFile, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path))
+	if err != nil {
+		return err
+	}
+
+	if _, err = tmpFile.Write(bytes); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		return err
+	}
+
+	if err = tmpFile.Close(); err != nil {
+		os.Remove(tmpFile.Name())
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), s.path)
+}
+
+func newJSONState() jsonState {
+	return jsonState{
+		Instances: map[string]Instance{},
+		Bindings:  map[string]Binding{},
+	}
+}
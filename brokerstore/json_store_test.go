@@ -0,0 +1,241 @@
+package brokerstore_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/sqs-broker/brokerstore"
+)
+
+var _ = Describe("JSONStore", func() {
+	var (
+		instance Instance
+		binding  Binding
+	)
+
+	BeforeEach(func() {
+		instance = Instance{
+			InstanceID:       "instance-id",
+			ServiceID:        "service-id",
+			PlanID:           "plan-id",
+			OrganizationGUID: "organization-guid",
+			SpaceGUID:        "space-guid",
+			Parameters:       map[string]interface{}{"delay_seconds": "1"},
+		}
+
+		binding = Binding{
+			BindingID:       "binding-id",
+			InstanceID:      "instance-id",
+			ServiceID:       "service-id",
+			PlanID:          "plan-id",
+			AppGUID:         "app-guid",
+			AccessKeyID:     "access-key-id",
+			SecretAccessKey: "secret-access-key",
+		}
+	})
+
+	Describe("NewMemoryStore", func() {
+		var (
+			store *JSONStore
+		)
+
+		BeforeEach(func() {
+			store = NewMemoryStore()
+		})
+
+		It("saves and returns the Instance", func() {
+			err := store.SaveInstance(instance)
+			Expect(err).ToNot(HaveOccurred())
+
+			storedInstance, err := store.GetInstance("instance-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(storedInstance).To(Equal(instance))
+		})
+
+		It("replaces an existing Instance", func() {
+			err := store.SaveInstance(instance)
+			Expect(err).ToNot(HaveOccurred())
+
+			instance.PlanID = "new-plan-id"
+			err = store.SaveInstance(instance)
+			Expect(err).ToNot(HaveOccurred())
+
+			storedInstance, err := store.GetInstance("instance-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(storedInstance.PlanID).To(Equal("new-plan-id"))
+		})
+
+		It("deletes the Instance", func() {
+			err := store.SaveInstance(instance)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = store.DeleteInstance("instance-id")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = store.GetInstance("instance-id")
+			Expect(err).To(Equal(ErrInstanceDoesNotExist))
+		})
+
+		It("returns the proper error when getting an unknown Instance", func() {
+			_, err := store.GetInstance("unknown")
+			Expect(err).To(Equal(ErrInstanceDoesNotExist))
+		})
+
+		It("returns the proper error when deleting an unknown Instance", func() {
+			err := store.DeleteInstance("unknown")
+			Expect(err).To(Equal(ErrInstanceDoesNotExist))
+		})
+
+		It("lists the Instances", func() {
+			instances, err := store.ListInstances()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instances).To(BeEmpty())
+
+			err = store.SaveInstance(instance)
+			Expect(err).ToNot(HaveOccurred())
+
+			instances, err = store.ListInstances()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instances).To(Equal([]Instance{instance}))
+		})
+
+		It("saves and returns the Binding", func() {
+			err := store.SaveBinding(binding)
+			Expect(err).ToNot(HaveOccurred())
+
+			storedBinding, err := store.GetBinding("binding-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(storedBinding).To(Equal(binding))
+		})
+
+		It("deletes the Binding", func() {
+			err := store.SaveBinding(binding)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = store.DeleteBinding("binding-id")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = store.GetBinding("binding-id")
+			Expect(err).To(Equal(ErrBindingDoesNotExist))
+		})
+
+		It("lists the Bindings", func() {
+			bindings, err := store.ListBindings()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bindings).To(BeEmpty())
+
+			err = store.SaveBinding(binding)
+			Expect(err).ToNot(HaveOccurred())
+
+			bindings, err = store.ListBindings()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bindings).To(Equal([]Binding{binding}))
+		})
+
+		It("returns the proper error when getting an unknown Binding", func() {
+			_, err := store.GetBinding("unknown")
+			Expect(err).To(Equal(ErrBindingDoesNotExist))
+		})
+
+		It("returns the proper error when deleting an unknown Binding", func() {
+			err := store.DeleteBinding("unknown")
+			Expect(err).To(Equal(ErrBindingDoesNotExist))
+		})
+	})
+
+	Describe("NewFileStore", func() {
+		var (
+			tmpDir    string
+			statePath string
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "brokerstore")
+			Expect(err).ToNot(HaveOccurred())
+			statePath = filepath.Join(tmpDir, "state.json")
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+
+		It("starts empty if the state file does not exist", func() {
+			store, err := NewFileStore(statePath)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = store.GetInstance("instance-id")
+			Expect(err).To(Equal(ErrInstanceDoesNotExist))
+		})
+
+		It("persists Instances across stores", func() {
+			store, err := NewFileStore(statePath)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = store.SaveInstance(instance)
+			Expect(err).ToNot(HaveOccurred())
+
+			reloadedStore, err := NewFileStore(statePath)
+			Expect(err).ToNot(HaveOccurred())
+
+			storedInstance, err := reloadedStore.GetInstance("instance-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(storedInstance).To(Equal(instance))
+		})
+
+		It("persists deleted Instances across stores", func() {
+			store, err := NewFileStore(statePath)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = store.SaveInstance(instance)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = store.DeleteInstance("instance-id")
+			Expect(err).ToNot(HaveOccurred())
+
+			reloadedStore, err := NewFileStore(statePath)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = reloadedStore.GetInstance("instance-id")
+			Expect(err).To(Equal(ErrInstanceDoesNotExist))
+		})
+
+		It("persists Bindings across stores", func() {
+			store, err := NewFileStore(statePath)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = store.SaveBinding(binding)
+			Expect(err).ToNot(HaveOccurred())
+
+			reloadedStore, err := NewFileStore(statePath)
+			Expect(err).ToNot(HaveOccurred())
+
+			storedBinding, err := reloadedStore.GetBinding("binding-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(storedBinding).To(Equal(binding))
+		})
+
+		It("returns error if the state file is not valid", func() {
+			err := ioutil.WriteFile(statePath, []byte("not-json"), 0600)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = NewFileStore(statePath)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("does not keep the Instance if the state file can not be written", func() {
+			store, err := NewFileStore(filepath.Join(tmpDir, "unknown", "state.json"))
+			Expect(err).ToNot(HaveOccurred())
+
+			err = store.SaveInstance(instance)
+			Expect(err).To(HaveOccurred())
+
+			_, err = store.GetInstance("instance-id")
+			Expect(err).To(Equal(ErrInstanceDoesNotExist))
+		})
+	})
+})
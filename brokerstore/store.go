@@ -0,0 +1,62 @@
+package brokerstore
+
+import (
+	"errors"
+)
+
+type Store interface {
+	ListInstances() ([]Instance, error)
+	GetInstance(instanceID string) (Instance, error)
+	SaveInstance(instance Instance) error
+	DeleteInstance(instanceID string) error
+	ListBindings() ([]Binding, error)
+	GetBinding(bindingID string) (Binding, error)
+	SaveBinding(binding Binding) error
+	DeleteBinding(bindingID string) error
+}
+
+type Instance struct {
+	InstanceID       string                  `json:"instance_id"`
+	ServiceID        string                  `json:"service_id"`
+	PlanID           string                  `json:"plan_id"`
+	OrganizationGUID string                  `json:"organization_guid"`
+	SpaceGUID        string                  `json:"space_guid"`
+	Region           string                  `json:"region,omitempty"`
+	RoleArn          string                  `json:"role_arn,omitempty"`
+	ExternalID       string                  `json:"external_id,omitempty"`
+	Parameters       map[string]interface{}  `json:"parameters,omitempty"`
+	Context          map[string]interface{}  `json:"context,omitempty"`
+	Subscriptions    map[string]Subscription `json:"subscriptions,omitempty"`
+
+	LastOperationState       string `json:"last_operation_state,omitempty"`
+	LastOperationDescription string `json:"last_operation_description,omitempty"`
+}
+
+type Subscription struct {
+	TopicInstanceID string `json:"topic_instance_id,omitempty"`
+	TopicArn        string `json:"topic_arn"`
+	SubscriptionArn string `json:"subscription_arn"`
+}
+
+type Binding struct {
+	BindingID       string                 `json:"binding_id"`
+	InstanceID      string                 `json:"instance_id"`
+	ServiceID       string                 `json:"service_id"`
+	PlanID          string                 `json:"plan_id"`
+	AppGUID         string                 `json:"app_guid,omitempty"`
+	Parameters      map[string]interface{} `json:"parameters,omitempty"`
+	Context         map[string]interface{} `json:"context,omitempty"`
+	AccessKeyID     string                 `json:"access_key_id"`
+	SecretAccessKey string                 `json:"secret_access_key,omitempty"`
+	TopicInstanceID string                 `json:"topic_instance_id,omitempty"`
+	TopicArn        string                 `json:"topic_arn,omitempty"`
+	SubscriptionArn string                 `json:"subscription_arn,omitempty"`
+
+	LastOperationState       string `json:"last_operation_state,omitempty"`
+	LastOperationDescription string `json:"last_operation_description,omitempty"`
+}
+
+var (
+	ErrInstanceDoesNotExist = errors.New("instance does not exist")
+	ErrBindingDoesNotExist  = errors.New("binding does not exist")
+)
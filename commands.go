@@ -0,0 +1,327 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/sqs-broker/brokerlog"
+	"github.com/cf-platform-eng/sqs-broker/sqsbroker"
+)
+
+const defaultCommand = "serve"
+
+// ErrUsage is returned once the usage has been printed for invalid arguments
+var ErrUsage = errors.New("Invalid usage")
+
+// ErrNoStateFile is returned by the commands reading the broker records, which are only kept in the memory
+// of the running broker without a state_file
+var ErrNoStateFile = errors.New("No state_file configured, the instances are only recorded in the memory of the running broker")
+
+type command struct {
+	name        string
+	arguments   string
+	description string
+	run         func(cmd command, args []string, stdout io.Writer, stderr io.Writer) error
+}
+
+var commands = []command{
+	{"serve", "", "Runs the broker (default command)", serveCommand},
+	{"validate-config", "", "Validates the config and checks it against the AWS limits", validateConfigCommand},
+	{"catalog", "", "Prints the catalog served to the platforms", catalogCommand},
+	{"list-instances", "", "Lists the instances recorded by the broker", listInstancesCommand},
+	{"describe-instance", "<instance-id>", "Prints an instance with the live attributes of its queue or topic, and its bindings", describeInstanceCommand},
+	{"check-permissions", "", "Checks that the broker AWS credentials are allowed every action the broker calls", checkPermissionsCommand},
+}
+
+// RunCommand runs the command named by the first argument, or serves the broker if it is a flag
+func RunCommand(args []string, stdout io.Writer, stderr io.Writer) error {
+	name := defaultCommand
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" {
+		printUsage(stdout)
+		return nil
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(cmd, args, stdout, stderr)
+		}
+	}
+
+	fmt.Fprintf(stderr, "Unknown command '%s'\n\n", name)
+	printUsage(stderr)
+
+	return ErrUsage
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: sqs-broker [command] [options]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s %s\t%s\n", cmd.name, cmd.arguments, cmd.description)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'sqs-broker <command> -h' for the options of a command.")
+}
+
+// newFlagSet returns the flags of a command, with the -config flag every command takes
+func newFlagSet(cmd command, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&configFilePath, "config", "", "Location of the config file")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: sqs-broker %s [options] %s\n\n%s\n\nOptions:\n", cmd.name, cmd.arguments, cmd.description)
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
+
+// parseFlags parses the arguments, and checks the number of positional arguments left
+func parseFlags(flags *flag.FlagSet, args []string, positionalArgs int) error {
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return ErrUsage
+	}
+
+	if flags.NArg() != positionalArgs {
+		flags.Usage()
+		return ErrUsage
+	}
+
+	return nil
+}
+
+func serveCommand(cmd command, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet(cmd, stderr)
+	port := flags.String("port", "3000", "Listen port")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	config, err := LoadConfig(configFilePath)
+	if err != nil {
+		return fmt.Errorf("Error loading config file: %s", err)
+	}
+
+	if err := LoadAWSCredentialsFiles(); err != nil {
+		return fmt.Errorf("Error loading AWS credentials: %s", err)
+	}
+
+	logger, levelSink, err := NewLogger(config)
+	if err != nil {
+		return fmt.Errorf("Error building logger: %s", err)
+	}
+	changeLogLevelOnSignal(levelSink, logger)
+
+	httpClient, err := buildHTTPClient(config.SQSConfig)
+	if err != nil {
+		return fmt.Errorf("Loading CA bundle: %s", err)
+	}
+
+	if err := CheckStartupPermissions(config, buildPolicySimulator(config.SQSConfig, httpClient, logger), logger); err != nil {
+		return fmt.Errorf("Error checking permissions: %s", err)
+	}
+
+	serviceBroker, brokerAPI, err := NewBroker(config, logger)
+	if err != nil {
+		return fmt.Errorf("Error building broker: %s", err)
+	}
+	reloadOnSignal(serviceBroker, logger)
+
+	if err := serviceBroker.FailInterruptedOperations(); err != nil {
+		return fmt.Errorf("Error failing interrupted operations: %s", err)
+	}
+
+	http.Handle("/", brokerAPI)
+
+	fmt.Fprintln(stdout, "SQS Service Broker started on port "+*port+"...")
+	return http.ListenAndServe(":"+*port, nil)
+}
+
+func validateConfigCommand(cmd command, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet(cmd, stderr)
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	config, err := LoadConfig(configFilePath)
+	if err != nil {
+		return fmt.Errorf("Error loading config file: %s", err)
+	}
+
+	problems := CheckConfig(config)
+	for _, problem := range problems {
+		fmt.Fprintln(stdout, problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("Found %d problems in the config", len(problems))
+	}
+
+	fmt.Fprintln(stdout, "The config is valid")
+	return nil
+}
+
+func catalogCommand(cmd command, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet(cmd, stderr)
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	serviceBroker, err := loadBroker(stderr, false)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(stdout, serviceBroker.Services())
+}
+
+func listInstancesCommand(cmd command, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet(cmd, stderr)
+	jsonOutput := flags.Bool("json", false, "Print the instances as JSON")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	serviceBroker, err := loadBroker(stderr, true)
+	if err != nil {
+		return err
+	}
+
+	instances, err := serviceBroker.AdminInstances()
+	if err != nil {
+		return fmt.Errorf("Listing instances: %s", err)
+	}
+
+	if *jsonOutput {
+		return printJSON(stdout, instances)
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "INSTANCE ID\tSERVICE ID\tPLAN ID\tREGION\tSTATE\tARN")
+	for _, instance := range instances {
+		arn := instance.Arn
+		if instance.Error != "" {
+			arn = "error: " + instance.Error
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", instance.InstanceID, instance.ServiceID, instance.PlanID, instance.Region, instance.LastOperationState, arn)
+	}
+
+	return tw.Flush()
+}
+
+func describeInstanceCommand(cmd command, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet(cmd, stderr)
+	if err := parseFlags(flags, args, 1); err != nil {
+		return err
+	}
+
+	serviceBroker, err := loadBroker(stderr, true)
+	if err != nil {
+		return err
+	}
+
+	instanceID := flags.Arg(0)
+	instance, err := serviceBroker.AdminInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("Describing instance '%s': %s", instanceID, err)
+	}
+
+	return printJSON(stdout, instance)
+}
+
+func checkPermissionsCommand(cmd command, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet(cmd, stderr)
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	config, err := LoadConfig(configFilePath)
+	if err != nil {
+		return fmt.Errorf("Error loading config file: %s", err)
+	}
+
+	if err := LoadAWSCredentialsFiles(); err != nil {
+		return fmt.Errorf("Error loading AWS credentials: %s", err)
+	}
+
+	httpClient, err := buildHTTPClient(config.SQSConfig)
+	if err != nil {
+		return fmt.Errorf("Loading CA bundle: %s", err)
+	}
+
+	policySimulator := buildPolicySimulator(config.SQSConfig, httpClient, commandLogger(stderr))
+
+	callerArn, actions, deniedActions, err := CheckRequiredPermissions(config.SQSConfig, policySimulator)
+	if err != nil {
+		return err
+	}
+
+	for _, action := range deniedActions {
+		fmt.Fprintln(stdout, "Denied: "+action)
+	}
+	if len(deniedActions) > 0 {
+		return fmt.Errorf("%d of the %d required actions are denied to '%s'", len(deniedActions), actions, callerArn)
+	}
+
+	fmt.Fprintf(stdout, "All the %d required actions are allowed to '%s'\n", actions, callerArn)
+	return nil
+}
+
+// loadBroker builds the broker of the operator commands, which never write to the audit log.
+// Commands reading the broker records require a state_file, as they run in a process of their own.
+func loadBroker(stderr io.Writer, requireStateFile bool) (*sqsbroker.SQSBroker, error) {
+	config, err := LoadConfig(configFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading config file: %s", err)
+	}
+
+	if requireStateFile && config.SQSConfig.StateFile == "" {
+		return nil, ErrNoStateFile
+	}
+
+	if err := LoadAWSCredentialsFiles(); err != nil {
+		return nil, fmt.Errorf("Error loading AWS credentials: %s", err)
+	}
+
+	config.AuditLogFile = ""
+	serviceBroker, _, err := NewBroker(config, commandLogger(stderr))
+	if err != nil {
+		return nil, fmt.Errorf("Error building broker: %s", err)
+	}
+
+	return serviceBroker, nil
+}
+
+// commandLogger reports the errors of the operator commands on stderr, leaving stdout to their output
+func commandLogger(stderr io.Writer) lager.Logger {
+	return brokerlog.NewLogger("sqs-broker", lager.NewWriterSink(stderr, lager.ERROR))
+}
+
+func printJSON(w io.Writer, value interface{}) error {
+	output, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(output))
+	return err
+}
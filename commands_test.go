@@ -0,0 +1,228 @@
+package main_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/sqs-broker"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cf-platform-eng/sqs-broker/awsemulator"
+	"github.com/cf-platform-eng/sqs-broker/sqsbroker"
+)
+
+var _ = Describe("RunCommand", func() {
+	var (
+		tmpDir         string
+		configFile     string
+		emulator       *awsemulator.Emulator
+		emulatorServer *httptest.Server
+		config         *Config
+
+		stdout *bytes.Buffer
+		stderr *bytes.Buffer
+	)
+
+	writeConfig := func() {
+		contents, err := json.Marshal(config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ioutil.WriteFile(configFile, contents, 0600)).To(Succeed())
+	}
+
+	runCommand := func(name string, args ...string) error {
+		writeConfig()
+		return RunCommand(append([]string{name, "-config=" + configFile}, args...), stdout, stderr)
+	}
+
+	provision := func() {
+		logger := lager.NewLogger("commands_test")
+		logger.RegisterSink(lagertest.NewTestSink())
+
+		_, brokerAPI, err := NewBroker(config, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		request, err := http.NewRequest("PUT", "/v2/service_instances/instance-id", strings.NewReader(`{"service_id":"service-id","plan_id":"plan-id","organization_guid":"organization-id","space_guid":"space-id"}`))
+		Expect(err).ToNot(HaveOccurred())
+		request.SetBasicAuth("broker-username", "broker-password")
+
+		recorder := httptest.NewRecorder()
+		brokerAPI.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "commands")
+		Expect(err).ToNot(HaveOccurred())
+		configFile = filepath.Join(tmpDir, "config.json")
+
+		os.Setenv("AWS_ACCESS_KEY_ID", "access-key-id")
+		os.Setenv("AWS_SECRET_ACCESS_KEY", "secret-access-key")
+
+		emulator = awsemulator.New(awsemulator.DefaultRegion, awsemulator.DefaultAccountID)
+		emulatorServer = httptest.NewTLSServer(emulator)
+
+		config = &Config{
+			LogLevel: "INFO",
+			Username: "broker-username",
+			Password: "broker-password",
+			SQSConfig: sqsbroker.Config{
+				Region:            awsemulator.DefaultRegion,
+				SQSEndpoint:       emulatorServer.URL,
+				IAMEndpoint:       emulatorServer.URL,
+				SkipSSLValidation: true,
+				SQSPrefix:         "cf",
+				StateFile:         filepath.Join(tmpDir, "state.json"),
+				Catalog: sqsbroker.Catalog{
+					Services: []sqsbroker.Service{
+						{
+							ID:          "service-id",
+							Name:        "sqs",
+							Description: "SQS queues",
+							Plans: []sqsbroker.ServicePlan{
+								{ID: "plan-id", Name: "standard", Description: "Standard queue"},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		stdout = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+	})
+
+	AfterEach(func() {
+		emulatorServer.Close()
+		os.Unsetenv("AWS_ACCESS_KEY_ID")
+		os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+		os.RemoveAll(tmpDir)
+	})
+
+	It("prints the usage of an unknown command", func() {
+		err := RunCommand([]string{"unknown"}, stdout, stderr)
+		Expect(err).To(Equal(ErrUsage))
+		Expect(stderr.String()).To(ContainSubstring("Unknown command 'unknown'"))
+		Expect(stderr.String()).To(ContainSubstring("check-permissions"))
+	})
+
+	It("prints the usage of a command with missing arguments", func() {
+		err := runCommand("describe-instance")
+		Expect(err).To(Equal(ErrUsage))
+		Expect(stderr.String()).To(ContainSubstring("Usage: sqs-broker describe-instance [options] <instance-id>"))
+	})
+
+	Describe("validate-config", func() {
+		It("accepts a valid config", func() {
+			Expect(runCommand("validate-config")).To(Succeed())
+			Expect(stdout.String()).To(Equal("The config is valid\n"))
+		})
+
+		It("returns the errors of Validate", func() {
+			config.Username = ""
+
+			err := runCommand("validate-config")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty Username"))
+		})
+
+		It("reports the problems found by the deeper checks", func() {
+			config.SQSConfig.Catalog.Services[0].Plans = append(config.SQSConfig.Catalog.Services[0].Plans, sqsbroker.ServicePlan{
+				ID:            "delayed-plan-id",
+				Name:          "delayed",
+				Description:   "Delayed queue",
+				SQSProperties: sqsbroker.SQSProperties{DelaySeconds: "901"},
+			})
+
+			err := runCommand("validate-config")
+			Expect(err).To(MatchError("Found 1 problems in the config"))
+			Expect(stdout.String()).To(Equal("sqs_config.catalog.services[0].plans[1].sqs_properties.delay_seconds: '901' is not an integer between 0 and 900\n"))
+		})
+	})
+
+	Describe("catalog", func() {
+		It("prints the catalog", func() {
+			Expect(runCommand("catalog")).To(Succeed())
+			Expect(stdout.String()).To(ContainSubstring(`"id": "service-id"`))
+			Expect(stdout.String()).To(ContainSubstring(`"name": "standard"`))
+		})
+	})
+
+	Describe("list-instances", func() {
+		It("lists the instances with the ARN of their queue", func() {
+			provision()
+
+			Expect(runCommand("list-instances")).To(Succeed())
+			Expect(stdout.String()).To(MatchRegexp(`(?m)^INSTANCE ID\s+SERVICE ID\s+PLAN ID\s+REGION\s+STATE\s+ARN$`))
+			Expect(stdout.String()).To(MatchRegexp(`(?m)^instance-id\s+service-id\s+plan-id\s+us-east-1\s+arn:aws:sqs:us-east-1:123456789012:cf-instance-id$`))
+		})
+
+		It("lists the instances as JSON", func() {
+			provision()
+
+			Expect(runCommand("list-instances", "-json")).To(Succeed())
+
+			var instances []sqsbroker.AdminInstance
+			Expect(json.Unmarshal(stdout.Bytes(), &instances)).To(Succeed())
+			Expect(instances).To(HaveLen(1))
+			Expect(instances[0].InstanceID).To(Equal("instance-id"))
+		})
+
+		It("returns error if there is no state file", func() {
+			config.SQSConfig.StateFile = ""
+
+			err := runCommand("list-instances")
+			Expect(err).To(Equal(ErrNoStateFile))
+			Expect(stdout.String()).To(BeEmpty())
+		})
+	})
+
+	Describe("describe-instance", func() {
+		It("prints the instance", func() {
+			provision()
+
+			Expect(runCommand("describe-instance", "instance-id")).To(Succeed())
+			Expect(stdout.String()).To(ContainSubstring(`"arn": "arn:aws:sqs:us-east-1:123456789012:cf-instance-id"`))
+			Expect(stdout.String()).To(ContainSubstring(`"visibility_timeout": "30"`))
+		})
+
+		It("returns error if the instance does not exist", func() {
+			err := runCommand("describe-instance", "unknown-instance-id")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("Describing instance 'unknown-instance-id': "))
+		})
+
+		It("returns error if there is no state file", func() {
+			config.SQSConfig.StateFile = ""
+
+			err := runCommand("describe-instance", "instance-id")
+			Expect(err).To(Equal(ErrNoStateFile))
+		})
+	})
+
+	Describe("check-permissions", func() {
+		It("succeeds if every required action is allowed", func() {
+			Expect(runCommand("check-permissions")).To(Succeed())
+			Expect(stdout.String()).To(Equal("All the 21 required actions are allowed to 'arn:aws:iam::123456789012:user/sqs-broker'\n"))
+		})
+
+		It("reports the denied actions", func() {
+			emulator.DenyAction("iam:AttachUserPolicy")
+
+			err := runCommand("check-permissions")
+			Expect(err).To(MatchError("1 of the 21 required actions are denied to 'arn:aws:iam::123456789012:user/sqs-broker'"))
+			Expect(stdout.String()).To(Equal("Denied: iam:AttachUserPolicy\n"))
+		})
+	})
+})
@@ -6,59 +6,233 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 
 	"github.com/cf-platform-eng/sqs-broker/sqsbroker"
 )
 
+const configEnvPrefix = "SQS_BROKER"
+const configEnvVar = "SQS_BROKER_CONFIG"
+const fileEnvSuffix = "_FILE"
+
+var awsCredentialsEnvVars = []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"}
+
 type Config struct {
-	LogLevel  string           `json:"log_level"`
-	Username  string           `json:"username"`
-	Password  string           `json:"password"`
-	SQSConfig sqsbroker.Config `json:"sqs_config"`
+	LogLevel          string           `json:"log_level"`
+	LogFormat         string           `json:"log_format,omitempty"`
+	LogFile           string           `json:"log_file,omitempty"`
+	LogFileMaxSizeMB  int              `json:"log_file_max_size_mb,omitempty"`
+	LogFileMaxBackups int              `json:"log_file_max_backups,omitempty"`
+	SyslogAddress     string           `json:"syslog_address,omitempty"`
+	AuditLogFile      string           `json:"audit_log_file,omitempty"`
+	AuditLogKey       string           `json:"audit_log_key,omitempty"`
+	PermissionsCheck  string           `json:"permissions_check,omitempty"`
+	Username          string           `json:"username"`
+	Password          string           `json:"password"`
+	AdminUsername     string           `json:"admin_username,omitempty"`
+	AdminPassword     string           `json:"admin_password,omitempty"`
+	SQSConfig         sqsbroker.Config `json:"sqs_config"`
 }
 
 func LoadConfig(configFile string) (config *Config, err error) {
-	if configFile == "" {
-		return config, errors.New("Must provide a config file")
-	}
+	config = &Config{}
 
-	file, err := os.Open(configFile)
+	envConfig, hasEnvConfig, err := lookupEnv(configEnvVar)
 	if err != nil {
 		return config, err
 	}
-	defer file.Close()
 
-	bytes, err := ioutil.ReadAll(file)
-	if err != nil {
-		return config, err
+	if configFile == "" && !hasEnvConfig {
+		return config, errors.New("Must provide a config file")
 	}
 
-	if err = json.Unmarshal(bytes, &config); err != nil {
+	if configFile != "" {
+		if err = decodeFile(configFile, config); err != nil {
+			return config, err
+		}
+	}
+
+	// The whole config can be sent as JSON in a single env var, on top of the config file
+	if hasEnvConfig {
+		if err = json.Unmarshal([]byte(envConfig), config); err != nil {
+			return config, fmt.Errorf("Parsing environment variable %s: %s", configEnvVar, err)
+		}
+	}
+
+	if err = applyEnvOverrides(reflect.ValueOf(config).Elem(), configEnvPrefix); err != nil {
 		return config, err
 	}
 
+	catalogFiles := map[string]string{}
+	if config.SQSConfig.CatalogPath != "" {
+		catalogPath := config.SQSConfig.CatalogPath
+		if configFile != "" && !filepath.IsAbs(catalogPath) {
+			catalogPath = filepath.Join(filepath.Dir(configFile), catalogPath)
+		}
+
+		if catalogFiles, err = loadCatalogPath(catalogPath, &config.SQSConfig.Catalog); err != nil {
+			return config, err
+		}
+	}
+
 	if err = config.Validate(); err != nil {
+		if validationErr, ok := err.(*sqsbroker.ValidationError); ok {
+			validationErr.File = configErrorFile(validationErr.Path, configFile, catalogFiles)
+		}
 		return config, fmt.Errorf("Validating config contents: %s", err)
 	}
 
 	return config, nil
 }
 
+// applyEnvOverrides sets every config field with a matching <prefix>_<JSON KEY> env var, walking nested structs
+func applyEnvOverrides(value reflect.Value, prefix string) error {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		envName := prefix + "_" + strings.ToUpper(name)
+		envValue, ok, err := lookupEnv(envName)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			if err := setField(value.Field(i), envValue); err != nil {
+				return fmt.Errorf("Parsing environment variable %s: %s", envName, err)
+			}
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := applyEnvOverrides(value.Field(i), envName); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// setField sets strings as is, comma separated string lists, and any other type from its JSON representation
+func setField(field reflect.Value, value string) error {
+	switch {
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+		return nil
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String && !strings.HasPrefix(strings.TrimSpace(value), "["):
+		values := []string{}
+		for _, v := range strings.Split(value, ",") {
+			values = append(values, strings.TrimSpace(v))
+		}
+		field.Set(reflect.ValueOf(values))
+		return nil
+	}
+
+	return json.Unmarshal([]byte(value), field.Addr().Interface())
+}
+
+// lookupEnv returns the value of an env var, or the contents of the file named by the <name>_FILE env var
+func lookupEnv(name string) (string, bool, error) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true, nil
+	}
+
+	path, ok := os.LookupEnv(name + fileEnvSuffix)
+	if !ok {
+		return "", false, nil
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("Reading environment variable %s%s: %s", name, fileEnvSuffix, err)
+	}
+
+	return strings.TrimRight(string(contents), "\r\n"), true, nil
+}
+
+// LoadAWSCredentialsFiles sets the AWS credentials env vars read by the AWS SDK from their <name>_FILE env vars
+func LoadAWSCredentialsFiles() error {
+	for _, name := range awsCredentialsEnvVars {
+		value, ok, err := lookupEnv(name)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			if err := os.Setenv(name, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 func (c Config) Validate() error {
 	if c.LogLevel == "" {
-		return errors.New("Must provide a non-empty LogLevel")
+		return sqsbroker.NewValidationError("log_level", errors.New("Must provide a non-empty LogLevel"))
+	}
+
+	switch c.LogFormat {
+	case "", logFormatJSON, logFormatConsole:
+	default:
+		return sqsbroker.NewValidationError("log_format", fmt.Errorf("Invalid LogFormat '%s', must be '%s' or '%s'", c.LogFormat, logFormatJSON, logFormatConsole))
+	}
+
+	if c.LogFileMaxSizeMB < 0 {
+		return sqsbroker.NewValidationError("log_file_max_size_mb", errors.New("Must provide a non-negative LogFileMaxSizeMB"))
+	}
+
+	if c.LogFileMaxBackups < 0 {
+		return sqsbroker.NewValidationError("log_file_max_backups", errors.New("Must provide a non-negative LogFileMaxBackups"))
+	}
+
+	if c.SyslogAddress != "" {
+		if _, _, err := parseSyslogAddress(c.SyslogAddress); err != nil {
+			return sqsbroker.NewValidationError("syslog_address", err)
+		}
+	}
+
+	if c.AuditLogFile != "" && c.AuditLogKey == "" {
+		return sqsbroker.NewValidationError("audit_log_key", errors.New("Must provide a non-empty AuditLogKey when AuditLogFile is set"))
+	}
+
+	switch c.PermissionsCheck {
+	case "", permissionsCheckWarn, permissionsCheckEnforce, permissionsCheckOff:
+	default:
+		return sqsbroker.NewValidationError("permissions_check", fmt.Errorf("Invalid PermissionsCheck '%s', must be '%s', '%s' or '%s'", c.PermissionsCheck, permissionsCheckWarn, permissionsCheckEnforce, permissionsCheckOff))
 	}
 
 	if c.Username == "" {
-		return errors.New("Must provide a non-empty Username")
+		return sqsbroker.NewValidationError("username", errors.New("Must provide a non-empty Username"))
 	}
 
 	if c.Password == "" {
-		return errors.New("Must provide a non-empty Password")
+		return sqsbroker.NewValidationError("password", errors.New("Must provide a non-empty Password"))
+	}
+
+	if c.AdminUsername != "" || c.AdminPassword != "" {
+		if c.AdminUsername == "" {
+			return sqsbroker.NewValidationError("admin_username", errors.New("Must provide a non-empty AdminUsername along with the AdminPassword"))
+		}
+
+		if c.AdminPassword == "" {
+			return sqsbroker.NewValidationError("admin_password", errors.New("Must provide a non-empty AdminPassword along with the AdminUsername"))
+		}
+
+		if c.AdminUsername == c.Username {
+			return sqsbroker.NewValidationError("admin_username", errors.New("Must provide an AdminUsername different from the Username"))
+		}
 	}
 
 	if err := c.SQSConfig.Validate(); err != nil {
-		return fmt.Errorf("Validating SQS configuration: %s", err)
+		return sqsbroker.NewValidationError("sqs_config", err)
 	}
 
 	return nil
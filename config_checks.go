@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cf-platform-eng/sqs-broker/sqsbroker"
+)
+
+// Cloud Foundry instance and binding IDs are 36 characters GUIDs
+const guidLength = 36
+
+// AWS limit on IAM user names, the shortest of the names built from the sqs_prefix (<sqs_prefix>-<binding id>)
+const maxIAMUserNameLength = 64
+
+// CheckConfig looks for the problems Validate lets through but AWS or the platforms would reject later on,
+// and returns them prefixed with the path of the offending option
+func CheckConfig(config *Config) []string {
+	problems := []string{}
+
+	sqsPrefix := config.SQSConfig.SQSPrefix
+	if len(sqsPrefix)+1+guidLength > maxIAMUserNameLength {
+		problems = append(problems, fmt.Sprintf("sqs_config.sqs_prefix: '%s' is too long, binding user names would exceed %d characters", sqsPrefix, maxIAMUserNameLength))
+	}
+
+	for i, service := range config.SQSConfig.Catalog.Services {
+		for j, plan := range service.Plans {
+			planPath := fmt.Sprintf("sqs_config.catalog.services[%d].plans[%d]", i, j)
+			problems = append(problems, checkSQSProperties(plan.SQSProperties, planPath+".sqs_properties")...)
+		}
+	}
+
+	return problems
+}
+
+// checkSQSProperties checks the numeric queue attributes set by a plan against their AWS limits
+func checkSQSProperties(sqsProperties sqsbroker.SQSProperties, path string) []string {
+	problems := []string{}
+
+	attributes := []struct {
+		name  string
+		value string
+		min   int
+		max   int
+	}{
+		{"delay_seconds", sqsProperties.DelaySeconds, 0, 900},
+		{"maximum_message_size", sqsProperties.MaximumMessageSize, 1024, 262144},
+		{"message_retention_period", sqsProperties.MessageRetentionPeriod, 60, 1209600},
+		{"receive_message_wait_time_seconds", sqsProperties.ReceiveMessageWaitTimeSeconds, 0, 20},
+		{"visibility_timeout", sqsProperties.VisibilityTimeout, 0, 43200},
+	}
+
+	for _, attribute := range attributes {
+		if attribute.value == "" {
+			continue
+		}
+
+		if value, err := strconv.Atoi(attribute.value); err != nil || value < attribute.min || value > attribute.max {
+			problems = append(problems, fmt.Sprintf("%s.%s: '%s' is not an integer between %d and %d", path, attribute.name, attribute.value, attribute.min, attribute.max))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/cf-platform-eng/sqs-broker/sqsbroker"
+)
+
+const catalogPathKey = "sqs_config.catalog"
+
+// catalogPlanFile is a single plan of a catalog directory, added to the service with ServiceID
+type catalogPlanFile struct {
+	ServiceID string `json:"service_id"`
+	sqsbroker.ServicePlan
+}
+
+func isYAMLFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		return true
+	}
+
+	return false
+}
+
+func isConfigFile(path string) bool {
+	return isYAMLFile(path) || strings.ToLower(filepath.Ext(path)) == ".json"
+}
+
+// decodeFile decodes a JSON file, or a YAML file (chosen by extension) through its JSON representation so the json tags apply
+func decodeFile(path string, v interface{}) error {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if isYAMLFile(path) {
+		var document interface{}
+		if err := yaml.Unmarshal(contents, &document); err != nil {
+			return fmt.Errorf("Parsing %s: %s", path, err)
+		}
+
+		jsonDocument, err := yamlToJSON(document)
+		if err != nil {
+			return fmt.Errorf("Parsing %s: %s", path, err)
+		}
+
+		if contents, err = json.Marshal(jsonDocument); err != nil {
+			return fmt.Errorf("Parsing %s: %s", path, err)
+		}
+	}
+
+	if err := json.Unmarshal(contents, v); err != nil {
+		return fmt.Errorf("Parsing %s: %s", path, err)
+	}
+
+	return nil
+}
+
+// yamlToJSON converts the map[interface{}]interface{} values returned by the YAML decoder into JSON objects
+func yamlToJSON(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		object := map[string]interface{}{}
+		for key, item := range value {
+			stringKey, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("Key '%v' is not a string", key)
+			}
+
+			jsonItem, err := yamlToJSON(item)
+			if err != nil {
+				return nil, err
+			}
+			object[stringKey] = jsonItem
+		}
+		return object, nil
+	case []interface{}:
+		array := make([]interface{}, len(value))
+		for i, item := range value {
+			jsonItem, err := yamlToJSON(item)
+			if err != nil {
+				return nil, err
+			}
+			array[i] = jsonItem
+		}
+		return array, nil
+	}
+
+	return value, nil
+}
+
+// loadCatalogPath adds the services of a catalog file, or of every catalog and plan file of a directory, to the catalog.
+// It returns the file each service or plan has been read from, by the config path of the service or plan.
+func loadCatalogPath(path string, catalog *sqsbroker.Catalog) (map[string]string, error) {
+	files := map[string]string{}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return files, err
+	}
+
+	if !info.IsDir() {
+		return files, addCatalogFile(path, catalog, files)
+	}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return files, err
+	}
+
+	var catalogFiles, planFiles []string
+	for _, entry := range entries {
+		filePath := filepath.Join(path, entry.Name())
+		if entry.IsDir() || !isConfigFile(filePath) {
+			continue
+		}
+
+		document := map[string]interface{}{}
+		if err := decodeFile(filePath, &document); err != nil {
+			return files, err
+		}
+
+		if _, ok := document["services"]; ok {
+			catalogFiles = append(catalogFiles, filePath)
+		} else {
+			planFiles = append(planFiles, filePath)
+		}
+	}
+	sort.Strings(catalogFiles)
+	sort.Strings(planFiles)
+
+	// Plans can only be added once all services are known
+	for _, filePath := range catalogFiles {
+		if err := addCatalogFile(filePath, catalog, files); err != nil {
+			return files, err
+		}
+	}
+
+	for _, filePath := range planFiles {
+		if err := addPlanFile(filePath, catalog, files); err != nil {
+			return files, err
+		}
+	}
+
+	return files, nil
+}
+
+func addCatalogFile(path string, catalog *sqsbroker.Catalog, files map[string]string) error {
+	fileCatalog := sqsbroker.Catalog{}
+	if err := decodeFile(path, &fileCatalog); err != nil {
+		return err
+	}
+
+	for _, service := range fileCatalog.Services {
+		files[fmt.Sprintf("%s.services[%d]", catalogPathKey, len(catalog.Services))] = path
+		catalog.Services = append(catalog.Services, service)
+	}
+
+	return nil
+}
+
+func addPlanFile(path string, catalog *sqsbroker.Catalog, files map[string]string) error {
+	planFile := catalogPlanFile{}
+	if err := decodeFile(path, &planFile); err != nil {
+		return err
+	}
+
+	for i, service := range catalog.Services {
+		if service.ID == planFile.ServiceID {
+			files[fmt.Sprintf("%s.services[%d].plans[%d]", catalogPathKey, i, len(service.Plans))] = path
+			catalog.Services[i].Plans = append(service.Plans, planFile.ServicePlan)
+			return nil
+		}
+	}
+
+	return &sqsbroker.ValidationError{
+		File: path,
+		Path: "service_id",
+		Err:  fmt.Errorf("Service '%s' not found", planFile.ServiceID),
+	}
+}
+
+// configErrorFile returns the file holding the key at path, the most specific catalog file wins over the config file
+func configErrorFile(path string, configFile string, catalogFiles map[string]string) string {
+	file := configFile
+	matched := ""
+	for catalogPath, catalogFile := range catalogFiles {
+		if len(catalogPath) > len(matched) && (path == catalogPath || strings.HasPrefix(path, catalogPath+".") || strings.HasPrefix(path, catalogPath+"[")) {
+			file = catalogFile
+			matched = catalogPath
+		}
+	}
+
+	return file
+}
@@ -1,6 +1,10 @@
 package main_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -24,6 +28,234 @@ var _ = Describe("Config", func() {
 		}
 	)
 
+	Describe("LoadConfig", func() {
+		var (
+			tmpDir     string
+			configFile string
+			envVars    = []string{
+				"SQS_BROKER_CONFIG",
+				"SQS_BROKER_PASSWORD",
+				"SQS_BROKER_PASSWORD_FILE",
+				"SQS_BROKER_SQS_CONFIG_REGION",
+				"SQS_BROKER_SQS_CONFIG_ALLOWED_REGIONS",
+				"SQS_BROKER_SQS_CONFIG_STORE_BINDING_SECRETS",
+				"SQS_BROKER_SQS_CONFIG_QUOTAS_MAX_BINDINGS_PER_INSTANCE",
+				"SQS_BROKER_SQS_CONFIG_CATALOG_PATH",
+			}
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "config")
+			Expect(err).ToNot(HaveOccurred())
+
+			configFile = filepath.Join(tmpDir, "config.json")
+			err = ioutil.WriteFile(configFile, []byte(`{
+				"log_level": "DEBUG",
+				"username": "broker-username",
+				"password": "broker-password",
+				"sqs_config": {"region": "sqs-region", "sqs_prefix": "cf"}
+			}`), 0600)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			for _, envVar := range envVars {
+				os.Unsetenv(envVar)
+			}
+			os.RemoveAll(tmpDir)
+		})
+
+		It("loads the config file", func() {
+			config, err := LoadConfig(configFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*config).To(Equal(validConfig))
+		})
+
+		It("returns error if there is no config file", func() {
+			_, err := LoadConfig("")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Must provide a config file"))
+		})
+
+		It("overrides fields from environment variables", func() {
+			os.Setenv("SQS_BROKER_PASSWORD", "env-password")
+			os.Setenv("SQS_BROKER_SQS_CONFIG_REGION", "env-region")
+			os.Setenv("SQS_BROKER_SQS_CONFIG_ALLOWED_REGIONS", "us-east-1, eu-west-1")
+			os.Setenv("SQS_BROKER_SQS_CONFIG_STORE_BINDING_SECRETS", "true")
+			os.Setenv("SQS_BROKER_SQS_CONFIG_QUOTAS_MAX_BINDINGS_PER_INSTANCE", "5")
+
+			config, err := LoadConfig(configFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.Password).To(Equal("env-password"))
+			Expect(config.SQSConfig.Region).To(Equal("env-region"))
+			Expect(config.SQSConfig.AllowedRegions).To(Equal([]string{"us-east-1", "eu-west-1"}))
+			Expect(config.SQSConfig.StoreBindingSecrets).To(BeTrue())
+			Expect(config.SQSConfig.Quotas.MaxBindingsPerInstance).To(Equal(5))
+			Expect(config.Username).To(Equal("broker-username"))
+		})
+
+		It("reads fields from the files named by _FILE environment variables", func() {
+			passwordFile := filepath.Join(tmpDir, "password")
+			err := ioutil.WriteFile(passwordFile, []byte("file-password\n"), 0600)
+			Expect(err).ToNot(HaveOccurred())
+			os.Setenv("SQS_BROKER_PASSWORD_FILE", passwordFile)
+
+			config, err := LoadConfig(configFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.Password).To(Equal("file-password"))
+		})
+
+		It("returns error if a _FILE environment variable names a missing file", func() {
+			os.Setenv("SQS_BROKER_PASSWORD_FILE", filepath.Join(tmpDir, "unknown"))
+
+			_, err := LoadConfig(configFile)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Reading environment variable SQS_BROKER_PASSWORD_FILE"))
+		})
+
+		It("returns error if an environment variable is not valid", func() {
+			os.Setenv("SQS_BROKER_SQS_CONFIG_STORE_BINDING_SECRETS", "maybe")
+
+			_, err := LoadConfig(configFile)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Parsing environment variable SQS_BROKER_SQS_CONFIG_STORE_BINDING_SECRETS"))
+		})
+
+		It("loads the whole config from an environment variable", func() {
+			os.Setenv("SQS_BROKER_CONFIG", `{
+				"log_level": "DEBUG",
+				"username": "broker-username",
+				"password": "broker-password",
+				"sqs_config": {"region": "sqs-region", "sqs_prefix": "cf"}
+			}`)
+
+			config, err := LoadConfig("")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*config).To(Equal(validConfig))
+		})
+
+		It("loads YAML config files", func() {
+			yamlConfigFile := filepath.Join(tmpDir, "config.yml")
+			err := ioutil.WriteFile(yamlConfigFile, []byte(`
+log_level: DEBUG
+username: broker-username
+password: broker-password
+sqs_config:
+  region: sqs-region
+  sqs_prefix: cf
+`), 0600)
+			Expect(err).ToNot(HaveOccurred())
+
+			config, err := LoadConfig(yamlConfigFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*config).To(Equal(validConfig))
+		})
+
+		It("returns error if the config file is not valid", func() {
+			err := ioutil.WriteFile(configFile, []byte(`{"log_level": 1}`), 0600)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = LoadConfig(configFile)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Parsing " + configFile))
+		})
+
+		Context("when the Catalog is in other files", func() {
+			var (
+				catalogPath string
+			)
+
+			BeforeEach(func() {
+				catalogPath = filepath.Join(tmpDir, "catalog")
+				err := os.Mkdir(catalogPath, 0700)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = ioutil.WriteFile(filepath.Join(catalogPath, "services.yml"), []byte(`
+services:
+- id: service-1
+  name: service-1
+  description: Service 1
+`), 0600)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = ioutil.WriteFile(filepath.Join(catalogPath, "plan-1.json"), []byte(`{
+					"service_id": "service-1",
+					"id": "plan-1",
+					"name": "plan-1",
+					"description": "Plan 1"
+				}`), 0600)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = ioutil.WriteFile(configFile, []byte(`{
+					"log_level": "DEBUG",
+					"username": "broker-username",
+					"password": "broker-password",
+					"sqs_config": {"region": "sqs-region", "sqs_prefix": "cf", "catalog_path": "catalog"}
+				}`), 0600)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("merges the catalog and plan files", func() {
+				config, err := LoadConfig(configFile)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.SQSConfig.Catalog).To(Equal(sqsbroker.Catalog{
+					Services: []sqsbroker.Service{
+						sqsbroker.Service{
+							ID:          "service-1",
+							Name:        "service-1",
+							Description: "Service 1",
+							Plans: []sqsbroker.ServicePlan{
+								sqsbroker.ServicePlan{ID: "plan-1", Name: "plan-1", Description: "Plan 1"},
+							},
+						},
+					},
+				}))
+			})
+
+			It("loads a single catalog file", func() {
+				os.Setenv("SQS_BROKER_SQS_CONFIG_CATALOG_PATH", filepath.Join(catalogPath, "services.yml"))
+
+				config, err := LoadConfig(configFile)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.SQSConfig.Catalog.Services).To(HaveLen(1))
+				Expect(config.SQSConfig.Catalog.Services[0].Plans).To(BeEmpty())
+			})
+
+			It("returns the file and path of invalid keys", func() {
+				planFile := filepath.Join(catalogPath, "plan-2.yml")
+				err := ioutil.WriteFile(planFile, []byte(`
+service_id: service-1
+id: plan-2
+name: plan-2
+`), 0600)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = LoadConfig(configFile)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Validating config contents: " + planFile + ": sqs_config.catalog.services[0].plans[1].description: Must provide a non-empty Description"))
+			})
+
+			It("returns error if a plan file refers to an unknown service", func() {
+				planFile := filepath.Join(catalogPath, "plan-2.yml")
+				err := ioutil.WriteFile(planFile, []byte("service_id: unknown\n"), 0600)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = LoadConfig(configFile)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(planFile + ": service_id: Service 'unknown' not found"))
+			})
+		})
+
+		It("validates the merged config", func() {
+			os.Setenv("SQS_BROKER_SQS_CONFIG_REGION", "")
+
+			_, err := LoadConfig(configFile)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty Region"))
+		})
+	})
+
 	Describe("Validate", func() {
 		BeforeEach(func() {
 			config = validConfig
@@ -42,6 +274,57 @@ var _ = Describe("Config", func() {
 			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty LogLevel"))
 		})
 
+		It("does not return error if the log options are valid", func() {
+			config.LogFormat = "console"
+			config.LogFile = "/var/log/sqs-broker.log"
+			config.LogFileMaxSizeMB = 10
+			config.LogFileMaxBackups = 3
+			config.SyslogAddress = "udp://syslog.example.com:514"
+
+			err := config.Validate()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns error if LogFormat is not valid", func() {
+			config.LogFormat = "text"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("log_format: Invalid LogFormat 'text', must be 'json' or 'console'"))
+		})
+
+		It("returns error if PermissionsCheck is not valid", func() {
+			config.PermissionsCheck = "strict"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("permissions_check: Invalid PermissionsCheck 'strict', must be 'warn', 'enforce' or 'off'"))
+		})
+
+		It("returns error if LogFileMaxSizeMB is not valid", func() {
+			config.LogFileMaxSizeMB = -1
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("log_file_max_size_mb: Must provide a non-negative LogFileMaxSizeMB"))
+		})
+
+		It("returns error if SyslogAddress is not valid", func() {
+			config.SyslogAddress = "syslog.example.com:514"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("syslog_address: Invalid syslog address 'syslog.example.com:514', must be 'local' or a udp:// or tcp:// URL"))
+		})
+
+		It("returns error if AuditLogKey is missing", func() {
+			config.AuditLogFile = "audit.log"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty AuditLogKey when AuditLogFile is set"))
+		})
+
 		It("returns error if Username is not valid", func() {
 			config.Username = ""
 
@@ -58,12 +341,45 @@ var _ = Describe("Config", func() {
 			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty Password"))
 		})
 
+		It("returns error if AdminPassword is missing", func() {
+			config.AdminUsername = "admin-username"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty AdminPassword along with the AdminUsername"))
+		})
+
+		It("returns error if AdminUsername is missing", func() {
+			config.AdminPassword = "admin-password"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty AdminUsername along with the AdminPassword"))
+		})
+
+		It("returns error if AdminUsername is the broker Username", func() {
+			config.AdminUsername = config.Username
+			config.AdminPassword = "admin-password"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide an AdminUsername different from the Username"))
+		})
+
+		It("does not return error if the admin credentials are valid", func() {
+			config.AdminUsername = "admin-username"
+			config.AdminPassword = "admin-password"
+
+			err := config.Validate()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
 		It("returns error if SQS configuration is not valid", func() {
 			config.SQSConfig = sqsbroker.Config{}
 
 			err := config.Validate()
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(ContainSubstring("Validating SQS configuration"))
+			Expect(err.Error()).To(Equal("sqs_config.region: Must provide a non-empty Region"))
 		})
 	})
 })
@@ -0,0 +1,514 @@
+package main_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+
+	. "github.com/cf-platform-eng/sqs-broker"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cf-platform-eng/sqs-broker/awsemulator"
+	"github.com/cf-platform-eng/sqs-broker/sqsbroker"
+)
+
+// conformanceCase is a request to the broker and the response mandated by the Open Service Broker API.
+// A case with a deviation documents a known difference with the spec and is run as a pending spec.
+type conformanceCase struct {
+	description  string
+	given        []string
+	method       string
+	path         string
+	body         string
+	noAuth       bool
+	apiVersion   string
+	noAPIVersion bool
+	status       int
+	responseBody types.GomegaMatcher
+	deviation    string
+}
+
+const (
+	givenInstance = "instance"
+	givenBinding  = "binding"
+)
+
+const (
+	provisionBody       = `{"service_id":"service-id","plan_id":"plan-id","organization_guid":"organization-id","space_guid":"space-id"}`
+	bindBody            = `{"service_id":"service-id","plan_id":"plan-id","app_guid":"app-id"}`
+	instancePath        = "/v2/service_instances/instance-id"
+	bindingPath         = "/v2/service_instances/instance-id/service_bindings/binding-id"
+	deprovisionQuery    = "?service_id=service-id&plan_id=plan-id"
+	supportedAPIVersion = "2.13"
+)
+
+// allOfMatcher succeeds when all its matchers succeed, the vendored gomega does not provide SatisfyAll
+type allOfMatcher struct {
+	matchers []types.GomegaMatcher
+	failed   types.GomegaMatcher
+}
+
+func allOf(matchers ...types.GomegaMatcher) types.GomegaMatcher {
+	return &allOfMatcher{matchers: matchers}
+}
+
+func (m *allOfMatcher) Match(actual interface{}) (bool, error) {
+	for _, matcher := range m.matchers {
+		success, err := matcher.Match(actual)
+		if !success || err != nil {
+			m.failed = matcher
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (m *allOfMatcher) FailureMessage(actual interface{}) string {
+	return m.failed.FailureMessage(actual)
+}
+
+func (m *allOfMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected\n\t%#v\nnot to match all of the matchers", actual)
+}
+
+var conformanceCases = []conformanceCase{
+	// Catalog
+	{
+		description:  "fetches the catalog",
+		method:       "GET",
+		path:         "/v2/catalog",
+		status:       http.StatusOK,
+		responseBody: HaveKey("services"),
+	},
+	{
+		description: "rejects requests without credentials",
+		method:      "GET",
+		path:        "/v2/catalog",
+		noAuth:      true,
+		status:      http.StatusUnauthorized,
+	},
+	{
+		description:  "rejects requests without an API version",
+		method:       "GET",
+		path:         "/v2/catalog",
+		noAPIVersion: true,
+		status:       http.StatusPreconditionFailed,
+		deviation:    "the X-Broker-API-Version header is not checked",
+	},
+	{
+		description: "rejects requests with an unsupported API version",
+		method:      "GET",
+		path:        "/v2/catalog",
+		apiVersion:  "1.0",
+		status:      http.StatusPreconditionFailed,
+		deviation:   "the X-Broker-API-Version header is not checked",
+	},
+
+	// Provision
+	{
+		description:  "provisions an instance",
+		method:       "PUT",
+		path:         instancePath,
+		body:         provisionBody,
+		status:       http.StatusCreated,
+		responseBody: BeEmpty(),
+	},
+	{
+		description:  "rejects a malformed provision request",
+		method:       "PUT",
+		path:         instancePath,
+		body:         `{`,
+		status:       http.StatusBadRequest,
+		responseBody: HaveKey("description"),
+	},
+	{
+		description:  "rejects a provision request for an unknown plan",
+		method:       "PUT",
+		path:         instancePath,
+		body:         `{"service_id":"service-id","plan_id":"unknown","organization_guid":"organization-id","space_guid":"space-id"}`,
+		status:       http.StatusBadRequest,
+		responseBody: HaveKey("description"),
+		deviation:    "returns 500",
+	},
+	{
+		description:  "rejects a provision request for an unknown service",
+		method:       "PUT",
+		path:         instancePath,
+		body:         `{"service_id":"unknown","plan_id":"plan-id","organization_guid":"organization-id","space_guid":"space-id"}`,
+		status:       http.StatusBadRequest,
+		responseBody: HaveKey("description"),
+		deviation:    "the service_id is not checked against the plan and the instance is provisioned",
+	},
+	{
+		description:  "rejects a provision request without organization and space",
+		method:       "PUT",
+		path:         instancePath,
+		body:         `{"service_id":"service-id","plan_id":"plan-id"}`,
+		status:       http.StatusBadRequest,
+		responseBody: HaveKey("description"),
+		deviation:    "organization_guid and space_guid are not required and the instance is provisioned",
+	},
+	{
+		description:  "accepts a provision request identical to an existing instance",
+		given:        []string{givenInstance},
+		method:       "PUT",
+		path:         instancePath,
+		body:         provisionBody,
+		status:       http.StatusOK,
+		responseBody: BeEmpty(),
+		deviation:    "returns 201",
+	},
+	{
+		description:  "rejects a provision request conflicting with an existing instance",
+		given:        []string{givenInstance},
+		method:       "PUT",
+		path:         instancePath,
+		body:         `{"service_id":"service-id","plan_id":"other-plan-id","organization_guid":"organization-id","space_guid":"space-id"}`,
+		status:       http.StatusConflict,
+		responseBody: BeEmpty(),
+		deviation:    "the existing instance is overwritten and 201 is returned",
+	},
+
+	// Fetch Instance
+	{
+		description:  "fetches an instance",
+		given:        []string{givenInstance},
+		method:       "GET",
+		path:         instancePath,
+		status:       http.StatusOK,
+		responseBody: allOf(HaveKeyWithValue("service_id", "service-id"), HaveKeyWithValue("plan_id", "plan-id")),
+	},
+	{
+		description:  "does not find an unknown instance",
+		method:       "GET",
+		path:         instancePath,
+		status:       http.StatusNotFound,
+		responseBody: BeEmpty(),
+	},
+
+	// Last Operation
+	{
+		description:  "returns the last operation of an instance",
+		given:        []string{givenInstance},
+		method:       "GET",
+		path:         instancePath + "/last_operation",
+		status:       http.StatusOK,
+		responseBody: HaveKeyWithValue("state", "succeeded"),
+	},
+	{
+		description:  "returns gone for the last operation of an unknown instance",
+		method:       "GET",
+		path:         instancePath + "/last_operation",
+		status:       http.StatusGone,
+		responseBody: BeEmpty(),
+		deviation:    "returns 500",
+	},
+
+	// Update
+	{
+		description:  "updates an instance",
+		given:        []string{givenInstance},
+		method:       "PATCH",
+		path:         instancePath,
+		body:         `{"service_id":"service-id","plan_id":"other-plan-id"}`,
+		status:       http.StatusOK,
+		responseBody: BeEmpty(),
+	},
+	{
+		description:  "rejects a malformed update request",
+		given:        []string{givenInstance},
+		method:       "PATCH",
+		path:         instancePath,
+		body:         `{`,
+		status:       http.StatusBadRequest,
+		responseBody: HaveKey("description"),
+	},
+	{
+		description:  "rejects an update request for an unknown plan",
+		given:        []string{givenInstance},
+		method:       "PATCH",
+		path:         instancePath,
+		body:         `{"service_id":"service-id","plan_id":"unknown"}`,
+		status:       http.StatusBadRequest,
+		responseBody: HaveKey("description"),
+		deviation:    "returns 500",
+	},
+	{
+		description:  "does not find an unknown instance to update",
+		method:       "PATCH",
+		path:         instancePath,
+		body:         `{"service_id":"service-id","plan_id":"other-plan-id"}`,
+		status:       http.StatusNotFound,
+		responseBody: HaveKey("description"),
+	},
+
+	// Bind
+	{
+		description:  "binds an instance",
+		given:        []string{givenInstance},
+		method:       "PUT",
+		path:         bindingPath,
+		body:         bindBody,
+		status:       http.StatusCreated,
+		responseBody: HaveKey("credentials"),
+	},
+	{
+		description:  "rejects a malformed bind request",
+		given:        []string{givenInstance},
+		method:       "PUT",
+		path:         bindingPath,
+		body:         `{`,
+		status:       http.StatusBadRequest,
+		responseBody: HaveKey("description"),
+	},
+	{
+		description:  "accepts a bind request identical to an existing binding",
+		given:        []string{givenInstance, givenBinding},
+		method:       "PUT",
+		path:         bindingPath,
+		body:         bindBody,
+		status:       http.StatusOK,
+		responseBody: HaveKey("credentials"),
+		deviation:    "creating the binding IAM user again fails and 500 is returned",
+	},
+	{
+		description:  "rejects a bind request conflicting with an existing binding",
+		given:        []string{givenInstance, givenBinding},
+		method:       "PUT",
+		path:         bindingPath,
+		body:         `{"service_id":"service-id","plan_id":"plan-id","app_guid":"other-app-id"}`,
+		status:       http.StatusConflict,
+		responseBody: BeEmpty(),
+		deviation:    "creating the binding IAM user again fails and 500 is returned",
+	},
+	{
+		description:  "does not find an unknown instance to bind",
+		method:       "PUT",
+		path:         bindingPath,
+		body:         bindBody,
+		status:       http.StatusNotFound,
+		responseBody: HaveKey("description"),
+	},
+
+	// Fetch Binding
+	{
+		description:  "fetches a binding",
+		given:        []string{givenInstance, givenBinding},
+		method:       "GET",
+		path:         bindingPath,
+		status:       http.StatusOK,
+		responseBody: HaveKey("credentials"),
+	},
+	{
+		description:  "does not find an unknown binding",
+		given:        []string{givenInstance},
+		method:       "GET",
+		path:         bindingPath,
+		status:       http.StatusNotFound,
+		responseBody: BeEmpty(),
+	},
+	{
+		description:  "does not find a binding of an unknown instance",
+		method:       "GET",
+		path:         bindingPath,
+		status:       http.StatusNotFound,
+		responseBody: BeEmpty(),
+	},
+	{
+		description:  "returns the last operation of a binding",
+		given:        []string{givenInstance, givenBinding},
+		method:       "GET",
+		path:         bindingPath + "/last_operation",
+		status:       http.StatusOK,
+		responseBody: HaveKeyWithValue("state", "succeeded"),
+	},
+	{
+		description:  "returns gone for the last operation of an unknown binding",
+		given:        []string{givenInstance},
+		method:       "GET",
+		path:         bindingPath + "/last_operation",
+		status:       http.StatusGone,
+		responseBody: BeEmpty(),
+	},
+
+	// Unbind
+	{
+		description:  "unbinds an instance",
+		given:        []string{givenInstance, givenBinding},
+		method:       "DELETE",
+		path:         bindingPath + deprovisionQuery,
+		status:       http.StatusOK,
+		responseBody: BeEmpty(),
+	},
+	{
+		description:  "returns gone when unbinding an unknown binding",
+		given:        []string{givenInstance},
+		method:       "DELETE",
+		path:         bindingPath + deprovisionQuery,
+		status:       http.StatusGone,
+		responseBody: BeEmpty(),
+		deviation:    "the missing binding IAM user is reported and 500 is returned",
+	},
+	{
+		description:  "returns gone when unbinding from an unknown instance",
+		method:       "DELETE",
+		path:         bindingPath + deprovisionQuery,
+		status:       http.StatusGone,
+		responseBody: BeEmpty(),
+		deviation:    "the missing binding IAM user is reported and 500 is returned",
+	},
+	{
+		description:  "rejects an unbind request without service and plan",
+		given:        []string{givenInstance, givenBinding},
+		method:       "DELETE",
+		path:         bindingPath,
+		status:       http.StatusBadRequest,
+		responseBody: HaveKey("description"),
+		deviation:    "service_id and plan_id are not required and the binding is deleted",
+	},
+
+	// Deprovision
+	{
+		description:  "deprovisions an instance",
+		given:        []string{givenInstance},
+		method:       "DELETE",
+		path:         instancePath + deprovisionQuery,
+		status:       http.StatusOK,
+		responseBody: BeEmpty(),
+	},
+	{
+		description:  "returns gone when deprovisioning an unknown instance",
+		method:       "DELETE",
+		path:         instancePath + deprovisionQuery,
+		status:       http.StatusGone,
+		responseBody: BeEmpty(),
+	},
+	{
+		description:  "rejects a deprovision request without service and plan",
+		given:        []string{givenInstance},
+		method:       "DELETE",
+		path:         instancePath,
+		status:       http.StatusBadRequest,
+		responseBody: HaveKey("description"),
+		deviation:    "service_id and plan_id are not required and the instance is deleted",
+	},
+}
+
+var _ = Describe("Open Service Broker API conformance", func() {
+	var (
+		emulatorServer *httptest.Server
+		brokerAPI      http.Handler
+	)
+
+	doRequest := func(c conformanceCase) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(c.method, c.path, strings.NewReader(c.body))
+		Expect(err).ToNot(HaveOccurred())
+		if !c.noAuth {
+			request.SetBasicAuth("broker-username", "broker-password")
+		}
+		if !c.noAPIVersion {
+			apiVersion := c.apiVersion
+			if apiVersion == "" {
+				apiVersion = supportedAPIVersion
+			}
+			request.Header.Set("X-Broker-API-Version", apiVersion)
+		}
+		request.Header.Set("Content-Type", "application/json")
+
+		recorder := httptest.NewRecorder()
+		brokerAPI.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	givenRequests := map[string]conformanceCase{
+		givenInstance: {method: "PUT", path: instancePath, body: provisionBody, status: http.StatusCreated},
+		givenBinding:  {method: "PUT", path: bindingPath, body: bindBody, status: http.StatusCreated},
+	}
+
+	BeforeEach(func() {
+		os.Setenv("AWS_ACCESS_KEY_ID", "access-key-id")
+		os.Setenv("AWS_SECRET_ACCESS_KEY", "secret-access-key")
+
+		emulatorServer = httptest.NewServer(awsemulator.New(awsemulator.DefaultRegion, awsemulator.DefaultAccountID))
+
+		config := &Config{
+			Username: "broker-username",
+			Password: "broker-password",
+			SQSConfig: sqsbroker.Config{
+				Region:      awsemulator.DefaultRegion,
+				SQSEndpoint: emulatorServer.URL,
+				IAMEndpoint: emulatorServer.URL,
+				SQSPrefix:   "cf",
+				// Fetching a Binding returns the credentials only when their secret is stored
+				StoreBindingSecrets: true,
+				Catalog: sqsbroker.Catalog{
+					Services: []sqsbroker.Service{
+						{
+							ID:             "service-id",
+							Name:           "sqs",
+							Description:    "SQS queues",
+							Bindable:       true,
+							PlanUpdateable: true,
+							Plans: []sqsbroker.ServicePlan{
+								{ID: "plan-id", Name: "standard", Description: "Standard queue"},
+								{ID: "other-plan-id", Name: "other", Description: "Other queue"},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		logger := lager.NewLogger("conformance_test")
+		logger.RegisterSink(lagertest.NewTestSink())
+
+		var err error
+		_, brokerAPI, err = NewBroker(config, logger)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		emulatorServer.Close()
+		os.Unsetenv("AWS_ACCESS_KEY_ID")
+		os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+	})
+
+	for _, c := range conformanceCases {
+		c := c
+		description := fmt.Sprintf("%s: %s %s returns %d", c.description, c.method, c.path, c.status)
+
+		spec := func() {
+			for _, given := range c.given {
+				recorder := doRequest(givenRequests[given])
+				Expect(recorder.Code).To(Equal(givenRequests[given].status), recorder.Body.String())
+			}
+
+			recorder := doRequest(c)
+			Expect(recorder.Code).To(Equal(c.status), recorder.Body.String())
+
+			if c.responseBody != nil {
+				Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("application/json"))
+
+				var responseBody map[string]interface{}
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &responseBody)).To(Succeed())
+				Expect(responseBody).To(c.responseBody)
+			}
+		}
+
+		if c.deviation != "" {
+			PIt(description+" (deviation: "+c.deviation+")", spec)
+		} else {
+			It(description, spec)
+		}
+	}
+})
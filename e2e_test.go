@@ -0,0 +1,192 @@
+package main_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/sqs-broker"
+
+	"github.com/pivotal-golang/lager/lagertest"
+
+	"github.com/cf-platform-eng/sqs-broker/awsemulator"
+	"github.com/cf-platform-eng/sqs-broker/brokerlog"
+	"github.com/cf-platform-eng/sqs-broker/sqsbroker"
+)
+
+var _ = Describe("End to end", func() {
+	var (
+		emulator       *awsemulator.Emulator
+		emulatorServer *httptest.Server
+		brokerServer   *httptest.Server
+		testSink       *lagertest.TestSink
+	)
+
+	doRequest := func(method string, path string, body string) (int, map[string]interface{}) {
+		request, err := http.NewRequest(method, brokerServer.URL+path, strings.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+		request.SetBasicAuth("broker-username", "broker-password")
+		request.Header.Set("X-Broker-API-Version", "2.13")
+		request.Header.Set("Content-Type", "application/json")
+
+		response, err := http.DefaultClient.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+		defer response.Body.Close()
+
+		responseBody := map[string]interface{}{}
+		Expect(json.NewDecoder(response.Body).Decode(&responseBody)).To(Succeed())
+
+		return response.StatusCode, responseBody
+	}
+
+	BeforeEach(func() {
+		os.Setenv("AWS_ACCESS_KEY_ID", "access-key-id")
+		os.Setenv("AWS_SECRET_ACCESS_KEY", "secret-access-key")
+
+		emulator = awsemulator.New(awsemulator.DefaultRegion, awsemulator.DefaultAccountID)
+		emulatorServer = httptest.NewServer(emulator)
+
+		config := &Config{
+			LogLevel: "DEBUG",
+			Username: "broker-username",
+			Password: "broker-password",
+			SQSConfig: sqsbroker.Config{
+				Region:                    awsemulator.DefaultRegion,
+				SQSEndpoint:               emulatorServer.URL,
+				SNSEndpoint:               emulatorServer.URL,
+				IAMEndpoint:               emulatorServer.URL,
+				SQSPrefix:                 "cf",
+				AllowUserUpdateParameters: true,
+				Catalog: sqsbroker.Catalog{
+					Services: []sqsbroker.Service{
+						{
+							ID:             "service-id",
+							Name:           "sqs",
+							Description:    "SQS queues",
+							Bindable:       true,
+							PlanUpdateable: true,
+							Plans: []sqsbroker.ServicePlan{
+								{
+									ID:          "plan-id",
+									Name:        "standard",
+									Description: "Standard queue",
+									SQSProperties: sqsbroker.SQSProperties{
+										VisibilityTimeout: "60",
+									},
+								},
+							},
+						},
+						{
+							ID:          "topic-service-id",
+							Name:        "sns",
+							Description: "SNS topics",
+							Backend:     sqsbroker.SNSServiceBackend,
+							Plans: []sqsbroker.ServicePlan{
+								{
+									ID:          "topic-plan-id",
+									Name:        "standard",
+									Description: "Standard topic",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		Expect(config.Validate()).To(Succeed())
+
+		testSink = lagertest.NewTestSink()
+		logger := brokerlog.NewLogger("e2e_test", testSink)
+
+		_, brokerAPI, err := NewBroker(config, logger)
+		Expect(err).ToNot(HaveOccurred())
+		brokerServer = httptest.NewServer(brokerAPI)
+	})
+
+	AfterEach(func() {
+		brokerServer.Close()
+		emulatorServer.Close()
+		os.Unsetenv("AWS_ACCESS_KEY_ID")
+		os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+	})
+
+	It("provisions, binds, unbinds and deprovisions a queue", func() {
+		statusCode, catalog := doRequest("GET", "/v2/catalog", "")
+		Expect(statusCode).To(Equal(http.StatusOK))
+		Expect(catalog["services"]).To(HaveLen(2))
+
+		statusCode, _ = doRequest("PUT", "/v2/service_instances/instance-id", `{"service_id":"service-id","plan_id":"plan-id","organization_guid":"organization-id","space_guid":"space-id"}`)
+		Expect(statusCode).To(Equal(http.StatusCreated))
+
+		queueName := "cf-instance-id"
+		Expect(emulator.QueueNames()).To(Equal([]string{queueName}))
+		attributes, _ := emulator.QueueAttributes(queueName)
+		Expect(attributes["VisibilityTimeout"]).To(Equal("60"))
+
+		statusCode, binding := doRequest("PUT", "/v2/service_instances/instance-id/service_bindings/binding-id", `{"service_id":"service-id","plan_id":"plan-id","app_guid":"app-id"}`)
+		Expect(statusCode).To(Equal(http.StatusCreated))
+		Expect(binding["credentials"]).To(HaveKeyWithValue("uri", emulatorServer.URL+"/"+awsemulator.DefaultAccountID+"/"+queueName))
+
+		Expect(emulator.UserNames()).To(Equal([]string{"cf-binding-id"}))
+		Expect(emulator.UserPolicyDocuments("cf-binding-id")).To(HaveLen(1))
+
+		statusCode, _ = doRequest("DELETE", "/v2/service_instances/instance-id/service_bindings/binding-id?service_id=service-id&plan_id=plan-id", "")
+		Expect(statusCode).To(Equal(http.StatusOK))
+		Expect(emulator.UserNames()).To(BeEmpty())
+		Expect(emulator.PolicyNames()).To(BeEmpty())
+
+		statusCode, _ = doRequest("DELETE", "/v2/service_instances/instance-id?service_id=service-id&plan_id=plan-id", "")
+		Expect(statusCode).To(Equal(http.StatusOK))
+		Expect(emulator.QueueNames()).To(BeEmpty())
+	})
+
+	It("subscribes a queue to a topic and deletes the subscription with the topic", func() {
+		statusCode, _ := doRequest("PUT", "/v2/service_instances/topic-instance-id", `{"service_id":"topic-service-id","plan_id":"topic-plan-id","organization_guid":"organization-id","space_guid":"space-id"}`)
+		Expect(statusCode).To(Equal(http.StatusCreated))
+		Expect(emulator.TopicNames()).To(Equal([]string{"cf-topic-instance-id"}))
+
+		statusCode, _ = doRequest("PUT", "/v2/service_instances/instance-id", `{"service_id":"service-id","plan_id":"plan-id","organization_guid":"organization-id","space_guid":"space-id"}`)
+		Expect(statusCode).To(Equal(http.StatusCreated))
+
+		statusCode, _ = doRequest("PATCH", "/v2/service_instances/instance-id", `{"service_id":"service-id","plan_id":"plan-id","parameters":{"subscribe_to_topic":"topic-instance-id"},"previous_values":{"service_id":"service-id","plan_id":"plan-id"}}`)
+		Expect(statusCode).To(Equal(http.StatusOK))
+
+		queueArn := "arn:aws:sqs:" + awsemulator.DefaultRegion + ":" + awsemulator.DefaultAccountID + ":cf-instance-id"
+		Expect(emulator.TopicSubscriptionEndpoints("cf-topic-instance-id")).To(Equal([]string{queueArn}))
+		attributes, _ := emulator.QueueAttributes("cf-instance-id")
+		Expect(attributes["Policy"]).To(ContainSubstring("arn:aws:sns:" + awsemulator.DefaultRegion + ":" + awsemulator.DefaultAccountID + ":cf-topic-instance-id"))
+
+		statusCode, _ = doRequest("PATCH", "/v2/service_instances/instance-id", `{"service_id":"service-id","plan_id":"plan-id","parameters":{"unsubscribe_from_topic":"topic-instance-id"},"previous_values":{"service_id":"service-id","plan_id":"plan-id"}}`)
+		Expect(statusCode).To(Equal(http.StatusOK))
+		Expect(emulator.TopicSubscriptionEndpoints("cf-topic-instance-id")).To(BeEmpty())
+
+		statusCode, _ = doRequest("PATCH", "/v2/service_instances/instance-id", `{"service_id":"service-id","plan_id":"plan-id","parameters":{"subscribe_to_topic":"topic-instance-id"},"previous_values":{"service_id":"service-id","plan_id":"plan-id"}}`)
+		Expect(statusCode).To(Equal(http.StatusOK))
+
+		statusCode, _ = doRequest("DELETE", "/v2/service_instances/topic-instance-id?service_id=topic-service-id&plan_id=topic-plan-id", "")
+		Expect(statusCode).To(Equal(http.StatusOK))
+		Expect(emulator.TopicNames()).To(BeEmpty())
+		attributes, _ = emulator.QueueAttributes("cf-instance-id")
+		Expect(attributes["Policy"]).ToNot(ContainSubstring("cf-topic-instance-id"))
+	})
+
+	It("does not log the secrets at debug level", func() {
+		statusCode, _ := doRequest("PUT", "/v2/service_instances/instance-id", `{"service_id":"service-id","plan_id":"plan-id","organization_guid":"organization-id","space_guid":"space-id"}`)
+		Expect(statusCode).To(Equal(http.StatusCreated))
+
+		statusCode, binding := doRequest("PUT", "/v2/service_instances/instance-id/service_bindings/binding-id", `{"service_id":"service-id","plan_id":"plan-id","app_guid":"app-id"}`)
+		Expect(statusCode).To(Equal(http.StatusCreated))
+		secretAccessKey := binding["credentials"].(map[string]interface{})["password"].(string)
+		Expect(secretAccessKey).ToNot(BeEmpty())
+
+		logs := string(testSink.Buffer().Contents())
+		Expect(logs).To(ContainSubstring("create-access-key"))
+		Expect(logs).ToNot(ContainSubstring(secretAccessKey))
+		Expect(logs).ToNot(ContainSubstring("broker-password"))
+	})
+})
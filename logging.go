@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/sqs-broker/brokerlog"
+)
+
+const (
+	logFormatJSON    = "json"
+	logFormatConsole = "console"
+
+	syslogLocalAddress = "local"
+	syslogTag          = "sqs-broker"
+
+	defaultLogFileMaxSizeMB  = 100
+	defaultLogFileMaxBackups = 5
+)
+
+// NewLogger builds the broker logger, writing to stdout and to the configured log file and syslog server.
+// The returned sink sets the log level of all of them at runtime.
+func NewLogger(config *Config) (lager.Logger, *lager.ReconfigurableSink, error) {
+	logLevel, ok := logLevels[strings.ToUpper(config.LogLevel)]
+	if !ok {
+		return nil, nil, fmt.Errorf("Invalid log level: %s", config.LogLevel)
+	}
+
+	sinks := []lager.Sink{lager.NewWriterSink(os.Stdout, lager.DEBUG)}
+
+	if config.LogFile != "" {
+		maxSizeMB := config.LogFileMaxSizeMB
+		if maxSizeMB == 0 {
+			maxSizeMB = defaultLogFileMaxSizeMB
+		}
+		maxBackups := config.LogFileMaxBackups
+		if maxBackups == 0 {
+			maxBackups = defaultLogFileMaxBackups
+		}
+
+		fileSink, err := brokerlog.NewFileSink(config.LogFile, int64(maxSizeMB)*1024*1024, maxBackups)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Opening log file: %s", err)
+		}
+		sinks = append(sinks, fileSink)
+	}
+
+	if config.SyslogAddress != "" {
+		network, address, err := parseSyslogAddress(config.SyslogAddress)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		syslogSink, err := brokerlog.NewSyslogSink(network, address, syslogTag)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Connecting to syslog: %s", err)
+		}
+		sinks = append(sinks, syslogSink)
+	}
+
+	var sink lager.Sink = brokerlog.NewMultiSink(sinks...)
+	if config.LogFormat == logFormatConsole {
+		sink = brokerlog.NewConsoleSink(sink)
+	}
+	levelSink := lager.NewReconfigurableSink(sink, logLevel)
+
+	return brokerlog.NewLogger("sqs-broker", levelSink), levelSink, nil
+}
+
+// parseSyslogAddress accepts "local" for the local syslog daemon, or a udp://<host>:<port> or tcp://<host>:<port> URL
+func parseSyslogAddress(syslogAddress string) (string, string, error) {
+	if syslogAddress == syslogLocalAddress {
+		return "", "", nil
+	}
+
+	parsedURL, err := url.Parse(syslogAddress)
+	if err != nil || (parsedURL.Scheme != "udp" && parsedURL.Scheme != "tcp") || parsedURL.Host == "" {
+		return "", "", fmt.Errorf("Invalid syslog address '%s', must be '%s' or a udp:// or tcp:// URL", syslogAddress, syslogLocalAddress)
+	}
+
+	return parsedURL.Scheme, parsedURL.Host, nil
+}
+
+// changeLogLevelOnSignal logs at DEBUG level on SIGUSR1, and restores the configured level on SIGUSR2
+func changeLogLevelOnSignal(levelSink *lager.ReconfigurableSink, logger lager.Logger) {
+	configuredLevel := levelSink.GetMinLevel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for sig := range signals {
+			level := configuredLevel
+			if sig == syscall.SIGUSR1 {
+				level = lager.DEBUG
+			}
+
+			levelSink.SetMinLevel(level)
+			logger.Info("log-level", lager.Data{"level": logLevelNames[level]})
+		}
+	}()
+}
@@ -0,0 +1,101 @@
+package main_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/sqs-broker"
+
+	"github.com/pivotal-golang/lager"
+)
+
+var _ = Describe("NewLogger", func() {
+	var (
+		tmpDir  string
+		logFile string
+		config  *Config
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "logger")
+		Expect(err).ToNot(HaveOccurred())
+		logFile = filepath.Join(tmpDir, "broker.log")
+
+		config = &Config{
+			LogLevel: "INFO",
+			LogFile:  logFile,
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	readLogFile := func() string {
+		contents, err := ioutil.ReadFile(logFile)
+		Expect(err).ToNot(HaveOccurred())
+		return string(contents)
+	}
+
+	It("writes JSON logs to the log file", func() {
+		logger, _, err := NewLogger(config)
+		Expect(err).ToNot(HaveOccurred())
+
+		logger.Info("provision", lager.Data{"instance-id": "instance-id"})
+
+		Expect(readLogFile()).To(MatchRegexp(`^\{"timestamp":"[0-9.]+","source":"sqs-broker","message":"sqs-broker.provision","log_level":1,"data":\{"instance-id":"instance-id"\}\}\n$`))
+	})
+
+	It("writes human readable logs to the log file", func() {
+		config.LogFormat = "console"
+
+		logger, _, err := NewLogger(config)
+		Expect(err).ToNot(HaveOccurred())
+
+		logger.Info("provision", lager.Data{"instance-id": "instance-id"})
+
+		Expect(readLogFile()).To(MatchRegexp(`Z INFO  sqs-broker.provision instance-id=instance-id\n$`))
+	})
+
+	It("changes the log level at runtime", func() {
+		logger, levelSink, err := NewLogger(config)
+		Expect(err).ToNot(HaveOccurred())
+
+		logger.Debug("hidden")
+		levelSink.SetMinLevel(lager.DEBUG)
+		logger.Debug("shown")
+
+		Expect(readLogFile()).ToNot(ContainSubstring("hidden"))
+		Expect(readLogFile()).To(ContainSubstring("shown"))
+	})
+
+	It("masks the secrets", func() {
+		logger, _, err := NewLogger(config)
+		Expect(err).ToNot(HaveOccurred())
+
+		logger.Info("config", lager.Data{"password": "broker-password"})
+
+		Expect(readLogFile()).ToNot(ContainSubstring("broker-password"))
+	})
+
+	It("returns error if the log level is not valid", func() {
+		config.LogLevel = "TRACE"
+
+		_, _, err := NewLogger(config)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Invalid log level: TRACE"))
+	})
+
+	It("returns error if the log file can not be opened", func() {
+		config.LogFile = filepath.Join(tmpDir, "unknown", "broker.log")
+
+		_, _, err := NewLogger(config)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("Opening log file: "))
+	})
+})
@@ -1,28 +1,43 @@
 package main
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
-	"strings"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sns"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/frodenas/brokerapi"
 	"github.com/pivotal-golang/lager"
 
+	"github.com/cf-platform-eng/sqs-broker/auditlog"
 	"github.com/cf-platform-eng/sqs-broker/awsiam"
+	"github.com/cf-platform-eng/sqs-broker/awssns"
 	"github.com/cf-platform-eng/sqs-broker/awssqs"
+	"github.com/cf-platform-eng/sqs-broker/brokerhttp"
+	"github.com/cf-platform-eng/sqs-broker/brokerstore"
 	"github.com/cf-platform-eng/sqs-broker/sqsbroker"
 )
 
+const roleSessionName = "sqs-broker"
+const roleExpiryWindow = time.Minute
+
 var (
 	configFilePath string
-	port           string
 
 	logLevels = map[string]lager.LogLevel{
 		"DEBUG": lager.DEBUG,
@@ -30,54 +45,178 @@ var (
 		"ERROR": lager.ERROR,
 		"FATAL": lager.FATAL,
 	}
+	logLevelNames = map[lager.LogLevel]string{
+		lager.DEBUG: "DEBUG",
+		lager.INFO:  "INFO",
+		lager.ERROR: "ERROR",
+		lager.FATAL: "FATAL",
+	}
 )
 
-func init() {
-	flag.StringVar(&configFilePath, "config", "", "Location of the config file")
-	flag.StringVar(&port, "port", "3000", "Listen port")
+func buildStore(stateFile string) (brokerstore.Store, error) {
+	if stateFile == "" {
+		return brokerstore.NewMemoryStore(), nil
+	}
+
+	return brokerstore.NewFileStore(stateFile)
 }
 
-func buildLogger(logLevel string) lager.Logger {
-	laggerLogLevel, ok := logLevels[strings.ToUpper(logLevel)]
-	if !ok {
-		log.Fatal("Invalid log level: ", logLevel)
+func buildAuditLogger(config *Config, logger lager.Logger) (auditlog.Logger, error) {
+	if config.AuditLogFile == "" {
+		return auditlog.Discard, nil
 	}
 
-	logger := lager.NewLogger("sqs-broker")
-	logger.RegisterSink(lager.NewWriterSink(os.Stdout, laggerLogLevel))
+	return auditlog.NewFileLogger(config.AuditLogFile, []byte(config.AuditLogKey), logger)
+}
+
+// endpointConfig points a service client at a custom endpoint, such as an emulator, instead of the AWS one
+func endpointConfig(endpoint string) *aws.Config {
+	awsConfig := aws.NewConfig()
+	if endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(endpoint)
+	}
 
-	return logger
+	return awsConfig
 }
 
-func main() {
-	flag.Parse()
+// buildHTTPClient returns the HTTP client used to call AWS, trusting only the CAs of the CA bundle if one is set
+func buildHTTPClient(config sqsbroker.Config) (*http.Client, error) {
+	if !config.SkipSSLValidation && config.CABundleFile == "" {
+		return http.DefaultClient, nil
+	}
 
-	config, err := LoadConfig(configFilePath)
-	if err != nil {
-		log.Fatalf("Error loading config file: %s", err)
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.SkipSSLValidation}
+
+	if config.CABundleFile != "" {
+		caBundle, err := ioutil.ReadFile(config.CABundleFile)
+		if err != nil {
+			return nil, err
+		}
+
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caBundle) {
+			return nil, errors.New("No PEM certificates found in " + config.CABundleFile)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
+
+func buildClientsFactory(config sqsbroker.Config, httpClient *http.Client, logger lager.Logger) sqsbroker.ClientsFactory {
+	return func(region string, accountRole sqsbroker.AccountRole) (awssqs.Queue, awssns.Topic, awsiam.User) {
+		awsConfig := aws.NewConfig().WithRegion(region).WithHTTPClient(httpClient)
+		if accountRole.RoleArn != "" {
+			stsSession := session.New(aws.NewConfig().WithRegion(region).WithHTTPClient(httpClient), endpointConfig(config.STSEndpoint))
+			// Assumed role credentials are cached and only refreshed when they are about to expire
+			awsConfig = awsConfig.WithCredentials(stscreds.NewCredentials(stsSession, accountRole.RoleArn, func(p *stscreds.AssumeRoleProvider) {
+				p.RoleSessionName = roleSessionName
+				p.ExpiryWindow = roleExpiryWindow
+				if accountRole.ExternalID != "" {
+					p.ExternalID = aws.String(accountRole.ExternalID)
+				}
+			}))
+		}
+		awsSession := session.New(awsConfig)
+
+		return awssqs.NewSQSQueue(sqs.New(awsSession, endpointConfig(config.SQSEndpoint)), config.SQSEndpoint, logger),
+			awssns.NewSNSTopic(sns.New(awsSession, endpointConfig(config.SNSEndpoint)), logger),
+			awsiam.NewIAMUser(iam.New(awsSession, endpointConfig(config.IAMEndpoint)), logger)
+	}
+}
+
+func buildBindingQueueFactory(config sqsbroker.Config, httpClient *http.Client, logger lager.Logger) sqsbroker.BindingQueueFactory {
+	return func(region string, accessKeyID string, secretAccessKey string) awssqs.Queue {
+		awsConfig := aws.NewConfig().
+			WithRegion(region).
+			WithHTTPClient(httpClient).
+			WithCredentials(credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""))
+
+		return awssqs.NewSQSQueue(sqs.New(session.New(awsConfig), endpointConfig(config.SQSEndpoint)), config.SQSEndpoint, logger)
 	}
+}
+
+func buildPolicySimulator(config sqsbroker.Config, httpClient *http.Client, logger lager.Logger) awsiam.PolicySimulator {
+	awsSession := session.New(aws.NewConfig().WithRegion(config.Region).WithHTTPClient(httpClient))
 
-	logger := buildLogger(config.LogLevel)
+	return awsiam.NewIAMPolicySimulator(iam.New(awsSession, endpointConfig(config.IAMEndpoint)), logger)
+}
+
+// NewBroker builds the service broker and the HTTP handler serving it from a loaded config
+func NewBroker(config *Config, logger lager.Logger) (*sqsbroker.SQSBroker, http.Handler, error) {
+	store, err := buildStore(config.SQSConfig.StateFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Loading state file: %s", err)
+	}
 
-	awsConfig := aws.NewConfig().WithRegion(config.SQSConfig.Region)
-	awsSession := session.New(awsConfig)
+	auditLogger, err := buildAuditLogger(config, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Opening audit log: %s", err)
+	}
 
-	sqssvc := sqs.New(awsSession)
-	queue := awssqs.NewSQSQueue(sqssvc, logger)
+	httpClient, err := buildHTTPClient(config.SQSConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Loading CA bundle: %s", err)
+	}
 
-	iamsvc := iam.New(awsSession)
-	user := awsiam.NewIAMUser(iamsvc, logger)
+	clientsFactory := buildClientsFactory(config.SQSConfig, httpClient, logger)
+	bindingQueueFactory := buildBindingQueueFactory(config.SQSConfig, httpClient, logger)
 
-	serviceBroker := sqsbroker.New(config.SQSConfig, queue, user, logger)
+	serviceBroker := sqsbroker.New(config.SQSConfig, clientsFactory, store, bindingQueueFactory, auditLogger, logger)
 
 	credentials := brokerapi.BrokerCredentials{
 		Username: config.Username,
 		Password: config.Password,
 	}
 
-	brokerAPI := brokerapi.New(serviceBroker, logger, credentials)
-	http.Handle("/", brokerAPI)
+	brokerAPI := brokerhttp.New(serviceBroker, auditLogger, logger, credentials)
+	if config.AdminUsername == "" {
+		return serviceBroker, brokerAPI, nil
+	}
+
+	adminCredentials := brokerapi.BrokerCredentials{
+		Username: config.AdminUsername,
+		Password: config.AdminPassword,
+	}
+
+	router := http.NewServeMux()
+	router.Handle("/admin/", brokerhttp.NewAdmin(serviceBroker, auditLogger, logger, adminCredentials))
+	router.Handle("/", brokerAPI)
+
+	return serviceBroker, router, nil
+}
+
+// reloadOnSignal reloads the catalog and the user parameters options from the config on SIGHUP
+func reloadOnSignal(serviceBroker *sqsbroker.SQSBroker, logger lager.Logger) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		for range signals {
+			config, err := LoadConfig(configFilePath)
+			if err != nil {
+				logger.Error("reload-config", err)
+				continue
+			}
+
+			if err := serviceBroker.Reload(config.SQSConfig); err != nil {
+				logger.Error("reload-config", err)
+			}
+		}
+	}()
+}
 
-	fmt.Println("SQS Service Broker started on port " + port + "...")
-	http.ListenAndServe(":"+port, nil)
+func main() {
+	switch err := RunCommand(os.Args[1:], os.Stdout, os.Stderr); err {
+	case nil, flag.ErrHelp:
+	case ErrUsage:
+		os.Exit(2)
+	default:
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/sqs-broker/awsiam"
+	"github.com/cf-platform-eng/sqs-broker/sqsbroker"
+)
+
+const (
+	permissionsCheckWarn    = "warn"
+	permissionsCheckEnforce = "enforce"
+	permissionsCheckOff     = "off"
+)
+
+// Kinds of resources the required actions are called on
+const (
+	queueResources      = "queue"
+	topicResources      = "topic"
+	userResources       = "user"
+	userPolicyResources = "user-policy"
+	roleResources       = "role"
+	callerResources     = "caller"
+	anyResources        = "*"
+)
+
+// requiredActions is a set of actions the broker calls on a kind of resources
+type requiredActions struct {
+	resources  string
+	actions    []string
+	topicsOnly bool
+}
+
+// requiredActionsTable lists every action the broker calls, both the startup check and check-permissions simulate it
+var requiredActionsTable = []requiredActions{
+	{resources: queueResources, actions: []string{"sqs:CreateQueue", "sqs:DeleteQueue", "sqs:GetQueueUrl", "sqs:GetQueueAttributes", "sqs:SetQueueAttributes", "sqs:ReceiveMessage", "sqs:SendMessage", "sqs:DeleteMessage"}},
+	{resources: userResources, actions: []string{"iam:GetUser", "iam:CreateUser", "iam:DeleteUser", "iam:ListAccessKeys", "iam:CreateAccessKey", "iam:DeleteAccessKey", "iam:ListAttachedUserPolicies", "iam:AttachUserPolicy", "iam:DetachUserPolicy"}},
+	{resources: userPolicyResources, actions: []string{"iam:CreatePolicy", "iam:DeletePolicy"}},
+	{resources: topicResources, actions: []string{"sns:CreateTopic", "sns:DeleteTopic", "sns:GetTopicAttributes", "sns:SetTopicAttributes", "sns:Subscribe", "sns:Unsubscribe", "sns:SetSubscriptionAttributes"}, topicsOnly: true},
+	{resources: anyResources, actions: []string{"sns:ListTopics"}, topicsOnly: true},
+	{resources: roleResources, actions: []string{"sts:AssumeRole"}},
+	{resources: callerResources, actions: []string{"iam:GetUser", "iam:SimulatePrincipalPolicy"}},
+}
+
+type iamPolicy struct {
+	Statements []iamPolicyStatement `json:"Statement"`
+}
+
+type iamPolicyStatement struct {
+	Effect string      `json:"Effect"`
+	Action interface{} `json:"Action"`
+}
+
+// LoadPolicyActions returns the actions allowed by an IAM policy document, such as iam_policy.json
+func LoadPolicyActions(path string) ([]string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy iamPolicy
+	if err := json.Unmarshal(contents, &policy); err != nil {
+		return nil, err
+	}
+
+	actions := []string{}
+	for i, statement := range policy.Statements {
+		if statement.Effect != "Allow" {
+			continue
+		}
+
+		// An Action is either a single action or a list of actions
+		switch action := statement.Action.(type) {
+		case string:
+			actions = append(actions, action)
+		case []interface{}:
+			for _, value := range action {
+				name, ok := value.(string)
+				if !ok {
+					return nil, fmt.Errorf("Invalid action in Statement[%d]", i)
+				}
+				actions = append(actions, name)
+			}
+		default:
+			return nil, fmt.Errorf("Invalid Action in Statement[%d]", i)
+		}
+	}
+
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("No allowed actions found in %s", path)
+	}
+
+	return actions, nil
+}
+
+// RequiredPermission is a set of actions the broker calls on a set of resources
+type RequiredPermission struct {
+	Actions      []string
+	ResourceArns []string
+}
+
+// RequiredPermissions returns the actions the broker calls, on the resources named after its sqs_prefix in every
+// region it can use, on the roles it assumes, and on the broker IAM user itself
+func RequiredPermissions(config sqsbroker.Config, accountID string, callerArn string) []RequiredPermission {
+	regions := configRegions(config)
+
+	hasTopics := false
+	for _, service := range config.Catalog.Services {
+		if service.IsTopic() {
+			hasTopics = true
+			break
+		}
+	}
+
+	permissions := []RequiredPermission{}
+	for _, required := range requiredActionsTable {
+		if required.topicsOnly && !hasTopics {
+			continue
+		}
+
+		var resourceArns []string
+		switch required.resources {
+		case queueResources:
+			for _, region := range regions {
+				resourceArns = append(resourceArns, fmt.Sprintf("arn:aws:sqs:%s:%s:%s-*", region, accountID, config.SQSPrefix))
+			}
+		case topicResources:
+			for _, region := range regions {
+				resourceArns = append(resourceArns, fmt.Sprintf("arn:aws:sns:%s:%s:%s-*", region, accountID, config.SQSPrefix))
+			}
+		case userResources:
+			resourceArns = sqsbroker.BindingUserArns(config.SQSPrefix, accountID)
+		case userPolicyResources:
+			resourceArns = sqsbroker.BindingPolicyArns(config.SQSPrefix, accountID)
+		case roleResources:
+			resourceArns = configRoleArns(config)
+		case callerResources:
+			resourceArns = []string{callerArn}
+		default:
+			resourceArns = []string{"*"}
+		}
+		if len(resourceArns) == 0 {
+			continue
+		}
+
+		permissions = append(permissions, RequiredPermission{Actions: required.actions, ResourceArns: resourceArns})
+	}
+
+	return permissions
+}
+
+// CheckRequiredPermissions simulates the policies of the broker IAM user, and returns its ARN, the number of required
+// actions and those it is denied
+func CheckRequiredPermissions(config sqsbroker.Config, policySimulator awsiam.PolicySimulator) (string, int, []string, error) {
+	callerArn, err := policySimulator.CallerArn()
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("Getting the broker IAM user: %s", err)
+	}
+
+	// arn:aws:iam::<account>:user/<name>, the policy simulator does not accept the assumed-role session ARNs of role
+	// credentials, so only IAM users can be checked
+	arnParts := strings.SplitN(callerArn, ":", 6)
+	if len(arnParts) < 6 || arnParts[4] == "" {
+		return callerArn, 0, nil, fmt.Errorf("Invalid IAM user ARN '%s'", callerArn)
+	}
+	if arnParts[2] != "iam" || !strings.HasPrefix(arnParts[5], "user/") {
+		return callerArn, 0, nil, fmt.Errorf("Only the permissions of an IAM user can be checked, '%s' is not an IAM user ARN", callerArn)
+	}
+
+	actions := 0
+	deniedActions := []string{}
+	for _, permission := range RequiredPermissions(config, arnParts[4], callerArn) {
+		denied, err := policySimulator.DeniedActions(callerArn, permission.Actions, permission.ResourceArns)
+		if err != nil {
+			return callerArn, 0, nil, fmt.Errorf("Simulating the policies of '%s': %s", callerArn, err)
+		}
+		actions += len(permission.Actions)
+		deniedActions = append(deniedActions, denied...)
+	}
+
+	return callerArn, actions, deniedActions, nil
+}
+
+// CheckStartupPermissions logs the required actions the broker is denied, and fails if the check is enforced
+func CheckStartupPermissions(config *Config, policySimulator awsiam.PolicySimulator, logger lager.Logger) error {
+	mode := config.PermissionsCheck
+	if mode == "" {
+		mode = permissionsCheckWarn
+	}
+	if mode == permissionsCheckOff {
+		return nil
+	}
+
+	_, _, deniedActions, err := CheckRequiredPermissions(config.SQSConfig, policySimulator)
+	if err != nil {
+		logger.Error("check-permissions", err)
+		if mode == permissionsCheckEnforce {
+			return err
+		}
+		return nil
+	}
+
+	if len(deniedActions) == 0 {
+		logger.Info("check-permissions", lager.Data{"denied-actions": deniedActions})
+		return nil
+	}
+
+	err = fmt.Errorf("Missing permissions for %s", strings.Join(deniedActions, ", "))
+	logger.Error("check-permissions", err, lager.Data{"denied-actions": deniedActions})
+	if mode == permissionsCheckEnforce {
+		return err
+	}
+
+	return nil
+}
+
+func configRegions(config sqsbroker.Config) []string {
+	found := map[string]bool{config.Region: true}
+	for _, region := range config.AllowedRegions {
+		found[region] = true
+	}
+	for _, service := range config.Catalog.Services {
+		for _, plan := range service.Plans {
+			if plan.Region != "" {
+				found[plan.Region] = true
+			}
+		}
+	}
+
+	regions := []string{}
+	for region := range found {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	return regions
+}
+
+func configRoleArns(config sqsbroker.Config) []string {
+	found := map[string]bool{}
+	for _, accountRole := range config.OrganizationAccounts {
+		found[accountRole.RoleArn] = true
+	}
+	for _, service := range config.Catalog.Services {
+		for _, plan := range service.Plans {
+			if plan.RoleArn != "" {
+				found[plan.RoleArn] = true
+			}
+		}
+	}
+
+	roleArns := []string{}
+	for roleArn := range found {
+		roleArns = append(roleArns, roleArn)
+	}
+	sort.Strings(roleArns)
+
+	return roleArns
+}
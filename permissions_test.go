@@ -0,0 +1,179 @@
+package main_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cf-platform-eng/sqs-broker"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	iamfake "github.com/cf-platform-eng/sqs-broker/awsiam/fakes"
+	"github.com/cf-platform-eng/sqs-broker/sqsbroker"
+)
+
+var _ = Describe("Permissions", func() {
+	var (
+		config          *Config
+		policySimulator *iamfake.FakePolicySimulator
+
+		testSink *lagertest.TestSink
+		logger   lager.Logger
+	)
+
+	BeforeEach(func() {
+		config = &Config{
+			SQSConfig: sqsbroker.Config{
+				Region:         "us-east-1",
+				AllowedRegions: []string{"eu-west-1"},
+				SQSPrefix:      "cf",
+				Catalog: sqsbroker.Catalog{
+					Services: []sqsbroker.Service{
+						{
+							ID: "service-id",
+							Plans: []sqsbroker.ServicePlan{
+								{ID: "plan-id"},
+								{ID: "other-account-plan-id", RoleArn: "arn:aws:iam::210987654321:role/broker"},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		policySimulator = &iamfake.FakePolicySimulator{
+			CallerArnArn: "arn:aws:iam::123456789012:user/sqs-broker",
+		}
+
+		logger = lager.NewLogger("permissions_test")
+		testSink = lagertest.NewTestSink()
+		logger.RegisterSink(testSink)
+	})
+
+	Describe("RequiredPermissions", func() {
+		It("scopes the actions to the resources named after the prefix", func() {
+			permissions := RequiredPermissions(config.SQSConfig, "123456789012", "arn:aws:iam::123456789012:user/sqs-broker")
+			Expect(permissions).To(HaveLen(5))
+			Expect(permissions[0].Actions).To(ContainElement("sqs:CreateQueue"))
+			Expect(permissions[0].ResourceArns).To(Equal([]string{
+				"arn:aws:sqs:eu-west-1:123456789012:cf-*",
+				"arn:aws:sqs:us-east-1:123456789012:cf-*",
+			}))
+			Expect(permissions[1].Actions).To(ContainElement("iam:AttachUserPolicy"))
+			Expect(permissions[1].ResourceArns).To(Equal([]string{
+				"arn:aws:iam::123456789012:user/cf-*",
+				"arn:aws:iam::123456789012:user/cf/*",
+			}))
+			Expect(permissions[2].Actions).To(Equal([]string{"iam:CreatePolicy", "iam:DeletePolicy"}))
+			Expect(permissions[2].ResourceArns).To(Equal([]string{"arn:aws:iam::123456789012:policy/cf-*"}))
+			Expect(permissions[3].Actions).To(Equal([]string{"sts:AssumeRole"}))
+			Expect(permissions[3].ResourceArns).To(Equal([]string{"arn:aws:iam::210987654321:role/broker"}))
+			Expect(permissions[4].Actions).To(Equal([]string{"iam:GetUser", "iam:SimulatePrincipalPolicy"}))
+			Expect(permissions[4].ResourceArns).To(Equal([]string{"arn:aws:iam::123456789012:user/sqs-broker"}))
+		})
+
+		It("adds the SNS actions if the catalog has topics", func() {
+			config.SQSConfig.Catalog.Services[0].Backend = sqsbroker.SNSServiceBackend
+
+			permissions := RequiredPermissions(config.SQSConfig, "123456789012", "arn:aws:iam::123456789012:user/sqs-broker")
+			Expect(permissions).To(HaveLen(7))
+			Expect(permissions[3].Actions).To(ContainElement("sns:Subscribe"))
+			Expect(permissions[3].ResourceArns).To(ContainElement("arn:aws:sns:us-east-1:123456789012:cf-*"))
+			Expect(permissions[4].Actions).To(Equal([]string{"sns:ListTopics"}))
+		})
+	})
+
+	Describe("LoadPolicyActions", func() {
+		It("allows every required action in the repository IAM policy", func() {
+			config.SQSConfig.Catalog.Services[0].Backend = sqsbroker.SNSServiceBackend
+
+			actions, err := LoadPolicyActions("iam_policy.json")
+			Expect(err).ToNot(HaveOccurred())
+			for _, permission := range RequiredPermissions(config.SQSConfig, "123456789012", "arn:aws:iam::123456789012:user/sqs-broker") {
+				for _, action := range permission.Actions {
+					Expect(actions).To(ContainElement(action))
+				}
+			}
+		})
+	})
+
+	Describe("CheckRequiredPermissions", func() {
+		It("returns the caller, the number of required actions and the denied ones", func() {
+			policySimulator.DeniedActionsDeniedActions = map[string]bool{"sqs:CreateQueue": true}
+
+			callerArn, actions, deniedActions, err := CheckRequiredPermissions(config.SQSConfig, policySimulator)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(callerArn).To(Equal("arn:aws:iam::123456789012:user/sqs-broker"))
+			Expect(actions).To(Equal(22))
+			Expect(deniedActions).To(Equal([]string{"sqs:CreateQueue"}))
+		})
+
+		It("rejects the assumed-role ARNs of role credentials", func() {
+			policySimulator.CallerArnArn = "arn:aws:sts::123456789012:assumed-role/sqs-broker/session"
+
+			_, _, _, err := CheckRequiredPermissions(config.SQSConfig, policySimulator)
+			Expect(err).To(MatchError("Only the permissions of an IAM user can be checked, 'arn:aws:sts::123456789012:assumed-role/sqs-broker/session' is not an IAM user ARN"))
+			Expect(policySimulator.DeniedActionsCalled).To(BeFalse())
+		})
+	})
+
+	Describe("CheckStartupPermissions", func() {
+		It("logs that every required action is allowed", func() {
+			err := CheckStartupPermissions(config, policySimulator, logger)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policySimulator.DeniedActionsPrincipalArn).To(Equal("arn:aws:iam::123456789012:user/sqs-broker"))
+			Expect(testSink.LogMessages()).To(Equal([]string{"permissions_test.check-permissions"}))
+			Expect(testSink.Logs()[0].LogLevel).To(Equal(lager.INFO))
+		})
+
+		It("logs the denied actions without failing", func() {
+			policySimulator.DeniedActionsDeniedActions = map[string]bool{"iam:AttachUserPolicy": true, "sts:AssumeRole": true}
+
+			err := CheckStartupPermissions(config, policySimulator, logger)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testSink.Logs()).To(HaveLen(1))
+			Expect(testSink.Logs()[0].LogLevel).To(Equal(lager.ERROR))
+			Expect(testSink.Logs()[0].Data).To(HaveKeyWithValue("denied-actions", []interface{}{"iam:AttachUserPolicy", "sts:AssumeRole"}))
+		})
+
+		It("logs the errors without failing", func() {
+			policySimulator.CallerArnError = errors.New("AccessDenied: not allowed")
+
+			err := CheckStartupPermissions(config, policySimulator, logger)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testSink.Logs()).To(HaveLen(1))
+			Expect(testSink.Logs()[0].Data).To(HaveKeyWithValue("error", "Getting the broker IAM user: AccessDenied: not allowed"))
+		})
+
+		Context("when the check is enforced", func() {
+			BeforeEach(func() {
+				config.PermissionsCheck = "enforce"
+			})
+
+			It("fails if an action is denied", func() {
+				policySimulator.DeniedActionsDeniedActions = map[string]bool{"iam:AttachUserPolicy": true}
+
+				err := CheckStartupPermissions(config, policySimulator, logger)
+				Expect(err).To(MatchError("Missing permissions for iam:AttachUserPolicy"))
+			})
+
+			It("fails if the policies can not be simulated", func() {
+				policySimulator.DeniedActionsError = errors.New("AccessDenied: not allowed")
+
+				err := CheckStartupPermissions(config, policySimulator, logger)
+				Expect(err).To(MatchError("Simulating the policies of 'arn:aws:iam::123456789012:user/sqs-broker': AccessDenied: not allowed"))
+			})
+		})
+
+		It("does not check the permissions when the check is off", func() {
+			config.PermissionsCheck = "off"
+
+			err := CheckStartupPermissions(config, policySimulator, logger)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policySimulator.CallerArnCalled).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,61 @@
+package sqsbroker
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cf-platform-eng/sqs-broker/brokerstore"
+)
+
+type AccountRole struct {
+	RoleArn    string `json:"role_arn"`
+	ExternalID string `json:"external_id,omitempty"`
+}
+
+type AccountMoveNotAllowedError struct {
+	PlanID  string
+	RoleArn string
+}
+
+func (e *AccountMoveNotAllowedError) Error() string {
+	return fmt.Sprintf("Service Plan '%s' assumes role '%s', instances can not be moved to another account", e.PlanID, e.RoleArn)
+}
+
+func (e *AccountMoveNotAllowedError) Kind() string {
+	return InvalidRequestErrorKind
+}
+
+func (a AccountRole) Validate() error {
+	if a.RoleArn == "" && a.ExternalID != "" {
+		return errors.New("Must provide a non-empty RoleArn when ExternalID is set")
+	}
+
+	return nil
+}
+
+func instanceAccountRole(instance brokerstore.Instance) AccountRole {
+	return AccountRole{
+		RoleArn:    instance.RoleArn,
+		ExternalID: instance.ExternalID,
+	}
+}
+
+// provisionAccountRole returns the role to assume for a new instance, a role set by the plan takes precedence over the organization one
+func (b *SQSBroker) provisionAccountRole(servicePlan ServicePlan, organizationGUID string) AccountRole {
+	if servicePlan.RoleArn != "" {
+		return AccountRole{
+			RoleArn:    servicePlan.RoleArn,
+			ExternalID: servicePlan.ExternalID,
+		}
+	}
+
+	return b.organizationAccounts[organizationGUID]
+}
+
+func (b *SQSBroker) checkAccount(servicePlan ServicePlan, instance brokerstore.Instance) error {
+	if servicePlan.RoleArn != "" && servicePlan.RoleArn != instance.RoleArn {
+		return &AccountMoveNotAllowedError{PlanID: servicePlan.ID, RoleArn: servicePlan.RoleArn}
+	}
+
+	return nil
+}
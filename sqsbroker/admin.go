@@ -0,0 +1,463 @@
+package sqsbroker
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/sqs-broker/awsiam"
+	"github.com/cf-platform-eng/sqs-broker/awssns"
+	"github.com/cf-platform-eng/sqs-broker/awssqs"
+	"github.com/cf-platform-eng/sqs-broker/brokerstore"
+)
+
+type BindingBusyError struct {
+	BindingID string
+}
+
+func (e *BindingBusyError) Error() string {
+	return fmt.Sprintf("Binding '%s' is being created or deleted, try again once it has finished", e.BindingID)
+}
+
+func (e *BindingBusyError) Kind() string {
+	return ConcurrencyErrorKind
+}
+
+// AdminInstance is an Instance recorded by the broker, with the ARN of its Queue or Topic as found in AWS
+type AdminInstance struct {
+	InstanceID         string                 `json:"instance_id"`
+	ServiceID          string                 `json:"service_id"`
+	PlanID             string                 `json:"plan_id"`
+	OrganizationGUID   string                 `json:"organization_guid,omitempty"`
+	SpaceGUID          string                 `json:"space_guid,omitempty"`
+	Context            map[string]interface{} `json:"context,omitempty"`
+	Region             string                 `json:"region"`
+	RoleArn            string                 `json:"role_arn,omitempty"`
+	Arn                string                 `json:"arn,omitempty"`
+	LastOperationState string                 `json:"last_operation_state,omitempty"`
+	Error              string                 `json:"error,omitempty"`
+}
+
+// AdminBinding is a Binding recorded by the broker, with the ARN of its IAM User as found in AWS
+type AdminBinding struct {
+	BindingID          string `json:"binding_id"`
+	InstanceID         string `json:"instance_id"`
+	PlanID             string `json:"plan_id"`
+	AppGUID            string `json:"app_guid,omitempty"`
+	AccessKeyID        string `json:"access_key_id,omitempty"`
+	UserArn            string `json:"user_arn,omitempty"`
+	SubscriptionArn    string `json:"subscription_arn,omitempty"`
+	LastOperationState string `json:"last_operation_state,omitempty"`
+	Error              string `json:"error,omitempty"`
+}
+
+type AdminInstanceResponse struct {
+	AdminInstance
+	Attributes interface{}    `json:"attributes"`
+	Stats      interface{}    `json:"stats"`
+	Bindings   []AdminBinding `json:"bindings"`
+}
+
+// ReconcileReport lists the records whose AWS resources no longer exist, and the Bindings of unknown Instances
+type ReconcileReport struct {
+	DryRun           bool              `json:"dry_run"`
+	InstancesChecked int               `json:"instances_checked"`
+	BindingsChecked  int               `json:"bindings_checked"`
+	MissingInstances []string          `json:"missing_instances"`
+	MissingBindings  []string          `json:"missing_bindings"`
+	OrphanedBindings []string          `json:"orphaned_bindings"`
+	Errors           map[string]string `json:"errors,omitempty"`
+}
+
+func (b *SQSBroker) AdminInstances() ([]AdminInstance, error) {
+	instances, err := b.store.ListInstances()
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(instancesByID(instances))
+
+	adminInstances := []AdminInstance{}
+	for _, instance := range instances {
+		adminInstances = append(adminInstances, b.adminInstance(instance))
+	}
+
+	return adminInstances, nil
+}
+
+func (b *SQSBroker) AdminBindings() ([]AdminBinding, error) {
+	bindings, err := b.store.ListBindings()
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(bindingsByID(bindings))
+
+	adminBindings := []AdminBinding{}
+	for _, binding := range bindings {
+		adminBindings = append(adminBindings, b.adminBinding(binding))
+	}
+
+	return adminBindings, nil
+}
+
+// AdminInstance returns the record of an Instance along with the live attributes of its Queue or Topic
+func (b *SQSBroker) AdminInstance(instanceID string) (AdminInstanceResponse, error) {
+	adminInstanceResponse := AdminInstanceResponse{}
+
+	instanceResponse, err := b.GetInstance(instanceID)
+	if err != nil {
+		return adminInstanceResponse, err
+	}
+
+	instance, err := b.store.GetInstance(instanceID)
+	if err != nil && err != brokerstore.ErrInstanceDoesNotExist {
+		return adminInstanceResponse, err
+	}
+	instance.InstanceID = instanceID
+
+	adminInstanceResponse.AdminInstance = b.adminInstance(instance)
+	adminInstanceResponse.Attributes = instanceResponse.Attributes
+	adminInstanceResponse.Stats = instanceResponse.Stats
+
+	bindings, err := b.instanceBindings(instanceID)
+	if err != nil {
+		return adminInstanceResponse, err
+	}
+	sort.Sort(bindingsByID(bindings))
+
+	adminInstanceResponse.Bindings = []AdminBinding{}
+	for _, binding := range bindings {
+		adminInstanceResponse.Bindings = append(adminInstanceResponse.Bindings, b.adminBinding(binding))
+	}
+
+	return adminInstanceResponse, nil
+}
+
+// ForceDeleteInstance deletes the Bindings, then the Queue or Topic of an Instance whatever its plan protections,
+// and drops its record even if the Queue or Topic is already gone. Queues archived on deletion are deleted in the background.
+// An operation stuck in progress is dropped, so its Instance can be deleted.
+func (b *SQSBroker) ForceDeleteInstance(instanceID string) (bool, error) {
+	b.logger.Info("force-delete-instance", lager.Data{
+		instanceIDLogKey: instanceID,
+	})
+
+	instance, err := b.store.GetInstance(instanceID)
+	if err != nil && err != brokerstore.ErrInstanceDoesNotExist {
+		return false, err
+	}
+	recorded := err == nil
+
+	if recorded && instance.LastOperationState == brokerapi.LastOperationInProgress {
+		b.logger.Info("drop-pending-operation", lager.Data{
+			instanceIDLogKey: instanceID,
+			"operation":      instance.LastOperationDescription,
+		})
+		instance.LastOperationState = ""
+		instance.LastOperationDescription = ""
+		if err := b.store.SaveInstance(instance); err != nil {
+			return false, err
+		}
+	}
+
+	bindings, err := b.instanceBindings(instanceID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, binding := range bindings {
+		if err := b.ForceDeleteBinding(binding.BindingID); err != nil {
+			return false, err
+		}
+	}
+
+	details := brokerapi.DeprovisionDetails{
+		ServiceID: instance.ServiceID,
+		PlanID:    instance.PlanID,
+	}
+	asynch, err := b.ForceDeprovision(instanceID, details, true)
+	if err == brokerapi.ErrInstanceDoesNotExist && recorded {
+		err = b.store.DeleteInstance(instanceID)
+	}
+	if err != nil && err != brokerstore.ErrInstanceDoesNotExist {
+		return false, err
+	}
+
+	return asynch, nil
+}
+
+// ForceDeleteBinding deletes the Subscription and IAM User of a Binding, and drops its record even if they are already gone
+func (b *SQSBroker) ForceDeleteBinding(bindingID string) error {
+	b.logger.Info("force-delete-binding", lager.Data{
+		bindingIDLogKey: bindingID,
+	})
+
+	binding, err := b.store.GetBinding(bindingID)
+	if err != nil {
+		if err == brokerstore.ErrBindingDoesNotExist {
+			return brokerapi.ErrBindingDoesNotExist
+		}
+		return err
+	}
+
+	clients, err := b.instanceClients(binding.InstanceID)
+	if err != nil {
+		return err
+	}
+
+	if binding.SubscriptionArn != "" {
+		subscription := brokerstore.Subscription{
+			TopicInstanceID: binding.TopicInstanceID,
+			TopicArn:        binding.TopicArn,
+			SubscriptionArn: binding.SubscriptionArn,
+		}
+		if err := b.unsubscribeQueue(binding.InstanceID, b.bindingSubscriptionSid(bindingID), subscription); err != nil {
+			return err
+		}
+	}
+
+	_, err = clients.user.Describe(b.userName(bindingID))
+	if err == nil {
+		err = b.deleteBindingUser(clients.user, binding.InstanceID, bindingID)
+	}
+	if err != nil && err != awsiam.ErrUserDoesNotExist {
+		return err
+	}
+
+	if err := b.store.DeleteBinding(bindingID); err != nil && err != brokerstore.ErrBindingDoesNotExist {
+		return err
+	}
+
+	return nil
+}
+
+// RotateBindingKeys replaces the access keys of a Binding, and returns its new credentials.
+// The old access keys stop working at once, so the applications must be handed the new credentials.
+func (b *SQSBroker) RotateBindingKeys(bindingID string) (BindingResponse, error) {
+	bindingResponse := BindingResponse{}
+
+	binding, err := b.store.GetBinding(bindingID)
+	if err != nil {
+		if err == brokerstore.ErrBindingDoesNotExist {
+			return bindingResponse, brokerapi.ErrBindingDoesNotExist
+		}
+		return bindingResponse, err
+	}
+
+	if binding.LastOperationState == brokerapi.LastOperationInProgress {
+		return bindingResponse, &BindingBusyError{BindingID: bindingID}
+	}
+
+	target, err := b.describeTarget(binding.InstanceID, b.isTopicService(binding.ServiceID))
+	if err != nil {
+		return bindingResponse, err
+	}
+
+	clients, err := b.instanceClients(binding.InstanceID)
+	if err != nil {
+		return bindingResponse, err
+	}
+
+	accessKeyID, secretAccessKey, err := b.reissueAccessKey(clients.user, binding.InstanceID, bindingID)
+	if err != nil {
+		return bindingResponse, err
+	}
+
+	binding.AccessKeyID = accessKeyID
+	binding.SecretAccessKey = ""
+	if b.storeBindingSecrets {
+		binding.SecretAccessKey = secretAccessKey
+	}
+	if err = b.store.SaveBinding(binding); err != nil {
+		return bindingResponse, err
+	}
+
+	bindingResponse.Credentials = &brokerapi.CredentialsHash{
+		Username: accessKeyID,
+		Password: secretAccessKey,
+		URI:      target.uri,
+	}
+	bindingResponse.Parameters = binding.Parameters
+
+	return bindingResponse, nil
+}
+
+// Reconcile checks the resources of every recorded Instance and Binding, and drops the records of those gone from AWS.
+// Bindings whose Instance is not recorded are only reported, as their IAM Users may still be in use.
+func (b *SQSBroker) Reconcile(dryRun bool) (ReconcileReport, error) {
+	report := ReconcileReport{
+		DryRun:           dryRun,
+		MissingInstances: []string{},
+		MissingBindings:  []string{},
+		OrphanedBindings: []string{},
+		Errors:           map[string]string{},
+	}
+
+	instances, err := b.store.ListInstances()
+	if err != nil {
+		return report, err
+	}
+	sort.Sort(instancesByID(instances))
+
+	recordedInstances := map[string]bool{}
+	for _, instance := range instances {
+		recordedInstances[instance.InstanceID] = true
+		if instance.LastOperationState == brokerapi.LastOperationInProgress {
+			continue
+		}
+
+		report.InstancesChecked++
+		if _, err := b.instanceArn(instance); err != nil {
+			if err != brokerapi.ErrInstanceDoesNotExist {
+				report.Errors[instance.InstanceID] = err.Error()
+				continue
+			}
+
+			report.MissingInstances = append(report.MissingInstances, instance.InstanceID)
+			if !dryRun {
+				if err := b.store.DeleteInstance(instance.InstanceID); err != nil {
+					report.Errors[instance.InstanceID] = err.Error()
+				}
+			}
+		}
+	}
+
+	bindings, err := b.store.ListBindings()
+	if err != nil {
+		return report, err
+	}
+	sort.Sort(bindingsByID(bindings))
+
+	for _, binding := range bindings {
+		if binding.LastOperationState == brokerapi.LastOperationInProgress {
+			continue
+		}
+
+		report.BindingsChecked++
+		if !recordedInstances[binding.InstanceID] {
+			report.OrphanedBindings = append(report.OrphanedBindings, binding.BindingID)
+		}
+
+		if _, err := b.bindingUserArn(binding); err != nil {
+			if err != awsiam.ErrUserDoesNotExist {
+				report.Errors[binding.BindingID] = err.Error()
+				continue
+			}
+
+			report.MissingBindings = append(report.MissingBindings, binding.BindingID)
+			if !dryRun {
+				if err := b.store.DeleteBinding(binding.BindingID); err != nil {
+					report.Errors[binding.BindingID] = err.Error()
+				}
+			}
+		}
+	}
+
+	b.logger.Info("reconcile", lager.Data{
+		"dry-run":           dryRun,
+		"missing-instances": report.MissingInstances,
+		"missing-bindings":  report.MissingBindings,
+		"orphaned-bindings": report.OrphanedBindings,
+		"errors":            report.Errors,
+	})
+
+	return report, nil
+}
+
+func (b *SQSBroker) adminInstance(instance brokerstore.Instance) AdminInstance {
+	region := instance.Region
+	if region == "" {
+		region = b.region
+	}
+
+	adminInstance := AdminInstance{
+		InstanceID:         instance.InstanceID,
+		ServiceID:          instance.ServiceID,
+		PlanID:             instance.PlanID,
+		OrganizationGUID:   instance.OrganizationGUID,
+		SpaceGUID:          instance.SpaceGUID,
+		Context:            instance.Context,
+		Region:             region,
+		RoleArn:            instance.RoleArn,
+		LastOperationState: instance.LastOperationState,
+	}
+
+	arn, err := b.instanceArn(instance)
+	if err != nil {
+		adminInstance.Error = err.Error()
+	}
+	adminInstance.Arn = arn
+
+	return adminInstance
+}
+
+func (b *SQSBroker) adminBinding(binding brokerstore.Binding) AdminBinding {
+	adminBinding := AdminBinding{
+		BindingID:          binding.BindingID,
+		InstanceID:         binding.InstanceID,
+		PlanID:             binding.PlanID,
+		AppGUID:            binding.AppGUID,
+		AccessKeyID:        binding.AccessKeyID,
+		SubscriptionArn:    binding.SubscriptionArn,
+		LastOperationState: binding.LastOperationState,
+	}
+
+	userArn, err := b.bindingUserArn(binding)
+	if err != nil {
+		adminBinding.Error = err.Error()
+	}
+	adminBinding.UserArn = userArn
+
+	return adminBinding
+}
+
+func (b *SQSBroker) instanceArn(instance brokerstore.Instance) (string, error) {
+	clients := b.storedInstanceClients(instance)
+
+	if b.isTopicService(instance.ServiceID) {
+		topicDetails, err := clients.topic.Describe(b.topicName(instance.InstanceID))
+		if err != nil {
+			if err == awssns.ErrTopicDoesNotExist {
+				return "", brokerapi.ErrInstanceDoesNotExist
+			}
+			return "", err
+		}
+		return topicDetails.TopicArn, nil
+	}
+
+	queueDetails, err := clients.queue.Describe(b.queueName(instance.InstanceID))
+	if err != nil {
+		if err == awssqs.ErrQueueDoesNotExist {
+			return "", brokerapi.ErrInstanceDoesNotExist
+		}
+		return "", err
+	}
+
+	return queueDetails.QueueArn, nil
+}
+
+func (b *SQSBroker) bindingUserArn(binding brokerstore.Binding) (string, error) {
+	clients, err := b.instanceClients(binding.InstanceID)
+	if err != nil {
+		return "", err
+	}
+
+	userDetails, err := clients.user.Describe(b.userName(binding.BindingID))
+	if err != nil {
+		return "", err
+	}
+
+	return userDetails.UserARN, nil
+}
+
+type instancesByID []brokerstore.Instance
+
+func (s instancesByID) Len() int           { return len(s) }
+func (s instancesByID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s instancesByID) Less(i, j int) bool { return s[i].InstanceID < s[j].InstanceID }
+
+type bindingsByID []brokerstore.Binding
+
+func (s bindingsByID) Len() int           { return len(s) }
+func (s bindingsByID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s bindingsByID) Less(i, j int) bool { return s[i].BindingID < s[j].BindingID }
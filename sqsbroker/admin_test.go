@@ -0,0 +1,394 @@
+package sqsbroker_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/cf-platform-eng/sqs-broker/sqsbroker"
+
+	auditfake "github.com/cf-platform-eng/sqs-broker/auditlog/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awsiam"
+	iamfake "github.com/cf-platform-eng/sqs-broker/awsiam/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awssns"
+	snsfake "github.com/cf-platform-eng/sqs-broker/awssns/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awssqs"
+	sqsfake "github.com/cf-platform-eng/sqs-broker/awssqs/fakes"
+	"github.com/cf-platform-eng/sqs-broker/brokerstore"
+)
+
+var _ = Describe("Admin", func() {
+	var (
+		queue *sqsfake.FakeQueue
+		topic *snsfake.FakeTopic
+		user  *iamfake.FakeUser
+
+		store *brokerstore.JSONStore
+
+		storeBindingSecrets bool
+
+		sqsBroker *SQSBroker
+
+		instance brokerstore.Instance
+		binding  brokerstore.Binding
+
+		queueArn = "arn:aws:sqs:sqs-region:123456789012:cf-instance-id"
+		userArn  = "arn:aws:iam::123456789012:user/cf-binding-id"
+	)
+
+	BeforeEach(func() {
+		queue = &sqsfake.FakeQueue{}
+		topic = &snsfake.FakeTopic{}
+		user = &iamfake.FakeUser{}
+		store = brokerstore.NewMemoryStore()
+		storeBindingSecrets = false
+
+		queue.DescribeQueueDetails = awssqs.QueueDetails{
+			QueueURL:                    "queue-url",
+			QueueArn:                    queueArn,
+			VisibilityTimeout:           "30",
+			ApproximateNumberOfMessages: "2",
+		}
+		user.DescribeUserDetails = awsiam.UserDetails{
+			UserName: "cf-binding-id",
+			UserARN:  userArn,
+		}
+		user.ListAccessKeysAccessKeys = []string{"old-access-key-id"}
+		user.CreateAccessKeyAccessKeyID = "new-access-key-id"
+		user.CreateAccessKeySecretAccessKey = "new-secret-access-key"
+
+		instance = brokerstore.Instance{
+			InstanceID:       "instance-id",
+			ServiceID:        "Service-1",
+			PlanID:           "Plan-1",
+			OrganizationGUID: "organization-id",
+			SpaceGUID:        "space-id",
+		}
+		binding = brokerstore.Binding{
+			BindingID:   "binding-id",
+			InstanceID:  "instance-id",
+			ServiceID:   "Service-1",
+			PlanID:      "Plan-1",
+			AppGUID:     "app-id",
+			AccessKeyID: "old-access-key-id",
+		}
+		Expect(store.SaveInstance(instance)).To(Succeed())
+		Expect(store.SaveBinding(binding)).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		config := Config{
+			Region:              "sqs-region",
+			SQSPrefix:           "cf",
+			StoreBindingSecrets: storeBindingSecrets,
+			Catalog: Catalog{
+				Services: []Service{
+					Service{
+						ID:          "Service-1",
+						Name:        "Service 1",
+						Description: "This is the Service 1",
+						Bindable:    true,
+						Plans: []ServicePlan{
+							ServicePlan{
+								ID:                 "Plan-1",
+								Name:               "Plan 1",
+								Description:        "This is the Plan 1",
+								DeletionProtection: true,
+							},
+						},
+					},
+				},
+			},
+		}
+
+		logger := lager.NewLogger("admin_test")
+		logger.RegisterSink(lagertest.NewTestSink())
+
+		clientsFactory := func(region string, accountRole AccountRole) (awssqs.Queue, awssns.Topic, awsiam.User) {
+			return queue, topic, user
+		}
+		sqsBroker = New(config, clientsFactory, store, nil, &auditfake.FakeLogger{}, logger)
+	})
+
+	Describe("AdminInstances", func() {
+		It("lists the Instances with the ARN of their Queue", func() {
+			instances, err := sqsBroker.AdminInstances()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instances).To(Equal([]AdminInstance{
+				AdminInstance{
+					InstanceID:       "instance-id",
+					ServiceID:        "Service-1",
+					PlanID:           "Plan-1",
+					OrganizationGUID: "organization-id",
+					SpaceGUID:        "space-id",
+					Region:           "sqs-region",
+					Arn:              queueArn,
+				},
+			}))
+		})
+
+		It("reports the Instances whose Queue can not be described", func() {
+			queue.DescribeError = awssqs.ErrQueueDoesNotExist
+
+			instances, err := sqsBroker.AdminInstances()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instances).To(HaveLen(1))
+			Expect(instances[0].Arn).To(BeEmpty())
+			Expect(instances[0].Error).To(Equal(brokerapi.ErrInstanceDoesNotExist.Error()))
+		})
+	})
+
+	Describe("AdminBindings", func() {
+		It("lists the Bindings with the ARN of their User", func() {
+			bindings, err := sqsBroker.AdminBindings()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bindings).To(Equal([]AdminBinding{
+				AdminBinding{
+					BindingID:   "binding-id",
+					InstanceID:  "instance-id",
+					PlanID:      "Plan-1",
+					AppGUID:     "app-id",
+					AccessKeyID: "old-access-key-id",
+					UserArn:     userArn,
+				},
+			}))
+			Expect(user.DescribeUserName).To(Equal("cf-binding-id"))
+		})
+	})
+
+	Describe("AdminInstance", func() {
+		It("returns the live attributes of the Queue and the Bindings", func() {
+			adminInstance, err := sqsBroker.AdminInstance("instance-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(adminInstance.Arn).To(Equal(queueArn))
+			Expect(adminInstance.Attributes.(InstanceAttributes).VisibilityTimeout).To(Equal("30"))
+			Expect(adminInstance.Stats.(InstanceStats).ApproximateNumberOfMessages).To(Equal("2"))
+			Expect(adminInstance.Bindings).To(HaveLen(1))
+			Expect(adminInstance.Bindings[0].BindingID).To(Equal("binding-id"))
+		})
+
+		It("returns the proper error if the Queue does not exist", func() {
+			queue.DescribeError = awssqs.ErrQueueDoesNotExist
+
+			_, err := sqsBroker.AdminInstance("instance-id")
+			Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+		})
+	})
+
+	Describe("ForceDeleteInstance", func() {
+		It("deletes the Bindings and the Queue even if it is protected against deletion", func() {
+			asynch, err := sqsBroker.ForceDeleteInstance("instance-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(asynch).To(BeFalse())
+			Expect(user.DeleteCalled).To(BeTrue())
+			Expect(user.DeleteUserName).To(Equal("cf-binding-id"))
+			Expect(queue.DeleteCalled).To(BeTrue())
+			Expect(queue.DeleteQueueName).To(Equal("cf-instance-id"))
+
+			_, err = store.GetBinding("binding-id")
+			Expect(err).To(Equal(brokerstore.ErrBindingDoesNotExist))
+			_, err = store.GetInstance("instance-id")
+			Expect(err).To(Equal(brokerstore.ErrInstanceDoesNotExist))
+		})
+
+		It("drops the record if the Queue is already gone", func() {
+			queue.DeleteError = awssqs.ErrQueueDoesNotExist
+
+			_, err := sqsBroker.ForceDeleteInstance("instance-id")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = store.GetInstance("instance-id")
+			Expect(err).To(Equal(brokerstore.ErrInstanceDoesNotExist))
+		})
+
+		It("deletes an Instance stuck in an operation", func() {
+			instance.LastOperationState = brokerapi.LastOperationInProgress
+			instance.LastOperationDescription = "Moving queue to region 'eu-west-1'"
+			Expect(store.SaveInstance(instance)).To(Succeed())
+
+			_, err := sqsBroker.Deprovision("instance-id", brokerapi.DeprovisionDetails{ServiceID: "Service-1", PlanID: "Plan-1"}, false)
+			Expect(err).To(BeAssignableToTypeOf(&InstanceBusyError{}))
+
+			_, err = sqsBroker.ForceDeleteInstance("instance-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(queue.DeleteCalled).To(BeTrue())
+
+			_, err = store.GetInstance("instance-id")
+			Expect(err).To(Equal(brokerstore.ErrInstanceDoesNotExist))
+		})
+
+		It("returns the proper error if the Instance is unknown", func() {
+			queue.DeleteError = awssqs.ErrQueueDoesNotExist
+
+			_, err := sqsBroker.ForceDeleteInstance("unknown-instance-id")
+			Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+		})
+	})
+
+	Describe("ForceDeleteBinding", func() {
+		It("deletes the User and the record", func() {
+			err := sqsBroker.ForceDeleteBinding("binding-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(user.DeleteAccessKeyAccessKeyID).To(Equal("old-access-key-id"))
+			Expect(user.DeleteCalled).To(BeTrue())
+
+			_, err = store.GetBinding("binding-id")
+			Expect(err).To(Equal(brokerstore.ErrBindingDoesNotExist))
+		})
+
+		It("drops the record if the User is already gone", func() {
+			user.DescribeError = awsiam.ErrUserDoesNotExist
+
+			err := sqsBroker.ForceDeleteBinding("binding-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(user.DeleteCalled).To(BeFalse())
+
+			_, err = store.GetBinding("binding-id")
+			Expect(err).To(Equal(brokerstore.ErrBindingDoesNotExist))
+		})
+
+		It("keeps the record if the User can not be deleted", func() {
+			user.DeleteError = errors.New("operation failed")
+
+			err := sqsBroker.ForceDeleteBinding("binding-id")
+			Expect(err).To(HaveOccurred())
+
+			_, err = store.GetBinding("binding-id")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns the proper error if the Binding is unknown", func() {
+			err := sqsBroker.ForceDeleteBinding("unknown-binding-id")
+			Expect(err).To(Equal(brokerapi.ErrBindingDoesNotExist))
+		})
+	})
+
+	Describe("RotateBindingKeys", func() {
+		It("replaces the access keys and returns the new credentials", func() {
+			bindingResponse, err := sqsBroker.RotateBindingKeys("binding-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bindingResponse.Credentials).To(Equal(&brokerapi.CredentialsHash{
+				Username: "new-access-key-id",
+				Password: "new-secret-access-key",
+				URI:      "queue-url",
+			}))
+			Expect(user.DeleteAccessKeyAccessKeyID).To(Equal("old-access-key-id"))
+
+			storedBinding, err := store.GetBinding("binding-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(storedBinding.AccessKeyID).To(Equal("new-access-key-id"))
+			Expect(storedBinding.SecretAccessKey).To(BeEmpty())
+		})
+
+		Context("when the binding secrets are stored", func() {
+			BeforeEach(func() {
+				storeBindingSecrets = true
+			})
+
+			It("stores the new secret", func() {
+				_, err := sqsBroker.RotateBindingKeys("binding-id")
+				Expect(err).ToNot(HaveOccurred())
+
+				storedBinding, err := store.GetBinding("binding-id")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(storedBinding.SecretAccessKey).To(Equal("new-secret-access-key"))
+			})
+		})
+
+		It("returns the proper error if the Binding is being created", func() {
+			binding.LastOperationState = brokerapi.LastOperationInProgress
+			Expect(store.SaveBinding(binding)).To(Succeed())
+
+			_, err := sqsBroker.RotateBindingKeys("binding-id")
+			Expect(err).To(BeAssignableToTypeOf(&BindingBusyError{}))
+			Expect(user.CreateAccessKeyCalled).To(BeFalse())
+		})
+
+		It("returns the proper error if the Binding is unknown", func() {
+			_, err := sqsBroker.RotateBindingKeys("unknown-binding-id")
+			Expect(err).To(Equal(brokerapi.ErrBindingDoesNotExist))
+		})
+	})
+
+	Describe("Reconcile", func() {
+		BeforeEach(func() {
+			orphanedBinding := binding
+			orphanedBinding.BindingID = "orphaned-binding-id"
+			orphanedBinding.InstanceID = "unknown-instance-id"
+			Expect(store.SaveBinding(orphanedBinding)).To(Succeed())
+		})
+
+		It("reports the Bindings of unknown Instances", func() {
+			report, err := sqsBroker.Reconcile(false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report).To(Equal(ReconcileReport{
+				InstancesChecked: 1,
+				BindingsChecked:  2,
+				MissingInstances: []string{},
+				MissingBindings:  []string{},
+				OrphanedBindings: []string{"orphaned-binding-id"},
+				Errors:           map[string]string{},
+			}))
+
+			_, err = store.GetBinding("orphaned-binding-id")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when the AWS resources are gone", func() {
+			BeforeEach(func() {
+				queue.DescribeError = awssqs.ErrQueueDoesNotExist
+				user.DescribeError = awsiam.ErrUserDoesNotExist
+			})
+
+			It("drops their records", func() {
+				report, err := sqsBroker.Reconcile(false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(report.MissingInstances).To(Equal([]string{"instance-id"}))
+				Expect(report.MissingBindings).To(Equal([]string{"binding-id", "orphaned-binding-id"}))
+
+				instances, err := store.ListInstances()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(instances).To(BeEmpty())
+				bindings, err := store.ListBindings()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(bindings).To(BeEmpty())
+			})
+
+			It("keeps the records on a dry run", func() {
+				report, err := sqsBroker.Reconcile(true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(report.DryRun).To(BeTrue())
+				Expect(report.MissingInstances).To(Equal([]string{"instance-id"}))
+
+				instances, err := store.ListInstances()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(instances).To(HaveLen(1))
+			})
+		})
+
+		It("reports the resources that can not be described", func() {
+			queue.DescribeError = errors.New("operation failed")
+
+			report, err := sqsBroker.Reconcile(false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.MissingInstances).To(BeEmpty())
+			Expect(report.Errors).To(Equal(map[string]string{"instance-id": "operation failed"}))
+		})
+
+		It("skips the Instances being migrated", func() {
+			instance.LastOperationState = brokerapi.LastOperationInProgress
+			Expect(store.SaveInstance(instance)).To(Succeed())
+			queue.DescribeError = awssqs.ErrQueueDoesNotExist
+
+			report, err := sqsBroker.Reconcile(false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.InstancesChecked).To(Equal(0))
+			Expect(report.MissingInstances).To(BeEmpty())
+		})
+	})
+})
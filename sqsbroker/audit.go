@@ -0,0 +1,69 @@
+package sqsbroker
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/sqs-broker/auditlog"
+)
+
+// audit records the outcome of an action creating or destroying AWS resources.
+// A failure to write the audit log does not fail the action, as the resources already changed.
+func (b *SQSBroker) audit(action string, instanceID string, bindingID string, resources []auditlog.Resource, err error) {
+	event := auditlog.Event{
+		Action:     action,
+		Outcome:    auditlog.OutcomeSuccess,
+		InstanceID: instanceID,
+		BindingID:  bindingID,
+		Resources:  resources,
+	}
+	if err != nil {
+		event.Outcome = auditlog.OutcomeFailure
+		event.Error = err.Error()
+	}
+
+	if err := b.auditLogger.Log(event); err != nil {
+		b.logger.Error("audit-log-failed", err, lager.Data{
+			"action":         action,
+			instanceIDLogKey: instanceID,
+			bindingIDLogKey:  bindingID,
+		})
+	}
+}
+
+func queueResource(queueName string, region string, queueArn string) auditlog.Resource {
+	return auditlog.Resource{Type: auditlog.ResourceSQSQueue, Name: queueName, Region: region, ARN: queueArn}
+}
+
+func topicResource(topicName string, topicArn string) auditlog.Resource {
+	return auditlog.Resource{Type: auditlog.ResourceSNSTopic, Name: topicName, ARN: topicArn}
+}
+
+func userResource(userName string, userArn string) auditlog.Resource {
+	return auditlog.Resource{Type: auditlog.ResourceIAMUser, Name: userName, ARN: userArn}
+}
+
+func accessKeyResource(accessKeyID string) auditlog.Resource {
+	return auditlog.Resource{Type: auditlog.ResourceIAMAccessKey, ID: accessKeyID}
+}
+
+func policyResource(policyArn string) auditlog.Resource {
+	return auditlog.Resource{Type: auditlog.ResourceIAMPolicy, ARN: policyArn}
+}
+
+// queueArnFromURL builds the Queue ARN from its URL (<endpoint>/<account-id>/<queue-name>), as creating a Queue only returns the URL
+func queueArnFromURL(region string, queueURL string) string {
+	parsedURL, err := url.Parse(queueURL)
+	if err != nil {
+		return ""
+	}
+
+	pathParts := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	if len(pathParts) < 2 {
+		return ""
+	}
+
+	return "arn:aws:sqs:" + region + ":" + pathParts[len(pathParts)-2] + ":" + pathParts[len(pathParts)-1]
+}
@@ -4,51 +4,172 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/frodenas/brokerapi"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pivotal-golang/lager"
 
+	"github.com/cf-platform-eng/sqs-broker/auditlog"
 	"github.com/cf-platform-eng/sqs-broker/awsiam"
+	"github.com/cf-platform-eng/sqs-broker/awssns"
 	"github.com/cf-platform-eng/sqs-broker/awssqs"
+	"github.com/cf-platform-eng/sqs-broker/brokerstore"
 )
 
 const instanceIDLogKey = "instance-id"
 const bindingIDLogKey = "binding-id"
 const detailsLogKey = "details"
+const contextLogKey = "context"
 const acceptsIncompleteLogKey = "acceptsIncomplete"
 
+const archiveMessageRetentionPeriod = "1209600"
+const archiveReceiveBatchSize = 10
+
+// ReceiveMessages waits up to a second for messages, so moving messages waits about as long as the
+// longest SQS delivery delay (15 minutes) for delayed and in flight messages before giving up
+const maxEmptyReceives = 900
+
+const bindingVerificationInterval = 2 * time.Second
+const defaultBindingVerificationTimeout = 60
+
+type BindingQueueFactory func(region string, accessKeyID string, secretAccessKey string) awssqs.Queue
+
+// BrokerError is implemented by the errors the broker API answers with a specific status,
+// Kind returns the OSBAPI error code or InvalidRequestErrorKind
+type BrokerError interface {
+	error
+	Kind() string
+}
+
+const InvalidRequestErrorKind = "InvalidRequest"
+const ConcurrencyErrorKind = "ConcurrencyError"
+const QueueNotEmptyErrorKind = "QueueNotEmpty"
+const SecretNotAvailableErrorKind = "SecretNotAvailable"
+const BindingLimitMetErrorKind = "BindingLimitMet"
+
+type QueueNotEmptyError struct {
+	QueueName          string
+	Messages           int
+	MessagesNotVisible int
+}
+
+func (e *QueueNotEmptyError) Error() string {
+	return fmt.Sprintf("Queue '%s' is protected against deletion and still holds %d messages (%d in flight), use force=true to delete it anyway", e.QueueName, e.Messages, e.MessagesNotVisible)
+}
+
+func (e *QueueNotEmptyError) Kind() string {
+	return QueueNotEmptyErrorKind
+}
+
+type MessagesInFlightError struct {
+	QueueName          string
+	MessagesNotVisible int
+}
+
+func (e *MessagesInFlightError) Error() string {
+	return fmt.Sprintf("Queue '%s' has %d messages in flight that can not be archived, stop its consumers and try again", e.QueueName, e.MessagesNotVisible)
+}
+
+func (e *MessagesInFlightError) Kind() string {
+	return QueueNotEmptyErrorKind
+}
+
+type SecretNotAvailableError struct {
+	BindingID string
+}
+
+func (e *SecretNotAvailableError) Error() string {
+	return fmt.Sprintf("The secret access key of Binding '%s' is not available anymore, rotate the binding keys to get new credentials", e.BindingID)
+}
+
+func (e *SecretNotAvailableError) Kind() string {
+	return SecretNotAvailableErrorKind
+}
+
+type PlatformNotAllowedError struct {
+	PlanID   string
+	Platform string
+}
+
+func (e *PlatformNotAllowedError) Error() string {
+	return fmt.Sprintf("Service Plan '%s' is not available on platform '%s'", e.PlanID, e.Platform)
+}
+
+func (e *PlatformNotAllowedError) Kind() string {
+	return InvalidRequestErrorKind
+}
+
+type bindTarget struct {
+	topic       bool
+	resourceArn string
+	uri         string
+}
+
 type SQSBroker struct {
-	sqsPrefix                    string
-	allowUserProvisionParameters bool
-	allowUserUpdateParameters    bool
-	catalog                      Catalog
-	queue                        awssqs.Queue
-	user                         awsiam.User
-	logger                       lager.Logger
+	sqsPrefix                  string
+	reloadable                 reloadableConfig
+	reloadableMutex            sync.RWMutex
+	storeBindingSecrets        bool
+	bindingVerificationTimeout time.Duration
+	region                     string
+	allowedRegions             []string
+	organizationAccounts       map[string]AccountRole
+	quotas                     Quotas
+	allowPlanMigration         bool
+	persistentRecords          bool
+	clients                    *clientPool
+	store                      brokerstore.Store
+	queuePolicyLocks           map[string]*sync.Mutex
+	queuePolicyLocksMutex      sync.Mutex
+	pendingSecrets             map[string]string
+	pendingSecretsMutex        sync.Mutex
+	bindingQueueFactory        BindingQueueFactory
+	auditLogger                auditlog.Logger
+	logger                     lager.Logger
 }
 
 func New(
 	config Config,
-	queue awssqs.Queue,
-	user awsiam.User,
+	clientsFactory ClientsFactory,
+	store brokerstore.Store,
+	bindingQueueFactory BindingQueueFactory,
+	auditLogger auditlog.Logger,
 	logger lager.Logger,
 ) *SQSBroker {
+	bindingVerificationTimeout := config.BindingVerificationTimeout
+	if bindingVerificationTimeout == 0 {
+		bindingVerificationTimeout = defaultBindingVerificationTimeout
+	}
+
 	return &SQSBroker{
-		sqsPrefix:                    config.SQSPrefix,
-		allowUserProvisionParameters: config.AllowUserProvisionParameters,
-		allowUserUpdateParameters:    config.AllowUserUpdateParameters,
-		catalog:                      config.Catalog,
-		queue:                        queue,
-		user:                         user,
-		logger:                       logger.Session("broker"),
+		sqsPrefix:                  config.SQSPrefix,
+		reloadable:                 newReloadableConfig(config),
+		storeBindingSecrets:        config.StoreBindingSecrets,
+		bindingVerificationTimeout: time.Duration(bindingVerificationTimeout) * time.Second,
+		region:                     config.Region,
+		allowedRegions:             config.AllowedRegions,
+		organizationAccounts:       config.OrganizationAccounts,
+		quotas:                     config.Quotas,
+		allowPlanMigration:         config.AllowPlanMigration,
+		persistentRecords:          config.StateFile != "",
+		clients:                    newClientPool(clientsFactory),
+		store:                      store,
+		queuePolicyLocks:           map[string]*sync.Mutex{},
+		pendingSecrets:             map[string]string{},
+		bindingQueueFactory:        bindingQueueFactory,
+		auditLogger:                auditLogger,
+		logger:                     logger.Session("broker"),
 	}
 }
 
 func (b *SQSBroker) Services() brokerapi.CatalogResponse {
 	catalogResponse := brokerapi.CatalogResponse{}
 
-	brokerCatalog, err := json.Marshal(b.catalog)
+	brokerCatalog, err := json.Marshal(b.currentConfig().catalog)
 	if err != nil {
 		b.logger.Error("marshal-error", err)
 		return catalogResponse
@@ -66,71 +187,228 @@ func (b *SQSBroker) Services() brokerapi.CatalogResponse {
 }
 
 func (b *SQSBroker) Provision(instanceID string, details brokerapi.ProvisionDetails, acceptsIncomplete bool) (brokerapi.ProvisioningResponse, bool, error) {
+	return b.ProvisionWithContext(instanceID, details, nil, acceptsIncomplete)
+}
+
+func (b *SQSBroker) ProvisionWithContext(instanceID string, details brokerapi.ProvisionDetails, requestContext map[string]interface{}, acceptsIncomplete bool) (brokerapi.ProvisioningResponse, bool, error) {
 	b.logger.Debug("provision", lager.Data{
 		instanceIDLogKey:        instanceID,
 		detailsLogKey:           details,
+		contextLogKey:           requestContext,
 		acceptsIncompleteLogKey: acceptsIncomplete,
 	})
 
 	provisioningResponse := brokerapi.ProvisioningResponse{}
+	config := b.currentConfig()
 
 	provisionParameters := ProvisionParameters{}
-	if b.allowUserProvisionParameters {
+	if config.allowUserProvisionParameters {
 		if err := mapstructure.Decode(details.Parameters, &provisionParameters); err != nil {
 			return provisioningResponse, false, err
 		}
+
+		if err := validateSourceArns(provisionParameters.SourceArns); err != nil {
+			return provisioningResponse, false, err
+		}
 	}
 
-	servicePlan, ok := b.catalog.FindServicePlan(details.PlanID)
+	servicePlan, ok := config.catalog.FindServicePlan(details.PlanID)
 	if !ok {
 		return provisioningResponse, false, fmt.Errorf("Service Plan '%s' not found", details.PlanID)
 	}
 
-	createQueueDetails := b.createQueueDetails(instanceID, servicePlan, provisionParameters, details)
-	if _, err := b.queue.Create(b.queueName(instanceID), *createQueueDetails); err != nil {
+	if err := b.checkPlatform(servicePlan, requestContext, details.OrganizationGUID, details.SpaceGUID); err != nil {
+		return provisioningResponse, false, err
+	}
+
+	region, err := b.provisionRegion(servicePlan, provisionParameters)
+	if err != nil {
+		return provisioningResponse, false, err
+	}
+
+	organizationContext, err := b.requestContext(requestContext, details.OrganizationGUID, details.SpaceGUID)
+	if err != nil {
+		return provisioningResponse, false, err
+	}
+	accountRole := b.provisionAccountRole(servicePlan, organizationContext.OrganizationGUID)
+
+	if err := b.checkInstanceQuotas(instanceID, details.PlanID, organizationContext); err != nil {
+		return provisioningResponse, false, err
+	}
+
+	clients := b.awsClients(region, accountRole)
+	queueURL := ""
+	if b.isTopicService(details.ServiceID) {
+		if err := b.createTopic(clients, instanceID, servicePlan); err != nil {
+			return provisioningResponse, false, err
+		}
+	} else {
+		createQueueDetails := b.createQueueDetails(instanceID, servicePlan, provisionParameters, details)
+
+		// A templated Policy refers to the Queue ARN, so it can only be rendered once the Queue exists
+		policyTemplate := ""
+		if isPolicyTemplate(createQueueDetails.Policy) {
+			policyTemplate = createQueueDetails.Policy
+			createQueueDetails.Policy = ""
+		}
+
+		queueURL, err = clients.queue.Create(b.queueName(instanceID), *createQueueDetails)
+		b.audit("create-queue", instanceID, "", []auditlog.Resource{queueResource(b.queueName(instanceID), clients.region, queueArnFromURL(clients.region, queueURL))}, err)
+		if err != nil {
+			return provisioningResponse, false, err
+		}
+
+		if policyTemplate != "" {
+			if err := b.applyQueuePolicyTemplate(clients.queue, b.queueName(instanceID), policyTemplate, provisionParameters.SourceArns); err != nil {
+				b.logger.Error("apply-queue-policy-failed", err, lager.Data{instanceIDLogKey: instanceID})
+				b.rollbackProvision(clients, instanceID, details.ServiceID, queueURL)
+				return provisioningResponse, false, err
+			}
+		}
+	}
+
+	instance := brokerstore.Instance{
+		InstanceID:       instanceID,
+		ServiceID:        details.ServiceID,
+		PlanID:           details.PlanID,
+		OrganizationGUID: details.OrganizationGUID,
+		SpaceGUID:        details.SpaceGUID,
+		Region:           region,
+		RoleArn:          accountRole.RoleArn,
+		ExternalID:       accountRole.ExternalID,
+		Context:          requestContext,
+	}
+	if config.allowUserProvisionParameters {
+		instance.Parameters = details.Parameters
+	}
+	if err := b.store.SaveInstance(instance); err != nil {
+		// Without a record the broker could not manage nor count the instance, so do not leave it behind
+		b.logger.Error("save-instance-failed", err, lager.Data{instanceIDLogKey: instanceID})
+		b.rollbackProvision(clients, instanceID, details.ServiceID, queueURL)
 		return provisioningResponse, false, err
 	}
 
 	return provisioningResponse, false, nil
 }
 
+func (b *SQSBroker) rollbackProvision(clients awsClients, instanceID string, serviceID string, queueURL string) {
+	if b.isTopicService(serviceID) {
+		err := clients.topic.Delete(b.topicName(instanceID))
+		b.audit("delete-topic", instanceID, "", []auditlog.Resource{topicResource(b.topicName(instanceID), "")}, err)
+		if err != nil {
+			b.logger.Error("delete-topic-failed", err, lager.Data{instanceIDLogKey: instanceID})
+		}
+		return
+	}
+
+	err := clients.queue.Delete(b.queueName(instanceID))
+	b.audit("delete-queue", instanceID, "", []auditlog.Resource{queueResource(b.queueName(instanceID), clients.region, queueArnFromURL(clients.region, queueURL))}, err)
+	if err != nil {
+		b.logger.Error("delete-queue-failed", err, lager.Data{instanceIDLogKey: instanceID})
+	}
+}
+
 func (b *SQSBroker) Update(instanceID string, details brokerapi.UpdateDetails, acceptsIncomplete bool) (bool, error) {
+	_, asynch, err := b.UpdateWithContext(instanceID, details, nil, acceptsIncomplete)
+	return asynch, err
+}
+
+func (b *SQSBroker) UpdateWithContext(instanceID string, details brokerapi.UpdateDetails, requestContext map[string]interface{}, acceptsIncomplete bool) (UpdateResponse, bool, error) {
 	b.logger.Debug("update", lager.Data{
 		instanceIDLogKey:        instanceID,
 		detailsLogKey:           details,
+		contextLogKey:           requestContext,
 		acceptsIncompleteLogKey: acceptsIncomplete,
 	})
 
+	config := b.currentConfig()
+
 	updateParameters := UpdateParameters{}
-	if b.allowUserUpdateParameters {
+	newParameters := map[string]interface{}{}
+	if config.allowUserUpdateParameters {
 		if err := mapstructure.Decode(details.Parameters, &updateParameters); err != nil {
-			return false, err
+			return UpdateResponse{}, false, err
 		}
+
+		if err := validateSourceArns(updateParameters.SourceArns); err != nil {
+			return UpdateResponse{}, false, err
+		}
+		newParameters = details.Parameters
 	}
 
-	service, ok := b.catalog.FindService(details.ServiceID)
+	service, ok := config.catalog.FindService(details.ServiceID)
 	if !ok {
-		return false, fmt.Errorf("Service '%s' not found", details.ServiceID)
+		return UpdateResponse{}, false, fmt.Errorf("Service '%s' not found", details.ServiceID)
 	}
 
 	if !service.PlanUpdateable {
-		return false, brokerapi.ErrInstanceNotUpdateable
+		return UpdateResponse{}, false, brokerapi.ErrInstanceNotUpdateable
 	}
 
-	servicePlan, ok := b.catalog.FindServicePlan(details.PlanID)
+	servicePlan, ok := config.catalog.FindServicePlan(details.PlanID)
 	if !ok {
-		return false, fmt.Errorf("Service Plan '%s' not found", details.PlanID)
+		return UpdateResponse{}, false, fmt.Errorf("Service Plan '%s' not found", details.PlanID)
 	}
 
-	modifyQueueDetails := b.modifyQueueDetails(instanceID, servicePlan, updateParameters, details)
-	if err := b.queue.Modify(b.queueName(instanceID), *modifyQueueDetails); err != nil {
-		if err == awssqs.ErrQueueDoesNotExist {
-			return false, brokerapi.ErrInstanceDoesNotExist
+	instance, stored, err := b.findInstance(instanceID, details.PreviousValues.OrganizationID, details.PreviousValues.SpaceID)
+	if err != nil {
+		return UpdateResponse{}, false, err
+	}
+
+	// The Queue attributes are computed from the plan, the stored user parameters and the new ones,
+	// so attributes no longer set anywhere are reset
+	queueParameters := UpdateParameters{}
+	if config.allowUserProvisionParameters || config.allowUserUpdateParameters {
+		if err := mapstructure.Decode(mergeParameters(instance.Parameters, newParameters), &queueParameters); err != nil {
+			return UpdateResponse{}, false, err
+		}
+	}
+
+	if requestContext == nil {
+		requestContext = instance.Context
+	}
+	if err := b.checkPlatform(servicePlan, requestContext, instance.OrganizationGUID, instance.SpaceGUID); err != nil {
+		return UpdateResponse{}, false, err
+	}
+
+	if err := b.checkInstanceNotBusy(instanceID); err != nil {
+		return UpdateResponse{}, false, err
+	}
+
+	if err := b.checkAccount(servicePlan, instance); err != nil {
+		return UpdateResponse{}, false, err
+	}
+
+	if err := b.checkRegion(servicePlan, instance); err != nil {
+		if !b.allowPlanMigration || service.IsTopic() {
+			return UpdateResponse{}, false, err
+		}
+		return b.startQueueMigration(instance, servicePlan, queueParameters, details, newParameters, requestContext, acceptsIncomplete)
+	}
+
+	updateResponse := UpdateResponse{}
+	if service.IsTopic() {
+		if err := b.modifyTopic(instanceID, servicePlan); err != nil {
+			return UpdateResponse{}, false, err
+		}
+	} else {
+		// Instances without a record (in-memory records after a restart) have no stored parameters, so their attributes are not reset
+		changes, err := b.modifyQueue(instanceID, servicePlan, queueParameters, details, !stored)
+		if err != nil {
+			return UpdateResponse{}, false, err
+		}
+		updateResponse.Description = describeQueueAttributeChanges(changes)
+
+		if err := b.updateInstanceSubscriptions(&instance, updateParameters); err != nil {
+			return UpdateResponse{}, false, err
 		}
-		return false, err
 	}
 
-	return false, nil
+	if err := b.updateInstance(instance, details, newParameters, requestContext); err != nil {
+		return UpdateResponse{}, false, err
+	}
+
+	return updateResponse, false, nil
 }
 
 func (b *SQSBroker) Deprovision(instanceID string, details brokerapi.DeprovisionDetails, acceptsIncomplete bool) (bool, error) {
@@ -140,84 +418,165 @@ func (b *SQSBroker) Deprovision(instanceID string, details brokerapi.Deprovision
 		acceptsIncompleteLogKey: acceptsIncomplete,
 	})
 
-	if err := b.queue.Delete(b.queueName(instanceID)); err != nil {
-		if err == awssqs.ErrQueueDoesNotExist {
-			return false, brokerapi.ErrInstanceDoesNotExist
-		}
-		return false, err
+	if b.isTopicService(details.ServiceID) {
+		return false, b.deleteTopic(instanceID)
 	}
 
-	return false, nil
+	return b.deleteQueue(instanceID, details, false, acceptsIncomplete)
 }
 
-func (b *SQSBroker) Bind(instanceID, bindingID string, details brokerapi.BindDetails) (brokerapi.BindingResponse, error) {
-	var err error
-	var accessKeyID, secretAccessKey string
-	var policyARN string
-
-	b.logger.Debug("bind", lager.Data{
+func (b *SQSBroker) GetInstance(instanceID string) (InstanceResponse, error) {
+	b.logger.Debug("get-instance", lager.Data{
 		instanceIDLogKey: instanceID,
-		bindingIDLogKey:  bindingID,
-		detailsLogKey:    details,
 	})
 
-	bindingResponse := brokerapi.BindingResponse{}
+	instanceResponse := InstanceResponse{}
 
-	service, ok := b.catalog.FindService(details.ServiceID)
-	if !ok {
-		return bindingResponse, fmt.Errorf("Service '%s' not found", details.ServiceID)
+	// Without a record the service and plan of the Queue are unknown, so the Instance is reported as missing
+	instance, err := b.store.GetInstance(instanceID)
+	if err != nil {
+		if err == brokerstore.ErrInstanceDoesNotExist {
+			return instanceResponse, brokerapi.ErrInstanceDoesNotExist
+		}
+		return instanceResponse, err
 	}
+	instance.InstanceID = instanceID
 
-	if !service.Bindable {
-		return bindingResponse, brokerapi.ErrInstanceNotBindable
+	if b.isTopicService(instance.ServiceID) {
+		return b.getTopicInstance(instance)
 	}
 
-	queueDetails, err := b.queue.Describe(b.queueName(instanceID))
+	clients := b.storedInstanceClients(instance)
+	queueDetails, err := clients.queue.Describe(b.queueName(instanceID))
 	if err != nil {
 		if err == awssqs.ErrQueueDoesNotExist {
-			return bindingResponse, brokerapi.ErrInstanceDoesNotExist
+			return instanceResponse, brokerapi.ErrInstanceDoesNotExist
 		}
-		return bindingResponse, err
+		return instanceResponse, err
+	}
+
+	instanceResponse.ServiceID = instance.ServiceID
+	instanceResponse.PlanID = instance.PlanID
+	instanceResponse.Parameters = instance.Parameters
+	instanceResponse.Attributes = InstanceAttributes{
+		QueueURL:                      queueDetails.QueueURL,
+		QueueArn:                      queueDetails.QueueArn,
+		DelaySeconds:                  queueDetails.DelaySeconds,
+		MaximumMessageSize:            queueDetails.MaximumMessageSize,
+		MessageRetentionPeriod:        queueDetails.MessageRetentionPeriod,
+		Policy:                        queueDetails.Policy,
+		ReceiveMessageWaitTimeSeconds: queueDetails.ReceiveMessageWaitTimeSeconds,
+		VisibilityTimeout:             queueDetails.VisibilityTimeout,
+	}
+	instanceResponse.Stats = InstanceStats{
+		ApproximateNumberOfMessages:           queueDetails.ApproximateNumberOfMessages,
+		ApproximateNumberOfMessagesNotVisible: queueDetails.ApproximateNumberOfMessagesNotVisible,
+		ApproximateNumberOfMessagesDelayed:    queueDetails.ApproximateNumberOfMessagesDelayed,
+		CreatedTimestamp:                      queueDetails.CreatedTimestamp,
+		LastModifiedTimestamp:                 queueDetails.LastModifiedTimestamp,
+	}
+
+	return instanceResponse, nil
+}
+
+func (b *SQSBroker) ForceDeprovision(instanceID string, details brokerapi.DeprovisionDetails, acceptsIncomplete bool) (bool, error) {
+	b.logger.Debug("force-deprovision", lager.Data{
+		instanceIDLogKey:        instanceID,
+		detailsLogKey:           details,
+		acceptsIncompleteLogKey: acceptsIncomplete,
+	})
+
+	if b.isTopicService(details.ServiceID) {
+		return false, b.deleteTopic(instanceID)
 	}
 
-	if _, err = b.user.Create(b.userName(bindingID)); err != nil {
+	return b.deleteQueue(instanceID, details, true, acceptsIncomplete)
+}
+
+func (b *SQSBroker) Bind(instanceID, bindingID string, details brokerapi.BindDetails) (brokerapi.BindingResponse, error) {
+	return b.bind(instanceID, bindingID, details, nil)
+}
+
+func (b *SQSBroker) bind(instanceID, bindingID string, details brokerapi.BindDetails, requestContext map[string]interface{}) (brokerapi.BindingResponse, error) {
+	b.logger.Debug("bind", lager.Data{
+		instanceIDLogKey: instanceID,
+		bindingIDLogKey:  bindingID,
+		detailsLogKey:    details,
+		contextLogKey:    requestContext,
+	})
+
+	bindingResponse := brokerapi.BindingResponse{}
+
+	if err := b.checkBindingQuotas(instanceID, bindingID); err != nil {
 		return bindingResponse, err
 	}
-	defer func() {
-		if err != nil {
-			if policyARN != "" {
-				b.user.DeletePolicy(policyARN)
-			}
-			if accessKeyID != "" {
-				b.user.DeleteAccessKey(b.userName(bindingID), accessKeyID)
-			}
-			b.user.Delete(b.userName(bindingID))
-		}
-	}()
 
-	accessKeyID, secretAccessKey, err = b.user.CreateAccessKey(b.userName(bindingID))
+	target, bindParameters, err := b.describeBindTarget(instanceID, details)
 	if err != nil {
 		return bindingResponse, err
 	}
 
-	policyARN, err = b.user.CreatePolicy(b.policyName(bindingID), "Allow", "sqs:*", queueDetails.QueueArn)
+	binding := b.newBinding(instanceID, bindingID, details, requestContext)
+
+	accessKeyID, secretAccessKey, err := b.createBindingResources(&binding, target, bindParameters)
 	if err != nil {
 		return bindingResponse, err
 	}
 
-	if err = b.user.AttachUserPolicy(b.userName(bindingID), policyARN); err != nil {
+	binding.AccessKeyID = accessKeyID
+	if b.storeBindingSecrets {
+		binding.SecretAccessKey = secretAccessKey
+	}
+	if err = b.store.SaveBinding(binding); err != nil {
+		b.deleteBindingResources(binding)
 		return bindingResponse, err
 	}
 
 	bindingResponse.Credentials = &brokerapi.CredentialsHash{
 		Username: accessKeyID,
 		Password: secretAccessKey,
-		URI:      queueDetails.QueueURL,
+		URI:      target.uri,
 	}
 
 	return bindingResponse, nil
 }
 
+func (b *SQSBroker) AsyncBind(instanceID, bindingID string, details brokerapi.BindDetails, requestContext map[string]interface{}, acceptsIncomplete bool) (brokerapi.BindingResponse, bool, error) {
+	if !acceptsIncomplete {
+		bindingResponse, err := b.bind(instanceID, bindingID, details, requestContext)
+		return bindingResponse, false, err
+	}
+
+	b.logger.Debug("async-bind", lager.Data{
+		instanceIDLogKey: instanceID,
+		bindingIDLogKey:  bindingID,
+		detailsLogKey:    details,
+		contextLogKey:    requestContext,
+	})
+
+	bindingResponse := brokerapi.BindingResponse{}
+
+	if err := b.checkBindingQuotas(instanceID, bindingID); err != nil {
+		return bindingResponse, false, err
+	}
+
+	target, bindParameters, err := b.describeBindTarget(instanceID, details)
+	if err != nil {
+		return bindingResponse, false, err
+	}
+
+	binding := b.newBinding(instanceID, bindingID, details, requestContext)
+	binding.LastOperationState = brokerapi.LastOperationInProgress
+	binding.LastOperationDescription = "Creating binding"
+	if err = b.store.SaveBinding(binding); err != nil {
+		return bindingResponse, false, err
+	}
+
+	go b.completeBind(binding, target, bindParameters)
+
+	return bindingResponse, true, nil
+}
+
 func (b *SQSBroker) Unbind(instanceID, bindingID string, details brokerapi.UnbindDetails) error {
 	b.logger.Debug("unbind", lager.Data{
 		instanceIDLogKey: instanceID,
@@ -225,57 +584,998 @@ func (b *SQSBroker) Unbind(instanceID, bindingID string, details brokerapi.Unbin
 		detailsLogKey:    details,
 	})
 
-	accessKeys, err := b.user.ListAccessKeys(b.userName(bindingID))
-	if err != nil {
+	binding, err := b.store.GetBinding(bindingID)
+	if err != nil && err != brokerstore.ErrBindingDoesNotExist {
 		return err
 	}
+	binding.BindingID = bindingID
+	binding.InstanceID = instanceID
 
-	for _, accessKey := range accessKeys {
-		if err := b.user.DeleteAccessKey(b.userName(bindingID), accessKey); err != nil {
-			return err
+	if err := b.deleteBindingResources(binding); err != nil {
+		return err
+	}
+
+	if err := b.store.DeleteBinding(bindingID); err != nil && err != brokerstore.ErrBindingDoesNotExist {
+		return err
+	}
+	b.takePendingSecret(bindingID)
+
+	return nil
+}
+
+func (b *SQSBroker) AsyncUnbind(instanceID, bindingID string, details brokerapi.UnbindDetails, acceptsIncomplete bool) (bool, error) {
+	if !acceptsIncomplete {
+		return false, b.Unbind(instanceID, bindingID, details)
+	}
+
+	b.logger.Debug("async-unbind", lager.Data{
+		instanceIDLogKey: instanceID,
+		bindingIDLogKey:  bindingID,
+		detailsLogKey:    details,
+	})
+
+	binding, err := b.store.GetBinding(bindingID)
+	if err != nil {
+		if err != brokerstore.ErrBindingDoesNotExist {
+			return false, err
+		}
+		binding = brokerstore.Binding{
+			BindingID:  bindingID,
+			InstanceID: instanceID,
+			ServiceID:  details.ServiceID,
+			PlanID:     details.PlanID,
 		}
 	}
 
-	userPolicies, err := b.user.ListAttachedUserPolicies(b.userName(bindingID))
+	binding.LastOperationState = brokerapi.LastOperationInProgress
+	binding.LastOperationDescription = "Deleting binding"
+	if err = b.store.SaveBinding(binding); err != nil {
+		return false, err
+	}
+
+	go b.completeUnbind(binding)
+
+	return true, nil
+}
+
+func (b *SQSBroker) GetBinding(instanceID, bindingID string) (BindingResponse, error) {
+	b.logger.Debug("get-binding", lager.Data{
+		instanceIDLogKey: instanceID,
+		bindingIDLogKey:  bindingID,
+	})
+
+	bindingResponse := BindingResponse{}
+
+	clients, err := b.instanceClients(instanceID)
 	if err != nil {
-		return err
+		return bindingResponse, err
 	}
 
-	for _, userPolicy := range userPolicies {
-		if err := b.user.DetachUserPolicy(b.userName(bindingID), userPolicy); err != nil {
-			return err
+	if _, err := clients.user.Describe(b.userName(bindingID)); err != nil {
+		if err == awsiam.ErrUserDoesNotExist {
+			return bindingResponse, brokerapi.ErrBindingDoesNotExist
 		}
+		return bindingResponse, err
+	}
 
-		if err := b.user.DeletePolicy(userPolicy); err != nil {
-			return err
+	binding, err := b.store.GetBinding(bindingID)
+	if err != nil {
+		if err != brokerstore.ErrBindingDoesNotExist {
+			return bindingResponse, err
+		}
+		binding = brokerstore.Binding{
+			BindingID:  bindingID,
+			InstanceID: instanceID,
 		}
 	}
 
-	if err := b.user.Delete(b.userName(bindingID)); err != nil {
-		return err
+	if binding.InstanceID != instanceID {
+		return bindingResponse, brokerapi.ErrBindingDoesNotExist
 	}
 
-	return nil
+	if binding.LastOperationState != "" && binding.LastOperationState != brokerapi.LastOperationSucceeded {
+		return bindingResponse, brokerapi.ErrBindingDoesNotExist
+	}
+
+	target, err := b.describeTarget(instanceID, b.isTopicService(binding.ServiceID))
+	if err != nil {
+		return bindingResponse, err
+	}
+
+	// IAM does not allow to read back a Secret Access Key, and fetching a Binding must not invalidate the
+	// credentials in use, so new ones are only issued by RotateBindingKeys
+	secretAccessKey := binding.SecretAccessKey
+	if secretAccessKey == "" {
+		secretAccessKey = b.takePendingSecret(bindingID)
+	}
+	if secretAccessKey == "" {
+		return bindingResponse, &SecretNotAvailableError{BindingID: bindingID}
+	} else if !b.storeBindingSecrets && binding.SecretAccessKey != "" {
+		// State files written by previous versions kept the secret of asynchronous bindings until they were fetched
+		binding.SecretAccessKey = ""
+		if err = b.store.SaveBinding(binding); err != nil {
+			return bindingResponse, err
+		}
+	}
+
+	bindingResponse.Credentials = &brokerapi.CredentialsHash{
+		Username: binding.AccessKeyID,
+		Password: secretAccessKey,
+		URI:      target.uri,
+	}
+	bindingResponse.Parameters = binding.Parameters
+
+	return bindingResponse, nil
 }
 
-func (b *SQSBroker) LastOperation(instanceID string) (brokerapi.LastOperationResponse, error) {
-	b.logger.Debug("last-operation", lager.Data{
+func (b *SQSBroker) BindingLastOperation(instanceID, bindingID string) (brokerapi.LastOperationResponse, error) {
+	b.logger.Debug("binding-last-operation", lager.Data{
 		instanceIDLogKey: instanceID,
+		bindingIDLogKey:  bindingID,
 	})
 
-	return brokerapi.LastOperationResponse{}, errors.New("This broker does not support LastOperation")
-}
+	lastOperationResponse := brokerapi.LastOperationResponse{}
 
-func (b *SQSBroker) queueName(instanceID string) string {
-	return fmt.Sprintf("%s-%s", b.sqsPrefix, instanceID)
-}
+	binding, err := b.store.GetBinding(bindingID)
+	if err != nil {
+		if err == brokerstore.ErrBindingDoesNotExist {
+			return lastOperationResponse, brokerapi.ErrBindingDoesNotExist
+		}
+		return lastOperationResponse, err
+	}
 
-func (b *SQSBroker) userName(bindingID string) string {
-	return fmt.Sprintf("%s-%s", b.sqsPrefix, bindingID)
+	if binding.InstanceID != instanceID {
+		return lastOperationResponse, brokerapi.ErrBindingDoesNotExist
+	}
+
+	lastOperationResponse.State = binding.LastOperationState
+	lastOperationResponse.Description = binding.LastOperationDescription
+	if lastOperationResponse.State == "" {
+		lastOperationResponse.State = brokerapi.LastOperationSucceeded
+	}
+
+	return lastOperationResponse, nil
 }
 
-func (b *SQSBroker) policyName(bindingID string) string {
-	return fmt.Sprintf("%s-%s", b.sqsPrefix, bindingID)
+func (b *SQSBroker) describeBindTarget(instanceID string, details brokerapi.BindDetails) (bindTarget, BindParameters, error) {
+	bindParameters := BindParameters{}
+	if err := mapstructure.Decode(details.Parameters, &bindParameters); err != nil {
+		return bindTarget{}, bindParameters, err
+	}
+
+	service, ok := b.currentConfig().catalog.FindService(details.ServiceID)
+	if !ok {
+		return bindTarget{}, bindParameters, fmt.Errorf("Service '%s' not found", details.ServiceID)
+	}
+
+	if !service.Bindable {
+		return bindTarget{}, bindParameters, brokerapi.ErrInstanceNotBindable
+	}
+
+	if service.IsTopic() && bindParameters.SubscribeToTopic != "" {
+		return bindTarget{}, bindParameters, errors.New("Only queues can be subscribed to a topic")
+	}
+
+	if err := b.checkInstanceNotBusy(instanceID); err != nil {
+		return bindTarget{}, bindParameters, err
+	}
+
+	target, err := b.describeTarget(instanceID, service.IsTopic())
+	if err != nil {
+		return target, bindParameters, err
+	}
+
+	return target, bindParameters, nil
+}
+
+func (b *SQSBroker) describeTarget(instanceID string, topic bool) (bindTarget, error) {
+	clients, err := b.instanceClients(instanceID)
+	if err != nil {
+		return bindTarget{}, err
+	}
+
+	if topic {
+		topicDetails, err := clients.topic.Describe(b.topicName(instanceID))
+		if err != nil {
+			if err == awssns.ErrTopicDoesNotExist {
+				return bindTarget{}, brokerapi.ErrInstanceDoesNotExist
+			}
+			return bindTarget{}, err
+		}
+
+		return bindTarget{
+			topic:       true,
+			resourceArn: topicDetails.TopicArn,
+			uri:         topicDetails.TopicArn,
+		}, nil
+	}
+
+	queueDetails, err := clients.queue.Describe(b.queueName(instanceID))
+	if err != nil {
+		if err == awssqs.ErrQueueDoesNotExist {
+			return bindTarget{}, brokerapi.ErrInstanceDoesNotExist
+		}
+		return bindTarget{}, err
+	}
+
+	return bindTarget{
+		resourceArn: queueDetails.QueueArn,
+		uri:         queueDetails.QueueURL,
+	}, nil
+}
+
+func (b *SQSBroker) newBinding(instanceID, bindingID string, details brokerapi.BindDetails, requestContext map[string]interface{}) brokerstore.Binding {
+	return brokerstore.Binding{
+		BindingID:  bindingID,
+		InstanceID: instanceID,
+		ServiceID:  details.ServiceID,
+		PlanID:     details.PlanID,
+		AppGUID:    details.AppGUID,
+		Parameters: details.Parameters,
+		Context:    requestContext,
+	}
+}
+
+func (b *SQSBroker) createBindingResources(binding *brokerstore.Binding, target bindTarget, bindParameters BindParameters) (string, string, error) {
+	action := "sqs:*"
+	if target.topic {
+		action = "sns:*"
+	}
+
+	clients, err := b.instanceClients(binding.InstanceID)
+	if err != nil {
+		return "", "", err
+	}
+
+	accessKeyID, secretAccessKey, err := b.createBindingUser(clients.user, binding.InstanceID, binding.BindingID, b.bindingUserPath(*binding), action, target.resourceArn)
+	if err != nil {
+		return "", "", err
+	}
+
+	if bindParameters.SubscribeToTopic == "" {
+		return accessKeyID, secretAccessKey, nil
+	}
+
+	subscriptionDetails, err := b.subscriptionDetails(bindParameters.RawMessageDelivery, bindParameters.FilterPolicy)
+	if err == nil {
+		var subscription brokerstore.Subscription
+		subscription, err = b.subscribeQueue(binding.InstanceID, b.bindingSubscriptionSid(binding.BindingID), bindParameters.SubscribeToTopic, subscriptionDetails)
+		binding.TopicInstanceID = subscription.TopicInstanceID
+		binding.TopicArn = subscription.TopicArn
+		binding.SubscriptionArn = subscription.SubscriptionArn
+	}
+	if err != nil {
+		b.deleteBindingUser(clients.user, binding.InstanceID, binding.BindingID)
+		return "", "", err
+	}
+
+	return accessKeyID, secretAccessKey, nil
+}
+
+func (b *SQSBroker) deleteBindingResources(binding brokerstore.Binding) error {
+	clients, err := b.instanceClients(binding.InstanceID)
+	if err != nil {
+		return err
+	}
+
+	if binding.SubscriptionArn != "" {
+		subscription := brokerstore.Subscription{
+			TopicInstanceID: binding.TopicInstanceID,
+			TopicArn:        binding.TopicArn,
+			SubscriptionArn: binding.SubscriptionArn,
+		}
+		if err := b.unsubscribeQueue(binding.InstanceID, b.bindingSubscriptionSid(binding.BindingID), subscription); err != nil {
+			return err
+		}
+	}
+
+	return b.deleteBindingUser(clients.user, binding.InstanceID, binding.BindingID)
+}
+
+func (b *SQSBroker) createBindingUser(user awsiam.User, instanceID string, bindingID string, userPath string, action string, resourceArn string) (accessKeyID string, secretAccessKey string, err error) {
+	var userARN, policyARN string
+
+	userARN, err = user.Create(b.userName(bindingID), userPath)
+	defer func() {
+		resources := []auditlog.Resource{userResource(b.userName(bindingID), userARN)}
+		if accessKeyID != "" {
+			resources = append(resources, accessKeyResource(accessKeyID))
+		}
+		if policyARN != "" {
+			resources = append(resources, policyResource(policyARN))
+		}
+		b.audit("create-binding-user", instanceID, bindingID, resources, err)
+	}()
+	if err != nil {
+		return "", "", err
+	}
+	defer func() {
+		if err != nil {
+			if policyARN != "" {
+				user.DeletePolicy(policyARN)
+			}
+			if accessKeyID != "" {
+				user.DeleteAccessKey(b.userName(bindingID), accessKeyID)
+			}
+			user.Delete(b.userName(bindingID))
+			accessKeyID, secretAccessKey = "", ""
+		}
+	}()
+
+	accessKeyID, secretAccessKey, err = user.CreateAccessKey(b.userName(bindingID))
+	if err != nil {
+		return accessKeyID, secretAccessKey, err
+	}
+
+	policyARN, err = user.CreatePolicy(b.policyName(bindingID), "Allow", action, resourceArn)
+	if err != nil {
+		return accessKeyID, secretAccessKey, err
+	}
+
+	if err = user.AttachUserPolicy(b.userName(bindingID), policyARN); err != nil {
+		return accessKeyID, secretAccessKey, err
+	}
+
+	return accessKeyID, secretAccessKey, nil
+}
+
+func (b *SQSBroker) deleteBindingUser(user awsiam.User, instanceID string, bindingID string) (err error) {
+	resources := []auditlog.Resource{userResource(b.userName(bindingID), "")}
+	defer func() {
+		b.audit("delete-binding-user", instanceID, bindingID, resources, err)
+	}()
+
+	accessKeys, err := user.ListAccessKeys(b.userName(bindingID))
+	if err != nil {
+		return err
+	}
+
+	for _, accessKey := range accessKeys {
+		if err := user.DeleteAccessKey(b.userName(bindingID), accessKey); err != nil {
+			return err
+		}
+		resources = append(resources, accessKeyResource(accessKey))
+	}
+
+	userPolicies, err := user.ListAttachedUserPolicies(b.userName(bindingID))
+	if err != nil {
+		return err
+	}
+
+	for _, userPolicy := range userPolicies {
+		if err := user.DetachUserPolicy(b.userName(bindingID), userPolicy); err != nil {
+			return err
+		}
+
+		if err := user.DeletePolicy(userPolicy); err != nil {
+			return err
+		}
+		resources = append(resources, policyResource(userPolicy))
+	}
+
+	if err := user.Delete(b.userName(bindingID)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (b *SQSBroker) completeBind(binding brokerstore.Binding, target bindTarget, bindParameters BindParameters) {
+	logger := b.logger.Session("complete-bind", lager.Data{
+		instanceIDLogKey: binding.InstanceID,
+		bindingIDLogKey:  binding.BindingID,
+	})
+
+	accessKeyID, secretAccessKey, err := b.createBindingResources(&binding, target, bindParameters)
+	if err == nil && !target.topic {
+		err = b.verifyAccessKey(binding.InstanceID, accessKeyID, secretAccessKey)
+		if err != nil {
+			b.deleteBindingResources(binding)
+		}
+	}
+
+	if err != nil {
+		logger.Error("bind-failed", err)
+		binding.LastOperationState = brokerapi.LastOperationFailed
+		binding.LastOperationDescription = err.Error()
+	} else {
+		// The platform fetches the credentials afterwards, so the secret is kept until then, out of the state file unless secrets are stored
+		binding.AccessKeyID = accessKeyID
+		if b.storeBindingSecrets {
+			binding.SecretAccessKey = secretAccessKey
+		} else {
+			b.setPendingSecret(binding.BindingID, secretAccessKey)
+		}
+		binding.LastOperationState = brokerapi.LastOperationSucceeded
+		binding.LastOperationDescription = ""
+	}
+
+	if err = b.store.SaveBinding(binding); err != nil {
+		logger.Error("save-binding-failed", err)
+	}
+}
+
+func (b *SQSBroker) completeUnbind(binding brokerstore.Binding) {
+	logger := b.logger.Session("complete-unbind", lager.Data{
+		instanceIDLogKey: binding.InstanceID,
+		bindingIDLogKey:  binding.BindingID,
+	})
+
+	if err := b.deleteBindingResources(binding); err != nil {
+		logger.Error("unbind-failed", err)
+		binding.LastOperationState = brokerapi.LastOperationFailed
+		binding.LastOperationDescription = err.Error()
+		if err = b.store.SaveBinding(binding); err != nil {
+			logger.Error("save-binding-failed", err)
+		}
+		return
+	}
+
+	if err := b.store.DeleteBinding(binding.BindingID); err != nil {
+		logger.Error("delete-binding-failed", err)
+	}
+	b.takePendingSecret(binding.BindingID)
+}
+
+// FailInterruptedOperations fails the operations a previous broker process left in progress, as the goroutines
+// running them did not survive it. The platform then deletes the failed bindings, and the instance operations can be retried.
+func (b *SQSBroker) FailInterruptedOperations() error {
+	instances, err := b.store.ListInstances()
+	if err != nil {
+		return err
+	}
+
+	for _, instance := range instances {
+		if instance.LastOperationState != brokerapi.LastOperationInProgress {
+			continue
+		}
+
+		b.logger.Info("fail-interrupted-operation", lager.Data{
+			instanceIDLogKey: instance.InstanceID,
+			"operation":      instance.LastOperationDescription,
+		})
+		instance.LastOperationState = brokerapi.LastOperationFailed
+		instance.LastOperationDescription = fmt.Sprintf("Interrupted by a broker restart: %s", instance.LastOperationDescription)
+		if err := b.store.SaveInstance(instance); err != nil {
+			return err
+		}
+	}
+
+	bindings, err := b.store.ListBindings()
+	if err != nil {
+		return err
+	}
+
+	for _, binding := range bindings {
+		if binding.LastOperationState != brokerapi.LastOperationInProgress {
+			continue
+		}
+
+		b.logger.Info("fail-interrupted-operation", lager.Data{
+			instanceIDLogKey: binding.InstanceID,
+			bindingIDLogKey:  binding.BindingID,
+			"operation":      binding.LastOperationDescription,
+		})
+		binding.LastOperationState = brokerapi.LastOperationFailed
+		binding.LastOperationDescription = fmt.Sprintf("Interrupted by a broker restart: %s", binding.LastOperationDescription)
+		if err := b.store.SaveBinding(binding); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *SQSBroker) setPendingSecret(bindingID string, secretAccessKey string) {
+	b.pendingSecretsMutex.Lock()
+	defer b.pendingSecretsMutex.Unlock()
+
+	b.pendingSecrets[bindingID] = secretAccessKey
+}
+
+// takePendingSecret returns the secret of an asynchronous binding not fetched yet, only once
+func (b *SQSBroker) takePendingSecret(bindingID string) string {
+	b.pendingSecretsMutex.Lock()
+	defer b.pendingSecretsMutex.Unlock()
+
+	secretAccessKey := b.pendingSecrets[bindingID]
+	delete(b.pendingSecrets, bindingID)
+
+	return secretAccessKey
+}
+
+func (b *SQSBroker) verifyAccessKey(instanceID string, accessKeyID string, secretAccessKey string) error {
+	instance, err := b.store.GetInstance(instanceID)
+	if err != nil && err != brokerstore.ErrInstanceDoesNotExist {
+		return err
+	}
+
+	region := instance.Region
+	if region == "" {
+		region = b.region
+	}
+
+	// IAM is eventually consistent, so a new access key may be rejected for a while
+	queue := b.bindingQueueFactory(region, accessKeyID, secretAccessKey)
+	deadline := time.Now().Add(b.bindingVerificationTimeout)
+
+	for {
+		_, err := queue.Describe(b.queueName(instanceID))
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().Add(bindingVerificationInterval).After(deadline) {
+			return fmt.Errorf("Verifying access key '%s': %s", accessKeyID, err)
+		}
+
+		time.Sleep(bindingVerificationInterval)
+	}
+}
+
+func (b *SQSBroker) reissueAccessKey(user awsiam.User, instanceID string, bindingID string) (accessKeyID string, secretAccessKey string, err error) {
+	resources := []auditlog.Resource{userResource(b.userName(bindingID), "")}
+	defer func() {
+		if accessKeyID != "" {
+			resources = append(resources, accessKeyResource(accessKeyID))
+		}
+		b.audit("reissue-access-key", instanceID, bindingID, resources, err)
+	}()
+
+	// Secrets can not be read back from IAM, so the only way to hand out credentials again is to replace the access keys
+	accessKeys, err := user.ListAccessKeys(b.userName(bindingID))
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, accessKey := range accessKeys {
+		if err := user.DeleteAccessKey(b.userName(bindingID), accessKey); err != nil {
+			return "", "", err
+		}
+		resources = append(resources, accessKeyResource(accessKey))
+	}
+
+	b.logger.Info("reissue-access-key", lager.Data{
+		bindingIDLogKey: bindingID,
+	})
+
+	return user.CreateAccessKey(b.userName(bindingID))
+}
+
+func (b *SQSBroker) deleteQueue(instanceID string, details brokerapi.DeprovisionDetails, force bool, acceptsIncomplete bool) (bool, error) {
+	if err := b.checkInstanceNotBusy(instanceID); err != nil {
+		return false, err
+	}
+
+	clients, err := b.instanceClients(instanceID)
+	if err != nil {
+		return false, err
+	}
+
+	servicePlan, ok := b.currentConfig().catalog.FindServicePlan(details.PlanID)
+	if ok && servicePlan.DeletionProtection && !force {
+		if err := b.checkQueueIsEmpty(clients.queue, instanceID); err != nil {
+			return false, err
+		}
+	}
+
+	if ok && servicePlan.DeletionArchiveMode == ArchiveQueueDeletionMode {
+		if err := b.startQueueArchive(clients, instanceID, details, acceptsIncomplete); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	return false, b.deleteQueueResources(clients, instanceID)
+}
+
+func (b *SQSBroker) deleteQueueResources(clients awsClients, instanceID string) error {
+	err := clients.queue.Delete(b.queueName(instanceID))
+	b.audit("delete-queue", instanceID, "", []auditlog.Resource{queueResource(b.queueName(instanceID), clients.region, "")}, err)
+	if err != nil {
+		if err == awssqs.ErrQueueDoesNotExist {
+			return brokerapi.ErrInstanceDoesNotExist
+		}
+		return err
+	}
+
+	if instance, err := b.store.GetInstance(instanceID); err == nil {
+		b.deleteInstanceSubscriptions(instance)
+	}
+
+	if err := b.store.DeleteInstance(instanceID); err != nil && err != brokerstore.ErrInstanceDoesNotExist {
+		return err
+	}
+
+	return nil
+}
+
+// findInstance returns the record of an Instance, or one built from the request when the broker has none
+func (b *SQSBroker) findInstance(instanceID string, organizationGUID string, spaceGUID string) (brokerstore.Instance, bool, error) {
+	instance, err := b.store.GetInstance(instanceID)
+	if err != nil {
+		if err != brokerstore.ErrInstanceDoesNotExist {
+			return instance, false, err
+		}
+		return brokerstore.Instance{
+			InstanceID:       instanceID,
+			OrganizationGUID: organizationGUID,
+			SpaceGUID:        spaceGUID,
+		}, false, nil
+	}
+
+	return instance, true, nil
+}
+
+// updateInstance records the new plan of an Instance, parameters are only the ones the user is allowed to update
+func (b *SQSBroker) updateInstance(instance brokerstore.Instance, details brokerapi.UpdateDetails, parameters map[string]interface{}, requestContext map[string]interface{}) error {
+	instance.ServiceID = details.ServiceID
+	instance.PlanID = details.PlanID
+	instance.Context = requestContext
+	if len(parameters) > 0 {
+		instance.Parameters = mergeParameters(instance.Parameters, parameters)
+	}
+
+	return b.store.SaveInstance(instance)
+}
+
+func (b *SQSBroker) modifyQueue(instanceID string, servicePlan ServicePlan, updateParameters UpdateParameters, details brokerapi.UpdateDetails, keepCurrentAttributes bool) ([]queueAttributeChange, error) {
+	desiredQueueDetails := b.modifyQueueDetails(instanceID, servicePlan, updateParameters, details)
+
+	clients, err := b.instanceClients(instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := b.lockQueuePolicy(instanceID)
+	defer unlock()
+
+	queueDetails, err := clients.queue.Describe(b.queueName(instanceID))
+	if err != nil {
+		if err == awssqs.ErrQueueDoesNotExist {
+			return nil, brokerapi.ErrInstanceDoesNotExist
+		}
+		return nil, err
+	}
+
+	if keepCurrentAttributes {
+		currentQueueDetails := awssqs.QueueDetails{
+			DelaySeconds:                  queueDetails.DelaySeconds,
+			MaximumMessageSize:            queueDetails.MaximumMessageSize,
+			MessageRetentionPeriod:        queueDetails.MessageRetentionPeriod,
+			Policy:                        queueDetails.Policy,
+			ReceiveMessageWaitTimeSeconds: queueDetails.ReceiveMessageWaitTimeSeconds,
+			VisibilityTimeout:             queueDetails.VisibilityTimeout,
+		}
+		mergeQueueDetails(&currentQueueDetails, *desiredQueueDetails)
+		desiredQueueDetails = &currentQueueDetails
+	}
+
+	desiredQueueDetails.Policy, err = renderQueuePolicy(desiredQueueDetails.Policy, queueDetails, updateParameters.SourceArns)
+	if err != nil {
+		return nil, err
+	}
+
+	// The plan Policy would otherwise drop the statements allowing Topics to send messages to the Queue
+	desiredQueueDetails.Policy, err = keepManagedPolicyStatements(desiredQueueDetails.Policy, queueDetails.Policy, b.sqsPrefix+"-")
+	if err != nil {
+		return nil, err
+	}
+
+	modifyQueueDetails, changes, err := diffQueueDetails(queueDetails, *desiredQueueDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	if modifyQueueDetails != (awssqs.QueueDetails{}) {
+		if err := clients.queue.Modify(b.queueName(instanceID), modifyQueueDetails); err != nil {
+			if err == awssqs.ErrQueueDoesNotExist {
+				return nil, brokerapi.ErrInstanceDoesNotExist
+			}
+			return nil, err
+		}
+	}
+
+	// Modify skips empty attributes, a removed Policy must be set explicitly
+	if queueDetails.Policy != "" && desiredQueueDetails.Policy == "" {
+		if err := clients.queue.SetPolicy(b.queueName(instanceID), ""); err != nil {
+			return nil, err
+		}
+	}
+
+	b.logger.Info("modify-queue", lager.Data{
+		instanceIDLogKey: instanceID,
+		"changes":        changes,
+	})
+
+	return changes, nil
+}
+
+func (b *SQSBroker) checkQueueIsEmpty(queue awssqs.Queue, instanceID string) error {
+	queueDetails, err := queue.Describe(b.queueName(instanceID))
+	if err != nil {
+		if err == awssqs.ErrQueueDoesNotExist {
+			return brokerapi.ErrInstanceDoesNotExist
+		}
+		return err
+	}
+
+	messages, _ := strconv.Atoi(queueDetails.ApproximateNumberOfMessages)
+	messagesNotVisible, _ := strconv.Atoi(queueDetails.ApproximateNumberOfMessagesNotVisible)
+	if messages > 0 || messagesNotVisible > 0 {
+		return &QueueNotEmptyError{
+			QueueName:          b.queueName(instanceID),
+			Messages:           messages,
+			MessagesNotVisible: messagesNotVisible,
+		}
+	}
+
+	return nil
+}
+
+// startQueueArchive moves the messages of a Queue to its archive Queue in the background, then deletes it.
+// In flight messages can not be received, so the Queue is only archived once its consumers have stopped.
+func (b *SQSBroker) startQueueArchive(clients awsClients, instanceID string, details brokerapi.DeprovisionDetails, acceptsIncomplete bool) error {
+	if !acceptsIncomplete {
+		return brokerapi.ErrAsyncRequired
+	}
+
+	queueDetails, err := clients.queue.Describe(b.queueName(instanceID))
+	if err != nil {
+		if err == awssqs.ErrQueueDoesNotExist {
+			return brokerapi.ErrInstanceDoesNotExist
+		}
+		return err
+	}
+
+	messagesNotVisible, _ := strconv.Atoi(queueDetails.ApproximateNumberOfMessagesNotVisible)
+	if messagesNotVisible > 0 {
+		return &MessagesInFlightError{
+			QueueName:          b.queueName(instanceID),
+			MessagesNotVisible: messagesNotVisible,
+		}
+	}
+
+	// Producers still sending would keep the archive running forever, it gives up past the messages counted now
+	messages, _ := strconv.Atoi(queueDetails.ApproximateNumberOfMessages)
+	messagesDelayed, _ := strconv.Atoi(queueDetails.ApproximateNumberOfMessagesDelayed)
+	limit := messages + messagesDelayed + archiveReceiveBatchSize
+
+	instance, err := b.store.GetInstance(instanceID)
+	if err != nil {
+		if err != brokerstore.ErrInstanceDoesNotExist {
+			return err
+		}
+		instance = brokerstore.Instance{
+			InstanceID: instanceID,
+			ServiceID:  details.ServiceID,
+			PlanID:     details.PlanID,
+		}
+	}
+
+	instance.LastOperationState = brokerapi.LastOperationInProgress
+	instance.LastOperationDescription = fmt.Sprintf("Archiving messages to queue '%s'", b.archiveQueueName(instanceID))
+	if err := b.store.SaveInstance(instance); err != nil {
+		return err
+	}
+
+	go b.archiveAndDeleteQueue(clients, instance, limit)
+
+	return nil
+}
+
+func (b *SQSBroker) archiveAndDeleteQueue(clients awsClients, instance brokerstore.Instance, limit int) {
+	logger := b.logger.Session("archive-and-delete-queue", lager.Data{
+		instanceIDLogKey: instance.InstanceID,
+	})
+
+	err := b.archiveQueue(clients, instance.InstanceID, limit)
+	if err == nil {
+		err = b.deleteQueueResources(clients, instance.InstanceID)
+	}
+	if err != nil {
+		logger.Error("archive-failed", err)
+		instance.LastOperationState = brokerapi.LastOperationFailed
+		instance.LastOperationDescription = err.Error()
+		if err := b.store.SaveInstance(instance); err != nil {
+			logger.Error("save-instance-failed", err)
+		}
+	}
+}
+
+func (b *SQSBroker) archiveQueue(clients awsClients, instanceID string, limit int) error {
+	queue := clients.queue
+	archiveQueueDetails := awssqs.QueueDetails{
+		MessageRetentionPeriod: archiveMessageRetentionPeriod,
+	}
+	archiveQueueURL, err := queue.Create(b.archiveQueueName(instanceID), archiveQueueDetails)
+	b.audit("create-archive-queue", instanceID, "", []auditlog.Resource{queueResource(b.archiveQueueName(instanceID), clients.region, queueArnFromURL(clients.region, archiveQueueURL))}, err)
+	if err != nil {
+		return err
+	}
+
+	archived, err := b.moveMessages(queue, b.queueName(instanceID), queue, b.archiveQueueName(instanceID), limit)
+	b.logger.Info("archive-queue", lager.Data{
+		instanceIDLogKey: instanceID,
+		"archive-queue":  b.archiveQueueName(instanceID),
+		"messages":       archived,
+	})
+	if err != nil {
+		if err == awssqs.ErrQueueDoesNotExist {
+			return brokerapi.ErrInstanceDoesNotExist
+		}
+		return err
+	}
+
+	return nil
+}
+
+// moveMessages receives messages until the source Queue holds no visible, delayed nor in flight messages. It fails once
+// limit messages were moved (no limit if 0), or when the Queue is still not empty after maxEmptyReceives empty receives,
+// as deleting it then would lose the remaining messages.
+func (b *SQSBroker) moveMessages(sourceQueue awssqs.Queue, sourceQueueName string, targetQueue awssqs.Queue, targetQueueName string, limit int) (int, error) {
+	moved := 0
+	emptyReceives := 0
+
+	for {
+		if limit > 0 && moved >= limit {
+			return moved, fmt.Errorf("Queue '%s' still receives messages after %d were moved, stop its producers and try again", sourceQueueName, moved)
+		}
+
+		messages, err := sourceQueue.ReceiveMessages(sourceQueueName, archiveReceiveBatchSize)
+		if err != nil {
+			return moved, err
+		}
+
+		if len(messages) > 0 {
+			if err = targetQueue.SendMessages(targetQueueName, messages); err != nil {
+				return moved, err
+			}
+
+			if err = sourceQueue.DeleteMessages(sourceQueueName, messages); err != nil {
+				return moved, err
+			}
+
+			moved += len(messages)
+			continue
+		}
+
+		// Delayed and in flight messages can not be received yet
+		queueDetails, err := sourceQueue.Describe(sourceQueueName)
+		if err != nil {
+			return moved, err
+		}
+
+		messagesVisible, _ := strconv.Atoi(queueDetails.ApproximateNumberOfMessages)
+		messagesDelayed, _ := strconv.Atoi(queueDetails.ApproximateNumberOfMessagesDelayed)
+		messagesNotVisible, _ := strconv.Atoi(queueDetails.ApproximateNumberOfMessagesNotVisible)
+		if messagesVisible == 0 && messagesDelayed == 0 && messagesNotVisible == 0 {
+			return moved, nil
+		}
+
+		emptyReceives++
+		if emptyReceives >= maxEmptyReceives {
+			return moved, fmt.Errorf("Queue '%s' still holds %d delayed and %d in flight messages after %d were moved, stop its consumers and try again", sourceQueueName, messagesDelayed, messagesNotVisible, moved)
+		}
+	}
+}
+
+func (b *SQSBroker) checkPlatform(servicePlan ServicePlan, rawContext map[string]interface{}, organizationGUID string, spaceGUID string) error {
+	requestContext, err := b.requestContext(rawContext, organizationGUID, spaceGUID)
+	if err != nil {
+		return err
+	}
+
+	if !servicePlan.AvailableOnPlatform(requestContext.Platform) {
+		return &PlatformNotAllowedError{PlanID: servicePlan.ID, Platform: requestContext.Platform}
+	}
+
+	return nil
+}
+
+func (b *SQSBroker) requestContext(rawContext map[string]interface{}, organizationGUID string, spaceGUID string) (RequestContext, error) {
+	requestContext := RequestContext{}
+	if err := mapstructure.Decode(rawContext, &requestContext); err != nil {
+		return requestContext, err
+	}
+
+	// Platforms predating the OSBAPI context object only send the CF org and space
+	if requestContext.OrganizationGUID == "" {
+		requestContext.OrganizationGUID = organizationGUID
+	}
+	if requestContext.SpaceGUID == "" {
+		requestContext.SpaceGUID = spaceGUID
+	}
+	if requestContext.Platform == "" && requestContext.OrganizationGUID != "" {
+		requestContext.Platform = CloudFoundryPlatform
+	}
+
+	return requestContext, nil
+}
+
+func (b *SQSBroker) bindingUserPath(binding brokerstore.Binding) string {
+	rawContext := binding.Context
+	var organizationGUID, spaceGUID string
+	if instance, err := b.store.GetInstance(binding.InstanceID); err == nil {
+		if rawContext == nil {
+			rawContext = instance.Context
+		}
+		organizationGUID = instance.OrganizationGUID
+		spaceGUID = instance.SpaceGUID
+	}
+
+	requestContext, err := b.requestContext(rawContext, organizationGUID, spaceGUID)
+	if err != nil {
+		b.logger.Error("decode-context-failed", err)
+		return ""
+	}
+
+	return b.userPath(requestContext)
+}
+
+func (b *SQSBroker) userPath(requestContext RequestContext) string {
+	var components []string
+	switch requestContext.Platform {
+	case "":
+		return ""
+	case KubernetesPlatform:
+		components = []string{requestContext.ClusterID, requestContext.Namespace}
+	default:
+		components = []string{requestContext.OrganizationGUID, requestContext.SpaceGUID}
+	}
+
+	path := prefixUserPath(b.sqsPrefix) + requestContext.Platform + "/"
+	for _, component := range components {
+		if component != "" {
+			path += strings.Replace(component, "/", "-", -1) + "/"
+		}
+	}
+
+	return path
+}
+
+func (b *SQSBroker) queueName(instanceID string) string {
+	return fmt.Sprintf("%s-%s", b.sqsPrefix, instanceID)
+}
+
+func (b *SQSBroker) archiveQueueName(instanceID string) string {
+	return fmt.Sprintf("%s-%s-archive", b.sqsPrefix, instanceID)
+}
+
+func (b *SQSBroker) userName(bindingID string) string {
+	return bindingUserName(b.sqsPrefix, bindingID)
+}
+
+func (b *SQSBroker) policyName(bindingID string) string {
+	return bindingPolicyName(b.sqsPrefix, bindingID)
+}
+
+func bindingUserName(sqsPrefix string, bindingID string) string {
+	return fmt.Sprintf("%s-%s", sqsPrefix, bindingID)
+}
+
+func bindingPolicyName(sqsPrefix string, bindingID string) string {
+	return fmt.Sprintf("%s-%s", sqsPrefix, bindingID)
+}
+
+// prefixUserPath is the IAM path of the Binding Users created with a request context, the others are created on the root path
+func prefixUserPath(sqsPrefix string) string {
+	return "/" + sqsPrefix + "/"
+}
+
+// BindingUserArns returns the ARN patterns matching the IAM Users the broker creates for Bindings, on any path
+func BindingUserArns(sqsPrefix string, accountID string) []string {
+	return []string{
+		fmt.Sprintf("arn:aws:iam::%s:user/%s", accountID, bindingUserName(sqsPrefix, "*")),
+		fmt.Sprintf("arn:aws:iam::%s:user%s*", accountID, prefixUserPath(sqsPrefix)),
+	}
+}
+
+// BindingPolicyArns returns the ARN patterns matching the IAM Policies the broker creates for Bindings
+func BindingPolicyArns(sqsPrefix string, accountID string) []string {
+	return []string{
+		fmt.Sprintf("arn:aws:iam::%s:policy/%s", accountID, bindingPolicyName(sqsPrefix, "*")),
+	}
 }
 
 func (b *SQSBroker) createQueueDetails(instanceID string, servicePlan ServicePlan, provisionParameters ProvisionParameters, details brokerapi.ProvisionDetails) *awssqs.QueueDetails {
@@ -12,10 +12,16 @@ import (
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotal-golang/lager/lagertest"
 
+	"github.com/cf-platform-eng/sqs-broker/auditlog"
+	auditfake "github.com/cf-platform-eng/sqs-broker/auditlog/fakes"
 	"github.com/cf-platform-eng/sqs-broker/awsiam"
 	iamfake "github.com/cf-platform-eng/sqs-broker/awsiam/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awssns"
+	snsfake "github.com/cf-platform-eng/sqs-broker/awssns/fakes"
 	"github.com/cf-platform-eng/sqs-broker/awssqs"
 	sqsfake "github.com/cf-platform-eng/sqs-broker/awssqs/fakes"
+	"github.com/cf-platform-eng/sqs-broker/brokerstore"
+	storefake "github.com/cf-platform-eng/sqs-broker/brokerstore/fakes"
 )
 
 var _ = Describe("SQS Broker", func() {
@@ -31,8 +37,23 @@ var _ = Describe("SQS Broker", func() {
 		config Config
 
 		queue *sqsfake.FakeQueue
+		topic *snsfake.FakeTopic
 		user  *iamfake.FakeUser
 
+		store *storefake.FakeStore
+
+		auditLogger *auditfake.FakeLogger
+
+		bindingQueue                *sqsfake.FakeQueue
+		bindingQueueAccessKeyID     string
+		bindingQueueSecretAccessKey string
+		bindingQueueFactory         BindingQueueFactory
+		bindingQueueRegion          string
+		clientsFactory              ClientsFactory
+		clientsRegions              []string
+		clientsAccountRoles         []AccountRole
+		bindingVerificationTimeout  int
+
 		testSink *lagertest.TestSink
 		logger   lager.Logger
 
@@ -42,6 +63,15 @@ var _ = Describe("SQS Broker", func() {
 		allowUserUpdateParameters    bool
 		serviceBindable              bool
 		planUpdateable               bool
+		deletionProtection           bool
+		deletionArchiveMode          string
+		planPlatforms                []string
+		planRegion                   string
+		planAccountRole              AccountRole
+		organizationAccounts         map[string]AccountRole
+		allowedRegions               []string
+		storeBindingSecrets          bool
+		quotas                       Quotas
 
 		instanceID = "instance-id"
 		bindingID  = "binding-id"
@@ -55,9 +85,38 @@ var _ = Describe("SQS Broker", func() {
 		allowUserUpdateParameters = true
 		serviceBindable = true
 		planUpdateable = true
+		deletionProtection = false
+		deletionArchiveMode = ""
+		planPlatforms = nil
+		planRegion = ""
+		planAccountRole = AccountRole{}
+		organizationAccounts = nil
+		allowedRegions = nil
+		storeBindingSecrets = false
+		quotas = Quotas{}
 
 		queue = &sqsfake.FakeQueue{}
+		topic = &snsfake.FakeTopic{}
 		user = &iamfake.FakeUser{}
+		auditLogger = &auditfake.FakeLogger{}
+		store = &storefake.FakeStore{}
+
+		bindingQueue = &sqsfake.FakeQueue{}
+		clientsRegions = nil
+		clientsAccountRoles = nil
+		clientsFactory = func(region string, accountRole AccountRole) (awssqs.Queue, awssns.Topic, awsiam.User) {
+			clientsRegions = append(clientsRegions, region)
+			clientsAccountRoles = append(clientsAccountRoles, accountRole)
+			return queue, topic, user
+		}
+
+		bindingQueueFactory = func(region string, accessKeyID string, secretAccessKey string) awssqs.Queue {
+			bindingQueueRegion = region
+			bindingQueueAccessKeyID = accessKeyID
+			bindingQueueSecretAccessKey = secretAccessKey
+			return bindingQueue
+		}
+		bindingVerificationTimeout = 1
 
 		sqsProperties1 = SQSProperties{}
 		sqsProperties2 = SQSProperties{}
@@ -65,15 +124,21 @@ var _ = Describe("SQS Broker", func() {
 
 	JustBeforeEach(func() {
 		plan1 = ServicePlan{
-			ID:            "Plan-1",
-			Name:          "Plan 1",
-			Description:   "This is the Plan 1",
-			SQSProperties: sqsProperties1,
+			ID:                  "Plan-1",
+			Name:                "Plan 1",
+			Description:         "This is the Plan 1",
+			DeletionProtection:  deletionProtection,
+			DeletionArchiveMode: deletionArchiveMode,
+			SQSProperties:       sqsProperties1,
 		}
 		plan2 = ServicePlan{
 			ID:            "Plan-2",
 			Name:          "Plan 2",
 			Description:   "This is the Plan 2",
+			Platforms:     planPlatforms,
+			Region:        planRegion,
+			RoleArn:       planAccountRole.RoleArn,
+			ExternalID:    planAccountRole.ExternalID,
 			SQSProperties: sqsProperties2,
 		}
 
@@ -100,9 +165,14 @@ var _ = Describe("SQS Broker", func() {
 
 		config = Config{
 			Region:                       "sqs-region",
+			AllowedRegions:               allowedRegions,
+			OrganizationAccounts:         organizationAccounts,
 			SQSPrefix:                    "cf",
 			AllowUserProvisionParameters: allowUserProvisionParameters,
 			AllowUserUpdateParameters:    allowUserUpdateParameters,
+			StoreBindingSecrets:          storeBindingSecrets,
+			BindingVerificationTimeout:   bindingVerificationTimeout,
+			Quotas:                       quotas,
 			Catalog:                      catalog,
 		}
 
@@ -110,7 +180,7 @@ var _ = Describe("SQS Broker", func() {
 		testSink = lagertest.NewTestSink()
 		logger.RegisterSink(testSink)
 
-		sqsBroker = New(config, queue, user, logger)
+		sqsBroker = New(config, clientsFactory, store, bindingQueueFactory, auditLogger, logger)
 	})
 
 	var _ = Describe("Services", func() {
@@ -197,7 +267,58 @@ var _ = Describe("SQS Broker", func() {
 			Expect(queue.CreateQueueDetails.MessageRetentionPeriod).To(Equal(""))
 			Expect(queue.CreateQueueDetails.ReceiveMessageWaitTimeSeconds).To(Equal(""))
 			Expect(queue.CreateQueueDetails.VisibilityTimeout).To(Equal(""))
+			Expect(store.SaveInstanceCalled).To(BeTrue())
+			Expect(store.SaveInstanceInstance).To(Equal(brokerstore.Instance{
+				InstanceID:       instanceID,
+				ServiceID:        "Service-1",
+				PlanID:           "Plan-1",
+				OrganizationGUID: "organization-id",
+				SpaceGUID:        "space-id",
+				Region:           "sqs-region",
+				Parameters:       map[string]interface{}{},
+			}))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("audits the Queue creation", func() {
+			queue.CreateQueueURL = "https://sqs.sqs-region.amazonaws.com/123456789012/" + queueName
+
+			_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
 			Expect(err).ToNot(HaveOccurred())
+			Expect(auditLogger.LogEvents).To(Equal([]auditlog.Event{
+				auditlog.Event{
+					Action:     "create-queue",
+					Outcome:    auditlog.OutcomeSuccess,
+					InstanceID: instanceID,
+					Resources: []auditlog.Resource{
+						auditlog.Resource{
+							Type:   auditlog.ResourceSQSQueue,
+							Name:   queueName,
+							Region: "sqs-region",
+							ARN:    "arn:aws:sqs:sqs-region:123456789012:" + queueName,
+						},
+					},
+				},
+			}))
+		})
+
+		It("audits a failed Queue creation", func() {
+			queue.CreateError = errors.New("operation failed")
+
+			_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+			Expect(err).To(HaveOccurred())
+			Expect(auditLogger.LogEvents).To(HaveLen(1))
+			Expect(auditLogger.LogEvents[0].Action).To(Equal("create-queue"))
+			Expect(auditLogger.LogEvents[0].Outcome).To(Equal(auditlog.OutcomeFailure))
+			Expect(auditLogger.LogEvents[0].Error).To(Equal("operation failed"))
+		})
+
+		It("does not fail when the audit log can not be written", func() {
+			auditLogger.LogError = errors.New("disk full")
+
+			_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(testSink.Buffer().Contents())).To(ContainSubstring("audit-log-failed"))
 		})
 
 		Context("when has DelaySeconds", func() {
@@ -316,7 +437,52 @@ var _ = Describe("SQS Broker", func() {
 			It("makes the proper calls", func() {
 				_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
 				Expect(queue.CreateQueueDetails.Policy).To(Equal("test-policy"))
+				Expect(queue.SetPolicyCalled).To(BeFalse())
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when has a Policy template", func() {
+			BeforeEach(func() {
+				sqsProperties1.Policy = `{"Statement":[{"Resource":"{{.QueueArn}}","Principal":{"AWS":"{{.AccountID}}"},"Condition":{"ArnLike":{"aws:SourceArn":{{json .SourceArns}}}}}]}`
+				provisionDetails.Parameters = map[string]interface{}{"source_arns": []interface{}{"arn:aws:s3:::bucket"}}
+				queue.DescribeQueueDetails = awssqs.QueueDetails{QueueArn: "arn:aws:sqs:sqs-region:123456789012:cf-instance-id"}
+			})
+
+			It("sets the rendered Policy once the Queue is created", func() {
+				_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
 				Expect(err).ToNot(HaveOccurred())
+				Expect(queue.CreateQueueDetails.Policy).To(Equal(""))
+				Expect(queue.SetPolicyCalled).To(BeTrue())
+				Expect(queue.SetPolicyQueueName).To(Equal(queueName))
+				Expect(queue.SetPolicyPolicy).To(MatchJSON(`{"Statement":[{"Resource":"arn:aws:sqs:sqs-region:123456789012:cf-instance-id","Principal":{"AWS":"123456789012"},"Condition":{"ArnLike":{"aws:SourceArn":["arn:aws:s3:::bucket"]}}}]}`))
+			})
+
+			Context("and a Source ARN has a wildcard", func() {
+				BeforeEach(func() {
+					provisionDetails.Parameters = map[string]interface{}{"source_arns": []interface{}{"arn:aws:s3:::*"}}
+				})
+
+				It("returns the proper error", func() {
+					_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).To(BeAssignableToTypeOf(&InvalidSourceArnError{}))
+					Expect(err.Error()).To(Equal("Source ARN 'arn:aws:s3:::*' is not a valid ARN, wildcards and quotes are not allowed"))
+					Expect(queue.CreateCalled).To(BeFalse())
+				})
+			})
+
+			Context("and setting the Policy fails", func() {
+				BeforeEach(func() {
+					queue.SetPolicyError = errors.New("operation failed")
+				})
+
+				It("deletes the Queue and returns the proper error", func() {
+					_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("operation failed"))
+					Expect(queue.DeleteCalled).To(BeTrue())
+					Expect(store.SaveInstanceCalled).To(BeFalse())
+				})
 			})
 		})
 
@@ -415,389 +581,1670 @@ var _ = Describe("SQS Broker", func() {
 			})
 		})
 
-		Context("when Service Plan is not found", func() {
+		Context("when has Parameters", func() {
 			BeforeEach(func() {
-				provisionDetails.PlanID = "unknown"
+				provisionDetails.Parameters = map[string]interface{}{"delay_seconds": "1"}
 			})
 
-			It("returns the proper error", func() {
+			It("saves the Parameters", func() {
 				_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("Service Plan 'unknown' not found"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(store.SaveInstanceInstance.Parameters).To(Equal(map[string]interface{}{"delay_seconds": "1"}))
+			})
+
+			Context("but user provision parameters are not allowed", func() {
+				BeforeEach(func() {
+					allowUserProvisionParameters = false
+				})
+
+				It("does not save the Parameters", func() {
+					_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(store.SaveInstanceInstance.Parameters).To(BeNil())
+				})
 			})
 		})
 
-		Context("when creating the Queue fails", func() {
+		Context("when saving the Instance fails", func() {
 			BeforeEach(func() {
-				queue.CreateError = errors.New("operation failed")
+				store.SaveInstanceError = errors.New("operation failed")
 			})
 
-			It("returns the proper error", func() {
+			It("deletes the Queue and returns the proper error", func() {
 				_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("operation failed"))
+				Expect(queue.CreateCalled).To(BeTrue())
+				Expect(queue.DeleteCalled).To(BeTrue())
+				Expect(queue.DeleteQueueName).To(Equal(queueName))
 			})
 		})
-	})
-
-	var _ = Describe("Update", func() {
-		var (
-			updateDetails     brokerapi.UpdateDetails
-			acceptsIncomplete bool
-		)
-
-		BeforeEach(func() {
-			updateDetails = brokerapi.UpdateDetails{
-				ServiceID:  "Service-2",
-				PlanID:     "Plan-2",
-				Parameters: map[string]interface{}{},
-				PreviousValues: brokerapi.PreviousValues{
-					PlanID:         "Plan-1",
-					ServiceID:      "Service-1",
-					OrganizationID: "organization-id",
-					SpaceID:        "space-id",
-				},
-			}
-			acceptsIncomplete = false
-		})
-
-		It("returns the proper response", func() {
-			asynch, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-			Expect(asynch).To(BeFalse())
-			Expect(err).ToNot(HaveOccurred())
-		})
 
-		It("makes the proper calls", func() {
-			_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-			Expect(queue.ModifyCalled).To(BeTrue())
-			Expect(queue.ModifyQueueName).To(Equal(queueName))
-			Expect(queue.ModifyQueueDetails.DelaySeconds).To(Equal(""))
-			Expect(queue.ModifyQueueDetails.MaximumMessageSize).To(Equal(""))
-			Expect(queue.ModifyQueueDetails.MessageRetentionPeriod).To(Equal(""))
-			Expect(queue.ModifyQueueDetails.ReceiveMessageWaitTimeSeconds).To(Equal(""))
-			Expect(queue.ModifyQueueDetails.VisibilityTimeout).To(Equal(""))
-			Expect(err).ToNot(HaveOccurred())
-		})
+		Context("when has a request Context", func() {
+			var requestContext map[string]interface{}
 
-		Context("when has DelaySeconds", func() {
 			BeforeEach(func() {
-				sqsProperties2.DelaySeconds = "test-delay-seconds"
+				requestContext = map[string]interface{}{
+					"platform":  "kubernetes",
+					"namespace": "namespace-1",
+					"clusterid": "cluster-1",
+				}
 			})
 
-			It("makes the proper calls", func() {
-				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-				Expect(queue.ModifyQueueDetails.DelaySeconds).To(Equal("test-delay-seconds"))
+			It("saves the request Context", func() {
+				_, _, err := sqsBroker.ProvisionWithContext(instanceID, provisionDetails, requestContext, acceptsIncomplete)
+				Expect(store.SaveInstanceInstance.Context).To(Equal(requestContext))
 				Expect(err).ToNot(HaveOccurred())
 			})
 
-			Context("and has DelaySeconds Parameter", func() {
+			Context("but the Service Plan is restricted to other platforms", func() {
 				BeforeEach(func() {
-					updateDetails.Parameters = map[string]interface{}{"delay_seconds": "test-delay-seconds-parameter"}
+					provisionDetails.PlanID = "Plan-2"
+					planPlatforms = []string{"cloudfoundry"}
 				})
 
-				It("makes the proper calls", func() {
-					_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-					Expect(queue.ModifyQueueDetails.DelaySeconds).To(Equal("test-delay-seconds-parameter"))
-					Expect(err).ToNot(HaveOccurred())
+				It("returns the proper error", func() {
+					_, _, err := sqsBroker.ProvisionWithContext(instanceID, provisionDetails, requestContext, acceptsIncomplete)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(BeAssignableToTypeOf(&PlatformNotAllowedError{}))
+					Expect(err.Error()).To(Equal("Service Plan 'Plan-2' is not available on platform 'kubernetes'"))
+					Expect(queue.CreateCalled).To(BeFalse())
 				})
+			})
+		})
 
-				Context("but user update parameters are not allowed", func() {
-					BeforeEach(func() {
-						allowUserUpdateParameters = false
-					})
+		Context("when the Service Plan has a Region", func() {
+			BeforeEach(func() {
+				provisionDetails.PlanID = "Plan-2"
+				planRegion = "plan-region"
+			})
 
-					It("makes the proper calls", func() {
-						_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-						Expect(queue.ModifyQueueDetails.DelaySeconds).To(Equal("test-delay-seconds"))
-						Expect(err).ToNot(HaveOccurred())
-					})
-				})
+			It("creates the Queue in the Service Plan Region", func() {
+				_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(clientsRegions).To(Equal([]string{"plan-region"}))
+				Expect(queue.CreateCalled).To(BeTrue())
+				Expect(store.SaveInstanceInstance.Region).To(Equal("plan-region"))
+				Expect(err).ToNot(HaveOccurred())
 			})
 		})
 
-		Context("when has MaximumMessageSize", func() {
+		Context("when the Organization is mapped to an Account", func() {
 			BeforeEach(func() {
-				sqsProperties2.MaximumMessageSize = "test-maximum-message-size"
+				organizationAccounts = map[string]AccountRole{
+					"organization-id": AccountRole{RoleArn: "organization-role-arn", ExternalID: "organization-external-id"},
+				}
 			})
 
-			It("makes the proper calls", func() {
-				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-				Expect(queue.ModifyQueueDetails.MaximumMessageSize).To(Equal("test-maximum-message-size"))
+			It("creates the Queue assuming the Organization Role", func() {
+				_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(clientsAccountRoles).To(Equal([]AccountRole{
+					AccountRole{RoleArn: "organization-role-arn", ExternalID: "organization-external-id"},
+				}))
+				Expect(store.SaveInstanceInstance.RoleArn).To(Equal("organization-role-arn"))
+				Expect(store.SaveInstanceInstance.ExternalID).To(Equal("organization-external-id"))
 				Expect(err).ToNot(HaveOccurred())
 			})
 
-			Context("and has MaximumMessageSize Parameter", func() {
+			Context("and the Service Plan assumes a Role", func() {
 				BeforeEach(func() {
-					updateDetails.Parameters = map[string]interface{}{"maximum_message_size": "test-maximum-message-size-parameter"}
+					provisionDetails.PlanID = "Plan-2"
+					planAccountRole = AccountRole{RoleArn: "plan-role-arn"}
 				})
 
-				It("makes the proper calls", func() {
-					_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-					Expect(queue.ModifyQueueDetails.MaximumMessageSize).To(Equal("test-maximum-message-size-parameter"))
+				It("creates the Queue assuming the Service Plan Role", func() {
+					_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(clientsAccountRoles).To(Equal([]AccountRole{AccountRole{RoleArn: "plan-role-arn"}}))
+					Expect(store.SaveInstanceInstance.RoleArn).To(Equal("plan-role-arn"))
 					Expect(err).ToNot(HaveOccurred())
 				})
-
-				Context("but user update parameters are not allowed", func() {
-					BeforeEach(func() {
-						allowUserUpdateParameters = false
-					})
-
-					It("makes the proper calls", func() {
-						_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-						Expect(queue.ModifyQueueDetails.MaximumMessageSize).To(Equal("test-maximum-message-size"))
-						Expect(err).ToNot(HaveOccurred())
-					})
-				})
 			})
 		})
 
-		Context("when has MessageRetentionPeriod", func() {
+		Context("when has Region", func() {
 			BeforeEach(func() {
-				sqsProperties2.MessageRetentionPeriod = "test-message-retention-period"
+				provisionDetails.Parameters = map[string]interface{}{"region": "eu-west-1"}
+				allowedRegions = []string{"us-east-1", "eu-west-1"}
 			})
 
-			It("makes the proper calls", func() {
-				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-				Expect(queue.ModifyQueueDetails.MessageRetentionPeriod).To(Equal("test-message-retention-period"))
+			It("creates the Queue in the requested Region", func() {
+				_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(clientsRegions).To(Equal([]string{"eu-west-1"}))
+				Expect(store.SaveInstanceInstance.Region).To(Equal("eu-west-1"))
 				Expect(err).ToNot(HaveOccurred())
 			})
 
-			Context("and has MessageRetentionPeriod Parameter", func() {
+			Context("but the Region is not allowed", func() {
 				BeforeEach(func() {
-					updateDetails.Parameters = map[string]interface{}{"message_retention_period": "test-message-retention-period-parameter"}
-				})
-
-				It("makes the proper calls", func() {
-					_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-					Expect(queue.ModifyQueueDetails.MessageRetentionPeriod).To(Equal("test-message-retention-period-parameter"))
-					Expect(err).ToNot(HaveOccurred())
+					allowedRegions = []string{"us-east-1"}
 				})
 
-				Context("but user update parameters are not allowed", func() {
-					BeforeEach(func() {
-						allowUserUpdateParameters = false
-					})
-
-					It("makes the proper calls", func() {
-						_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-						Expect(queue.ModifyQueueDetails.MessageRetentionPeriod).To(Equal("test-message-retention-period"))
-						Expect(err).ToNot(HaveOccurred())
-					})
+				It("returns the proper error", func() {
+					_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(BeAssignableToTypeOf(&RegionNotAllowedError{}))
+					Expect(err.Error()).To(Equal("Region 'eu-west-1' is not allowed"))
+					Expect(queue.CreateCalled).To(BeFalse())
 				})
 			})
 		})
 
-		Context("when has Policy", func() {
+		Context("when the Service Plan is restricted to Cloud Foundry", func() {
 			BeforeEach(func() {
-				sqsProperties2.Policy = "test-policy"
+				provisionDetails.PlanID = "Plan-2"
+				planPlatforms = []string{"cloudfoundry"}
 			})
 
-			It("makes the proper calls", func() {
-				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-				Expect(queue.ModifyQueueDetails.Policy).To(Equal("test-policy"))
+			It("accepts requests without a request Context", func() {
+				_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(queue.CreateCalled).To(BeTrue())
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
 
-		Context("when has ReceiveMessageWaitTimeSeconds", func() {
+		Context("when has Quotas", func() {
 			BeforeEach(func() {
-				sqsProperties2.ReceiveMessageWaitTimeSeconds = "test-receive-message-wait-time-seconds"
-			})
-
-			It("makes the proper calls", func() {
-				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-				Expect(queue.ModifyQueueDetails.ReceiveMessageWaitTimeSeconds).To(Equal("test-receive-message-wait-time-seconds"))
-				Expect(err).ToNot(HaveOccurred())
+				store.ListInstancesInstances = []brokerstore.Instance{
+					brokerstore.Instance{InstanceID: instanceID, PlanID: "Plan-1", OrganizationGUID: "organization-id", SpaceGUID: "space-id"},
+					brokerstore.Instance{InstanceID: "instance-1", PlanID: "Plan-1", OrganizationGUID: "organization-id", SpaceGUID: "space-id"},
+					brokerstore.Instance{InstanceID: "instance-2", PlanID: "Plan-2", OrganizationGUID: "organization-id", SpaceGUID: "other-space-id"},
+					brokerstore.Instance{InstanceID: "instance-3", PlanID: "Plan-1", OrganizationGUID: "other-organization-id", SpaceGUID: "other-space-id"},
+				}
 			})
 
-			Context("and has ReceiveMessageWaitTimeSeconds Parameter", func() {
+			Context("and no quota is met", func() {
 				BeforeEach(func() {
-					updateDetails.Parameters = map[string]interface{}{"receive_message_wait_time_seconds": "test-receive-message-wait-time-seconds-parameter"}
+					quotas = Quotas{MaxInstancesPerOrganization: 3, MaxInstancesPerSpace: 2, MaxInstancesPerPlan: 3}
 				})
 
-				It("makes the proper calls", func() {
-					_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-					Expect(queue.ModifyQueueDetails.ReceiveMessageWaitTimeSeconds).To(Equal("test-receive-message-wait-time-seconds-parameter"))
+				It("creates the Queue", func() {
+					_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(store.ListInstancesCalled).To(BeTrue())
+					Expect(queue.CreateCalled).To(BeTrue())
 					Expect(err).ToNot(HaveOccurred())
 				})
+			})
 
-				Context("but user update parameters are not allowed", func() {
-					BeforeEach(func() {
-						allowUserUpdateParameters = false
-					})
+			Context("and the Organization quota is met", func() {
+				BeforeEach(func() {
+					quotas.MaxInstancesPerOrganization = 2
+				})
 
-					It("makes the proper calls", func() {
-						_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-						Expect(queue.ModifyQueueDetails.ReceiveMessageWaitTimeSeconds).To(Equal("test-receive-message-wait-time-seconds"))
-						Expect(err).ToNot(HaveOccurred())
-					})
+				It("returns the proper error", func() {
+					_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).To(Equal(brokerapi.ErrInstanceLimitMet))
+					Expect(queue.CreateCalled).To(BeFalse())
+					Expect(store.SaveInstanceCalled).To(BeFalse())
 				})
 			})
-		})
 
-		Context("when has VisibilityTimeout", func() {
-			BeforeEach(func() {
-				sqsProperties2.VisibilityTimeout = "test-visibility-timeout"
-			})
+			Context("and the Space quota is met", func() {
+				BeforeEach(func() {
+					quotas.MaxInstancesPerSpace = 1
+				})
 
-			It("makes the proper calls", func() {
-				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-				Expect(queue.ModifyQueueDetails.VisibilityTimeout).To(Equal("test-visibility-timeout"))
-				Expect(err).ToNot(HaveOccurred())
+				It("returns the proper error", func() {
+					_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).To(Equal(brokerapi.ErrInstanceLimitMet))
+					Expect(queue.CreateCalled).To(BeFalse())
+				})
 			})
 
-			Context("and has VisibilityTimeout Parameter", func() {
+			Context("and the Service Plan quota is met", func() {
 				BeforeEach(func() {
-					updateDetails.Parameters = map[string]interface{}{"visibility_timeout": "test-visibility-timeout-parameter"}
+					quotas.MaxInstancesPerPlan = 2
 				})
 
-				It("makes the proper calls", func() {
-					_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-					Expect(queue.ModifyQueueDetails.VisibilityTimeout).To(Equal("test-visibility-timeout-parameter"))
-					Expect(err).ToNot(HaveOccurred())
+				It("returns the proper error", func() {
+					_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).To(Equal(brokerapi.ErrInstanceLimitMet))
+					Expect(queue.CreateCalled).To(BeFalse())
 				})
+			})
 
-				Context("but user update parameters are not allowed", func() {
-					BeforeEach(func() {
-						allowUserUpdateParameters = false
-					})
+			Context("and listing the Instances fails", func() {
+				BeforeEach(func() {
+					quotas.MaxInstancesPerPlan = 2
+					store.ListInstancesError = errors.New("operation failed")
+				})
 
-					It("makes the proper calls", func() {
-						_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-						Expect(queue.ModifyQueueDetails.VisibilityTimeout).To(Equal("test-visibility-timeout"))
+				It("returns the proper error", func() {
+					_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("operation failed"))
+					Expect(queue.CreateCalled).To(BeFalse())
+				})
+			})
+		})
+
+		Context("when Service Plan is not found", func() {
+			BeforeEach(func() {
+				provisionDetails.PlanID = "unknown"
+			})
+
+			It("returns the proper error", func() {
+				_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Service Plan 'unknown' not found"))
+			})
+		})
+
+		Context("when creating the Queue fails", func() {
+			BeforeEach(func() {
+				queue.CreateError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			It("does not save the Instance", func() {
+				sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(store.SaveInstanceCalled).To(BeFalse())
+			})
+		})
+
+		Context("when saving the Instance fails", func() {
+			BeforeEach(func() {
+				store.SaveInstanceError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, _, err := sqsBroker.Provision(instanceID, provisionDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+		})
+	})
+
+	var _ = Describe("Update", func() {
+		var (
+			updateDetails     brokerapi.UpdateDetails
+			acceptsIncomplete bool
+		)
+
+		BeforeEach(func() {
+			updateDetails = brokerapi.UpdateDetails{
+				ServiceID:  "Service-2",
+				PlanID:     "Plan-2",
+				Parameters: map[string]interface{}{},
+				PreviousValues: brokerapi.PreviousValues{
+					PlanID:         "Plan-1",
+					ServiceID:      "Service-1",
+					OrganizationID: "organization-id",
+					SpaceID:        "space-id",
+				},
+			}
+			acceptsIncomplete = false
+			queue.DescribeQueueDetails = awssqs.QueueDetails{
+				DelaySeconds:      "5",
+				VisibilityTimeout: "60",
+			}
+		})
+
+		It("returns the proper response", func() {
+			asynch, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+			Expect(asynch).To(BeFalse())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("makes the proper calls", func() {
+			_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+			Expect(queue.ModifyCalled).To(BeTrue())
+			Expect(queue.ModifyQueueName).To(Equal(queueName))
+			Expect(queue.ModifyQueueDetails.DelaySeconds).To(Equal("0"))
+			Expect(queue.ModifyQueueDetails.MaximumMessageSize).To(Equal(""))
+			Expect(queue.ModifyQueueDetails.MessageRetentionPeriod).To(Equal(""))
+			Expect(queue.ModifyQueueDetails.ReceiveMessageWaitTimeSeconds).To(Equal(""))
+			Expect(queue.ModifyQueueDetails.VisibilityTimeout).To(Equal("30"))
+			Expect(store.GetInstanceCalled).To(BeTrue())
+			Expect(store.GetInstanceInstanceID).To(Equal(instanceID))
+			Expect(store.SaveInstanceCalled).To(BeTrue())
+			Expect(store.SaveInstanceInstance.ServiceID).To(Equal("Service-2"))
+			Expect(store.SaveInstanceInstance.PlanID).To(Equal("Plan-2"))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when the Instance has stored Parameters", func() {
+			BeforeEach(func() {
+				store.GetInstanceInstance = brokerstore.Instance{
+					InstanceID:       instanceID,
+					ServiceID:        "Service-1",
+					PlanID:           "Plan-1",
+					OrganizationGUID: "organization-id",
+					SpaceGUID:        "space-id",
+					Parameters:       map[string]interface{}{"delay_seconds": "1", "visibility_timeout": "10"},
+				}
+				updateDetails.Parameters = map[string]interface{}{"delay_seconds": "2"}
+			})
+
+			It("applies the stored Parameters to the Queue", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(queue.ModifyQueueDetails.DelaySeconds).To(Equal("2"))
+				Expect(queue.ModifyQueueDetails.VisibilityTimeout).To(Equal("10"))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("removes the stored Parameters set to null", func() {
+				updateDetails.Parameters = map[string]interface{}{"visibility_timeout": nil}
+
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(queue.ModifyQueueDetails.DelaySeconds).To(Equal("1"))
+				Expect(queue.ModifyQueueDetails.VisibilityTimeout).To(Equal("30"))
+				Expect(store.SaveInstanceInstance.Parameters).To(Equal(map[string]interface{}{"delay_seconds": "1"}))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("merges the new Parameters into the stored ones", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(store.SaveInstanceInstance).To(Equal(brokerstore.Instance{
+					InstanceID:       instanceID,
+					ServiceID:        "Service-2",
+					PlanID:           "Plan-2",
+					OrganizationGUID: "organization-id",
+					SpaceGUID:        "space-id",
+					Parameters:       map[string]interface{}{"delay_seconds": "2", "visibility_timeout": "10"},
+				}))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			Context("but user update parameters are not allowed", func() {
+				BeforeEach(func() {
+					allowUserUpdateParameters = false
+				})
+
+				It("keeps the stored Parameters", func() {
+					_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(queue.ModifyQueueDetails.DelaySeconds).To(Equal("1"))
+					Expect(store.SaveInstanceInstance.Parameters).To(Equal(map[string]interface{}{"delay_seconds": "1", "visibility_timeout": "10"}))
+				})
+			})
+		})
+
+		It("describes the changed attributes", func() {
+			updateResponse, _, err := sqsBroker.UpdateWithContext(instanceID, updateDetails, nil, acceptsIncomplete)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updateResponse.Description).To(Equal("Queue attributes changed: delay_seconds (5 -> 0), visibility_timeout (60 -> 30)"))
+		})
+
+		Context("when the Queue already has the desired attributes", func() {
+			BeforeEach(func() {
+				sqsProperties2.DelaySeconds = "5"
+				sqsProperties2.VisibilityTimeout = "60"
+			})
+
+			It("does not modify the Queue", func() {
+				updateResponse, _, err := sqsBroker.UpdateWithContext(instanceID, updateDetails, nil, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(queue.ModifyCalled).To(BeFalse())
+				Expect(updateResponse.Description).To(Equal(""))
+			})
+		})
+
+		Context("when the Service Plan no longer has a Policy", func() {
+			BeforeEach(func() {
+				queue.DescribeQueueDetails.Policy = `{"Version":"2012-10-17","Statement":[{"Sid":"plan"}]}`
+			})
+
+			It("removes the Queue Policy", func() {
+				updateResponse, _, err := sqsBroker.UpdateWithContext(instanceID, updateDetails, nil, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(queue.SetPolicyCalled).To(BeTrue())
+				Expect(queue.SetPolicyPolicy).To(Equal(""))
+				Expect(updateResponse.Description).To(ContainSubstring("policy (removed)"))
+			})
+		})
+
+		Context("when describing the Queue fails", func() {
+			BeforeEach(func() {
+				queue.DescribeError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+				Expect(queue.ModifyCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the Instance is not stored", func() {
+			BeforeEach(func() {
+				store.GetInstanceError = brokerstore.ErrInstanceDoesNotExist
+			})
+
+			It("saves the Instance from the previous values", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(store.SaveInstanceInstance).To(Equal(brokerstore.Instance{
+					InstanceID:       instanceID,
+					ServiceID:        "Service-2",
+					PlanID:           "Plan-2",
+					OrganizationGUID: "organization-id",
+					SpaceGUID:        "space-id",
+				}))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("keeps the Queue attributes not set by the Service Plan or the Parameters", func() {
+				updateDetails.Parameters = map[string]interface{}{"delay_seconds": "1"}
+
+				updateResponse, _, err := sqsBroker.UpdateWithContext(instanceID, updateDetails, nil, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(queue.ModifyQueueDetails.DelaySeconds).To(Equal("1"))
+				Expect(queue.ModifyQueueDetails.VisibilityTimeout).To(Equal(""))
+				Expect(updateResponse.Description).To(Equal("Queue attributes changed: delay_seconds (5 -> 1)"))
+			})
+		})
+
+		Context("when getting the Instance fails", func() {
+			BeforeEach(func() {
+				store.GetInstanceError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+		})
+
+		Context("when the Service Plan is in another Region", func() {
+			BeforeEach(func() {
+				planRegion = "plan-region"
+				store.GetInstanceInstance = brokerstore.Instance{
+					InstanceID: instanceID,
+					Region:     "eu-west-1",
+				}
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err).To(BeAssignableToTypeOf(&RegionMoveNotAllowedError{}))
+				Expect(err.Error()).To(Equal("Service Plan 'Plan-2' is in region 'plan-region', instances can not be moved from region 'eu-west-1'"))
+				Expect(queue.ModifyCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the Service Plan assumes another Role", func() {
+			BeforeEach(func() {
+				planAccountRole = AccountRole{RoleArn: "plan-role-arn"}
+				store.GetInstanceInstance = brokerstore.Instance{
+					InstanceID: instanceID,
+					RoleArn:    "instance-role-arn",
+				}
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err).To(BeAssignableToTypeOf(&AccountMoveNotAllowedError{}))
+				Expect(err.Error()).To(Equal("Service Plan 'Plan-2' assumes role 'plan-role-arn', instances can not be moved to another account"))
+				Expect(queue.ModifyCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the Instance lives in another Account", func() {
+			BeforeEach(func() {
+				store.GetInstanceInstance = brokerstore.Instance{
+					InstanceID: instanceID,
+					RoleArn:    "instance-role-arn",
+					ExternalID: "instance-external-id",
+				}
+			})
+
+			It("modifies the Queue assuming the Instance Role", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(clientsAccountRoles).To(Equal([]AccountRole{
+					AccountRole{RoleArn: "instance-role-arn", ExternalID: "instance-external-id"},
+				}))
+				Expect(queue.ModifyCalled).To(BeTrue())
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the Instance lives in another Region", func() {
+			BeforeEach(func() {
+				store.GetInstanceInstance = brokerstore.Instance{
+					InstanceID: instanceID,
+					Region:     "eu-west-1",
+				}
+			})
+
+			It("modifies the Queue in the Instance Region", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(clientsRegions).To(Equal([]string{"eu-west-1"}))
+				Expect(queue.ModifyCalled).To(BeTrue())
+				Expect(store.SaveInstanceInstance.Region).To(Equal("eu-west-1"))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the Service Plan is restricted to some platforms", func() {
+			BeforeEach(func() {
+				planPlatforms = []string{"kubernetes"}
+				store.GetInstanceInstance = brokerstore.Instance{
+					InstanceID:       instanceID,
+					OrganizationGUID: "organization-id",
+					SpaceGUID:        "space-id",
+				}
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err).To(BeAssignableToTypeOf(&PlatformNotAllowedError{}))
+				Expect(err.Error()).To(Equal("Service Plan 'Plan-2' is not available on platform 'cloudfoundry'"))
+				Expect(queue.ModifyCalled).To(BeFalse())
+			})
+
+			Context("and the Instance has been provisioned from an allowed platform", func() {
+				BeforeEach(func() {
+					store.GetInstanceInstance = brokerstore.Instance{
+						InstanceID: instanceID,
+						Context:    map[string]interface{}{"platform": "kubernetes", "namespace": "namespace-1"},
+					}
+				})
+
+				It("keeps the stored request Context", func() {
+					_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(store.SaveInstanceInstance.Context).To(Equal(map[string]interface{}{"platform": "kubernetes", "namespace": "namespace-1"}))
+					Expect(err).ToNot(HaveOccurred())
+				})
+			})
+
+			Context("and has a request Context from an allowed platform", func() {
+				It("saves the new request Context", func() {
+					requestContext := map[string]interface{}{"platform": "kubernetes", "namespace": "namespace-2"}
+					_, _, err := sqsBroker.UpdateWithContext(instanceID, updateDetails, requestContext, acceptsIncomplete)
+					Expect(store.SaveInstanceInstance.Context).To(Equal(requestContext))
+					Expect(err).ToNot(HaveOccurred())
+				})
+			})
+		})
+
+		Context("when has DelaySeconds", func() {
+			BeforeEach(func() {
+				sqsProperties2.DelaySeconds = "test-delay-seconds"
+			})
+
+			It("makes the proper calls", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(queue.ModifyQueueDetails.DelaySeconds).To(Equal("test-delay-seconds"))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			Context("and has DelaySeconds Parameter", func() {
+				BeforeEach(func() {
+					updateDetails.Parameters = map[string]interface{}{"delay_seconds": "test-delay-seconds-parameter"}
+				})
+
+				It("makes the proper calls", func() {
+					_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(queue.ModifyQueueDetails.DelaySeconds).To(Equal("test-delay-seconds-parameter"))
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				Context("but user update parameters are not allowed", func() {
+					BeforeEach(func() {
+						allowUserUpdateParameters = false
+					})
+
+					It("makes the proper calls", func() {
+						_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+						Expect(queue.ModifyQueueDetails.DelaySeconds).To(Equal("test-delay-seconds"))
+						Expect(err).ToNot(HaveOccurred())
+					})
+				})
+			})
+		})
+
+		Context("when has MaximumMessageSize", func() {
+			BeforeEach(func() {
+				sqsProperties2.MaximumMessageSize = "test-maximum-message-size"
+			})
+
+			It("makes the proper calls", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(queue.ModifyQueueDetails.MaximumMessageSize).To(Equal("test-maximum-message-size"))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			Context("and has MaximumMessageSize Parameter", func() {
+				BeforeEach(func() {
+					updateDetails.Parameters = map[string]interface{}{"maximum_message_size": "test-maximum-message-size-parameter"}
+				})
+
+				It("makes the proper calls", func() {
+					_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(queue.ModifyQueueDetails.MaximumMessageSize).To(Equal("test-maximum-message-size-parameter"))
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				Context("but user update parameters are not allowed", func() {
+					BeforeEach(func() {
+						allowUserUpdateParameters = false
+					})
+
+					It("makes the proper calls", func() {
+						_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+						Expect(queue.ModifyQueueDetails.MaximumMessageSize).To(Equal("test-maximum-message-size"))
+						Expect(err).ToNot(HaveOccurred())
+					})
+				})
+			})
+		})
+
+		Context("when has MessageRetentionPeriod", func() {
+			BeforeEach(func() {
+				sqsProperties2.MessageRetentionPeriod = "test-message-retention-period"
+			})
+
+			It("makes the proper calls", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(queue.ModifyQueueDetails.MessageRetentionPeriod).To(Equal("test-message-retention-period"))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			Context("and has MessageRetentionPeriod Parameter", func() {
+				BeforeEach(func() {
+					updateDetails.Parameters = map[string]interface{}{"message_retention_period": "test-message-retention-period-parameter"}
+				})
+
+				It("makes the proper calls", func() {
+					_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(queue.ModifyQueueDetails.MessageRetentionPeriod).To(Equal("test-message-retention-period-parameter"))
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				Context("but user update parameters are not allowed", func() {
+					BeforeEach(func() {
+						allowUserUpdateParameters = false
+					})
+
+					It("makes the proper calls", func() {
+						_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+						Expect(queue.ModifyQueueDetails.MessageRetentionPeriod).To(Equal("test-message-retention-period"))
+						Expect(err).ToNot(HaveOccurred())
+					})
+				})
+			})
+		})
+
+		Context("when has Policy", func() {
+			BeforeEach(func() {
+				sqsProperties2.Policy = "test-policy"
+			})
+
+			It("makes the proper calls", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(queue.ModifyQueueDetails.Policy).To(Equal("test-policy"))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when has a Policy template", func() {
+			BeforeEach(func() {
+				sqsProperties2.Policy = `{"Statement":[{"Resource":"{{.QueueArn}}","Condition":{"ArnLike":{"aws:SourceArn":{{json .SourceArns}}}}}]}`
+				updateDetails.Parameters = map[string]interface{}{"source_arns": []interface{}{"arn:aws:sns:sqs-region:123456789012:topic"}}
+				queue.DescribeQueueDetails.QueueArn = "arn:aws:sqs:sqs-region:123456789012:cf-instance-id"
+			})
+
+			It("renders the Policy for the Queue", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(queue.ModifyQueueDetails.Policy).To(MatchJSON(`{"Statement":[{"Resource":"arn:aws:sqs:sqs-region:123456789012:cf-instance-id","Condition":{"ArnLike":{"aws:SourceArn":["arn:aws:sns:sqs-region:123456789012:topic"]}}}]}`))
+			})
+
+			Context("and a Source ARN is not valid", func() {
+				BeforeEach(func() {
+					updateDetails.Parameters = map[string]interface{}{"source_arns": []interface{}{`arn:aws:sns:sqs-region:123456789012:topic"],"Effect":"Allow`}}
+				})
+
+				It("returns the proper error", func() {
+					_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(err).To(BeAssignableToTypeOf(&InvalidSourceArnError{}))
+					Expect(queue.ModifyCalled).To(BeFalse())
+				})
+			})
+
+			Context("and a Source ARN is not an ARN", func() {
+				BeforeEach(func() {
+					updateDetails.Parameters = map[string]interface{}{"source_arns": []interface{}{"123456789012"}}
+				})
+
+				It("returns the proper error", func() {
+					_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(err).To(BeAssignableToTypeOf(&InvalidSourceArnError{}))
+					Expect(queue.ModifyCalled).To(BeFalse())
+				})
+			})
+		})
+
+		Context("when has ReceiveMessageWaitTimeSeconds", func() {
+			BeforeEach(func() {
+				sqsProperties2.ReceiveMessageWaitTimeSeconds = "test-receive-message-wait-time-seconds"
+			})
+
+			It("makes the proper calls", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(queue.ModifyQueueDetails.ReceiveMessageWaitTimeSeconds).To(Equal("test-receive-message-wait-time-seconds"))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			Context("and has ReceiveMessageWaitTimeSeconds Parameter", func() {
+				BeforeEach(func() {
+					updateDetails.Parameters = map[string]interface{}{"receive_message_wait_time_seconds": "test-receive-message-wait-time-seconds-parameter"}
+				})
+
+				It("makes the proper calls", func() {
+					_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(queue.ModifyQueueDetails.ReceiveMessageWaitTimeSeconds).To(Equal("test-receive-message-wait-time-seconds-parameter"))
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				Context("but user update parameters are not allowed", func() {
+					BeforeEach(func() {
+						allowUserUpdateParameters = false
+					})
+
+					It("makes the proper calls", func() {
+						_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+						Expect(queue.ModifyQueueDetails.ReceiveMessageWaitTimeSeconds).To(Equal("test-receive-message-wait-time-seconds"))
+						Expect(err).ToNot(HaveOccurred())
+					})
+				})
+			})
+		})
+
+		Context("when has VisibilityTimeout", func() {
+			BeforeEach(func() {
+				sqsProperties2.VisibilityTimeout = "test-visibility-timeout"
+			})
+
+			It("makes the proper calls", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(queue.ModifyQueueDetails.VisibilityTimeout).To(Equal("test-visibility-timeout"))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			Context("and has VisibilityTimeout Parameter", func() {
+				BeforeEach(func() {
+					updateDetails.Parameters = map[string]interface{}{"visibility_timeout": "test-visibility-timeout-parameter"}
+				})
+
+				It("makes the proper calls", func() {
+					_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(queue.ModifyQueueDetails.VisibilityTimeout).To(Equal("test-visibility-timeout-parameter"))
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				Context("but user update parameters are not allowed", func() {
+					BeforeEach(func() {
+						allowUserUpdateParameters = false
+					})
+
+					It("makes the proper calls", func() {
+						_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+						Expect(queue.ModifyQueueDetails.VisibilityTimeout).To(Equal("test-visibility-timeout"))
+						Expect(err).ToNot(HaveOccurred())
+					})
+				})
+			})
+		})
+
+		Context("when Parameters are not valid", func() {
+			BeforeEach(func() {
+				updateDetails.Parameters = map[string]interface{}{"delay_seconds": true}
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("'delay_seconds' expected type 'string', got unconvertible type 'bool'"))
+			})
+
+			Context("but user update parameters are not allowed", func() {
+				BeforeEach(func() {
+					allowUserUpdateParameters = false
+				})
+
+				It("does not return an error", func() {
+					_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+				})
+			})
+		})
+
+		Context("when Service is not found", func() {
+			BeforeEach(func() {
+				updateDetails.ServiceID = "unknown"
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Service 'unknown' not found"))
+			})
+		})
+
+		Context("when Plans is not updateable", func() {
+			BeforeEach(func() {
+				planUpdateable = false
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err).To(Equal(brokerapi.ErrInstanceNotUpdateable))
+			})
+		})
+
+		Context("when Service Plan is not found", func() {
+			BeforeEach(func() {
+				updateDetails.PlanID = "unknown"
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Service Plan 'unknown' not found"))
+			})
+		})
+
+		Context("when modifying the Queue fails", func() {
+			BeforeEach(func() {
+				queue.ModifyError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+		})
+	})
+
+	var _ = Describe("Deprovision", func() {
+		var (
+			deprovisionDetails brokerapi.DeprovisionDetails
+			acceptsIncomplete  bool
+		)
+
+		BeforeEach(func() {
+			deprovisionDetails = brokerapi.DeprovisionDetails{
+				ServiceID: "Service-1",
+				PlanID:    "Plan-1",
+			}
+			acceptsIncomplete = false
+		})
+
+		It("returns the proper response", func() {
+			asynch, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+			Expect(asynch).To(BeFalse())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("makes the proper calls", func() {
+			_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+			Expect(queue.DeleteCalled).To(BeTrue())
+			Expect(queue.DeleteQueueName).To(Equal(queueName))
+			Expect(store.DeleteInstanceCalled).To(BeTrue())
+			Expect(store.DeleteInstanceInstanceID).To(Equal(instanceID))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("audits the Queue deletion", func() {
+			_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(auditLogger.LogEvents).To(Equal([]auditlog.Event{
+				auditlog.Event{
+					Action:     "delete-queue",
+					Outcome:    auditlog.OutcomeSuccess,
+					InstanceID: instanceID,
+					Resources: []auditlog.Resource{
+						auditlog.Resource{Type: auditlog.ResourceSQSQueue, Name: queueName, Region: "sqs-region"},
+					},
+				},
+			}))
+		})
+
+		Context("when the Instance is not stored", func() {
+			BeforeEach(func() {
+				store.DeleteInstanceError = brokerstore.ErrInstanceDoesNotExist
+			})
+
+			It("does not return an error", func() {
+				_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when deleting the Instance fails", func() {
+			BeforeEach(func() {
+				store.DeleteInstanceError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+		})
+
+		Context("when deleting the Queue fails", func() {
+			BeforeEach(func() {
+				queue.DeleteError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("when the Queue does not exists", func() {
+				BeforeEach(func() {
+					queue.DeleteError = awssqs.ErrQueueDoesNotExist
+				})
+
+				It("returns the proper error", func() {
+					_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+				})
+			})
+		})
+
+		Context("when Service Plan has deletion protection", func() {
+			BeforeEach(func() {
+				deletionProtection = true
+				queue.DescribeQueueDetails = awssqs.QueueDetails{
+					ApproximateNumberOfMessages:           "0",
+					ApproximateNumberOfMessagesNotVisible: "0",
+				}
+			})
+
+			It("makes the proper calls", func() {
+				_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(queue.DescribeCalled).To(BeTrue())
+				Expect(queue.DescribeQueueName).To(Equal(queueName))
+				Expect(queue.DeleteCalled).To(BeTrue())
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			Context("and the Queue has messages", func() {
+				BeforeEach(func() {
+					queue.DescribeQueueDetails.ApproximateNumberOfMessages = "12"
+					queue.DescribeQueueDetails.ApproximateNumberOfMessagesNotVisible = "3"
+				})
+
+				It("returns the proper error", func() {
+					_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(&QueueNotEmptyError{
+						QueueName:          queueName,
+						Messages:           12,
+						MessagesNotVisible: 3,
+					}))
+					Expect(queue.DeleteCalled).To(BeFalse())
+				})
+
+				Context("but deprovision is forced", func() {
+					It("deletes the Queue", func() {
+						asynch, err := sqsBroker.ForceDeprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+						Expect(asynch).To(BeFalse())
+						Expect(queue.DescribeCalled).To(BeFalse())
+						Expect(queue.DeleteCalled).To(BeTrue())
+						Expect(queue.DeleteQueueName).To(Equal(queueName))
+						Expect(err).ToNot(HaveOccurred())
+					})
+				})
+			})
+
+			Context("and the Queue has in flight messages", func() {
+				BeforeEach(func() {
+					queue.DescribeQueueDetails.ApproximateNumberOfMessagesNotVisible = "1"
+				})
+
+				It("returns the proper error", func() {
+					_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("still holds 0 messages (1 in flight)"))
+				})
+			})
+
+			Context("and describing the Queue fails", func() {
+				BeforeEach(func() {
+					queue.DescribeError = awssqs.ErrQueueDoesNotExist
+				})
+
+				It("returns the proper error", func() {
+					_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+				})
+			})
+		})
+
+		Context("when Service Plan archives the Queue on deletion", func() {
+			var messages []awssqs.Message
+
+			BeforeEach(func() {
+				deletionArchiveMode = ArchiveQueueDeletionMode
+				acceptsIncomplete = true
+				messages = []awssqs.Message{
+					awssqs.Message{Body: "message-1", ReceiptHandle: "receipt-handle-1"},
+					awssqs.Message{Body: "message-2", ReceiptHandle: "receipt-handle-2"},
+				}
+				queue.ReceiveMessagesMessages = messages
+				queue.DescribeQueueDetailsSequence = []awssqs.QueueDetails{
+					awssqs.QueueDetails{
+						ApproximateNumberOfMessages:           "2",
+						ApproximateNumberOfMessagesNotVisible: "0",
+					},
+				}
+				queue.DescribeQueueDetails = awssqs.QueueDetails{
+					ApproximateNumberOfMessages:           "0",
+					ApproximateNumberOfMessagesDelayed:    "0",
+					ApproximateNumberOfMessagesNotVisible: "0",
+				}
+				store.GetInstanceInstance = brokerstore.Instance{
+					InstanceID: instanceID,
+					ServiceID:  "Service-1",
+					PlanID:     "Plan-1",
+				}
+			})
+
+			deleted := func() bool {
+				return store.DeleteInstanceCalled
+			}
+
+			lastOperationState := func() string {
+				return store.SaveInstanceInstance.LastOperationState
+			}
+
+			It("archives the messages and deletes the Queue in the background", func() {
+				asynch, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(asynch).To(BeTrue())
+				Expect(store.SaveInstanceInstance.LastOperationDescription).To(Equal("Archiving messages to queue 'cf-instance-id-archive'"))
+
+				Eventually(deleted).Should(BeTrue())
+				Expect(queue.CreateCalled).To(BeTrue())
+				Expect(queue.CreateQueueName).To(Equal("cf-instance-id-archive"))
+				Expect(queue.CreateQueueDetails.MessageRetentionPeriod).To(Equal("1209600"))
+				Expect(queue.ReceiveMessagesQueueName).To(Equal(queueName))
+				Expect(queue.SendMessagesQueueName).To(Equal("cf-instance-id-archive"))
+				Expect(queue.SendMessagesMessages).To(Equal(messages))
+				Expect(queue.DeleteMessagesQueueName).To(Equal(queueName))
+				Expect(queue.DeleteMessagesMessages).To(Equal(messages))
+				Expect(queue.DeleteCalled).To(BeTrue())
+				Expect(queue.DeleteQueueName).To(Equal(queueName))
+			})
+
+			It("requires accepts_incomplete", func() {
+				_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, false)
+				Expect(err).To(Equal(brokerapi.ErrAsyncRequired))
+				Expect(queue.CreateCalled).To(BeFalse())
+				Expect(queue.DeleteCalled).To(BeFalse())
+			})
+
+			Context("when the Queue has in flight messages", func() {
+				BeforeEach(func() {
+					queue.DescribeQueueDetailsSequence[0].ApproximateNumberOfMessagesNotVisible = "3"
+				})
+
+				It("returns the proper error", func() {
+					_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Expect(err).To(Equal(&MessagesInFlightError{
+						QueueName:          queueName,
+						MessagesNotVisible: 3,
+					}))
+					Expect(queue.CreateCalled).To(BeFalse())
+					Expect(queue.DeleteCalled).To(BeFalse())
+				})
+			})
+
+			Context("when producers keep sending messages", func() {
+				BeforeEach(func() {
+					queue.DescribeQueueDetailsSequence[0].ApproximateNumberOfMessages = "0"
+					queue.ReceiveMessagesMessages = make([]awssqs.Message, 11)
+				})
+
+				It("gives up without deleting the Queue", func() {
+					_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+					Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationFailed))
+					Expect(store.SaveInstanceInstance.LastOperationDescription).To(Equal("Queue 'cf-instance-id' still receives messages after 11 were moved, stop its producers and try again"))
+					Expect(queue.DeleteCalled).To(BeFalse())
+				})
+			})
+
+			Context("when the Queue has delayed messages", func() {
+				var delayedMessage awssqs.Message
+
+				BeforeEach(func() {
+					delayedMessage = awssqs.Message{Body: "message-3", ReceiptHandle: "receipt-handle-3"}
+					queue.ReceiveMessagesMessages = nil
+					queue.ReceiveMessagesBatches = [][]awssqs.Message{messages, []awssqs.Message{}, []awssqs.Message{delayedMessage}}
+					queue.DescribeQueueDetailsSequence = []awssqs.QueueDetails{
+						awssqs.QueueDetails{ApproximateNumberOfMessages: "2", ApproximateNumberOfMessagesDelayed: "1"},
+						awssqs.QueueDetails{ApproximateNumberOfMessagesDelayed: "1"},
+					}
+				})
+
+				It("waits for them before deleting the Queue", func() {
+					_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+
+					Eventually(deleted).Should(BeTrue())
+					Expect(queue.SendMessagesMessages).To(Equal(append(messages, delayedMessage)))
+					Expect(queue.DeleteMessagesMessages).To(Equal(append(messages, delayedMessage)))
+					Expect(queue.DeleteCalled).To(BeTrue())
+				})
+
+				Context("but they are never delivered", func() {
+					BeforeEach(func() {
+						queue.ReceiveMessagesBatches = [][]awssqs.Message{messages}
+						queue.DescribeQueueDetails.ApproximateNumberOfMessagesDelayed = "1"
+					})
+
+					It("gives up without deleting the Queue", func() {
+						_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
 						Expect(err).ToNot(HaveOccurred())
+						Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationFailed))
+						Expect(store.SaveInstanceInstance.LastOperationDescription).To(Equal("Queue 'cf-instance-id' still holds 1 delayed and 0 in flight messages after 2 were moved, stop its consumers and try again"))
+						Expect(queue.DeleteCalled).To(BeFalse())
+						Expect(store.DeleteInstanceCalled).To(BeFalse())
 					})
 				})
 			})
+
+			Context("when creating the archive Queue fails", func() {
+				BeforeEach(func() {
+					queue.CreateError = errors.New("operation failed")
+				})
+
+				It("does not delete the Queue", func() {
+					_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+					Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationFailed))
+					Expect(store.SaveInstanceInstance.LastOperationDescription).To(Equal("operation failed"))
+					Expect(queue.DeleteCalled).To(BeFalse())
+				})
+			})
+
+			Context("when moving the messages fails", func() {
+				BeforeEach(func() {
+					queue.SendMessagesError = errors.New("operation failed")
+				})
+
+				It("does not delete the Queue", func() {
+					_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Expect(err).ToNot(HaveOccurred())
+					Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationFailed))
+					Expect(queue.DeleteMessagesCalled).To(BeFalse())
+					Expect(queue.DeleteCalled).To(BeFalse())
+				})
+			})
+
+			Context("when the Queue is being archived", func() {
+				BeforeEach(func() {
+					store.GetInstanceInstance.LastOperationState = brokerapi.LastOperationInProgress
+				})
+
+				It("returns the proper error", func() {
+					_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+					Expect(err).To(Equal(&InstanceBusyError{InstanceID: instanceID}))
+					Expect(queue.CreateCalled).To(BeFalse())
+				})
+			})
+		})
+	})
+
+	var _ = Describe("GetInstance", func() {
+		var (
+			properInstanceResponse InstanceResponse
+		)
+
+		BeforeEach(func() {
+			store.GetInstanceInstance = brokerstore.Instance{
+				InstanceID: instanceID,
+				ServiceID:  "Service-1",
+				PlanID:     "Plan-1",
+				Parameters: map[string]interface{}{"delay_seconds": "1"},
+			}
+			queue.DescribeQueueDetails = awssqs.QueueDetails{
+				QueueURL:                              "queue-url",
+				QueueArn:                              "queue-arn",
+				DelaySeconds:                          "1",
+				MaximumMessageSize:                    "262144",
+				MessageRetentionPeriod:                "345600",
+				ReceiveMessageWaitTimeSeconds:         "0",
+				VisibilityTimeout:                     "30",
+				ApproximateNumberOfMessages:           "5",
+				ApproximateNumberOfMessagesNotVisible: "2",
+				ApproximateNumberOfMessagesDelayed:    "1",
+				CreatedTimestamp:                      "1445000000",
+				LastModifiedTimestamp:                 "1446000000",
+			}
+
+			properInstanceResponse = InstanceResponse{
+				ServiceID:  "Service-1",
+				PlanID:     "Plan-1",
+				Parameters: map[string]interface{}{"delay_seconds": "1"},
+				Attributes: InstanceAttributes{
+					QueueURL:                      "queue-url",
+					QueueArn:                      "queue-arn",
+					DelaySeconds:                  "1",
+					MaximumMessageSize:            "262144",
+					MessageRetentionPeriod:        "345600",
+					ReceiveMessageWaitTimeSeconds: "0",
+					VisibilityTimeout:             "30",
+				},
+				Stats: InstanceStats{
+					ApproximateNumberOfMessages:           "5",
+					ApproximateNumberOfMessagesNotVisible: "2",
+					ApproximateNumberOfMessagesDelayed:    "1",
+					CreatedTimestamp:                      "1445000000",
+					LastModifiedTimestamp:                 "1446000000",
+				},
+			}
+		})
+
+		It("returns the proper response", func() {
+			instanceResponse, err := sqsBroker.GetInstance(instanceID)
+			Expect(instanceResponse).To(Equal(properInstanceResponse))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("makes the proper calls", func() {
+			_, err := sqsBroker.GetInstance(instanceID)
+			Expect(store.GetInstanceCalled).To(BeTrue())
+			Expect(store.GetInstanceInstanceID).To(Equal(instanceID))
+			Expect(queue.DescribeCalled).To(BeTrue())
+			Expect(queue.DescribeQueueName).To(Equal(queueName))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when the Instance is not stored", func() {
+			BeforeEach(func() {
+				store.GetInstanceInstance = brokerstore.Instance{}
+				store.GetInstanceError = brokerstore.ErrInstanceDoesNotExist
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.GetInstance(instanceID)
+				Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+				Expect(queue.DescribeCalled).To(BeFalse())
+			})
+		})
+
+		Context("when getting the Instance fails", func() {
+			BeforeEach(func() {
+				store.GetInstanceError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.GetInstance(instanceID)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+		})
+
+		Context("when describing the Queue fails", func() {
+			BeforeEach(func() {
+				queue.DescribeError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.GetInstance(instanceID)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("when the Queue does not exists", func() {
+				BeforeEach(func() {
+					queue.DescribeError = awssqs.ErrQueueDoesNotExist
+				})
+
+				It("returns the proper error", func() {
+					_, err := sqsBroker.GetInstance(instanceID)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+				})
+			})
+		})
+	})
+
+	var _ = Describe("Bind", func() {
+		var (
+			bindDetails brokerapi.BindDetails
+		)
+
+		BeforeEach(func() {
+			bindDetails = brokerapi.BindDetails{
+				ServiceID:  "Service-1",
+				PlanID:     "Plan-1",
+				AppGUID:    "Application-1",
+				Parameters: map[string]interface{}{},
+			}
+
+			queue.DescribeQueueDetails = awssqs.QueueDetails{
+				QueueURL: "queue-url",
+				QueueArn: "queue-arn",
+			}
+
+			user.CreateAccessKeyAccessKeyID = "user-access-key-id"
+			user.CreateAccessKeySecretAccessKey = "user-secret-access-key"
+
+			user.CreatePolicyPolicyARN = "policy-arn"
+
+			user.DescribeUserDetails = awsiam.UserDetails{
+				UserName: userName,
+				UserARN:  "user-arn",
+			}
+		})
+
+		It("returns the proper response", func() {
+			bindingResponse, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+			credentials := bindingResponse.Credentials.(*brokerapi.CredentialsHash)
+			Expect(bindingResponse.SyslogDrainURL).To(BeEmpty())
+			Expect(credentials.Host).To(Equal(""))
+			Expect(credentials.Port).To(Equal(int64(0)))
+			Expect(credentials.Name).To(Equal(""))
+			Expect(credentials.Username).To(Equal("user-access-key-id"))
+			Expect(credentials.Password).To(Equal("user-secret-access-key"))
+			Expect(credentials.URI).To(Equal("queue-url"))
+			Expect(credentials.JDBCURI).To(Equal(""))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("makes the proper calls", func() {
+			_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+			Expect(queue.DescribeCalled).To(BeTrue())
+			Expect(queue.DescribeQueueName).To(Equal(queueName))
+			Expect(user.CreateCalled).To(BeTrue())
+			Expect(user.CreateUserName).To(Equal(userName))
+			Expect(user.CreateAccessKeyCalled).To(BeTrue())
+			Expect(user.CreateAccessKeyUserName).To(Equal(userName))
+			Expect(user.CreatePolicyCalled).To(BeTrue())
+			Expect(user.CreatePolicyPolicyName).To(Equal(policyName))
+			Expect(user.CreatePolicyEffect).To(Equal("Allow"))
+			Expect(user.CreatePolicyAction).To(Equal("sqs:*"))
+			Expect(user.CreatePolicyResource).To(Equal("queue-arn"))
+			Expect(user.AttachUserPolicyCalled).To(BeTrue())
+			Expect(user.AttachUserPolicyUserName).To(Equal(userName))
+			Expect(user.AttachUserPolicyPolicyARN).To(Equal("policy-arn"))
+			Expect(user.DeleteCalled).To(BeFalse())
+			Expect(user.DeleteAccessKeyCalled).To(BeFalse())
+			Expect(user.DeletePolicyCalled).To(BeFalse())
+			Expect(store.SaveBindingCalled).To(BeTrue())
+			Expect(store.SaveBindingBinding).To(Equal(brokerstore.Binding{
+				BindingID:   bindingID,
+				InstanceID:  instanceID,
+				ServiceID:   "Service-1",
+				PlanID:      "Plan-1",
+				AppGUID:     "Application-1",
+				Parameters:  map[string]interface{}{},
+				AccessKeyID: "user-access-key-id",
+			}))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("audits the User creation without its Secret Access Key", func() {
+			user.CreateUserARN = "user-arn"
+
+			_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(auditLogger.LogEvents).To(Equal([]auditlog.Event{
+				auditlog.Event{
+					Action:     "create-binding-user",
+					Outcome:    auditlog.OutcomeSuccess,
+					InstanceID: instanceID,
+					BindingID:  bindingID,
+					Resources: []auditlog.Resource{
+						auditlog.Resource{Type: auditlog.ResourceIAMUser, Name: userName, ARN: "user-arn"},
+						auditlog.Resource{Type: auditlog.ResourceIAMAccessKey, ID: "user-access-key-id"},
+						auditlog.Resource{Type: auditlog.ResourceIAMPolicy, ARN: "policy-arn"},
+					},
+				},
+			}))
+		})
+
+		Context("when the Instance has been provisioned from Cloud Foundry", func() {
+			BeforeEach(func() {
+				store.GetInstanceInstance = brokerstore.Instance{
+					InstanceID:       instanceID,
+					OrganizationGUID: "organization-id",
+					SpaceGUID:        "space-id",
+				}
+			})
+
+			It("creates the User under the organization and space path", func() {
+				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+				Expect(user.CreateUserPath).To(Equal("/cf/cloudfoundry/organization-id/space-id/"))
+				Expect(err).ToNot(HaveOccurred())
+			})
 		})
 
-		Context("when Parameters are not valid", func() {
+		Context("when has a Kubernetes request Context", func() {
+			var requestContext map[string]interface{}
+
 			BeforeEach(func() {
-				updateDetails.Parameters = map[string]interface{}{"delay_seconds": true}
+				requestContext = map[string]interface{}{
+					"platform":  "kubernetes",
+					"namespace": "namespace-1",
+					"clusterid": "cluster/1",
+				}
+			})
+
+			It("creates the User under the cluster and namespace path", func() {
+				_, _, err := sqsBroker.AsyncBind(instanceID, bindingID, bindDetails, requestContext, false)
+				Expect(user.CreateUserPath).To(Equal("/cf/kubernetes/cluster-1/namespace-1/"))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("saves the request Context", func() {
+				_, _, err := sqsBroker.AsyncBind(instanceID, bindingID, bindDetails, requestContext, false)
+				Expect(store.SaveBindingBinding.Context).To(Equal(requestContext))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when binding secrets are stored", func() {
+			BeforeEach(func() {
+				storeBindingSecrets = true
+			})
+
+			It("saves the Secret Access Key", func() {
+				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+				Expect(store.SaveBindingBinding.SecretAccessKey).To(Equal("user-secret-access-key"))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the Instance has reached its Bindings quota", func() {
+			BeforeEach(func() {
+				quotas.MaxBindingsPerInstance = 1
+				store.ListBindingsBindings = []brokerstore.Binding{
+					brokerstore.Binding{BindingID: "binding-1", InstanceID: instanceID},
+					brokerstore.Binding{BindingID: "binding-2", InstanceID: "instance-2"},
+				}
 			})
 
 			It("returns the proper error", func() {
-				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("'delay_seconds' expected type 'string', got unconvertible type 'bool'"))
+				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+				Expect(err).To(BeAssignableToTypeOf(&BindingLimitMetError{}))
+				Expect(user.CreateCalled).To(BeFalse())
+				Expect(store.SaveBindingCalled).To(BeFalse())
 			})
 
-			Context("but user update parameters are not allowed", func() {
-				BeforeEach(func() {
-					allowUserUpdateParameters = false
-				})
+			It("does not count the Binding being created", func() {
+				store.ListBindingsBindings[0].BindingID = bindingID
+				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+				Expect(user.CreateCalled).To(BeTrue())
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
 
-				It("does not return an error", func() {
-					_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
-					Expect(err).ToNot(HaveOccurred())
-				})
+		Context("when saving the Binding fails", func() {
+			BeforeEach(func() {
+				store.SaveBindingError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			It("makes the proper calls", func() {
+				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+				Expect(err).To(HaveOccurred())
+				Expect(user.ListAccessKeysCalled).To(BeTrue())
+				Expect(user.ListAccessKeysUserName).To(Equal(userName))
+				Expect(user.ListAttachedUserPoliciesCalled).To(BeTrue())
+				Expect(user.DeleteCalled).To(BeTrue())
+				Expect(user.DeleteUserName).To(Equal(userName))
 			})
 		})
 
 		Context("when Service is not found", func() {
 			BeforeEach(func() {
-				updateDetails.ServiceID = "unknown"
+				bindDetails.ServiceID = "unknown"
 			})
 
 			It("returns the proper error", func() {
-				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("Service 'unknown' not found"))
 			})
 		})
 
-		Context("when Plans is not updateable", func() {
+		Context("when Service is not bindable", func() {
 			BeforeEach(func() {
-				planUpdateable = false
+				serviceBindable = false
 			})
 
 			It("returns the proper error", func() {
-				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
 				Expect(err).To(HaveOccurred())
-				Expect(err).To(Equal(brokerapi.ErrInstanceNotUpdateable))
+				Expect(err).To(Equal(brokerapi.ErrInstanceNotBindable))
 			})
 		})
 
-		Context("when Service Plan is not found", func() {
+		Context("when describing the Queue fails", func() {
 			BeforeEach(func() {
-				updateDetails.PlanID = "unknown"
+				queue.DescribeError = errors.New("operation failed")
 			})
 
 			It("returns the proper error", func() {
-				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
 				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("Service Plan 'unknown' not found"))
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("when the Queue does not exists", func() {
+				BeforeEach(func() {
+					queue.DescribeError = awssqs.ErrQueueDoesNotExist
+				})
+
+				It("returns the proper error", func() {
+					_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+				})
 			})
 		})
 
-		Context("when modifying the Queue fails", func() {
+		Context("when creating the User fails", func() {
 			BeforeEach(func() {
-				queue.ModifyError = errors.New("operation failed")
+				user.CreateError = errors.New("operation failed")
 			})
 
 			It("returns the proper error", func() {
-				_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("operation failed"))
 			})
 		})
-	})
 
-	var _ = Describe("Deprovision", func() {
-		var (
-			deprovisionDetails brokerapi.DeprovisionDetails
-			acceptsIncomplete  bool
-		)
+		Context("when creating the User Access Keys fails", func() {
+			BeforeEach(func() {
+				user.CreateAccessKeyError = errors.New("operation failed")
+			})
 
-		BeforeEach(func() {
-			deprovisionDetails = brokerapi.DeprovisionDetails{
-				ServiceID: "Service-1",
-				PlanID:    "Plan-1",
-			}
-			acceptsIncomplete = false
-		})
+			It("returns the proper error", func() {
+				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
 
-		It("returns the proper response", func() {
-			asynch, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
-			Expect(asynch).To(BeFalse())
-			Expect(err).ToNot(HaveOccurred())
+			It("makes the proper calls", func() {
+				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+				Expect(err).To(HaveOccurred())
+				Expect(user.DeleteCalled).To(BeTrue())
+				Expect(user.DeleteUserName).To(Equal(userName))
+			})
 		})
 
-		It("makes the proper calls", func() {
-			_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
-			Expect(queue.DeleteCalled).To(BeTrue())
-			Expect(queue.DeleteQueueName).To(Equal(queueName))
-			Expect(err).ToNot(HaveOccurred())
+		Context("when creating the User Policy fails", func() {
+			BeforeEach(func() {
+				user.CreatePolicyError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			It("makes the proper calls", func() {
+				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+				Expect(err).To(HaveOccurred())
+				Expect(user.DeleteAccessKeyCalled).To(BeTrue())
+				Expect(user.DeleteAccessKeyUserName).To(Equal(userName))
+				Expect(user.DeleteAccessKeyAccessKeyID).To(Equal("user-access-key-id"))
+				Expect(user.DeleteCalled).To(BeTrue())
+				Expect(user.DeleteUserName).To(Equal(userName))
+			})
 		})
 
-		Context("when deleting the Queue fails", func() {
+		Context("when attaching the Policy to the User fails", func() {
 			BeforeEach(func() {
-				queue.DeleteError = errors.New("operation failed")
+				user.AttachUserPolicyError = errors.New("operation failed")
 			})
 
 			It("returns the proper error", func() {
-				_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
+				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("operation failed"))
 			})
 
-			Context("when the Queue does not exists", func() {
-				BeforeEach(func() {
-					queue.DeleteError = awssqs.ErrQueueDoesNotExist
-				})
-
-				It("returns the proper error", func() {
-					_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, acceptsIncomplete)
-					Expect(err).To(HaveOccurred())
-					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
-				})
+			It("makes the proper calls", func() {
+				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+				Expect(err).To(HaveOccurred())
+				Expect(user.DeletePolicyCalled).To(BeTrue())
+				Expect(user.DeletePolicyPolicyARN).To(Equal("policy-arn"))
+				Expect(user.DeleteAccessKeyCalled).To(BeTrue())
+				Expect(user.DeleteAccessKeyUserName).To(Equal(userName))
+				Expect(user.DeleteAccessKeyAccessKeyID).To(Equal("user-access-key-id"))
+				Expect(user.DeleteCalled).To(BeTrue())
+				Expect(user.DeleteUserName).To(Equal(userName))
 			})
 		})
 	})
 
-	var _ = Describe("Bind", func() {
+	var _ = Describe("AsyncBind", func() {
 		var (
-			bindDetails brokerapi.BindDetails
+			bindDetails       brokerapi.BindDetails
+			acceptsIncomplete bool
+
+			memoryStore *brokerstore.JSONStore
 		)
 
 		BeforeEach(func() {
@@ -807,6 +2254,7 @@ var _ = Describe("SQS Broker", func() {
 				AppGUID:    "Application-1",
 				Parameters: map[string]interface{}{},
 			}
+			acceptsIncomplete = true
 
 			queue.DescribeQueueDetails = awssqs.QueueDetails{
 				QueueURL: "queue-url",
@@ -815,174 +2263,332 @@ var _ = Describe("SQS Broker", func() {
 
 			user.CreateAccessKeyAccessKeyID = "user-access-key-id"
 			user.CreateAccessKeySecretAccessKey = "user-secret-access-key"
-
 			user.CreatePolicyPolicyARN = "policy-arn"
 
-			user.DescribeUserDetails = awsiam.UserDetails{
-				UserName: userName,
-				UserARN:  "user-arn",
-			}
+			memoryStore = brokerstore.NewMemoryStore()
+		})
+
+		JustBeforeEach(func() {
+			sqsBroker = New(config, clientsFactory, memoryStore, bindingQueueFactory, auditLogger, logger)
 		})
 
+		lastOperationState := func() string {
+			lastOperationResponse, err := sqsBroker.BindingLastOperation(instanceID, bindingID)
+			Expect(err).ToNot(HaveOccurred())
+			return lastOperationResponse.State
+		}
+
 		It("returns the proper response", func() {
-			bindingResponse, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
-			credentials := bindingResponse.Credentials.(*brokerapi.CredentialsHash)
-			Expect(bindingResponse.SyslogDrainURL).To(BeEmpty())
-			Expect(credentials.Host).To(Equal(""))
-			Expect(credentials.Port).To(Equal(int64(0)))
-			Expect(credentials.Name).To(Equal(""))
-			Expect(credentials.Username).To(Equal("user-access-key-id"))
-			Expect(credentials.Password).To(Equal("user-secret-access-key"))
-			Expect(credentials.URI).To(Equal("queue-url"))
-			Expect(credentials.JDBCURI).To(Equal(""))
+			bindingResponse, asynch, err := sqsBroker.AsyncBind(instanceID, bindingID, bindDetails, nil, acceptsIncomplete)
+			Expect(bindingResponse).To(Equal(brokerapi.BindingResponse{}))
+			Expect(asynch).To(BeTrue())
 			Expect(err).ToNot(HaveOccurred())
+			Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationSucceeded))
 		})
 
-		It("makes the proper calls", func() {
-			_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
-			Expect(queue.DescribeCalled).To(BeTrue())
-			Expect(queue.DescribeQueueName).To(Equal(queueName))
-			Expect(user.CreateCalled).To(BeTrue())
+		It("creates the User and verifies the Access Key in the background", func() {
+			_, _, err := sqsBroker.AsyncBind(instanceID, bindingID, bindDetails, nil, acceptsIncomplete)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationSucceeded))
 			Expect(user.CreateUserName).To(Equal(userName))
-			Expect(user.CreateAccessKeyCalled).To(BeTrue())
-			Expect(user.CreateAccessKeyUserName).To(Equal(userName))
-			Expect(user.CreatePolicyCalled).To(BeTrue())
-			Expect(user.CreatePolicyPolicyName).To(Equal(policyName))
-			Expect(user.CreatePolicyEffect).To(Equal("Allow"))
-			Expect(user.CreatePolicyAction).To(Equal("sqs:*"))
-			Expect(user.CreatePolicyResource).To(Equal("queue-arn"))
-			Expect(user.AttachUserPolicyCalled).To(BeTrue())
-			Expect(user.AttachUserPolicyUserName).To(Equal(userName))
 			Expect(user.AttachUserPolicyPolicyARN).To(Equal("policy-arn"))
-			Expect(user.DeleteCalled).To(BeFalse())
+			Expect(bindingQueueAccessKeyID).To(Equal("user-access-key-id"))
+			Expect(bindingQueueSecretAccessKey).To(Equal("user-secret-access-key"))
+			Expect(bindingQueueRegion).To(Equal("sqs-region"))
+			Expect(bindingQueue.DescribeCalled).To(BeTrue())
+			Expect(bindingQueue.DescribeQueueName).To(Equal(queueName))
+		})
+
+		Context("when the Instance has reached its Bindings quota", func() {
+			BeforeEach(func() {
+				quotas.MaxBindingsPerInstance = 1
+				err := memoryStore.SaveBinding(brokerstore.Binding{BindingID: "binding-1", InstanceID: instanceID})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns the proper error", func() {
+				_, asynch, err := sqsBroker.AsyncBind(instanceID, bindingID, bindDetails, nil, acceptsIncomplete)
+				Expect(asynch).To(BeFalse())
+				Expect(err).To(BeAssignableToTypeOf(&BindingLimitMetError{}))
+				_, err = memoryStore.GetBinding(bindingID)
+				Expect(err).To(Equal(brokerstore.ErrBindingDoesNotExist))
+			})
+		})
+
+		Context("when the Instance lives in another Region", func() {
+			BeforeEach(func() {
+				err := memoryStore.SaveInstance(brokerstore.Instance{
+					InstanceID: instanceID,
+					Region:     "eu-west-1",
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("uses the clients of the Instance Region", func() {
+				_, _, err := sqsBroker.AsyncBind(instanceID, bindingID, bindDetails, nil, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationSucceeded))
+				Expect(clientsRegions).To(Equal([]string{"eu-west-1"}))
+				Expect(bindingQueueRegion).To(Equal("eu-west-1"))
+			})
+		})
+
+		It("hands out the credentials only once", func() {
+			_, _, err := sqsBroker.AsyncBind(instanceID, bindingID, bindDetails, nil, acceptsIncomplete)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationSucceeded))
+
+			pendingBinding, err := memoryStore.GetBinding(bindingID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pendingBinding.AccessKeyID).To(Equal("user-access-key-id"))
+			Expect(pendingBinding.SecretAccessKey).To(BeEmpty())
+
+			bindingResponse, err := sqsBroker.GetBinding(instanceID, bindingID)
+			Expect(err).ToNot(HaveOccurred())
+			credentials := bindingResponse.Credentials.(*brokerapi.CredentialsHash)
+			Expect(credentials.Username).To(Equal("user-access-key-id"))
+			Expect(credentials.Password).To(Equal("user-secret-access-key"))
 			Expect(user.DeleteAccessKeyCalled).To(BeFalse())
-			Expect(user.DeletePolicyCalled).To(BeFalse())
+
+			binding, err := memoryStore.GetBinding(bindingID)
 			Expect(err).ToNot(HaveOccurred())
+			Expect(binding.SecretAccessKey).To(BeEmpty())
+		})
+
+		Context("when the Binding is still in progress", func() {
+			BeforeEach(func() {
+				err := memoryStore.SaveBinding(brokerstore.Binding{
+					BindingID:          bindingID,
+					InstanceID:         instanceID,
+					LastOperationState: brokerapi.LastOperationInProgress,
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("does not return the Binding", func() {
+				_, err := sqsBroker.GetBinding(instanceID, bindingID)
+				Expect(err).To(Equal(brokerapi.ErrBindingDoesNotExist))
+			})
 		})
 
-		Context("when Service is not found", func() {
+		Context("when the Access Key can not be verified", func() {
 			BeforeEach(func() {
-				bindDetails.ServiceID = "unknown"
+				bindingQueue.DescribeError = errors.New("InvalidClientTokenId: The security token included in the request is invalid")
 			})
 
-			It("returns the proper error", func() {
-				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("Service 'unknown' not found"))
+			It("fails the Binding and cleans up the User", func() {
+				_, _, err := sqsBroker.AsyncBind(instanceID, bindingID, bindDetails, nil, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(lastOperationState, "5s").Should(Equal(brokerapi.LastOperationFailed))
+				Expect(user.DeleteCalled).To(BeTrue())
+				Expect(user.DeleteUserName).To(Equal(userName))
+
+				lastOperationResponse, err := sqsBroker.BindingLastOperation(instanceID, bindingID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lastOperationResponse.Description).To(ContainSubstring("InvalidClientTokenId"))
 			})
 		})
 
-		Context("when Service is not bindable", func() {
+		Context("when creating the User fails", func() {
 			BeforeEach(func() {
-				serviceBindable = false
+				user.CreateError = errors.New("operation failed")
 			})
 
-			It("returns the proper error", func() {
-				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
-				Expect(err).To(HaveOccurred())
-				Expect(err).To(Equal(brokerapi.ErrInstanceNotBindable))
+			It("fails the Binding", func() {
+				_, _, err := sqsBroker.AsyncBind(instanceID, bindingID, bindDetails, nil, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationFailed))
+				Expect(bindingQueue.DescribeCalled).To(BeFalse())
 			})
 		})
 
-		Context("when describing the Queue fails", func() {
+		Context("when the Queue does not exists", func() {
 			BeforeEach(func() {
-				queue.DescribeError = errors.New("operation failed")
+				queue.DescribeError = awssqs.ErrQueueDoesNotExist
 			})
 
 			It("returns the proper error", func() {
-				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("operation failed"))
+				_, _, err := sqsBroker.AsyncBind(instanceID, bindingID, bindDetails, nil, acceptsIncomplete)
+				Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
 			})
+		})
 
-			Context("when the Queue does not exists", func() {
-				BeforeEach(func() {
-					queue.DescribeError = awssqs.ErrQueueDoesNotExist
-				})
+		Context("when the request does not accept incomplete", func() {
+			BeforeEach(func() {
+				acceptsIncomplete = false
+			})
 
-				It("returns the proper error", func() {
-					_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
-					Expect(err).To(HaveOccurred())
-					Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
-				})
+			It("binds synchronously", func() {
+				bindingResponse, asynch, err := sqsBroker.AsyncBind(instanceID, bindingID, bindDetails, nil, acceptsIncomplete)
+				Expect(asynch).To(BeFalse())
+				Expect(err).ToNot(HaveOccurred())
+				credentials := bindingResponse.Credentials.(*brokerapi.CredentialsHash)
+				Expect(credentials.Username).To(Equal("user-access-key-id"))
+				Expect(bindingQueue.DescribeCalled).To(BeFalse())
 			})
 		})
+	})
 
-		Context("when creating the User fails", func() {
-			BeforeEach(func() {
-				user.CreateError = errors.New("operation failed")
+	var _ = Describe("FailInterruptedOperations", func() {
+		var memoryStore *brokerstore.JSONStore
+
+		BeforeEach(func() {
+			memoryStore = brokerstore.NewMemoryStore()
+			err := memoryStore.SaveInstance(brokerstore.Instance{
+				InstanceID:               instanceID,
+				LastOperationState:       brokerapi.LastOperationInProgress,
+				LastOperationDescription: "Archiving messages to queue 'cf-instance-id-archive'",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			err = memoryStore.SaveBinding(brokerstore.Binding{
+				BindingID:                bindingID,
+				InstanceID:               instanceID,
+				LastOperationState:       brokerapi.LastOperationInProgress,
+				LastOperationDescription: "Creating binding",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			err = memoryStore.SaveBinding(brokerstore.Binding{
+				BindingID:          "other-binding-id",
+				InstanceID:         instanceID,
+				LastOperationState: brokerapi.LastOperationSucceeded,
 			})
+			Expect(err).ToNot(HaveOccurred())
+		})
 
-			It("returns the proper error", func() {
-				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("operation failed"))
+		JustBeforeEach(func() {
+			sqsBroker = New(config, clientsFactory, memoryStore, bindingQueueFactory, auditLogger, logger)
+		})
+
+		It("fails the operations left in progress", func() {
+			err := sqsBroker.FailInterruptedOperations()
+			Expect(err).ToNot(HaveOccurred())
+
+			lastOperationResponse, err := sqsBroker.LastOperation(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastOperationResponse).To(Equal(brokerapi.LastOperationResponse{
+				State:       brokerapi.LastOperationFailed,
+				Description: "Interrupted by a broker restart: Archiving messages to queue 'cf-instance-id-archive'",
+			}))
+
+			lastOperationResponse, err = sqsBroker.BindingLastOperation(instanceID, bindingID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastOperationResponse).To(Equal(brokerapi.LastOperationResponse{
+				State:       brokerapi.LastOperationFailed,
+				Description: "Interrupted by a broker restart: Creating binding",
+			}))
+
+			lastOperationResponse, err = sqsBroker.BindingLastOperation(instanceID, "other-binding-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationSucceeded))
+		})
+	})
+
+	var _ = Describe("AsyncUnbind", func() {
+		var (
+			unbindDetails     brokerapi.UnbindDetails
+			acceptsIncomplete bool
+
+			memoryStore *brokerstore.JSONStore
+		)
+
+		BeforeEach(func() {
+			unbindDetails = brokerapi.UnbindDetails{
+				ServiceID: "Service-1",
+				PlanID:    "Plan-1",
+			}
+			acceptsIncomplete = true
+
+			memoryStore = brokerstore.NewMemoryStore()
+			err := memoryStore.SaveBinding(brokerstore.Binding{
+				BindingID:  bindingID,
+				InstanceID: instanceID,
 			})
+			Expect(err).ToNot(HaveOccurred())
 		})
 
-		Context("when creating the User Access Keys fails", func() {
+		JustBeforeEach(func() {
+			sqsBroker = New(config, clientsFactory, memoryStore, bindingQueueFactory, auditLogger, logger)
+		})
+
+		bindingLastOperation := func() error {
+			_, err := sqsBroker.BindingLastOperation(instanceID, bindingID)
+			return err
+		}
+
+		It("deletes the User in the background", func() {
+			asynch, err := sqsBroker.AsyncUnbind(instanceID, bindingID, unbindDetails, acceptsIncomplete)
+			Expect(asynch).To(BeTrue())
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(bindingLastOperation).Should(Equal(brokerapi.ErrBindingDoesNotExist))
+			Expect(user.DeleteCalled).To(BeTrue())
+			Expect(user.DeleteUserName).To(Equal(userName))
+		})
+
+		Context("when deleting the User fails", func() {
 			BeforeEach(func() {
-				user.CreateAccessKeyError = errors.New("operation failed")
+				user.DeleteError = errors.New("operation failed")
 			})
 
-			It("returns the proper error", func() {
-				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("operation failed"))
+			It("fails the Binding", func() {
+				_, err := sqsBroker.AsyncUnbind(instanceID, bindingID, unbindDetails, acceptsIncomplete)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(func() string {
+					lastOperationResponse, _ := sqsBroker.BindingLastOperation(instanceID, bindingID)
+					return lastOperationResponse.State
+				}).Should(Equal(brokerapi.LastOperationFailed))
 			})
+		})
 
-			It("makes the proper calls", func() {
-				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
-				Expect(err).To(HaveOccurred())
+		Context("when the request does not accept incomplete", func() {
+			BeforeEach(func() {
+				acceptsIncomplete = false
+			})
+
+			It("unbinds synchronously", func() {
+				asynch, err := sqsBroker.AsyncUnbind(instanceID, bindingID, unbindDetails, acceptsIncomplete)
+				Expect(asynch).To(BeFalse())
+				Expect(err).ToNot(HaveOccurred())
 				Expect(user.DeleteCalled).To(BeTrue())
-				Expect(user.DeleteUserName).To(Equal(userName))
+				Expect(bindingLastOperation()).To(Equal(brokerapi.ErrBindingDoesNotExist))
 			})
 		})
+	})
 
-		Context("when creating the User Policy fails", func() {
+	var _ = Describe("BindingLastOperation", func() {
+		BeforeEach(func() {
+			store.GetBindingBinding = brokerstore.Binding{
+				BindingID:  bindingID,
+				InstanceID: instanceID,
+			}
+		})
+
+		It("returns succeeded for synchronous Bindings", func() {
+			lastOperationResponse, err := sqsBroker.BindingLastOperation(instanceID, bindingID)
+			Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationSucceeded))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when the Binding belongs to another Instance", func() {
 			BeforeEach(func() {
-				user.CreatePolicyError = errors.New("operation failed")
+				store.GetBindingBinding = brokerstore.Binding{InstanceID: "other-instance-id"}
 			})
 
 			It("returns the proper error", func() {
-				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("operation failed"))
-			})
-
-			It("makes the proper calls", func() {
-				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
-				Expect(err).To(HaveOccurred())
-				Expect(user.DeleteAccessKeyCalled).To(BeTrue())
-				Expect(user.DeleteAccessKeyUserName).To(Equal(userName))
-				Expect(user.DeleteAccessKeyAccessKeyID).To(Equal("user-access-key-id"))
-				Expect(user.DeleteCalled).To(BeTrue())
-				Expect(user.DeleteUserName).To(Equal(userName))
+				_, err := sqsBroker.BindingLastOperation(instanceID, bindingID)
+				Expect(err).To(Equal(brokerapi.ErrBindingDoesNotExist))
 			})
 		})
 
-		Context("when attaching the Policy to the User fails", func() {
+		Context("when getting the Binding fails", func() {
 			BeforeEach(func() {
-				user.AttachUserPolicyError = errors.New("operation failed")
+				store.GetBindingError = errors.New("operation failed")
 			})
 
 			It("returns the proper error", func() {
-				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+				_, err := sqsBroker.BindingLastOperation(instanceID, bindingID)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("operation failed"))
 			})
-
-			It("makes the proper calls", func() {
-				_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
-				Expect(err).To(HaveOccurred())
-				Expect(user.DeletePolicyCalled).To(BeTrue())
-				Expect(user.DeletePolicyPolicyARN).To(Equal("policy-arn"))
-				Expect(user.DeleteAccessKeyCalled).To(BeTrue())
-				Expect(user.DeleteAccessKeyUserName).To(Equal(userName))
-				Expect(user.DeleteAccessKeyAccessKeyID).To(Equal("user-access-key-id"))
-				Expect(user.DeleteCalled).To(BeTrue())
-				Expect(user.DeleteUserName).To(Equal(userName))
-			})
 		})
 	})
 
@@ -1006,9 +2612,44 @@ var _ = Describe("SQS Broker", func() {
 			Expect(user.ListAttachedUserPoliciesUserName).To(Equal(userName))
 			Expect(user.DeleteCalled).To(BeTrue())
 			Expect(user.DeleteUserName).To(Equal(userName))
+			Expect(store.DeleteBindingCalled).To(BeTrue())
+			Expect(store.DeleteBindingBindingID).To(Equal(bindingID))
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		It("audits the User deletion", func() {
+			err := sqsBroker.Unbind(instanceID, bindingID, unbindDetails)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(auditLogger.LogEvents).To(HaveLen(1))
+			Expect(auditLogger.LogEvents[0].Action).To(Equal("delete-binding-user"))
+			Expect(auditLogger.LogEvents[0].Outcome).To(Equal(auditlog.OutcomeSuccess))
+			Expect(auditLogger.LogEvents[0].InstanceID).To(Equal(instanceID))
+			Expect(auditLogger.LogEvents[0].BindingID).To(Equal(bindingID))
+		})
+
+		Context("when the Binding is not stored", func() {
+			BeforeEach(func() {
+				store.DeleteBindingError = brokerstore.ErrBindingDoesNotExist
+			})
+
+			It("does not return an error", func() {
+				err := sqsBroker.Unbind(instanceID, bindingID, unbindDetails)
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when deleting the Binding fails", func() {
+			BeforeEach(func() {
+				store.DeleteBindingError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				err := sqsBroker.Unbind(instanceID, bindingID, unbindDetails)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+		})
+
 		Context("when listing the User Access Keys fails", func() {
 			BeforeEach(func() {
 				user.ListAccessKeysError = errors.New("operation failed")
@@ -1112,11 +2753,174 @@ var _ = Describe("SQS Broker", func() {
 		})
 	})
 
+	var _ = Describe("GetBinding", func() {
+		BeforeEach(func() {
+			user.DescribeUserDetails = awsiam.UserDetails{
+				UserName: userName,
+				UserARN:  "user-arn",
+			}
+
+			store.GetBindingBinding = brokerstore.Binding{
+				BindingID:   bindingID,
+				InstanceID:  instanceID,
+				ServiceID:   "Service-1",
+				PlanID:      "Plan-1",
+				Parameters:  map[string]interface{}{"key": "value"},
+				AccessKeyID: "old-access-key-id",
+			}
+
+			queue.DescribeQueueDetails = awssqs.QueueDetails{
+				QueueURL: "queue-url",
+				QueueArn: "queue-arn",
+			}
+		})
+
+		It("does not return credentials it can not recover", func() {
+			_, err := sqsBroker.GetBinding(instanceID, bindingID)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(&SecretNotAvailableError{}))
+			Expect(err.Error()).To(Equal("The secret access key of Binding 'binding-id' is not available anymore, rotate the binding keys to get new credentials"))
+		})
+
+		It("does not reissue the Access Key", func() {
+			_, err := sqsBroker.GetBinding(instanceID, bindingID)
+			Expect(err).To(HaveOccurred())
+			Expect(user.DescribeCalled).To(BeTrue())
+			Expect(user.DescribeUserName).To(Equal(userName))
+			Expect(store.GetBindingCalled).To(BeTrue())
+			Expect(store.GetBindingBindingID).To(Equal(bindingID))
+			Expect(user.DeleteAccessKeyCalled).To(BeFalse())
+			Expect(user.CreateAccessKeyCalled).To(BeFalse())
+			Expect(store.SaveBindingCalled).To(BeFalse())
+		})
+
+		Context("when binding secrets are stored", func() {
+			BeforeEach(func() {
+				storeBindingSecrets = true
+				store.GetBindingBinding.SecretAccessKey = "old-secret-access-key"
+			})
+
+			It("returns the stored credentials", func() {
+				bindingResponse, err := sqsBroker.GetBinding(instanceID, bindingID)
+				credentials := bindingResponse.Credentials.(*brokerapi.CredentialsHash)
+				Expect(credentials.Username).To(Equal("old-access-key-id"))
+				Expect(credentials.Password).To(Equal("old-secret-access-key"))
+				Expect(credentials.URI).To(Equal("queue-url"))
+				Expect(bindingResponse.Parameters).To(Equal(map[string]interface{}{"key": "value"}))
+				Expect(queue.DescribeCalled).To(BeTrue())
+				Expect(queue.DescribeQueueName).To(Equal(queueName))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("does not reissue the Access Key", func() {
+				_, err := sqsBroker.GetBinding(instanceID, bindingID)
+				Expect(user.DeleteAccessKeyCalled).To(BeFalse())
+				Expect(user.CreateAccessKeyCalled).To(BeFalse())
+				Expect(store.SaveBindingCalled).To(BeFalse())
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the Binding is not stored", func() {
+			BeforeEach(func() {
+				store.GetBindingBinding = brokerstore.Binding{}
+				store.GetBindingError = brokerstore.ErrBindingDoesNotExist
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.GetBinding(instanceID, bindingID)
+				Expect(err).To(BeAssignableToTypeOf(&SecretNotAvailableError{}))
+				Expect(user.CreateAccessKeyCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the Binding belongs to another Instance", func() {
+			BeforeEach(func() {
+				store.GetBindingBinding.InstanceID = "other-instance-id"
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.GetBinding(instanceID, bindingID)
+				Expect(err).To(HaveOccurred())
+				Expect(err).To(Equal(brokerapi.ErrBindingDoesNotExist))
+			})
+		})
+
+		Context("when describing the User fails", func() {
+			BeforeEach(func() {
+				user.DescribeError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.GetBinding(instanceID, bindingID)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+
+			Context("when the User does not exists", func() {
+				BeforeEach(func() {
+					user.DescribeError = awsiam.ErrUserDoesNotExist
+				})
+
+				It("returns the proper error", func() {
+					_, err := sqsBroker.GetBinding(instanceID, bindingID)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(brokerapi.ErrBindingDoesNotExist))
+				})
+			})
+		})
+
+		Context("when getting the Binding fails", func() {
+			BeforeEach(func() {
+				store.GetBindingError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.GetBinding(instanceID, bindingID)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+		})
+
+		Context("when describing the Queue fails", func() {
+			BeforeEach(func() {
+				queue.DescribeError = awssqs.ErrQueueDoesNotExist
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.GetBinding(instanceID, bindingID)
+				Expect(err).To(HaveOccurred())
+				Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+			})
+		})
+	})
+
 	var _ = Describe("LastOperation", func() {
-		It("returns the proper error", func() {
+		It("returns succeeded if the Instance has no pending operation", func() {
+			lastOperationResponse, err := sqsBroker.LastOperation(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastOperationResponse.State).To(Equal(brokerapi.LastOperationSucceeded))
+		})
+
+		It("returns the state of the last operation", func() {
+			store.GetInstanceInstance = brokerstore.Instance{
+				LastOperationState:       brokerapi.LastOperationInProgress,
+				LastOperationDescription: "Moving queue to region 'eu-west-1'",
+			}
+
+			lastOperationResponse, err := sqsBroker.LastOperation(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastOperationResponse).To(Equal(brokerapi.LastOperationResponse{
+				State:       brokerapi.LastOperationInProgress,
+				Description: "Moving queue to region 'eu-west-1'",
+			}))
+		})
+
+		It("returns the proper error if the Instance is not stored", func() {
+			store.GetInstanceError = brokerstore.ErrInstanceDoesNotExist
+
 			_, err := sqsBroker.LastOperation(instanceID)
-			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(Equal("This broker does not support LastOperation"))
+			Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
 		})
 	})
 })
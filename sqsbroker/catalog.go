@@ -1,12 +1,36 @@
 package sqsbroker
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
 )
 
 const minAllocatedStorage = 5
 const maxAllocatedStorage = 6144
 
+const ArchiveQueueDeletionMode = "archive_queue"
+
+const SQSServiceBackend = "sqs"
+const SNSServiceBackend = "sns"
+
+const CloudFoundryPlatform = "cloudfoundry"
+const KubernetesPlatform = "kubernetes"
+
+// Service and plan names must be CLI-friendly: lowercase, without spaces
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// Permissions a service can require, as defined by the Open Service Broker API
+const (
+	SyslogDrainPermission     = "syslog_drain"
+	RouteForwardingPermission = "route_forwarding"
+	VolumeMountPermission     = "volume_mount"
+)
+
+var servicePermissions = []string{SyslogDrainPermission, RouteForwardingPermission, VolumeMountPermission}
+
 type Catalog struct {
 	Services []Service `json:"services,omitempty"`
 }
@@ -22,6 +46,7 @@ type Service struct {
 	PlanUpdateable  bool             `json:"plan_updateable"`
 	Plans           []ServicePlan    `json:"plans,omitempty"`
 	DashboardClient *DashboardClient `json:"dashboard_client,omitempty"`
+	Backend         string           `json:"backend,omitempty"`
 }
 
 type ServiceMetadata struct {
@@ -34,12 +59,19 @@ type ServiceMetadata struct {
 }
 
 type ServicePlan struct {
-	ID            string               `json:"id"`
-	Name          string               `json:"name"`
-	Description   string               `json:"description"`
-	Metadata      *ServicePlanMetadata `json:"metadata,omitempty"`
-	Free          bool                 `json:"free"`
-	SQSProperties SQSProperties        `json:"sqs_properties,omitempty"`
+	ID                  string               `json:"id"`
+	Name                string               `json:"name"`
+	Description         string               `json:"description"`
+	Metadata            *ServicePlanMetadata `json:"metadata,omitempty"`
+	Free                bool                 `json:"free"`
+	DeletionProtection  bool                 `json:"deletion_protection,omitempty"`
+	DeletionArchiveMode string               `json:"deletion_archive_mode,omitempty"`
+	Platforms           []string             `json:"platforms,omitempty"`
+	Region              string               `json:"region,omitempty"`
+	RoleArn             string               `json:"role_arn,omitempty"`
+	ExternalID          string               `json:"external_id,omitempty"`
+	SQSProperties       SQSProperties        `json:"sqs_properties,omitempty"`
+	SNSProperties       SNSProperties        `json:"sns_properties,omitempty"`
 }
 
 type ServicePlanMetadata struct {
@@ -68,10 +100,31 @@ type SQSProperties struct {
 	VisibilityTimeout             string `json:"visibility_timeout,omitempty"`
 }
 
+type SNSProperties struct {
+	DisplayName    string `json:"display_name,omitempty"`
+	Policy         string `json:"policy,omitempty"`
+	DeliveryPolicy string `json:"delivery_policy,omitempty"`
+}
+
 func (c Catalog) Validate() error {
-	for _, service := range c.Services {
+	serviceIDs := map[string]int{}
+	planIDs := map[string]string{}
+	for i, service := range c.Services {
 		if err := service.Validate(); err != nil {
-			return fmt.Errorf("Validating Services configuration: %s", err)
+			return NewValidationError(fmt.Sprintf("services[%d]", i), err)
+		}
+
+		if j, ok := serviceIDs[service.ID]; ok {
+			return NewValidationError(fmt.Sprintf("services[%d].id", i), fmt.Errorf("Duplicate ID '%s', already used by services[%d]", service.ID, j))
+		}
+		serviceIDs[service.ID] = i
+
+		// Plan IDs are unique across services, as plans are looked up by their ID only
+		for j, servicePlan := range service.Plans {
+			if path, ok := planIDs[servicePlan.ID]; ok {
+				return NewValidationError(fmt.Sprintf("services[%d].plans[%d].id", i, j), fmt.Errorf("Duplicate ID '%s', already used by %s", servicePlan.ID, path))
+			}
+			planIDs[servicePlan.ID] = fmt.Sprintf("services[%d].plans[%d]", i, j)
 		}
 	}
 
@@ -102,21 +155,45 @@ func (c Catalog) FindServicePlan(planID string) (plan ServicePlan, found bool) {
 
 func (s Service) Validate() error {
 	if s.ID == "" {
-		return fmt.Errorf("Must provide a non-empty ID (%+v)", s)
+		return NewValidationError("id", errors.New("Must provide a non-empty ID"))
 	}
 
-	if s.Name == "" {
-		return fmt.Errorf("Must provide a non-empty Name (%+v)", s)
+	if err := validateName(s.Name); err != nil {
+		return NewValidationError("name", err)
 	}
 
 	if s.Description == "" {
-		return fmt.Errorf("Must provide a non-empty Description (%+v)", s)
+		return NewValidationError("description", errors.New("Must provide a non-empty Description"))
+	}
+
+	for i, permission := range s.Requires {
+		if !isPermission(permission) {
+			return NewValidationError(fmt.Sprintf("requires[%d]", i), fmt.Errorf("Invalid Requires '%s', must be one of '%s'", permission, strings.Join(servicePermissions, "', '")))
+		}
+	}
+
+	if s.DashboardClient != nil {
+		if err := s.DashboardClient.Validate(); err != nil {
+			return NewValidationError("dashboard_client", err)
+		}
+	}
+
+	switch s.Backend {
+	case "", SQSServiceBackend, SNSServiceBackend:
+	default:
+		return NewValidationError("backend", fmt.Errorf("Invalid Backend '%s'", s.Backend))
 	}
 
-	for _, servicePlan := range s.Plans {
+	planNames := map[string]int{}
+	for i, servicePlan := range s.Plans {
 		if err := servicePlan.Validate(); err != nil {
-			return fmt.Errorf("Validating Plans configuration: %s", err)
+			return NewValidationError(fmt.Sprintf("plans[%d]", i), err)
+		}
+
+		if j, ok := planNames[servicePlan.Name]; ok {
+			return NewValidationError(fmt.Sprintf("plans[%d].name", i), fmt.Errorf("Duplicate Name '%s', already used by plans[%d]", servicePlan.Name, j))
 		}
+		planNames[servicePlan.Name] = i
 	}
 
 	return nil
@@ -124,25 +201,105 @@ func (s Service) Validate() error {
 
 func (sp ServicePlan) Validate() error {
 	if sp.ID == "" {
-		return fmt.Errorf("Must provide a non-empty ID (%+v)", sp)
+		return NewValidationError("id", errors.New("Must provide a non-empty ID"))
 	}
 
-	if sp.Name == "" {
-		return fmt.Errorf("Must provide a non-empty Name (%+v)", sp)
+	if err := validateName(sp.Name); err != nil {
+		return NewValidationError("name", err)
 	}
 
 	if sp.Description == "" {
-		return fmt.Errorf("Must provide a non-empty Description (%+v)", sp)
+		return NewValidationError("description", errors.New("Must provide a non-empty Description"))
+	}
+
+	switch sp.DeletionArchiveMode {
+	case "", ArchiveQueueDeletionMode:
+	default:
+		return NewValidationError("deletion_archive_mode", fmt.Errorf("Invalid DeletionArchiveMode '%s'", sp.DeletionArchiveMode))
+	}
+
+	for i, platform := range sp.Platforms {
+		if platform == "" {
+			return NewValidationError(fmt.Sprintf("platforms[%d]", i), errors.New("Must provide non-empty Platforms"))
+		}
+	}
+
+	accountRole := AccountRole{RoleArn: sp.RoleArn, ExternalID: sp.ExternalID}
+	if err := accountRole.Validate(); err != nil {
+		return NewValidationError("role_arn", err)
 	}
 
 	if err := sp.SQSProperties.Validate(); err != nil {
-		return fmt.Errorf("Validating SQS Properties configuration: %s", err)
+		return NewValidationError("sqs_properties", err)
 	}
 
 	return nil
 }
 
+func (dc DashboardClient) Validate() error {
+	if dc.ID == "" {
+		return NewValidationError("id", errors.New("Must provide a non-empty ID"))
+	}
+
+	if dc.Secret == "" {
+		return NewValidationError("secret", errors.New("Must provide a non-empty Secret"))
+	}
+
+	if dc.RedirectURI != "" {
+		redirectURI, err := url.Parse(dc.RedirectURI)
+		if err != nil || redirectURI.Host == "" || (redirectURI.Scheme != "http" && redirectURI.Scheme != "https") {
+			return NewValidationError("redirect_uri", fmt.Errorf("Invalid RedirectURI '%s', must be an http or https URL", dc.RedirectURI))
+		}
+	}
+
+	return nil
+}
+
+func isPermission(permission string) bool {
+	for _, known := range servicePermissions {
+		if permission == known {
+			return true
+		}
+	}
+	return false
+}
+
+func validateName(name string) error {
+	if name == "" {
+		return errors.New("Must provide a non-empty Name")
+	}
+
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("Invalid Name '%s', must be lowercase letters, digits, '-', '_' or '.'", name)
+	}
+
+	return nil
+}
+
+func (sp ServicePlan) AvailableOnPlatform(platform string) bool {
+	if len(sp.Platforms) == 0 {
+		return true
+	}
+
+	for _, planPlatform := range sp.Platforms {
+		if planPlatform == platform {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s Service) IsTopic() bool {
+	return s.Backend == SNSServiceBackend
+}
+
 func (sq SQSProperties) Validate() error {
+	if isPolicyTemplate(sq.Policy) {
+		if err := validatePolicyTemplate(sq.Policy); err != nil {
+			return NewValidationError("policy", err)
+		}
+	}
 
 	return nil
 }
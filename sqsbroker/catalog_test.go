@@ -36,7 +36,34 @@ var _ = Describe("Catalog", func() {
 
 			err := catalog.Validate()
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(ContainSubstring("Validating Services configuration"))
+			Expect(err.Error()).To(Equal("services[0].id: Must provide a non-empty ID"))
+		})
+
+		It("returns error if a Service ID is duplicated", func() {
+			catalog.Services = []Service{
+				Service{ID: "service-1", Name: "service-1", Description: "Service 1"},
+				Service{ID: "service-1", Name: "service-2", Description: "Service 2"},
+			}
+
+			err := catalog.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("services[1].id: Duplicate ID 'service-1', already used by services[0]"))
+		})
+
+		It("returns error if a Service Plan ID is used by another Service", func() {
+			catalog.Services = []Service{
+				Service{ID: "service-1", Name: "service-1", Description: "Service 1", Plans: []ServicePlan{
+					ServicePlan{ID: "plan-1", Name: "plan-1", Description: "Plan 1"},
+				}},
+				Service{ID: "service-2", Name: "service-2", Description: "Service 2", Plans: []ServicePlan{
+					ServicePlan{ID: "plan-2", Name: "plan-2", Description: "Plan 2"},
+					ServicePlan{ID: "plan-1", Name: "plan-1", Description: "Plan 1"},
+				}},
+			}
+
+			err := catalog.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("services[1].plans[1].id: Duplicate ID 'plan-1', already used by services[0].plans[0]"))
 		})
 	})
 
@@ -85,15 +112,15 @@ var _ = Describe("Service", func() {
 
 		validService = Service{
 			ID:              "Service-1",
-			Name:            "Service 1",
+			Name:            "service-1",
 			Description:     "Service 1 description",
 			Bindable:        true,
 			Tags:            []string{"service"},
 			Metadata:        &ServiceMetadata{},
-			Requires:        []string{"syslog"},
+			Requires:        []string{"syslog_drain"},
 			PlanUpdateable:  true,
 			Plans:           []ServicePlan{},
-			DashboardClient: &DashboardClient{},
+			DashboardClient: &DashboardClient{ID: "client-id", Secret: "client-secret", RedirectURI: "https://dashboard.example.com"},
 		}
 	)
 
@@ -123,6 +150,14 @@ var _ = Describe("Service", func() {
 			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty Name"))
 		})
 
+		It("returns error if Name is not CLI-friendly", func() {
+			service.Name = "Service 1"
+
+			err := service.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("name: Invalid Name 'Service 1', must be lowercase letters, digits, '-', '_' or '.'"))
+		})
+
 		It("returns error if Description is empty", func() {
 			service.Description = ""
 
@@ -131,6 +166,70 @@ var _ = Describe("Service", func() {
 			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty Description"))
 		})
 
+		It("returns error if Backend is not valid", func() {
+			service.Backend = "unknown"
+
+			err := service.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Invalid Backend 'unknown'"))
+		})
+
+		It("accepts every permission of the Open Service Broker API in Requires", func() {
+			service.Requires = []string{"syslog_drain", "route_forwarding", "volume_mount"}
+
+			Expect(service.Validate()).To(Succeed())
+		})
+
+		It("returns error if Requires is not valid", func() {
+			service.Requires = []string{"syslog_drain", "unknown"}
+
+			err := service.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("requires[1]: Invalid Requires 'unknown', must be one of 'syslog_drain', 'route_forwarding', 'volume_mount'"))
+		})
+
+		It("does not return error if DashboardClient is not set", func() {
+			service.DashboardClient = nil
+
+			err := service.Validate()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns error if DashboardClient ID is empty", func() {
+			service.DashboardClient = &DashboardClient{Secret: "client-secret"}
+
+			err := service.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("dashboard_client.id: Must provide a non-empty ID"))
+		})
+
+		It("returns error if DashboardClient Secret is empty", func() {
+			service.DashboardClient = &DashboardClient{ID: "client-id"}
+
+			err := service.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("dashboard_client.secret: Must provide a non-empty Secret"))
+		})
+
+		It("returns error if DashboardClient RedirectURI is not an URL", func() {
+			service.DashboardClient = &DashboardClient{ID: "client-id", Secret: "client-secret", RedirectURI: "dashboard.example.com"}
+
+			err := service.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Invalid RedirectURI 'dashboard.example.com'"))
+		})
+
+		It("returns error if a Service Plan Name is duplicated", func() {
+			service.Plans = []ServicePlan{
+				ServicePlan{ID: "plan-1", Name: "standard", Description: "Plan 1"},
+				ServicePlan{ID: "plan-2", Name: "standard", Description: "Plan 2"},
+			}
+
+			err := service.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("plans[1].name: Duplicate Name 'standard', already used by plans[0]"))
+		})
+
 		It("returns error if Plans are not valid", func() {
 			service.Plans = []ServicePlan{
 				ServicePlan{},
@@ -138,7 +237,18 @@ var _ = Describe("Service", func() {
 
 			err := service.Validate()
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(ContainSubstring("Validating Plans configuration"))
+			Expect(err.Error()).To(Equal("plans[0].id: Must provide a non-empty ID"))
+		})
+	})
+
+	Describe("IsTopic", func() {
+		It("returns false if Backend is not set", func() {
+			Expect(service.IsTopic()).To(BeFalse())
+		})
+
+		It("returns true if Backend is sns", func() {
+			service.Backend = SNSServiceBackend
+			Expect(service.IsTopic()).To(BeTrue())
 		})
 	})
 })
@@ -149,7 +259,7 @@ var _ = Describe("ServicePlan", func() {
 
 		validServicePlan = ServicePlan{
 			ID:            "Plan-1",
-			Name:          "Plan 1",
+			Name:          "plan-1",
 			Description:   "Plan-1 description",
 			Metadata:      &ServicePlanMetadata{},
 			Free:          true,
@@ -183,6 +293,14 @@ var _ = Describe("ServicePlan", func() {
 			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty Name"))
 		})
 
+		It("returns error if Name is not CLI-friendly", func() {
+			servicePlan.Name = "Plan_1"
+
+			err := servicePlan.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Invalid Name 'Plan_1'"))
+		})
+
 		It("returns error if Description is empty", func() {
 			servicePlan.Description = ""
 
@@ -190,5 +308,70 @@ var _ = Describe("ServicePlan", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty Description"))
 		})
+
+		It("returns error if DeletionArchiveMode is not valid", func() {
+			servicePlan.DeletionArchiveMode = "unknown"
+
+			err := servicePlan.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Invalid DeletionArchiveMode 'unknown'"))
+		})
+
+		It("returns error if Platforms are empty", func() {
+			servicePlan.Platforms = []string{"cloudfoundry", ""}
+
+			err := servicePlan.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide non-empty Platforms"))
+		})
+
+		It("returns error if ExternalID is set without RoleArn", func() {
+			servicePlan.ExternalID = "external-id"
+
+			err := servicePlan.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty RoleArn when ExternalID is set"))
+		})
+
+		It("does not return error if the Policy template is valid", func() {
+			servicePlan.SQSProperties.Policy = `{"Statement":[{"Resource":"{{.QueueArn}}","Condition":{"ArnLike":{"aws:SourceArn":{{json .SourceArns}}}}}]}`
+
+			err := servicePlan.Validate()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns error if the Policy template refers to an unknown variable", func() {
+			servicePlan.SQSProperties.Policy = `{"Resource":"{{.QueueID}}"}`
+
+			err := servicePlan.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("sqs_properties.policy: "))
+			Expect(err.Error()).To(ContainSubstring("QueueID"))
+		})
+
+		It("returns error if the Policy template does not render valid JSON", func() {
+			servicePlan.SQSProperties.Policy = `{"Resource":{{.QueueArn}}}`
+
+			err := servicePlan.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Rendered Queue Policy is not valid JSON"))
+		})
+	})
+
+	Describe("AvailableOnPlatform", func() {
+		It("returns true if Platforms are not set", func() {
+			Expect(servicePlan.AvailableOnPlatform("kubernetes")).To(BeTrue())
+		})
+
+		It("returns true if the platform is allowed", func() {
+			servicePlan.Platforms = []string{"cloudfoundry", "kubernetes"}
+			Expect(servicePlan.AvailableOnPlatform("kubernetes")).To(BeTrue())
+		})
+
+		It("returns false if the platform is not allowed", func() {
+			servicePlan.Platforms = []string{"cloudfoundry"}
+			Expect(servicePlan.AvailableOnPlatform("kubernetes")).To(BeFalse())
+			Expect(servicePlan.AvailableOnPlatform("")).To(BeFalse())
+		})
 	})
 })
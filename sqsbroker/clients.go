@@ -0,0 +1,89 @@
+package sqsbroker
+
+import (
+	"sync"
+
+	"github.com/cf-platform-eng/sqs-broker/awsiam"
+	"github.com/cf-platform-eng/sqs-broker/awssns"
+	"github.com/cf-platform-eng/sqs-broker/awssqs"
+	"github.com/cf-platform-eng/sqs-broker/brokerstore"
+)
+
+type ClientsFactory func(region string, accountRole AccountRole) (awssqs.Queue, awssns.Topic, awsiam.User)
+
+type awsClients struct {
+	region string
+	queue  awssqs.Queue
+	topic  awssns.Topic
+	user   awsiam.User
+}
+
+type clientsKey struct {
+	region      string
+	accountRole AccountRole
+}
+
+type clientPool struct {
+	sync.Mutex
+	factory ClientsFactory
+	clients map[clientsKey]awsClients
+}
+
+func newClientPool(factory ClientsFactory) *clientPool {
+	return &clientPool{
+		factory: factory,
+		clients: map[clientsKey]awsClients{},
+	}
+}
+
+// get builds the clients of a region and account the first time they are needed
+func (p *clientPool) get(region string, accountRole AccountRole) awsClients {
+	p.Lock()
+	defer p.Unlock()
+
+	key := clientsKey{region: region, accountRole: accountRole}
+	clients, ok := p.clients[key]
+	if !ok {
+		clients.region = region
+		clients.queue, clients.topic, clients.user = p.factory(region, accountRole)
+		p.clients[key] = clients
+	}
+
+	return clients
+}
+
+func (b *SQSBroker) awsClients(region string, accountRole AccountRole) awsClients {
+	if region == "" {
+		region = b.region
+	}
+
+	return b.clients.get(region, accountRole)
+}
+
+func (b *SQSBroker) instanceClients(instanceID string) (awsClients, error) {
+	instance, err := b.store.GetInstance(instanceID)
+	if err != nil && err != brokerstore.ErrInstanceDoesNotExist {
+		return awsClients{}, err
+	}
+
+	return b.storedInstanceClients(instance), nil
+}
+
+func (b *SQSBroker) storedInstanceClients(instance brokerstore.Instance) awsClients {
+	return b.awsClients(instance.Region, instanceAccountRole(instance))
+}
+
+// subscriptionClients returns the clients of the account and region owning the subscribed Topic
+func (b *SQSBroker) subscriptionClients(subscription brokerstore.Subscription) (awsClients, error) {
+	if subscription.TopicInstanceID != "" {
+		instance, err := b.store.GetInstance(subscription.TopicInstanceID)
+		if err == nil {
+			return b.storedInstanceClients(instance), nil
+		}
+		if err != brokerstore.ErrInstanceDoesNotExist {
+			return awsClients{}, err
+		}
+	}
+
+	return b.awsClients(arnRegion(subscription.TopicArn), AccountRole{}), nil
+}
@@ -3,27 +3,119 @@ package sqsbroker
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 )
 
 type Config struct {
-	Region                       string  `json:"region"`
-	SQSPrefix                    string  `json:"sqs_prefix"`
-	AllowUserProvisionParameters bool    `json:"allow_user_provision_parameters"`
-	AllowUserUpdateParameters    bool    `json:"allow_user_update_parameters"`
-	Catalog                      Catalog `json:"catalog"`
+	Region                       string                 `json:"region"`
+	SQSEndpoint                  string                 `json:"sqs_endpoint,omitempty"`
+	SNSEndpoint                  string                 `json:"sns_endpoint,omitempty"`
+	IAMEndpoint                  string                 `json:"iam_endpoint,omitempty"`
+	STSEndpoint                  string                 `json:"sts_endpoint,omitempty"`
+	SkipSSLValidation            bool                   `json:"skip_ssl_validation,omitempty"`
+	CABundleFile                 string                 `json:"ca_bundle_file,omitempty"`
+	AllowedRegions               []string               `json:"allowed_regions,omitempty"`
+	OrganizationAccounts         map[string]AccountRole `json:"organization_accounts,omitempty"`
+	SQSPrefix                    string                 `json:"sqs_prefix"`
+	AllowUserProvisionParameters bool                   `json:"allow_user_provision_parameters"`
+	AllowUserUpdateParameters    bool                   `json:"allow_user_update_parameters"`
+	StateFile                    string                 `json:"state_file"`
+	StoreBindingSecrets          bool                   `json:"store_binding_secrets"`
+	BindingVerificationTimeout   int                    `json:"binding_verification_timeout"`
+	Quotas                       Quotas                 `json:"quotas"`
+	AllowPlanMigration           bool                   `json:"allow_plan_migration"`
+	Catalog                      Catalog                `json:"catalog"`
+	CatalogPath                  string                 `json:"catalog_path,omitempty"`
+}
+
+// ValidationError locates a configuration error by the path of the offending key, and the file it was read from
+type ValidationError struct {
+	File string
+	Path string
+	Err  error
+}
+
+func (e *ValidationError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%s: %s", e.Path, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", e.File, e.Path, e.Err)
+}
+
+// NewValidationError returns a ValidationError for path, prefixing the path of a nested ValidationError
+func NewValidationError(path string, err error) error {
+	validationErr, ok := err.(*ValidationError)
+	if !ok {
+		return &ValidationError{Path: path, Err: err}
+	}
+
+	if strings.HasPrefix(validationErr.Path, "[") {
+		path = path + validationErr.Path
+	} else {
+		path = path + "." + validationErr.Path
+	}
+
+	return &ValidationError{File: validationErr.File, Path: path, Err: validationErr.Err}
 }
 
 func (c Config) Validate() error {
 	if c.Region == "" {
-		return errors.New("Must provide a non-empty Region")
+		return NewValidationError("region", errors.New("Must provide a non-empty Region"))
+	}
+
+	if err := validateEndpoint(c.SQSEndpoint); err != nil {
+		return NewValidationError("sqs_endpoint", err)
+	}
+
+	if err := validateEndpoint(c.SNSEndpoint); err != nil {
+		return NewValidationError("sns_endpoint", err)
+	}
+
+	if err := validateEndpoint(c.IAMEndpoint); err != nil {
+		return NewValidationError("iam_endpoint", err)
+	}
+
+	if err := validateEndpoint(c.STSEndpoint); err != nil {
+		return NewValidationError("sts_endpoint", err)
+	}
+
+	for i, region := range c.AllowedRegions {
+		if region == "" {
+			return NewValidationError(fmt.Sprintf("allowed_regions[%d]", i), errors.New("Must provide non-empty AllowedRegions"))
+		}
+	}
+
+	for organizationGUID, accountRole := range c.OrganizationAccounts {
+		if accountRole.RoleArn == "" {
+			return NewValidationError(fmt.Sprintf("organization_accounts.%s.role_arn", organizationGUID), fmt.Errorf("Must provide a non-empty RoleArn for organization '%s'", organizationGUID))
+		}
 	}
 
 	if c.SQSPrefix == "" {
-		return errors.New("Must provide a non-empty SQSPrefix")
+		return NewValidationError("sqs_prefix", errors.New("Must provide a non-empty SQSPrefix"))
+	}
+
+	if err := c.Quotas.Validate(); err != nil {
+		return NewValidationError("quotas", err)
 	}
 
 	if err := c.Catalog.Validate(); err != nil {
-		return fmt.Errorf("Validating Catalog configuration: %s", err)
+		return NewValidationError("catalog", err)
+	}
+
+	return nil
+}
+
+func validateEndpoint(endpoint string) error {
+	if endpoint == "" {
+		return nil
+	}
+
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil || endpointURL.Host == "" || (endpointURL.Scheme != "http" && endpointURL.Scheme != "https") {
+		return fmt.Errorf("Invalid endpoint '%s', must be an http or https URL", endpoint)
 	}
 
 	return nil
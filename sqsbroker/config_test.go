@@ -18,7 +18,7 @@ var _ = Describe("Config", func() {
 				[]Service{
 					Service{
 						ID:          "service-1",
-						Name:        "Service 1",
+						Name:        "service-1",
 						Description: "Service 1 description",
 					},
 				},
@@ -44,6 +44,64 @@ var _ = Describe("Config", func() {
 			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty Region"))
 		})
 
+		It("does not return error if the endpoints are valid", func() {
+			config.SQSEndpoint = "http://localhost:4566"
+			config.SNSEndpoint = "http://localhost:4566"
+			config.IAMEndpoint = "https://iam.example.com"
+			config.STSEndpoint = "https://sts.example.com"
+
+			err := config.Validate()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns error if SQSEndpoint is not valid", func() {
+			config.SQSEndpoint = "localhost:4566"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("sqs_endpoint: Invalid endpoint 'localhost:4566', must be an http or https URL"))
+		})
+
+		It("returns error if SNSEndpoint is not valid", func() {
+			config.SNSEndpoint = "sns.example.com"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("sns_endpoint: Invalid endpoint 'sns.example.com', must be an http or https URL"))
+		})
+
+		It("returns error if IAMEndpoint is not valid", func() {
+			config.IAMEndpoint = "ftp://iam.example.com"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("iam_endpoint: Invalid endpoint 'ftp://iam.example.com', must be an http or https URL"))
+		})
+
+		It("returns error if STSEndpoint is not valid", func() {
+			config.STSEndpoint = "sts.example.com"
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("sts_endpoint: Invalid endpoint 'sts.example.com', must be an http or https URL"))
+		})
+
+		It("returns error if AllowedRegions are not valid", func() {
+			config.AllowedRegions = []string{"eu-west-1", ""}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide non-empty AllowedRegions"))
+		})
+
+		It("returns error if OrganizationAccounts are not valid", func() {
+			config.OrganizationAccounts = map[string]AccountRole{"organization-id": AccountRole{}}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty RoleArn for organization 'organization-id'"))
+		})
+
 		It("returns error if SQSPrefix is not valid", func() {
 			config.SQSPrefix = ""
 
@@ -52,6 +110,14 @@ var _ = Describe("Config", func() {
 			Expect(err.Error()).To(ContainSubstring("Must provide a non-empty SQSPrefix"))
 		})
 
+		It("returns error if Quotas are not valid", func() {
+			config.Quotas = Quotas{MaxBindingsPerInstance: -1}
+
+			err := config.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("quotas.max_bindings_per_instance: Must provide a non-negative MaxBindingsPerInstance"))
+		})
+
 		It("returns error if Catalog is not valid", func() {
 			config.Catalog = Catalog{
 				[]Service{
@@ -61,7 +127,7 @@ var _ = Describe("Config", func() {
 
 			err := config.Validate()
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(ContainSubstring("Validating Catalog configuration"))
+			Expect(err.Error()).To(Equal("catalog.services[0].id: Must provide a non-empty ID"))
 		})
 	})
 })
@@ -0,0 +1,345 @@
+package sqsbroker
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/sqs-broker/auditlog"
+	"github.com/cf-platform-eng/sqs-broker/awsiam"
+	"github.com/cf-platform-eng/sqs-broker/awssqs"
+	"github.com/cf-platform-eng/sqs-broker/brokerstore"
+)
+
+type InstanceBusyError struct {
+	InstanceID string
+}
+
+func (e *InstanceBusyError) Error() string {
+	return fmt.Sprintf("Instance '%s' is being migrated or archived, try again once the operation has finished", e.InstanceID)
+}
+
+func (e *InstanceBusyError) Kind() string {
+	return ConcurrencyErrorKind
+}
+
+type QueueSubscribedError struct {
+	QueueName string
+	BindingID string
+	Region    string
+}
+
+func (e *QueueSubscribedError) Error() string {
+	if e.BindingID != "" {
+		return fmt.Sprintf("Queue '%s' is subscribed to topics by binding '%s', unbind it before moving the queue to region '%s'", e.QueueName, e.BindingID, e.Region)
+	}
+	return fmt.Sprintf("Queue '%s' is subscribed to topics, unsubscribe it before moving it to region '%s'", e.QueueName, e.Region)
+}
+
+func (e *QueueSubscribedError) Kind() string {
+	return InvalidRequestErrorKind
+}
+
+func (b *SQSBroker) LastOperation(instanceID string) (brokerapi.LastOperationResponse, error) {
+	b.logger.Debug("last-operation", lager.Data{
+		instanceIDLogKey: instanceID,
+	})
+
+	lastOperationResponse := brokerapi.LastOperationResponse{}
+
+	instance, err := b.store.GetInstance(instanceID)
+	if err != nil {
+		if err == brokerstore.ErrInstanceDoesNotExist {
+			return lastOperationResponse, brokerapi.ErrInstanceDoesNotExist
+		}
+		return lastOperationResponse, err
+	}
+
+	lastOperationResponse.State = instance.LastOperationState
+	lastOperationResponse.Description = instance.LastOperationDescription
+	if lastOperationResponse.State == "" {
+		lastOperationResponse.State = brokerapi.LastOperationSucceeded
+	}
+
+	return lastOperationResponse, nil
+}
+
+func (b *SQSBroker) checkInstanceNotBusy(instanceID string) error {
+	instance, err := b.store.GetInstance(instanceID)
+	if err != nil && err != brokerstore.ErrInstanceDoesNotExist {
+		return err
+	}
+
+	if instance.LastOperationState == brokerapi.LastOperationInProgress {
+		return &InstanceBusyError{InstanceID: instanceID}
+	}
+
+	return nil
+}
+
+// startQueueMigration moves the Queue of an Instance to the region of its new plan in the background
+func (b *SQSBroker) startQueueMigration(instance brokerstore.Instance, servicePlan ServicePlan, updateParameters UpdateParameters, details brokerapi.UpdateDetails, parameters map[string]interface{}, requestContext map[string]interface{}, acceptsIncomplete bool) (UpdateResponse, bool, error) {
+	if !acceptsIncomplete {
+		return UpdateResponse{}, false, brokerapi.ErrAsyncRequired
+	}
+
+	// Subscriptions are bound to the Queue ARN, so they would keep delivering to the old Queue
+	if len(instance.Subscriptions) > 0 {
+		return UpdateResponse{}, false, &QueueSubscribedError{QueueName: b.queueName(instance.InstanceID), Region: servicePlan.Region}
+	}
+
+	bindings, err := b.instanceBindings(instance.InstanceID)
+	if err != nil {
+		return UpdateResponse{}, false, err
+	}
+
+	for _, binding := range bindings {
+		if binding.SubscriptionArn != "" {
+			return UpdateResponse{}, false, &QueueSubscribedError{QueueName: b.queueName(instance.InstanceID), BindingID: binding.BindingID, Region: servicePlan.Region}
+		}
+	}
+
+	instance.LastOperationState = brokerapi.LastOperationInProgress
+	instance.LastOperationDescription = fmt.Sprintf("Moving queue to region '%s'", servicePlan.Region)
+	if err := b.store.SaveInstance(instance); err != nil {
+		return UpdateResponse{}, false, err
+	}
+
+	go b.migrateQueue(instance, servicePlan, updateParameters, details, parameters, requestContext, bindings)
+
+	return UpdateResponse{Description: instance.LastOperationDescription}, true, nil
+}
+
+func (b *SQSBroker) migrateQueue(instance brokerstore.Instance, servicePlan ServicePlan, updateParameters UpdateParameters, details brokerapi.UpdateDetails, parameters map[string]interface{}, requestContext map[string]interface{}, bindings []brokerstore.Binding) {
+	logger := b.logger.Session("migrate-queue", lager.Data{
+		instanceIDLogKey: instance.InstanceID,
+		"source-region":  instance.Region,
+		"target-region":  servicePlan.Region,
+	})
+
+	if err := b.moveQueue(instance, servicePlan, updateParameters, details, bindings, logger); err != nil {
+		logger.Error("migration-failed", err)
+		instance.LastOperationState = brokerapi.LastOperationFailed
+		instance.LastOperationDescription = err.Error()
+		if err := b.store.SaveInstance(instance); err != nil {
+			logger.Error("save-instance-failed", err)
+		}
+		return
+	}
+
+	instance.Region = servicePlan.Region
+	instance.LastOperationState = brokerapi.LastOperationSucceeded
+	instance.LastOperationDescription = fmt.Sprintf("Queue moved to region '%s'", servicePlan.Region)
+	if err := b.updateInstance(instance, details, parameters, requestContext); err != nil {
+		logger.Error("save-instance-failed", err)
+	}
+}
+
+func (b *SQSBroker) moveQueue(instance brokerstore.Instance, servicePlan ServicePlan, updateParameters UpdateParameters, details brokerapi.UpdateDetails, bindings []brokerstore.Binding, logger lager.Logger) (err error) {
+	queueName := b.queueName(instance.InstanceID)
+	source := b.storedInstanceClients(instance)
+	target := b.awsClients(servicePlan.Region, instanceAccountRole(instance))
+
+	sourceQueueDetails, err := source.queue.Describe(queueName)
+	if err != nil {
+		return err
+	}
+
+	// The current attributes are kept unless the new plan or parameters set them, the policy refers to the old Queue ARN
+	targetQueueDetails := awssqs.QueueDetails{
+		DelaySeconds:                  sourceQueueDetails.DelaySeconds,
+		MaximumMessageSize:            sourceQueueDetails.MaximumMessageSize,
+		MessageRetentionPeriod:        sourceQueueDetails.MessageRetentionPeriod,
+		ReceiveMessageWaitTimeSeconds: sourceQueueDetails.ReceiveMessageWaitTimeSeconds,
+		VisibilityTimeout:             sourceQueueDetails.VisibilityTimeout,
+	}
+	mergeQueueDetails(&targetQueueDetails, *b.modifyQueueDetails(instance.InstanceID, servicePlan, updateParameters, details))
+
+	policyTemplate := ""
+	if isPolicyTemplate(targetQueueDetails.Policy) {
+		policyTemplate = targetQueueDetails.Policy
+		targetQueueDetails.Policy = ""
+	}
+
+	targetQueueURL, err := target.queue.Create(queueName, targetQueueDetails)
+	b.audit("create-queue", instance.InstanceID, "", []auditlog.Resource{queueResource(queueName, target.region, queueArnFromURL(target.region, targetQueueURL))}, err)
+	if err != nil {
+		return err
+	}
+
+	moved := 0
+	bindingsMoved := false
+	defer func() {
+		if err != nil {
+			b.rollbackQueueMove(instance, source, target, sourceQueueDetails.QueueArn, bindings, bindingsMoved, moved, logger)
+		}
+	}()
+
+	targetQueue, err := target.queue.Describe(queueName)
+	if err != nil {
+		return err
+	}
+
+	if policyTemplate != "" {
+		policy, err := renderQueuePolicy(policyTemplate, targetQueue, updateParameters.SourceArns)
+		if err != nil {
+			return err
+		}
+
+		if err := target.queue.SetPolicy(queueName, policy); err != nil {
+			return err
+		}
+	}
+
+	// Producers still sending would keep the move running forever, it gives up past the messages counted now
+	n, err := b.moveMessages(source.queue, queueName, target.queue, queueName, moveMessagesLimit(sourceQueueDetails))
+	moved += n
+	logger.Info("move-messages", lager.Data{"messages": n})
+	if err != nil {
+		return err
+	}
+
+	bindingsMoved = true
+	for _, binding := range bindings {
+		if err = b.replaceBindingPolicy(target.user, instance.InstanceID, binding.BindingID, "sqs:*", targetQueue.QueueArn); err != nil {
+			err = fmt.Errorf("Moving binding '%s': %s", binding.BindingID, err)
+			return err
+		}
+	}
+
+	// Applications still using the old Queue URL might have sent messages while the bindings were moved
+	sourceQueueDetails, err = source.queue.Describe(queueName)
+	if err != nil {
+		return err
+	}
+
+	// moveMessages only returns once the source Queue holds no visible, delayed or in flight messages
+	n, err = b.moveMessages(source.queue, queueName, target.queue, queueName, moveMessagesLimit(sourceQueueDetails))
+	moved += n
+	logger.Info("move-messages", lager.Data{"messages": n})
+	if err != nil {
+		return err
+	}
+
+	err = source.queue.Delete(queueName)
+	b.audit("delete-queue", instance.InstanceID, "", []auditlog.Resource{queueResource(queueName, source.region, sourceQueueDetails.QueueArn)}, err)
+
+	return err
+}
+
+// rollbackQueueMove moves the messages and bindings of a failed migration back to the source Queue, then deletes the target Queue.
+// The target Queue is kept when its messages can not be moved back.
+func (b *SQSBroker) rollbackQueueMove(instance brokerstore.Instance, source awsClients, target awsClients, sourceQueueArn string, bindings []brokerstore.Binding, bindingsMoved bool, moved int, logger lager.Logger) {
+	queueName := b.queueName(instance.InstanceID)
+
+	if bindingsMoved {
+		for _, binding := range bindings {
+			if err := b.replaceBindingPolicy(source.user, instance.InstanceID, binding.BindingID, "sqs:*", sourceQueueArn); err != nil {
+				logger.Error("rollback-binding-failed", err, lager.Data{bindingIDLogKey: binding.BindingID})
+			}
+		}
+	}
+
+	movedBack, err := b.moveMessages(target.queue, queueName, source.queue, queueName, moved+archiveReceiveBatchSize)
+	logger.Info("rollback-messages", lager.Data{"messages": movedBack})
+	if err != nil {
+		logger.Error("rollback-messages-failed", err)
+		return
+	}
+
+	targetQueueDetails, err := target.queue.Describe(queueName)
+	if err != nil {
+		logger.Error("rollback-queue-failed", err)
+		return
+	}
+
+	err = target.queue.Delete(queueName)
+	b.audit("delete-queue", instance.InstanceID, "", []auditlog.Resource{queueResource(queueName, target.region, targetQueueDetails.QueueArn)}, err)
+	if err != nil {
+		logger.Error("rollback-queue-failed", err)
+	}
+}
+
+// moveMessagesLimit bounds a move to the messages a Queue holds now, plus one batch
+func moveMessagesLimit(queueDetails awssqs.QueueDetails) int {
+	messages, _ := strconv.Atoi(queueDetails.ApproximateNumberOfMessages)
+	messagesDelayed, _ := strconv.Atoi(queueDetails.ApproximateNumberOfMessagesDelayed)
+	messagesNotVisible, _ := strconv.Atoi(queueDetails.ApproximateNumberOfMessagesNotVisible)
+
+	return messages + messagesDelayed + messagesNotVisible + archiveReceiveBatchSize
+}
+
+func (b *SQSBroker) replaceBindingPolicy(user awsiam.User, instanceID string, bindingID string, action string, resourceArn string) (err error) {
+	resources := []auditlog.Resource{userResource(b.userName(bindingID), "")}
+	defer func() {
+		b.audit("replace-binding-policy", instanceID, bindingID, resources, err)
+	}()
+
+	userPolicies, err := user.ListAttachedUserPolicies(b.userName(bindingID))
+	if err != nil {
+		return err
+	}
+
+	for _, userPolicy := range userPolicies {
+		if err := user.DetachUserPolicy(b.userName(bindingID), userPolicy); err != nil {
+			return err
+		}
+
+		if err := user.DeletePolicy(userPolicy); err != nil {
+			return err
+		}
+		resources = append(resources, policyResource(userPolicy))
+	}
+
+	policyARN, err := user.CreatePolicy(b.policyName(bindingID), "Allow", action, resourceArn)
+	if err != nil {
+		return err
+	}
+	resources = append(resources, policyResource(policyARN))
+
+	return user.AttachUserPolicy(b.userName(bindingID), policyARN)
+}
+
+func (b *SQSBroker) instanceBindings(instanceID string) ([]brokerstore.Binding, error) {
+	bindings, err := b.store.ListBindings()
+	if err != nil {
+		return nil, err
+	}
+
+	instanceBindings := []brokerstore.Binding{}
+	for _, binding := range bindings {
+		if binding.InstanceID == instanceID {
+			instanceBindings = append(instanceBindings, binding)
+		}
+	}
+
+	return instanceBindings, nil
+}
+
+func mergeQueueDetails(queueDetails *awssqs.QueueDetails, overrides awssqs.QueueDetails) {
+	if overrides.DelaySeconds != "" {
+		queueDetails.DelaySeconds = overrides.DelaySeconds
+	}
+
+	if overrides.MaximumMessageSize != "" {
+		queueDetails.MaximumMessageSize = overrides.MaximumMessageSize
+	}
+
+	if overrides.MessageRetentionPeriod != "" {
+		queueDetails.MessageRetentionPeriod = overrides.MessageRetentionPeriod
+	}
+
+	if overrides.Policy != "" {
+		queueDetails.Policy = overrides.Policy
+	}
+
+	if overrides.ReceiveMessageWaitTimeSeconds != "" {
+		queueDetails.ReceiveMessageWaitTimeSeconds = overrides.ReceiveMessageWaitTimeSeconds
+	}
+
+	if overrides.VisibilityTimeout != "" {
+		queueDetails.VisibilityTimeout = overrides.VisibilityTimeout
+	}
+}
@@ -0,0 +1,275 @@
+package sqsbroker_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/cf-platform-eng/sqs-broker/sqsbroker"
+
+	"github.com/cf-platform-eng/sqs-broker/auditlog"
+	"github.com/cf-platform-eng/sqs-broker/awsiam"
+	iamfake "github.com/cf-platform-eng/sqs-broker/awsiam/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awssns"
+	snsfake "github.com/cf-platform-eng/sqs-broker/awssns/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awssqs"
+	sqsfake "github.com/cf-platform-eng/sqs-broker/awssqs/fakes"
+	"github.com/cf-platform-eng/sqs-broker/brokerstore"
+)
+
+var _ = Describe("Plan migration", func() {
+	var (
+		sourceQueue *sqsfake.FakeQueue
+		targetQueue *sqsfake.FakeQueue
+		user        *iamfake.FakeUser
+		store       *brokerstore.JSONStore
+
+		sqsBroker *SQSBroker
+
+		allowPlanMigration bool
+		acceptsIncomplete  bool
+		updateDetails      brokerapi.UpdateDetails
+
+		instanceID = "instance-id"
+		queueName  = "cf-instance-id"
+		userName   = "cf-binding-id"
+		messages   = []awssqs.Message{awssqs.Message{MessageID: "message-id", Body: "body"}}
+	)
+
+	BeforeEach(func() {
+		sourceQueue = &sqsfake.FakeQueue{}
+		targetQueue = &sqsfake.FakeQueue{}
+		user = &iamfake.FakeUser{}
+		store = brokerstore.NewMemoryStore()
+		allowPlanMigration = true
+		acceptsIncomplete = true
+
+		sourceQueue.DescribeQueueDetails = awssqs.QueueDetails{
+			QueueArn:          "arn:aws:sqs:sqs-region:123456789012:cf-instance-id",
+			DelaySeconds:      "5",
+			VisibilityTimeout: "30",
+			Policy:            "source-policy",
+		}
+		sourceQueue.ReceiveMessagesMessages = messages
+		targetQueue.DescribeQueueDetails = awssqs.QueueDetails{
+			QueueArn: "arn:aws:sqs:eu-west-1:123456789012:cf-instance-id",
+		}
+		user.ListAttachedUserPoliciesUserPolicies = []string{"old-policy-arn"}
+		user.CreatePolicyPolicyARN = "new-policy-arn"
+
+		err := store.SaveInstance(brokerstore.Instance{
+			InstanceID: instanceID,
+			ServiceID:  "Service-1",
+			PlanID:     "Plan-1",
+			Region:     "sqs-region",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		err = store.SaveBinding(brokerstore.Binding{BindingID: "binding-id", InstanceID: instanceID})
+		Expect(err).ToNot(HaveOccurred())
+
+		updateDetails = brokerapi.UpdateDetails{
+			ServiceID:  "Service-1",
+			PlanID:     "Plan-2",
+			Parameters: map[string]interface{}{"visibility_timeout": "60"},
+			PreviousValues: brokerapi.PreviousValues{
+				PlanID:    "Plan-1",
+				ServiceID: "Service-1",
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		config := Config{
+			Region:                    "sqs-region",
+			SQSPrefix:                 "cf",
+			AllowUserUpdateParameters: true,
+			AllowPlanMigration:        allowPlanMigration,
+			Catalog: Catalog{
+				Services: []Service{
+					Service{
+						ID:             "Service-1",
+						Name:           "Service 1",
+						Description:    "This is the Service 1",
+						Bindable:       true,
+						PlanUpdateable: true,
+						Plans: []ServicePlan{
+							ServicePlan{ID: "Plan-1", Name: "Plan 1", Description: "This is the Plan 1"},
+							ServicePlan{ID: "Plan-2", Name: "Plan 2", Description: "This is the Plan 2", Region: "eu-west-1"},
+						},
+					},
+				},
+			},
+		}
+
+		clientsFactory := func(region string, accountRole AccountRole) (awssqs.Queue, awssns.Topic, awsiam.User) {
+			if region == "eu-west-1" {
+				return targetQueue, &snsfake.FakeTopic{}, user
+			}
+			return sourceQueue, &snsfake.FakeTopic{}, user
+		}
+
+		logger := lager.NewLogger("migration_test")
+		logger.RegisterSink(lagertest.NewTestSink())
+
+		sqsBroker = New(config, clientsFactory, store, nil, auditlog.Discard, logger)
+	})
+
+	lastOperationState := func() string {
+		lastOperationResponse, err := sqsBroker.LastOperation(instanceID)
+		Expect(err).ToNot(HaveOccurred())
+		return lastOperationResponse.State
+	}
+
+	It("moves the Queue to the Region of the new Service Plan in the background", func() {
+		asynch, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(asynch).To(BeTrue())
+		Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationSucceeded))
+
+		Expect(targetQueue.CreateQueueName).To(Equal(queueName))
+		Expect(targetQueue.CreateQueueDetails).To(Equal(awssqs.QueueDetails{
+			DelaySeconds:      "5",
+			VisibilityTimeout: "60",
+		}))
+		Expect(targetQueue.SendMessagesMessages).To(Equal(messages))
+		Expect(sourceQueue.DeleteMessagesMessages).To(Equal(messages))
+		Expect(sourceQueue.DeleteQueueName).To(Equal(queueName))
+
+		Expect(user.DetachUserPolicyUserName).To(Equal(userName))
+		Expect(user.DeletePolicyPolicyARN).To(Equal("old-policy-arn"))
+		Expect(user.CreatePolicyAction).To(Equal("sqs:*"))
+		Expect(user.CreatePolicyResource).To(Equal("arn:aws:sqs:eu-west-1:123456789012:cf-instance-id"))
+		Expect(user.AttachUserPolicyPolicyARN).To(Equal("new-policy-arn"))
+
+		instance, err := store.GetInstance(instanceID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instance.Region).To(Equal("eu-west-1"))
+		Expect(instance.PlanID).To(Equal("Plan-2"))
+		Expect(instance.LastOperationDescription).To(Equal("Queue moved to region 'eu-west-1'"))
+	})
+
+	It("rejects other operations while the Queue is moved", func() {
+		instance, err := store.GetInstance(instanceID)
+		Expect(err).ToNot(HaveOccurred())
+		instance.LastOperationState = brokerapi.LastOperationInProgress
+		err = store.SaveInstance(instance)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+		Expect(err).To(BeAssignableToTypeOf(&InstanceBusyError{}))
+
+		_, err = sqsBroker.Bind(instanceID, "other-binding-id", brokerapi.BindDetails{ServiceID: "Service-1", PlanID: "Plan-1"})
+		Expect(err).To(BeAssignableToTypeOf(&InstanceBusyError{}))
+
+		_, err = sqsBroker.Deprovision(instanceID, brokerapi.DeprovisionDetails{ServiceID: "Service-1", PlanID: "Plan-1"}, false)
+		Expect(err).To(BeAssignableToTypeOf(&InstanceBusyError{}))
+	})
+
+	Context("when moving the messages fails", func() {
+		BeforeEach(func() {
+			targetQueue.SendMessagesError = errors.New("operation failed")
+		})
+
+		It("fails the migration and keeps the Queue", func() {
+			_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationFailed))
+
+			Expect(sourceQueue.DeleteCalled).To(BeFalse())
+			Expect(targetQueue.DeleteQueueName).To(Equal(queueName))
+			instance, err := store.GetInstance(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instance.Region).To(Equal("sqs-region"))
+			Expect(instance.PlanID).To(Equal("Plan-1"))
+			Expect(instance.LastOperationDescription).To(Equal("operation failed"))
+		})
+	})
+
+	Context("when the Queue keeps receiving messages", func() {
+		BeforeEach(func() {
+			sourceQueue.ReceiveMessagesMessages = nil
+			for i := 0; i < 20; i++ {
+				sourceQueue.ReceiveMessagesBatches = append(sourceQueue.ReceiveMessagesBatches, messages)
+			}
+		})
+
+		It("gives up and keeps the Queue", func() {
+			_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationFailed))
+
+			Expect(sourceQueue.DeleteCalled).To(BeFalse())
+			instance, err := store.GetInstance(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instance.LastOperationDescription).To(Equal("Queue 'cf-instance-id' still receives messages after 10 were moved, stop its producers and try again"))
+		})
+	})
+
+	Context("when moving the bindings fails", func() {
+		BeforeEach(func() {
+			user.AttachUserPolicyError = errors.New("operation failed")
+			targetQueue.ReceiveMessagesMessages = messages
+		})
+
+		It("moves the messages back and deletes the new Queue", func() {
+			_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(lastOperationState).Should(Equal(brokerapi.LastOperationFailed))
+
+			Expect(sourceQueue.SendMessagesMessages).To(Equal(messages))
+			Expect(targetQueue.DeleteMessagesMessages).To(Equal(messages))
+			Expect(targetQueue.DeleteQueueName).To(Equal(queueName))
+			Expect(sourceQueue.DeleteCalled).To(BeFalse())
+			Expect(user.CreatePolicyResource).To(Equal("arn:aws:sqs:sqs-region:123456789012:cf-instance-id"))
+
+			instance, err := store.GetInstance(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instance.Region).To(Equal("sqs-region"))
+			Expect(instance.LastOperationDescription).To(Equal("Moving binding 'binding-id': operation failed"))
+		})
+	})
+
+	Context("when the Queue is subscribed to a Topic", func() {
+		BeforeEach(func() {
+			err := store.SaveBinding(brokerstore.Binding{BindingID: "binding-id", InstanceID: instanceID, SubscriptionArn: "subscription-arn"})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns the proper error", func() {
+			_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(&QueueSubscribedError{}))
+			Expect(err.Error()).To(Equal("Queue 'cf-instance-id' is subscribed to topics by binding 'binding-id', unbind it before moving the queue to region 'eu-west-1'"))
+			Expect(targetQueue.CreateCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the platform does not accept incomplete operations", func() {
+		BeforeEach(func() {
+			acceptsIncomplete = false
+		})
+
+		It("returns the proper error", func() {
+			_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+			Expect(err).To(Equal(brokerapi.ErrAsyncRequired))
+		})
+	})
+
+	Context("when plan migrations are not allowed", func() {
+		BeforeEach(func() {
+			allowPlanMigration = false
+		})
+
+		It("returns the proper error", func() {
+			_, err := sqsBroker.Update(instanceID, updateDetails, acceptsIncomplete)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(&RegionMoveNotAllowedError{}))
+			Expect(err.Error()).To(Equal("Service Plan 'Plan-2' is in region 'eu-west-1', instances can not be moved from region 'sqs-region'"))
+		})
+	})
+})
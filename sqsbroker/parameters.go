@@ -1,17 +1,38 @@
 package sqsbroker
 
 type ProvisionParameters struct {
-	DelaySeconds                  string `mapstructure:"delay_seconds"`
-	MaximumMessageSize            string `mapstructure:"maximum_message_size"`
-	MessageRetentionPeriod        string `mapstructure:"message_retention_period"`
-	ReceiveMessageWaitTimeSeconds string `mapstructure:"receive_message_wait_time_seconds"`
-	VisibilityTimeout             string `mapstructure:"visibility_timeout"`
+	DelaySeconds                  string   `mapstructure:"delay_seconds"`
+	MaximumMessageSize            string   `mapstructure:"maximum_message_size"`
+	MessageRetentionPeriod        string   `mapstructure:"message_retention_period"`
+	ReceiveMessageWaitTimeSeconds string   `mapstructure:"receive_message_wait_time_seconds"`
+	VisibilityTimeout             string   `mapstructure:"visibility_timeout"`
+	SourceArns                    []string `mapstructure:"source_arns"`
+	Region                        string   `mapstructure:"region"`
 }
 
 type UpdateParameters struct {
-	DelaySeconds                  string `mapstructure:"delay_seconds"`
-	MaximumMessageSize            string `mapstructure:"maximum_message_size"`
-	MessageRetentionPeriod        string `mapstructure:"message_retention_period"`
-	ReceiveMessageWaitTimeSeconds string `mapstructure:"receive_message_wait_time_seconds"`
-	VisibilityTimeout             string `mapstructure:"visibility_timeout"`
+	DelaySeconds                  string      `mapstructure:"delay_seconds"`
+	MaximumMessageSize            string      `mapstructure:"maximum_message_size"`
+	MessageRetentionPeriod        string      `mapstructure:"message_retention_period"`
+	ReceiveMessageWaitTimeSeconds string      `mapstructure:"receive_message_wait_time_seconds"`
+	VisibilityTimeout             string      `mapstructure:"visibility_timeout"`
+	SourceArns                    []string    `mapstructure:"source_arns"`
+	SubscribeToTopic              string      `mapstructure:"subscribe_to_topic"`
+	UnsubscribeFromTopic          string      `mapstructure:"unsubscribe_from_topic"`
+	RawMessageDelivery            *bool       `mapstructure:"raw_message_delivery"`
+	FilterPolicy                  interface{} `mapstructure:"filter_policy"`
+}
+
+type BindParameters struct {
+	SubscribeToTopic   string      `mapstructure:"subscribe_to_topic"`
+	RawMessageDelivery *bool       `mapstructure:"raw_message_delivery"`
+	FilterPolicy       interface{} `mapstructure:"filter_policy"`
+}
+
+type RequestContext struct {
+	Platform         string `mapstructure:"platform"`
+	OrganizationGUID string `mapstructure:"organization_guid"`
+	SpaceGUID        string `mapstructure:"space_guid"`
+	Namespace        string `mapstructure:"namespace"`
+	ClusterID        string `mapstructure:"clusterid"`
 }
@@ -0,0 +1,143 @@
+package sqsbroker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/cf-platform-eng/sqs-broker/awssqs"
+)
+
+// policyTemplateVariables are the variables available to a templated plan Policy
+type policyTemplateVariables struct {
+	QueueArn   string
+	QueueName  string
+	QueueURL   string
+	AccountID  string
+	Region     string
+	SourceArns []string
+}
+
+// sourceArnPattern matches arn:<partition>:<service>:<region>:<account-id>:<resource>, without wildcards or characters
+// that would change the meaning of the rendered Policy
+var sourceArnPattern = regexp.MustCompile(`^arn:aws[a-z-]*:[a-z0-9-]+:[a-z0-9-]*:[0-9]*:[^*?"'\\\s]+$`)
+
+type InvalidSourceArnError struct {
+	SourceArn string
+}
+
+func (e *InvalidSourceArnError) Error() string {
+	return fmt.Sprintf("Source ARN '%s' is not a valid ARN, wildcards and quotes are not allowed", e.SourceArn)
+}
+
+func (e *InvalidSourceArnError) Kind() string {
+	return InvalidRequestErrorKind
+}
+
+func validateSourceArns(sourceArns []string) error {
+	for _, sourceArn := range sourceArns {
+		if !sourceArnPattern.MatchString(sourceArn) {
+			return &InvalidSourceArnError{SourceArn: sourceArn}
+		}
+	}
+
+	return nil
+}
+
+// isPolicyTemplate tells whether a Policy must be rendered once the Queue exists
+func isPolicyTemplate(policy string) bool {
+	return strings.Contains(policy, "{{")
+}
+
+func parsePolicyTemplate(policy string) (*template.Template, error) {
+	return template.New("policy").Option("missingkey=error").Funcs(template.FuncMap{
+		"json": func(value interface{}) (string, error) {
+			contents, err := json.Marshal(value)
+			return string(contents), err
+		},
+	}).Parse(policy)
+}
+
+func renderPolicyTemplate(policy string, variables policyTemplateVariables) (string, error) {
+	policyTemplate, err := parsePolicyTemplate(policy)
+	if err != nil {
+		return "", err
+	}
+
+	var rendered bytes.Buffer
+	if err := policyTemplate.Execute(&rendered, variables); err != nil {
+		return "", err
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(rendered.Bytes(), &document); err != nil {
+		return "", fmt.Errorf("Rendered Queue Policy is not valid JSON: %s", err)
+	}
+
+	return rendered.String(), nil
+}
+
+// queuePolicyVariables reads the account and region from the Queue ARN (arn:aws:sqs:<region>:<account-id>:<queue-name>)
+func queuePolicyVariables(queueDetails awssqs.QueueDetails, sourceArns []string) (policyTemplateVariables, error) {
+	arnParts := strings.Split(queueDetails.QueueArn, ":")
+	if len(arnParts) != 6 {
+		return policyTemplateVariables{}, fmt.Errorf("Invalid Queue ARN '%s'", queueDetails.QueueArn)
+	}
+
+	if sourceArns == nil {
+		sourceArns = []string{}
+	}
+
+	return policyTemplateVariables{
+		QueueArn:   queueDetails.QueueArn,
+		QueueName:  arnParts[5],
+		QueueURL:   queueDetails.QueueURL,
+		AccountID:  arnParts[4],
+		Region:     arnParts[3],
+		SourceArns: sourceArns,
+	}, nil
+}
+
+func renderQueuePolicy(policy string, queueDetails awssqs.QueueDetails, sourceArns []string) (string, error) {
+	if !isPolicyTemplate(policy) {
+		return policy, nil
+	}
+
+	variables, err := queuePolicyVariables(queueDetails, sourceArns)
+	if err != nil {
+		return "", err
+	}
+
+	return renderPolicyTemplate(policy, variables)
+}
+
+// applyQueuePolicyTemplate sets a templated plan Policy on a Queue that has just been created without it
+func (b *SQSBroker) applyQueuePolicyTemplate(queue awssqs.Queue, queueName string, policy string, sourceArns []string) error {
+	queueDetails, err := queue.Describe(queueName)
+	if err != nil {
+		return err
+	}
+
+	renderedPolicy, err := renderQueuePolicy(policy, queueDetails, sourceArns)
+	if err != nil {
+		return err
+	}
+
+	return queue.SetPolicy(queueName, renderedPolicy)
+}
+
+func validatePolicyTemplate(policy string) error {
+	_, err := renderPolicyTemplate(policy, policyTemplateVariables{
+		QueueArn:   "arn:aws:sqs:us-east-1:123456789012:queue",
+		QueueName:  "queue",
+		QueueURL:   "https://sqs.us-east-1.amazonaws.com/123456789012/queue",
+		AccountID:  "123456789012",
+		Region:     "us-east-1",
+		SourceArns: []string{"arn:aws:s3:::bucket"},
+	})
+
+	return err
+}
@@ -0,0 +1,139 @@
+package sqsbroker
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cf-platform-eng/sqs-broker/awssqs"
+)
+
+// AWS SQS values for the attributes a plan or the user parameters do not set
+const (
+	defaultDelaySeconds                  = "0"
+	defaultMaximumMessageSize            = "262144"
+	defaultMessageRetentionPeriod        = "345600"
+	defaultReceiveMessageWaitTimeSeconds = "0"
+	defaultVisibilityTimeout             = "30"
+)
+
+type UpdateResponse struct {
+	Description string `json:"description,omitempty"`
+}
+
+type queueAttributeChange struct {
+	Name     string
+	Previous string
+	Current  string
+}
+
+func (c queueAttributeChange) String() string {
+	switch {
+	case c.Name != "policy":
+		return fmt.Sprintf("%s (%s -> %s)", c.Name, c.Previous, c.Current)
+	case c.Previous == "":
+		return "policy (added)"
+	case c.Current == "":
+		return "policy (removed)"
+	default:
+		return "policy (changed)"
+	}
+}
+
+// diffQueueDetails returns the attributes to set on a Queue to move it from its current to its desired state,
+// and the changes made. Attributes not set in the desired state are reset to their AWS SQS default.
+func diffQueueDetails(current awssqs.QueueDetails, desired awssqs.QueueDetails) (awssqs.QueueDetails, []queueAttributeChange, error) {
+	modifyQueueDetails := awssqs.QueueDetails{}
+	changes := []queueAttributeChange{}
+
+	attributes := []struct {
+		name         string
+		current      string
+		desired      string
+		defaultValue string
+		modify       *string
+	}{
+		{"delay_seconds", current.DelaySeconds, desired.DelaySeconds, defaultDelaySeconds, &modifyQueueDetails.DelaySeconds},
+		{"maximum_message_size", current.MaximumMessageSize, desired.MaximumMessageSize, defaultMaximumMessageSize, &modifyQueueDetails.MaximumMessageSize},
+		{"message_retention_period", current.MessageRetentionPeriod, desired.MessageRetentionPeriod, defaultMessageRetentionPeriod, &modifyQueueDetails.MessageRetentionPeriod},
+		{"receive_message_wait_time_seconds", current.ReceiveMessageWaitTimeSeconds, desired.ReceiveMessageWaitTimeSeconds, defaultReceiveMessageWaitTimeSeconds, &modifyQueueDetails.ReceiveMessageWaitTimeSeconds},
+		{"visibility_timeout", current.VisibilityTimeout, desired.VisibilityTimeout, defaultVisibilityTimeout, &modifyQueueDetails.VisibilityTimeout},
+	}
+
+	for _, attribute := range attributes {
+		currentValue := valueOrDefault(attribute.current, attribute.defaultValue)
+		desiredValue := valueOrDefault(attribute.desired, attribute.defaultValue)
+		if currentValue != desiredValue {
+			*attribute.modify = desiredValue
+			changes = append(changes, queueAttributeChange{Name: attribute.name, Previous: currentValue, Current: desiredValue})
+		}
+	}
+
+	equal, err := equalPolicies(current.Policy, desired.Policy)
+	if err != nil {
+		return modifyQueueDetails, changes, err
+	}
+	if !equal {
+		modifyQueueDetails.Policy = desired.Policy
+		changes = append(changes, queueAttributeChange{Name: "policy", Previous: current.Policy, Current: desired.Policy})
+	}
+
+	return modifyQueueDetails, changes, nil
+}
+
+func describeQueueAttributeChanges(changes []queueAttributeChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	descriptions := make([]string, len(changes))
+	for i, change := range changes {
+		descriptions[i] = change.String()
+	}
+
+	return "Queue attributes changed: " + strings.Join(descriptions, ", ")
+}
+
+// mergeParameters adds the new parameters to the stored ones, a null value removes a stored parameter
+func mergeParameters(parameters map[string]interface{}, newParameters map[string]interface{}) map[string]interface{} {
+	mergedParameters := map[string]interface{}{}
+	for key, value := range parameters {
+		mergedParameters[key] = value
+	}
+
+	for key, value := range newParameters {
+		if value == nil {
+			delete(mergedParameters, key)
+			continue
+		}
+		mergedParameters[key] = value
+	}
+
+	return mergedParameters
+}
+
+func valueOrDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
+
+// equalPolicies compares policies by their JSON document, as AWS SQS does not keep the policy formatting
+func equalPolicies(policy string, otherPolicy string) (bool, error) {
+	if policy == "" || otherPolicy == "" {
+		return policy == otherPolicy, nil
+	}
+
+	var document, otherDocument interface{}
+	if err := json.Unmarshal([]byte(policy), &document); err != nil {
+		return false, fmt.Errorf("Invalid Queue Policy: %s", err)
+	}
+	if err := json.Unmarshal([]byte(otherPolicy), &otherDocument); err != nil {
+		return false, fmt.Errorf("Invalid Queue Policy: %s", err)
+	}
+
+	return reflect.DeepEqual(document, otherDocument), nil
+}
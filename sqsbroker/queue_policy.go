@@ -0,0 +1,135 @@
+package sqsbroker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const policyVersion = "2012-10-17"
+
+func addTopicPolicyStatement(policy string, sid string, queueArn string, topicArn string) (string, error) {
+	document, statements, err := parsePolicy(policy)
+	if err != nil {
+		return "", err
+	}
+
+	statements = removeStatements(statements, func(statementSid string) bool { return statementSid == sid })
+	statements = append(statements, map[string]interface{}{
+		"Sid":       sid,
+		"Effect":    "Allow",
+		"Principal": map[string]interface{}{"Service": "sns.amazonaws.com"},
+		"Action":    "sqs:SendMessage",
+		"Resource":  queueArn,
+		"Condition": map[string]interface{}{
+			"ArnEquals": map[string]interface{}{"aws:SourceArn": topicArn},
+		},
+	})
+
+	return marshalPolicy(document, statements)
+}
+
+func removePolicyStatement(policy string, sid string) (string, error) {
+	if policy == "" {
+		return "", nil
+	}
+
+	document, statements, err := parsePolicy(policy)
+	if err != nil {
+		return "", err
+	}
+
+	statements = removeStatements(statements, func(statementSid string) bool { return statementSid == sid })
+
+	return marshalPolicy(document, statements)
+}
+
+// keepManagedPolicyStatements copies the statements managed by the broker from the current policy into a new one
+func keepManagedPolicyStatements(policy string, currentPolicy string, sidPrefix string) (string, error) {
+	if currentPolicy == "" {
+		return policy, nil
+	}
+
+	_, currentStatements, err := parsePolicy(currentPolicy)
+	if err != nil {
+		return "", err
+	}
+
+	var managedStatements []interface{}
+	for _, statement := range currentStatements {
+		if strings.HasPrefix(statementSid(statement), sidPrefix) {
+			managedStatements = append(managedStatements, statement)
+		}
+	}
+	if len(managedStatements) == 0 {
+		return policy, nil
+	}
+
+	document, statements, err := parsePolicy(policy)
+	if err != nil {
+		return "", err
+	}
+
+	statements = removeStatements(statements, func(statementSid string) bool { return strings.HasPrefix(statementSid, sidPrefix) })
+	statements = append(statements, managedStatements...)
+
+	return marshalPolicy(document, statements)
+}
+
+func parsePolicy(policy string) (map[string]interface{}, []interface{}, error) {
+	document := map[string]interface{}{}
+	if policy == "" {
+		document["Version"] = policyVersion
+		return document, []interface{}{}, nil
+	}
+
+	if err := json.Unmarshal([]byte(policy), &document); err != nil {
+		return nil, nil, fmt.Errorf("Invalid Queue Policy: %s", err)
+	}
+
+	// IAM policies allow a single statement instead of a list
+	switch statement := document["Statement"].(type) {
+	case nil:
+		return document, []interface{}{}, nil
+	case []interface{}:
+		return document, statement, nil
+	default:
+		return document, []interface{}{statement}, nil
+	}
+}
+
+func marshalPolicy(document map[string]interface{}, statements []interface{}) (string, error) {
+	// An empty Policy removes the Queue Policy, as AWS SQS rejects policies without statements
+	if len(statements) == 0 {
+		return "", nil
+	}
+
+	document["Statement"] = statements
+	policy, err := json.Marshal(document)
+	if err != nil {
+		return "", err
+	}
+
+	return string(policy), nil
+}
+
+func removeStatements(statements []interface{}, matches func(sid string) bool) []interface{} {
+	keptStatements := []interface{}{}
+	for _, statement := range statements {
+		if !matches(statementSid(statement)) {
+			keptStatements = append(keptStatements, statement)
+		}
+	}
+
+	return keptStatements
+}
+
+func statementSid(statement interface{}) string {
+	if statementMap, ok := statement.(map[string]interface{}); ok {
+		if sid, ok := statementMap["Sid"].(string); ok {
+			return sid
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,129 @@
+package sqsbroker
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+)
+
+type BindingLimitMetError struct {
+	InstanceID string
+	Limit      int
+}
+
+func (e *BindingLimitMetError) Error() string {
+	return fmt.Sprintf("Instance '%s' has reached its max_bindings_per_instance quota of %d bindings", e.InstanceID, e.Limit)
+}
+
+func (e *BindingLimitMetError) Kind() string {
+	return BindingLimitMetErrorKind
+}
+
+type Quotas struct {
+	MaxInstancesPerOrganization int `json:"max_instances_per_organization,omitempty"`
+	MaxInstancesPerSpace        int `json:"max_instances_per_space,omitempty"`
+	MaxInstancesPerPlan         int `json:"max_instances_per_plan,omitempty"`
+	MaxBindingsPerInstance      int `json:"max_bindings_per_instance,omitempty"`
+}
+
+func (q Quotas) Validate() error {
+	if q.MaxInstancesPerOrganization < 0 {
+		return NewValidationError("max_instances_per_organization", errors.New("Must provide a non-negative MaxInstancesPerOrganization"))
+	}
+
+	if q.MaxInstancesPerSpace < 0 {
+		return NewValidationError("max_instances_per_space", errors.New("Must provide a non-negative MaxInstancesPerSpace"))
+	}
+
+	if q.MaxInstancesPerPlan < 0 {
+		return NewValidationError("max_instances_per_plan", errors.New("Must provide a non-negative MaxInstancesPerPlan"))
+	}
+
+	if q.MaxBindingsPerInstance < 0 {
+		return NewValidationError("max_bindings_per_instance", errors.New("Must provide a non-negative MaxBindingsPerInstance"))
+	}
+
+	return nil
+}
+
+// checkInstanceQuotas counts the instances recorded by the broker, ignoring the instance being provisioned
+func (b *SQSBroker) checkInstanceQuotas(instanceID string, planID string, organizationContext RequestContext) error {
+	if b.quotas.MaxInstancesPerOrganization == 0 && b.quotas.MaxInstancesPerSpace == 0 && b.quotas.MaxInstancesPerPlan == 0 {
+		return nil
+	}
+
+	instances, err := b.store.ListInstances()
+	if err != nil {
+		return err
+	}
+
+	var organizationInstances, spaceInstances, planInstances int
+	for _, instance := range instances {
+		if instance.InstanceID == instanceID {
+			continue
+		}
+
+		if instance.PlanID == planID {
+			planInstances++
+		}
+
+		instanceContext, err := b.requestContext(instance.Context, instance.OrganizationGUID, instance.SpaceGUID)
+		if err != nil {
+			return err
+		}
+
+		if organizationContext.OrganizationGUID != "" && instanceContext.OrganizationGUID == organizationContext.OrganizationGUID {
+			organizationInstances++
+			if organizationContext.SpaceGUID != "" && instanceContext.SpaceGUID == organizationContext.SpaceGUID {
+				spaceInstances++
+			}
+		}
+	}
+
+	if b.quotaExceeded("max-instances-per-organization", organizationInstances, b.quotas.MaxInstancesPerOrganization, lager.Data{instanceIDLogKey: instanceID, "organization-guid": organizationContext.OrganizationGUID}) ||
+		b.quotaExceeded("max-instances-per-space", spaceInstances, b.quotas.MaxInstancesPerSpace, lager.Data{instanceIDLogKey: instanceID, "space-guid": organizationContext.SpaceGUID}) ||
+		b.quotaExceeded("max-instances-per-plan", planInstances, b.quotas.MaxInstancesPerPlan, lager.Data{instanceIDLogKey: instanceID, "plan-id": planID}) {
+		return brokerapi.ErrInstanceLimitMet
+	}
+
+	return nil
+}
+
+// checkBindingQuotas counts the bindings recorded by the broker, ignoring the binding being created
+func (b *SQSBroker) checkBindingQuotas(instanceID string, bindingID string) error {
+	if b.quotas.MaxBindingsPerInstance == 0 {
+		return nil
+	}
+
+	bindings, err := b.store.ListBindings()
+	if err != nil {
+		return err
+	}
+
+	var instanceBindings int
+	for _, binding := range bindings {
+		if binding.InstanceID == instanceID && binding.BindingID != bindingID {
+			instanceBindings++
+		}
+	}
+
+	if b.quotaExceeded("max-bindings-per-instance", instanceBindings, b.quotas.MaxBindingsPerInstance, lager.Data{instanceIDLogKey: instanceID, bindingIDLogKey: bindingID}) {
+		return &BindingLimitMetError{InstanceID: instanceID, Limit: b.quotas.MaxBindingsPerInstance}
+	}
+
+	return nil
+}
+
+func (b *SQSBroker) quotaExceeded(quota string, count int, limit int, data lager.Data) bool {
+	if limit == 0 || count < limit {
+		return false
+	}
+
+	data["quota"] = quota
+	data["limit"] = limit
+	b.logger.Info("quota-exceeded", data)
+
+	return true
+}
@@ -0,0 +1,76 @@
+package sqsbroker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cf-platform-eng/sqs-broker/brokerstore"
+)
+
+type RegionNotAllowedError struct {
+	Region string
+}
+
+func (e *RegionNotAllowedError) Error() string {
+	return fmt.Sprintf("Region '%s' is not allowed", e.Region)
+}
+
+func (e *RegionNotAllowedError) Kind() string {
+	return InvalidRequestErrorKind
+}
+
+type RegionMoveNotAllowedError struct {
+	PlanID         string
+	PlanRegion     string
+	InstanceRegion string
+}
+
+func (e *RegionMoveNotAllowedError) Error() string {
+	return fmt.Sprintf("Service Plan '%s' is in region '%s', instances can not be moved from region '%s'", e.PlanID, e.PlanRegion, e.InstanceRegion)
+}
+
+func (e *RegionMoveNotAllowedError) Kind() string {
+	return InvalidRequestErrorKind
+}
+
+// arnRegion returns the region of an ARN (arn:partition:service:region:account:resource), or an empty string
+func arnRegion(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 {
+		return ""
+	}
+
+	return parts[3]
+}
+
+func (b *SQSBroker) provisionRegion(servicePlan ServicePlan, provisionParameters ProvisionParameters) (string, error) {
+	region := b.region
+	if servicePlan.Region != "" {
+		region = servicePlan.Region
+	}
+
+	if provisionParameters.Region == "" || provisionParameters.Region == region {
+		return region, nil
+	}
+
+	for _, allowedRegion := range b.allowedRegions {
+		if allowedRegion == provisionParameters.Region {
+			return provisionParameters.Region, nil
+		}
+	}
+
+	return "", &RegionNotAllowedError{Region: provisionParameters.Region}
+}
+
+func (b *SQSBroker) checkRegion(servicePlan ServicePlan, instance brokerstore.Instance) error {
+	instanceRegion := instance.Region
+	if instanceRegion == "" {
+		instanceRegion = b.region
+	}
+
+	if servicePlan.Region != "" && servicePlan.Region != instanceRegion {
+		return &RegionMoveNotAllowedError{PlanID: servicePlan.ID, PlanRegion: servicePlan.Region, InstanceRegion: instanceRegion}
+	}
+
+	return nil
+}
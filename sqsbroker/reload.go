@@ -0,0 +1,140 @@
+package sqsbroker
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pivotal-golang/lager"
+)
+
+// reloadableConfig is the part of the Config that can be swapped by Reload while the broker is serving requests
+type reloadableConfig struct {
+	catalog                      Catalog
+	allowUserProvisionParameters bool
+	allowUserUpdateParameters    bool
+}
+
+type catalogDiff struct {
+	AddedServices   []string `json:"added_services,omitempty"`
+	RemovedServices []string `json:"removed_services,omitempty"`
+	ChangedServices []string `json:"changed_services,omitempty"`
+	AddedPlans      []string `json:"added_plans,omitempty"`
+	RemovedPlans    []string `json:"removed_plans,omitempty"`
+	ChangedPlans    []string `json:"changed_plans,omitempty"`
+}
+
+func newReloadableConfig(config Config) reloadableConfig {
+	return reloadableConfig{
+		catalog:                      config.Catalog,
+		allowUserProvisionParameters: config.AllowUserProvisionParameters,
+		allowUserUpdateParameters:    config.AllowUserUpdateParameters,
+	}
+}
+
+func (b *SQSBroker) currentConfig() reloadableConfig {
+	b.reloadableMutex.RLock()
+	defer b.reloadableMutex.RUnlock()
+
+	return b.reloadable
+}
+
+// Reload swaps the catalog and the user parameters options, other options are only read on startup
+func (b *SQSBroker) Reload(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	reloadable := newReloadableConfig(config)
+
+	b.reloadableMutex.Lock()
+	defer b.reloadableMutex.Unlock()
+
+	diff := diffCatalogs(b.reloadable.catalog, reloadable.catalog)
+	if len(diff.RemovedPlans) > 0 {
+		// In-memory records only hold the instances created since startup, so they can not tell whether a plan is unused
+		if !b.persistentRecords {
+			return fmt.Errorf("Service Plan '%s' can not be removed without a state_file, restart the broker with the new catalog instead", diff.RemovedPlans[0])
+		}
+
+		instances, err := b.store.ListInstances()
+		if err != nil {
+			return err
+		}
+
+		for _, planID := range diff.RemovedPlans {
+			for _, instance := range instances {
+				if instance.PlanID == planID {
+					return fmt.Errorf("Service Plan '%s' can not be removed, it is still used by instance '%s'", planID, instance.InstanceID)
+				}
+			}
+		}
+	}
+
+	b.logger.Info("reload", lager.Data{
+		"catalog":                         diff,
+		"allow-user-provision-parameters": reloadable.allowUserProvisionParameters,
+		"allow-user-update-parameters":    reloadable.allowUserUpdateParameters,
+	})
+	b.reloadable = reloadable
+
+	return nil
+}
+
+func diffCatalogs(previous Catalog, current Catalog) catalogDiff {
+	diff := catalogDiff{}
+
+	previousServices, previousPlans := indexCatalog(previous)
+	currentServices, currentPlans := indexCatalog(current)
+
+	for _, service := range current.Services {
+		previousService, ok := previousServices[service.ID]
+		switch {
+		case !ok:
+			diff.AddedServices = append(diff.AddedServices, service.ID)
+		case !reflect.DeepEqual(withoutPlans(previousService), withoutPlans(service)):
+			diff.ChangedServices = append(diff.ChangedServices, service.ID)
+		}
+
+		for _, plan := range service.Plans {
+			previousPlan, ok := previousPlans[plan.ID]
+			switch {
+			case !ok:
+				diff.AddedPlans = append(diff.AddedPlans, plan.ID)
+			case !reflect.DeepEqual(previousPlan, plan):
+				diff.ChangedPlans = append(diff.ChangedPlans, plan.ID)
+			}
+		}
+	}
+
+	for _, service := range previous.Services {
+		if _, ok := currentServices[service.ID]; !ok {
+			diff.RemovedServices = append(diff.RemovedServices, service.ID)
+		}
+
+		for _, plan := range service.Plans {
+			if _, ok := currentPlans[plan.ID]; !ok {
+				diff.RemovedPlans = append(diff.RemovedPlans, plan.ID)
+			}
+		}
+	}
+
+	return diff
+}
+
+func indexCatalog(catalog Catalog) (map[string]Service, map[string]ServicePlan) {
+	services := map[string]Service{}
+	plans := map[string]ServicePlan{}
+	for _, service := range catalog.Services {
+		services[service.ID] = service
+		for _, plan := range service.Plans {
+			plans[plan.ID] = plan
+		}
+	}
+
+	return services, plans
+}
+
+func withoutPlans(service Service) Service {
+	service.Plans = nil
+	return service
+}
@@ -0,0 +1,187 @@
+package sqsbroker_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/cf-platform-eng/sqs-broker/sqsbroker"
+
+	"github.com/cf-platform-eng/sqs-broker/auditlog"
+	"github.com/cf-platform-eng/sqs-broker/awsiam"
+	iamfake "github.com/cf-platform-eng/sqs-broker/awsiam/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awssns"
+	snsfake "github.com/cf-platform-eng/sqs-broker/awssns/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awssqs"
+	sqsfake "github.com/cf-platform-eng/sqs-broker/awssqs/fakes"
+	"github.com/cf-platform-eng/sqs-broker/brokerstore"
+	storefake "github.com/cf-platform-eng/sqs-broker/brokerstore/fakes"
+)
+
+var _ = Describe("Reload", func() {
+	var (
+		queue *sqsfake.FakeQueue
+		store *storefake.FakeStore
+
+		testSink *lagertest.TestSink
+		logger   lager.Logger
+
+		sqsBroker *SQSBroker
+
+		config    Config
+		newConfig Config
+
+		plan1 = ServicePlan{ID: "Plan-1", Name: "plan-1", Description: "This is the Plan 1"}
+		plan2 = ServicePlan{ID: "Plan-2", Name: "plan-2", Description: "This is the Plan 2"}
+	)
+
+	BeforeEach(func() {
+		queue = &sqsfake.FakeQueue{}
+		store = &storefake.FakeStore{}
+
+		config = Config{
+			Region:    "sqs-region",
+			SQSPrefix: "cf",
+			Catalog: Catalog{
+				Services: []Service{
+					Service{
+						ID:          "Service-1",
+						Name:        "service-1",
+						Description: "This is the Service 1",
+						Plans:       []ServicePlan{plan1},
+					},
+				},
+			},
+		}
+
+		newConfig = config
+		newConfig.AllowUserProvisionParameters = true
+		newConfig.Catalog = Catalog{
+			Services: []Service{
+				Service{
+					ID:          "Service-1",
+					Name:        "service-1",
+					Description: "This is the new Service 1",
+					Plans:       []ServicePlan{plan1, plan2},
+				},
+			},
+		}
+
+		logger = lager.NewLogger("reload_test")
+		testSink = lagertest.NewTestSink()
+		logger.RegisterSink(testSink)
+	})
+
+	JustBeforeEach(func() {
+		clientsFactory := func(region string, accountRole AccountRole) (awssqs.Queue, awssns.Topic, awsiam.User) {
+			return queue, &snsfake.FakeTopic{}, &iamfake.FakeUser{}
+		}
+		sqsBroker = New(config, clientsFactory, store, nil, auditlog.Discard, logger)
+	})
+
+	It("swaps the Catalog", func() {
+		err := sqsBroker.Reload(newConfig)
+		Expect(err).ToNot(HaveOccurred())
+
+		services := sqsBroker.Services().Services
+		Expect(services).To(HaveLen(1))
+		Expect(services[0].Description).To(Equal("This is the new Service 1"))
+		Expect(services[0].Plans).To(HaveLen(2))
+	})
+
+	It("swaps the user parameters options", func() {
+		err := sqsBroker.Reload(newConfig)
+		Expect(err).ToNot(HaveOccurred())
+
+		provisionDetails := brokerapi.ProvisionDetails{
+			ServiceID:  "Service-1",
+			PlanID:     "Plan-2",
+			Parameters: map[string]interface{}{"delay_seconds": "10"},
+		}
+		_, _, err = sqsBroker.Provision("instance-id", provisionDetails, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(queue.CreateQueueDetails.DelaySeconds).To(Equal("10"))
+	})
+
+	It("logs the differences", func() {
+		err := sqsBroker.Reload(newConfig)
+		Expect(err).ToNot(HaveOccurred())
+
+		logs := testSink.Logs()
+		Expect(logs).To(HaveLen(1))
+		Expect(logs[0].Message).To(Equal("reload_test.broker.reload"))
+		Expect(logs[0].Data["catalog"]).To(Equal(map[string]interface{}{
+			"changed_services": []interface{}{"Service-1"},
+			"added_plans":      []interface{}{"Plan-2"},
+		}))
+		Expect(logs[0].Data["allow-user-provision-parameters"]).To(BeTrue())
+	})
+
+	Context("when the new Config is not valid", func() {
+		BeforeEach(func() {
+			newConfig.Region = ""
+		})
+
+		It("returns the proper error and keeps the Catalog", func() {
+			err := sqsBroker.Reload(newConfig)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("region: Must provide a non-empty Region"))
+			Expect(sqsBroker.Services().Services[0].Plans).To(HaveLen(1))
+		})
+	})
+
+	Context("when a Service Plan is removed", func() {
+		BeforeEach(func() {
+			config.StateFile = "state.json"
+			newConfig.Catalog.Services[0].Plans = []ServicePlan{plan2}
+		})
+
+		It("swaps the Catalog if no Instance uses the Service Plan", func() {
+			store.ListInstancesInstances = []brokerstore.Instance{
+				brokerstore.Instance{InstanceID: "instance-id", PlanID: "Plan-2"},
+			}
+
+			err := sqsBroker.Reload(newConfig)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sqsBroker.Services().Services[0].Plans[0].ID).To(Equal("Plan-2"))
+		})
+
+		It("returns the proper error if an Instance still uses the Service Plan", func() {
+			store.ListInstancesInstances = []brokerstore.Instance{
+				brokerstore.Instance{InstanceID: "instance-id", PlanID: "Plan-1"},
+			}
+
+			err := sqsBroker.Reload(newConfig)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Service Plan 'Plan-1' can not be removed, it is still used by instance 'instance-id'"))
+			Expect(sqsBroker.Services().Services[0].Plans[0].ID).To(Equal("Plan-1"))
+		})
+
+		It("returns the proper error if listing the Instances fails", func() {
+			store.ListInstancesError = errors.New("operation failed")
+
+			err := sqsBroker.Reload(newConfig)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("operation failed"))
+		})
+
+		Context("and no state file is configured", func() {
+			BeforeEach(func() {
+				config.StateFile = ""
+			})
+
+			It("returns the proper error and keeps the Catalog", func() {
+				err := sqsBroker.Reload(newConfig)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Service Plan 'Plan-1' can not be removed without a state_file, restart the broker with the new catalog instead"))
+				Expect(sqsBroker.Services().Services[0].Plans[0].ID).To(Equal("Plan-1"))
+				Expect(store.ListInstancesCalled).To(BeFalse())
+			})
+		})
+	})
+})
@@ -0,0 +1,46 @@
+package sqsbroker
+
+type InstanceResponse struct {
+	ServiceID  string                 `json:"service_id"`
+	PlanID     string                 `json:"plan_id"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Attributes interface{}            `json:"attributes"`
+	Stats      interface{}            `json:"stats"`
+}
+
+type InstanceAttributes struct {
+	QueueURL                      string `json:"queue_url"`
+	QueueArn                      string `json:"queue_arn"`
+	DelaySeconds                  string `json:"delay_seconds"`
+	MaximumMessageSize            string `json:"maximum_message_size"`
+	MessageRetentionPeriod        string `json:"message_retention_period"`
+	Policy                        string `json:"policy,omitempty"`
+	ReceiveMessageWaitTimeSeconds string `json:"receive_message_wait_time_seconds"`
+	VisibilityTimeout             string `json:"visibility_timeout"`
+}
+
+type InstanceStats struct {
+	ApproximateNumberOfMessages           string `json:"approximate_number_of_messages"`
+	ApproximateNumberOfMessagesNotVisible string `json:"approximate_number_of_messages_not_visible"`
+	ApproximateNumberOfMessagesDelayed    string `json:"approximate_number_of_messages_delayed"`
+	CreatedTimestamp                      string `json:"created_timestamp"`
+	LastModifiedTimestamp                 string `json:"last_modified_timestamp"`
+}
+
+type TopicAttributes struct {
+	TopicArn       string `json:"topic_arn"`
+	DisplayName    string `json:"display_name,omitempty"`
+	Policy         string `json:"policy,omitempty"`
+	DeliveryPolicy string `json:"delivery_policy,omitempty"`
+}
+
+type TopicStats struct {
+	SubscriptionsConfirmed string `json:"subscriptions_confirmed"`
+	SubscriptionsPending   string `json:"subscriptions_pending"`
+	SubscriptionsDeleted   string `json:"subscriptions_deleted"`
+}
+
+type BindingResponse struct {
+	Credentials interface{}            `json:"credentials"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
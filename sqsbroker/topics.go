@@ -0,0 +1,451 @@
+package sqsbroker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+
+	"github.com/cf-platform-eng/sqs-broker/auditlog"
+	"github.com/cf-platform-eng/sqs-broker/awssns"
+	"github.com/cf-platform-eng/sqs-broker/awssqs"
+	"github.com/cf-platform-eng/sqs-broker/brokerstore"
+)
+
+const sqsSubscriptionProtocol = "sqs"
+
+type TopicNotOwnedError struct {
+	TopicInstanceID string
+}
+
+func (e *TopicNotOwnedError) Error() string {
+	return fmt.Sprintf("Topic instance '%s' does not belong to the organization and space, or namespace, of the queue", e.TopicInstanceID)
+}
+
+func (e *TopicNotOwnedError) Kind() string {
+	return InvalidRequestErrorKind
+}
+
+func (b *SQSBroker) isTopicService(serviceID string) bool {
+	service, ok := b.currentConfig().catalog.FindService(serviceID)
+	return ok && service.IsTopic()
+}
+
+func (b *SQSBroker) createTopic(clients awsClients, instanceID string, servicePlan ServicePlan) error {
+	topicArn, err := clients.topic.Create(b.topicName(instanceID), *b.topicDetailsFromPlan(servicePlan))
+	b.audit("create-topic", instanceID, "", []auditlog.Resource{topicResource(b.topicName(instanceID), topicArn)}, err)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (b *SQSBroker) modifyTopic(instanceID string, servicePlan ServicePlan) error {
+	clients, err := b.instanceClients(instanceID)
+	if err != nil {
+		return err
+	}
+
+	if err := clients.topic.Modify(b.topicName(instanceID), *b.topicDetailsFromPlan(servicePlan)); err != nil {
+		if err == awssns.ErrTopicDoesNotExist {
+			return brokerapi.ErrInstanceDoesNotExist
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (b *SQSBroker) deleteTopic(instanceID string) error {
+	clients, err := b.instanceClients(instanceID)
+	if err != nil {
+		return err
+	}
+
+	// Deleting a Topic also deletes all its Subscriptions, but not the Queue policy statements nor the stored Subscriptions
+	if err := b.deleteTopicSubscribers(instanceID); err != nil {
+		return err
+	}
+
+	err = clients.topic.Delete(b.topicName(instanceID))
+	b.audit("delete-topic", instanceID, "", []auditlog.Resource{topicResource(b.topicName(instanceID), "")}, err)
+	if err != nil {
+		if err == awssns.ErrTopicDoesNotExist {
+			return brokerapi.ErrInstanceDoesNotExist
+		}
+		return err
+	}
+
+	if err := b.store.DeleteInstance(instanceID); err != nil && err != brokerstore.ErrInstanceDoesNotExist {
+		return err
+	}
+
+	return nil
+}
+
+func (b *SQSBroker) getTopicInstance(instance brokerstore.Instance) (InstanceResponse, error) {
+	instanceResponse := InstanceResponse{}
+
+	topicDetails, err := b.storedInstanceClients(instance).topic.Describe(b.topicName(instance.InstanceID))
+	if err != nil {
+		if err == awssns.ErrTopicDoesNotExist {
+			return instanceResponse, brokerapi.ErrInstanceDoesNotExist
+		}
+		return instanceResponse, err
+	}
+
+	instanceResponse.ServiceID = instance.ServiceID
+	instanceResponse.PlanID = instance.PlanID
+	instanceResponse.Parameters = instance.Parameters
+	instanceResponse.Attributes = TopicAttributes{
+		TopicArn:       topicDetails.TopicArn,
+		DisplayName:    topicDetails.DisplayName,
+		Policy:         topicDetails.Policy,
+		DeliveryPolicy: topicDetails.DeliveryPolicy,
+	}
+	instanceResponse.Stats = TopicStats{
+		SubscriptionsConfirmed: topicDetails.SubscriptionsConfirmed,
+		SubscriptionsPending:   topicDetails.SubscriptionsPending,
+		SubscriptionsDeleted:   topicDetails.SubscriptionsDeleted,
+	}
+
+	return instanceResponse, nil
+}
+
+func (b *SQSBroker) subscribeQueue(instanceID string, sid string, topicInstanceID string, subscriptionDetails awssns.SubscriptionDetails) (brokerstore.Subscription, error) {
+	subscription := brokerstore.Subscription{}
+
+	if err := b.checkTopicOwner(instanceID, topicInstanceID); err != nil {
+		return subscription, err
+	}
+
+	// Topics and Queues may live in different regions, SNS supports cross-region subscriptions
+	queueClients, err := b.instanceClients(instanceID)
+	if err != nil {
+		return subscription, err
+	}
+
+	topicClients, err := b.instanceClients(topicInstanceID)
+	if err != nil {
+		return subscription, err
+	}
+
+	topicDetails, err := topicClients.topic.Describe(b.topicName(topicInstanceID))
+	if err != nil {
+		if err == awssns.ErrTopicDoesNotExist {
+			return subscription, fmt.Errorf("Topic instance '%s' not found", topicInstanceID)
+		}
+		return subscription, err
+	}
+
+	unlock := b.lockQueuePolicy(instanceID)
+	defer unlock()
+
+	queueName := b.queueName(instanceID)
+	queueDetails, err := queueClients.queue.Describe(queueName)
+	if err != nil {
+		if err == awssqs.ErrQueueDoesNotExist {
+			return subscription, brokerapi.ErrInstanceDoesNotExist
+		}
+		return subscription, err
+	}
+
+	// The Queue must allow the Topic to send messages before subscribing, otherwise the first messages are lost
+	policy, err := addTopicPolicyStatement(queueDetails.Policy, sid, queueDetails.QueueArn, topicDetails.TopicArn)
+	if err != nil {
+		return subscription, err
+	}
+
+	if err = queueClients.queue.SetPolicy(queueName, policy); err != nil {
+		return subscription, err
+	}
+
+	subscriptionArn, err := topicClients.topic.Subscribe(b.topicName(topicInstanceID), sqsSubscriptionProtocol, queueDetails.QueueArn, subscriptionDetails)
+	b.audit("subscribe-queue", instanceID, "", []auditlog.Resource{
+		queueResource(queueName, queueClients.region, queueDetails.QueueArn),
+		topicResource(b.topicName(topicInstanceID), topicDetails.TopicArn),
+		{Type: auditlog.ResourceSNSSubscription, ARN: subscriptionArn},
+	}, err)
+	if err != nil {
+		if subscriptionArn != "" {
+			topicClients.topic.Unsubscribe(subscriptionArn)
+		}
+		queueClients.queue.SetPolicy(queueName, queueDetails.Policy)
+		return subscription, err
+	}
+
+	b.logger.Info("subscribe-queue", lager.Data{
+		"queue":            queueName,
+		"topic-arn":        topicDetails.TopicArn,
+		"subscription-arn": subscriptionArn,
+	})
+
+	subscription.TopicInstanceID = topicInstanceID
+	subscription.TopicArn = topicDetails.TopicArn
+	subscription.SubscriptionArn = subscriptionArn
+
+	return subscription, nil
+}
+
+func (b *SQSBroker) unsubscribeQueue(instanceID string, sid string, subscription brokerstore.Subscription) error {
+	topicClients, err := b.subscriptionClients(subscription)
+	if err != nil {
+		return err
+	}
+
+	err = topicClients.topic.Unsubscribe(subscription.SubscriptionArn)
+	if err == awssns.ErrSubscriptionDoesNotExist {
+		err = nil
+	}
+	b.audit("unsubscribe-queue", instanceID, "", []auditlog.Resource{
+		{Type: auditlog.ResourceSNSSubscription, ARN: subscription.SubscriptionArn},
+		topicResource("", subscription.TopicArn),
+	}, err)
+	if err != nil {
+		return err
+	}
+
+	clients, err := b.instanceClients(instanceID)
+	if err != nil {
+		return err
+	}
+
+	unlock := b.lockQueuePolicy(instanceID)
+	defer unlock()
+
+	queueDetails, err := clients.queue.Describe(b.queueName(instanceID))
+	if err != nil {
+		if err == awssqs.ErrQueueDoesNotExist {
+			return nil
+		}
+		return err
+	}
+
+	policy, err := removePolicyStatement(queueDetails.Policy, sid)
+	if err != nil {
+		return err
+	}
+
+	if policy != queueDetails.Policy {
+		if err = clients.queue.SetPolicy(b.queueName(instanceID), policy); err != nil && err != awssqs.ErrQueueDoesNotExist {
+			return err
+		}
+	}
+
+	b.logger.Info("unsubscribe-queue", lager.Data{
+		instanceIDLogKey:   instanceID,
+		"subscription-arn": subscription.SubscriptionArn,
+	})
+
+	return nil
+}
+
+// checkTopicOwner only lets a Queue subscribe to the Topics of its own organization and space, or namespace,
+// as the Queue policy grants the Topic the right to send messages
+func (b *SQSBroker) checkTopicOwner(instanceID string, topicInstanceID string) error {
+	topicInstance, err := b.store.GetInstance(topicInstanceID)
+	if err != nil {
+		if err == brokerstore.ErrInstanceDoesNotExist {
+			return fmt.Errorf("Topic instance '%s' not found", topicInstanceID)
+		}
+		return err
+	}
+
+	if !b.isTopicService(topicInstance.ServiceID) {
+		return fmt.Errorf("Topic instance '%s' not found", topicInstanceID)
+	}
+
+	instance, err := b.store.GetInstance(instanceID)
+	if err != nil && err != brokerstore.ErrInstanceDoesNotExist {
+		return err
+	}
+
+	owner, err := b.requestContext(instance.Context, instance.OrganizationGUID, instance.SpaceGUID)
+	if err != nil {
+		return err
+	}
+
+	topicOwner, err := b.requestContext(topicInstance.Context, topicInstance.OrganizationGUID, topicInstance.SpaceGUID)
+	if err != nil {
+		return err
+	}
+
+	// The user path holds the platform and its organization and space, or cluster and namespace
+	if b.userPath(owner) == "" || b.userPath(owner) != b.userPath(topicOwner) {
+		return &TopicNotOwnedError{TopicInstanceID: topicInstanceID}
+	}
+
+	return nil
+}
+
+// deleteTopicSubscribers removes the Queue policy statements allowing the Topic to send messages
+// and forgets the Subscriptions of the Queue instances and bindings
+func (b *SQSBroker) deleteTopicSubscribers(topicInstanceID string) error {
+	instances, err := b.store.ListInstances()
+	if err != nil {
+		return err
+	}
+
+	for _, instance := range instances {
+		subscription, ok := instance.Subscriptions[topicInstanceID]
+		if !ok {
+			continue
+		}
+
+		if err := b.unsubscribeQueue(instance.InstanceID, b.instanceSubscriptionSid(instance.InstanceID, topicInstanceID), subscription); err != nil {
+			return err
+		}
+
+		delete(instance.Subscriptions, topicInstanceID)
+		if err := b.store.SaveInstance(instance); err != nil {
+			return err
+		}
+	}
+
+	bindings, err := b.store.ListBindings()
+	if err != nil {
+		return err
+	}
+
+	for _, binding := range bindings {
+		if binding.TopicInstanceID != topicInstanceID || binding.SubscriptionArn == "" {
+			continue
+		}
+
+		subscription := brokerstore.Subscription{
+			TopicInstanceID: binding.TopicInstanceID,
+			TopicArn:        binding.TopicArn,
+			SubscriptionArn: binding.SubscriptionArn,
+		}
+		if err := b.unsubscribeQueue(binding.InstanceID, b.bindingSubscriptionSid(binding.BindingID), subscription); err != nil {
+			return err
+		}
+
+		binding.TopicInstanceID = ""
+		binding.TopicArn = ""
+		binding.SubscriptionArn = ""
+		if err := b.store.SaveBinding(binding); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lockQueuePolicy serializes the read-modify-write of a Queue policy,
+// concurrent updates would otherwise drop each other's statements
+func (b *SQSBroker) lockQueuePolicy(instanceID string) func() {
+	b.queuePolicyLocksMutex.Lock()
+	lock, ok := b.queuePolicyLocks[instanceID]
+	if !ok {
+		lock = &sync.Mutex{}
+		b.queuePolicyLocks[instanceID] = lock
+	}
+	b.queuePolicyLocksMutex.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+func (b *SQSBroker) updateInstanceSubscriptions(instance *brokerstore.Instance, updateParameters UpdateParameters) error {
+	if updateParameters.UnsubscribeFromTopic != "" {
+		if subscription, ok := instance.Subscriptions[updateParameters.UnsubscribeFromTopic]; ok {
+			if err := b.unsubscribeQueue(instance.InstanceID, b.instanceSubscriptionSid(instance.InstanceID, updateParameters.UnsubscribeFromTopic), subscription); err != nil {
+				return err
+			}
+			delete(instance.Subscriptions, updateParameters.UnsubscribeFromTopic)
+		}
+	}
+
+	if updateParameters.SubscribeToTopic == "" {
+		return nil
+	}
+
+	subscriptionDetails, err := b.subscriptionDetails(updateParameters.RawMessageDelivery, updateParameters.FilterPolicy)
+	if err != nil {
+		return err
+	}
+
+	if subscription, ok := instance.Subscriptions[updateParameters.SubscribeToTopic]; ok {
+		topicClients, err := b.subscriptionClients(subscription)
+		if err != nil {
+			return err
+		}
+		return topicClients.topic.ModifySubscription(subscription.SubscriptionArn, subscriptionDetails)
+	}
+
+	subscription, err := b.subscribeQueue(instance.InstanceID, b.instanceSubscriptionSid(instance.InstanceID, updateParameters.SubscribeToTopic), updateParameters.SubscribeToTopic, subscriptionDetails)
+	if err != nil {
+		return err
+	}
+
+	if instance.Subscriptions == nil {
+		instance.Subscriptions = map[string]brokerstore.Subscription{}
+	}
+	instance.Subscriptions[updateParameters.SubscribeToTopic] = subscription
+
+	return nil
+}
+
+func (b *SQSBroker) deleteInstanceSubscriptions(instance brokerstore.Instance) {
+	for _, subscription := range instance.Subscriptions {
+		topicClients, err := b.subscriptionClients(subscription)
+		if err == nil {
+			err = topicClients.topic.Unsubscribe(subscription.SubscriptionArn)
+		}
+		if err != nil && err != awssns.ErrSubscriptionDoesNotExist {
+			b.logger.Error("unsubscribe-failed", err, lager.Data{
+				instanceIDLogKey:   instance.InstanceID,
+				"subscription-arn": subscription.SubscriptionArn,
+			})
+		}
+	}
+}
+
+func (b *SQSBroker) subscriptionDetails(rawMessageDelivery *bool, filterPolicy interface{}) (awssns.SubscriptionDetails, error) {
+	subscriptionDetails := awssns.SubscriptionDetails{}
+
+	if rawMessageDelivery != nil {
+		subscriptionDetails.RawMessageDelivery = strconv.FormatBool(*rawMessageDelivery)
+	}
+
+	switch policy := filterPolicy.(type) {
+	case nil:
+	case string:
+		subscriptionDetails.FilterPolicy = policy
+	default:
+		encodedPolicy, err := json.Marshal(policy)
+		if err != nil {
+			return subscriptionDetails, fmt.Errorf("Invalid filter_policy: %s", err)
+		}
+		subscriptionDetails.FilterPolicy = string(encodedPolicy)
+	}
+
+	return subscriptionDetails, nil
+}
+
+func (b *SQSBroker) topicName(instanceID string) string {
+	return fmt.Sprintf("%s-%s", b.sqsPrefix, instanceID)
+}
+
+func (b *SQSBroker) bindingSubscriptionSid(bindingID string) string {
+	return fmt.Sprintf("%s-%s", b.sqsPrefix, bindingID)
+}
+
+func (b *SQSBroker) instanceSubscriptionSid(instanceID string, topicInstanceID string) string {
+	return fmt.Sprintf("%s-%s-%s", b.sqsPrefix, instanceID, topicInstanceID)
+}
+
+func (b *SQSBroker) topicDetailsFromPlan(servicePlan ServicePlan) *awssns.TopicDetails {
+	topicDetails := &awssns.TopicDetails{
+		DisplayName:    servicePlan.SNSProperties.DisplayName,
+		Policy:         servicePlan.SNSProperties.Policy,
+		DeliveryPolicy: servicePlan.SNSProperties.DeliveryPolicy,
+	}
+
+	return topicDetails
+}
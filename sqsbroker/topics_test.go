@@ -0,0 +1,722 @@
+package sqsbroker_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/frodenas/brokerapi"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/lagertest"
+
+	. "github.com/cf-platform-eng/sqs-broker/sqsbroker"
+
+	auditfake "github.com/cf-platform-eng/sqs-broker/auditlog/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awsiam"
+	iamfake "github.com/cf-platform-eng/sqs-broker/awsiam/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awssns"
+	snsfake "github.com/cf-platform-eng/sqs-broker/awssns/fakes"
+	"github.com/cf-platform-eng/sqs-broker/awssqs"
+	sqsfake "github.com/cf-platform-eng/sqs-broker/awssqs/fakes"
+	"github.com/cf-platform-eng/sqs-broker/brokerstore"
+)
+
+var _ = Describe("SNS Topics", func() {
+	var (
+		queue *sqsfake.FakeQueue
+		topic *snsfake.FakeTopic
+		user  *iamfake.FakeUser
+
+		store *brokerstore.JSONStore
+
+		auditLogger *auditfake.FakeLogger
+
+		sqsBroker *SQSBroker
+
+		queuePlanPolicy string
+		accountRoles    []AccountRole
+
+		instanceID      = "instance-id"
+		topicInstanceID = "topic-instance-id"
+		bindingID       = "binding-id"
+		topicName       = "cf-instance-id"
+		topicArn        = "arn:aws:sns:sqs-region:123456789012:cf-topic-instance-id"
+		queueArn        = "arn:aws:sqs:sqs-region:123456789012:cf-instance-id"
+	)
+
+	BeforeEach(func() {
+		queue = &sqsfake.FakeQueue{}
+		topic = &snsfake.FakeTopic{}
+		user = &iamfake.FakeUser{}
+		auditLogger = &auditfake.FakeLogger{}
+		store = brokerstore.NewMemoryStore()
+		queuePlanPolicy = ""
+		accountRoles = nil
+
+		queue.DescribeQueueDetails = awssqs.QueueDetails{
+			QueueURL: "queue-url",
+			QueueArn: queueArn,
+		}
+		topic.DescribeTopicDetails = awssns.TopicDetails{
+			TopicArn:               topicArn,
+			DisplayName:            "Topic 1",
+			SubscriptionsConfirmed: "1",
+		}
+		topic.SubscribeSubscriptionArn = "subscription-arn"
+
+		user.CreateAccessKeyAccessKeyID = "user-access-key-id"
+		user.CreateAccessKeySecretAccessKey = "user-secret-access-key"
+		user.CreatePolicyPolicyARN = "policy-arn"
+	})
+
+	JustBeforeEach(func() {
+		config := Config{
+			Region:                       "sqs-region",
+			SQSPrefix:                    "cf",
+			AllowUserProvisionParameters: true,
+			AllowUserUpdateParameters:    true,
+			Catalog: Catalog{
+				Services: []Service{
+					Service{
+						ID:             "Queue-Service",
+						Name:           "Queue Service",
+						Description:    "This is the Queue Service",
+						Bindable:       true,
+						PlanUpdateable: true,
+						Plans: []ServicePlan{
+							ServicePlan{
+								ID:          "Queue-Plan",
+								Name:        "Queue Plan",
+								Description: "This is the Queue Plan",
+								SQSProperties: SQSProperties{
+									Policy: queuePlanPolicy,
+								},
+							},
+						},
+					},
+					Service{
+						ID:             "Topic-Service",
+						Name:           "Topic Service",
+						Description:    "This is the Topic Service",
+						Backend:        SNSServiceBackend,
+						Bindable:       true,
+						PlanUpdateable: true,
+						Plans: []ServicePlan{
+							ServicePlan{
+								ID:          "Topic-Plan",
+								Name:        "Topic Plan",
+								Description: "This is the Topic Plan",
+								SNSProperties: SNSProperties{
+									DisplayName: "Topic 1",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		logger := lager.NewLogger("topics_test")
+		logger.RegisterSink(lagertest.NewTestSink())
+
+		clientsFactory := func(region string, accountRole AccountRole) (awssqs.Queue, awssns.Topic, awsiam.User) {
+			accountRoles = append(accountRoles, accountRole)
+			return queue, topic, user
+		}
+		sqsBroker = New(config, clientsFactory, store, nil, auditLogger, logger)
+	})
+
+	Describe("Provision", func() {
+		var provisionDetails brokerapi.ProvisionDetails
+
+		BeforeEach(func() {
+			provisionDetails = brokerapi.ProvisionDetails{
+				OrganizationGUID: "organization-id",
+				PlanID:           "Topic-Plan",
+				ServiceID:        "Topic-Service",
+				SpaceGUID:        "space-id",
+			}
+		})
+
+		It("creates a Topic instead of a Queue", func() {
+			_, _, err := sqsBroker.Provision(instanceID, provisionDetails, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(topic.CreateCalled).To(BeTrue())
+			Expect(topic.CreateTopicName).To(Equal(topicName))
+			Expect(topic.CreateTopicDetails.DisplayName).To(Equal("Topic 1"))
+			Expect(queue.CreateCalled).To(BeFalse())
+
+			instance, err := store.GetInstance(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instance.ServiceID).To(Equal("Topic-Service"))
+		})
+
+		Context("when creating the Topic fails", func() {
+			BeforeEach(func() {
+				topic.CreateError = errors.New("operation failed")
+			})
+
+			It("returns the proper error", func() {
+				_, _, err := sqsBroker.Provision(instanceID, provisionDetails, false)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("operation failed"))
+			})
+		})
+	})
+
+	Describe("Update", func() {
+		var updateDetails brokerapi.UpdateDetails
+
+		BeforeEach(func() {
+			updateDetails = brokerapi.UpdateDetails{
+				ServiceID:  "Queue-Service",
+				PlanID:     "Queue-Plan",
+				Parameters: map[string]interface{}{},
+			}
+
+			err := store.SaveInstance(brokerstore.Instance{
+				InstanceID: instanceID,
+				ServiceID:  "Queue-Service",
+				PlanID:     "Queue-Plan",
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("modifies a Topic instance", func() {
+			updateDetails.ServiceID = "Topic-Service"
+			updateDetails.PlanID = "Topic-Plan"
+
+			_, err := sqsBroker.Update(instanceID, updateDetails, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(topic.ModifyCalled).To(BeTrue())
+			Expect(topic.ModifyTopicName).To(Equal(topicName))
+			Expect(queue.ModifyCalled).To(BeFalse())
+		})
+
+		Context("when the Queue Plan has a Policy", func() {
+			BeforeEach(func() {
+				queuePlanPolicy = `{"Version":"2012-10-17","Statement":[{"Sid":"plan"}]}`
+				queue.DescribeQueueDetails.Policy = `{"Version":"2012-10-17","Statement":[{"Sid":"old"},{"Sid":"cf-instance-id-topic-instance-id"}]}`
+			})
+
+			It("keeps the statements allowing Topics to send messages", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(queue.ModifyQueueDetails.Policy).To(MatchJSON(`{"Version":"2012-10-17","Statement":[{"Sid":"plan"},{"Sid":"cf-instance-id-topic-instance-id"}]}`))
+			})
+		})
+
+		Context("when has SubscribeToTopic", func() {
+			BeforeEach(func() {
+				updateDetails.Parameters = map[string]interface{}{
+					"subscribe_to_topic":   topicInstanceID,
+					"raw_message_delivery": true,
+					"filter_policy":        map[string]interface{}{"event": []interface{}{"created"}},
+				}
+
+				err := store.SaveInstance(brokerstore.Instance{
+					InstanceID:       instanceID,
+					ServiceID:        "Queue-Service",
+					PlanID:           "Queue-Plan",
+					OrganizationGUID: "organization-id",
+					SpaceGUID:        "space-id",
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				err = store.SaveInstance(brokerstore.Instance{
+					InstanceID:       topicInstanceID,
+					ServiceID:        "Topic-Service",
+					PlanID:           "Topic-Plan",
+					OrganizationGUID: "organization-id",
+					SpaceGUID:        "space-id",
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("subscribes the Queue to the Topic", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(queue.SetPolicyCalled).To(BeTrue())
+				Expect(queue.SetPolicyPolicy).To(MatchJSON(`{
+					"Version": "2012-10-17",
+					"Statement": [{
+						"Sid": "cf-instance-id-topic-instance-id",
+						"Effect": "Allow",
+						"Principal": {"Service": "sns.amazonaws.com"},
+						"Action": "sqs:SendMessage",
+						"Resource": "` + queueArn + `",
+						"Condition": {"ArnEquals": {"aws:SourceArn": "` + topicArn + `"}}
+					}]
+				}`))
+				Expect(topic.SubscribeCalled).To(BeTrue())
+				Expect(topic.SubscribeTopicName).To(Equal("cf-topic-instance-id"))
+				Expect(topic.SubscribeProtocol).To(Equal("sqs"))
+				Expect(topic.SubscribeEndpoint).To(Equal(queueArn))
+				Expect(topic.SubscribeSubscriptionDetails).To(Equal(awssns.SubscriptionDetails{
+					RawMessageDelivery: "true",
+					FilterPolicy:       `{"event":["created"]}`,
+				}))
+
+				instance, err := store.GetInstance(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(instance.Subscriptions).To(Equal(map[string]brokerstore.Subscription{
+					topicInstanceID: brokerstore.Subscription{
+						TopicInstanceID: topicInstanceID,
+						TopicArn:        topicArn,
+						SubscriptionArn: "subscription-arn",
+					},
+				}))
+			})
+
+			Context("when the Queue is already subscribed to the Topic", func() {
+				BeforeEach(func() {
+					err := store.SaveInstance(brokerstore.Instance{
+						InstanceID: instanceID,
+						ServiceID:  "Queue-Service",
+						PlanID:     "Queue-Plan",
+						Subscriptions: map[string]brokerstore.Subscription{
+							topicInstanceID: brokerstore.Subscription{
+								TopicArn:        topicArn,
+								SubscriptionArn: "subscription-arn",
+							},
+						},
+					})
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				It("modifies the Subscription", func() {
+					_, err := sqsBroker.Update(instanceID, updateDetails, false)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(topic.SubscribeCalled).To(BeFalse())
+					Expect(topic.ModifySubscriptionCalled).To(BeTrue())
+					Expect(topic.ModifySubscriptionSubscriptionArn).To(Equal("subscription-arn"))
+					Expect(topic.ModifySubscriptionSubscriptionDetails.RawMessageDelivery).To(Equal("true"))
+				})
+			})
+
+			Context("when the Topic instance does not exist", func() {
+				BeforeEach(func() {
+					topic.DescribeError = awssns.ErrTopicDoesNotExist
+				})
+
+				It("returns the proper error", func() {
+					_, err := sqsBroker.Update(instanceID, updateDetails, false)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("Topic instance 'topic-instance-id' not found"))
+					Expect(queue.SetPolicyCalled).To(BeFalse())
+				})
+			})
+
+			Context("when the Topic instance is unknown", func() {
+				BeforeEach(func() {
+					err := store.DeleteInstance(topicInstanceID)
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				It("returns the proper error", func() {
+					_, err := sqsBroker.Update(instanceID, updateDetails, false)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("Topic instance 'topic-instance-id' not found"))
+					Expect(queue.SetPolicyCalled).To(BeFalse())
+					Expect(topic.SubscribeCalled).To(BeFalse())
+				})
+			})
+
+			Context("when the Topic instance belongs to another space", func() {
+				BeforeEach(func() {
+					err := store.SaveInstance(brokerstore.Instance{
+						InstanceID:       topicInstanceID,
+						ServiceID:        "Topic-Service",
+						PlanID:           "Topic-Plan",
+						OrganizationGUID: "organization-id",
+						SpaceGUID:        "other-space-id",
+					})
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				It("returns the proper error", func() {
+					_, err := sqsBroker.Update(instanceID, updateDetails, false)
+					Expect(err).To(HaveOccurred())
+					Expect(err).To(Equal(&TopicNotOwnedError{TopicInstanceID: topicInstanceID}))
+					Expect(queue.SetPolicyCalled).To(BeFalse())
+					Expect(topic.SubscribeCalled).To(BeFalse())
+				})
+			})
+
+			Context("when the instance is not a Topic", func() {
+				BeforeEach(func() {
+					err := store.SaveInstance(brokerstore.Instance{
+						InstanceID:       topicInstanceID,
+						ServiceID:        "Queue-Service",
+						PlanID:           "Queue-Plan",
+						OrganizationGUID: "organization-id",
+						SpaceGUID:        "space-id",
+					})
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				It("returns the proper error", func() {
+					_, err := sqsBroker.Update(instanceID, updateDetails, false)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("Topic instance 'topic-instance-id' not found"))
+					Expect(topic.SubscribeCalled).To(BeFalse())
+				})
+			})
+
+			Context("when subscribing fails", func() {
+				BeforeEach(func() {
+					queue.DescribeQueueDetails.Policy = `{"Version":"2012-10-17","Statement":[{"Sid":"custom"}]}`
+					topic.SubscribeSubscriptionArn = ""
+					topic.SubscribeError = errors.New("operation failed")
+				})
+
+				It("restores the Queue Policy", func() {
+					_, err := sqsBroker.Update(instanceID, updateDetails, false)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("operation failed"))
+					Expect(queue.SetPolicyPolicy).To(Equal(`{"Version":"2012-10-17","Statement":[{"Sid":"custom"}]}`))
+				})
+			})
+		})
+
+		Context("when has UnsubscribeFromTopic", func() {
+			BeforeEach(func() {
+				updateDetails.Parameters = map[string]interface{}{
+					"unsubscribe_from_topic": topicInstanceID,
+				}
+				queue.DescribeQueueDetails.Policy = `{"Version":"2012-10-17","Statement":[{"Sid":"cf-instance-id-topic-instance-id"}]}`
+
+				err := store.SaveInstance(brokerstore.Instance{
+					InstanceID: instanceID,
+					ServiceID:  "Queue-Service",
+					PlanID:     "Queue-Plan",
+					Subscriptions: map[string]brokerstore.Subscription{
+						topicInstanceID: brokerstore.Subscription{
+							TopicArn:        topicArn,
+							SubscriptionArn: "subscription-arn",
+						},
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("unsubscribes the Queue from the Topic", func() {
+				_, err := sqsBroker.Update(instanceID, updateDetails, false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(topic.UnsubscribeCalled).To(BeTrue())
+				Expect(topic.UnsubscribeSubscriptionArn).To(Equal("subscription-arn"))
+				Expect(queue.SetPolicyCalled).To(BeTrue())
+				Expect(queue.SetPolicyPolicy).To(Equal(""))
+
+				instance, err := store.GetInstance(instanceID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(instance.Subscriptions).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Deprovision", func() {
+		var deprovisionDetails brokerapi.DeprovisionDetails
+
+		BeforeEach(func() {
+			deprovisionDetails = brokerapi.DeprovisionDetails{
+				ServiceID: "Topic-Service",
+				PlanID:    "Topic-Plan",
+			}
+		})
+
+		It("deletes the Topic", func() {
+			_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(topic.DeleteCalled).To(BeTrue())
+			Expect(topic.DeleteTopicName).To(Equal(topicName))
+			Expect(queue.DeleteCalled).To(BeFalse())
+		})
+
+		Context("when Queues are subscribed to the Topic", func() {
+			BeforeEach(func() {
+				queue.DescribeQueueDetails.Policy = `{"Version":"2012-10-17","Statement":[{"Sid":"custom"},{"Sid":"cf-queue-instance-id-instance-id"},{"Sid":"cf-binding-id"}]}`
+
+				err := store.SaveInstance(brokerstore.Instance{
+					InstanceID: "queue-instance-id",
+					ServiceID:  "Queue-Service",
+					PlanID:     "Queue-Plan",
+					Subscriptions: map[string]brokerstore.Subscription{
+						instanceID: brokerstore.Subscription{
+							TopicInstanceID: instanceID,
+							TopicArn:        topicArn,
+							SubscriptionArn: "subscription-arn",
+						},
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				err = store.SaveBinding(brokerstore.Binding{
+					BindingID:       bindingID,
+					InstanceID:      "queue-instance-id",
+					ServiceID:       "Queue-Service",
+					PlanID:          "Queue-Plan",
+					TopicInstanceID: instanceID,
+					TopicArn:        topicArn,
+					SubscriptionArn: "binding-subscription-arn",
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("removes the Queue Policy statements and the stored Subscriptions", func() {
+				_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(topic.DeleteCalled).To(BeTrue())
+				Expect(queue.SetPolicyCalled).To(BeTrue())
+				Expect(queue.SetPolicyQueueName).To(Equal("cf-queue-instance-id"))
+
+				instance, err := store.GetInstance("queue-instance-id")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(instance.Subscriptions).To(BeEmpty())
+
+				binding, err := store.GetBinding(bindingID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(binding.TopicInstanceID).To(BeEmpty())
+				Expect(binding.SubscriptionArn).To(BeEmpty())
+			})
+
+			Context("when removing a statement fails", func() {
+				BeforeEach(func() {
+					queue.SetPolicyError = errors.New("operation failed")
+				})
+
+				It("keeps the Topic", func() {
+					_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, false)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("operation failed"))
+					Expect(topic.DeleteCalled).To(BeFalse())
+				})
+			})
+		})
+
+		Context("when the Topic does not exist", func() {
+			BeforeEach(func() {
+				topic.DeleteError = awssns.ErrTopicDoesNotExist
+			})
+
+			It("returns the proper error", func() {
+				_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, false)
+				Expect(err).To(HaveOccurred())
+				Expect(err).To(Equal(brokerapi.ErrInstanceDoesNotExist))
+			})
+		})
+
+		Context("when the Queue is subscribed to Topics", func() {
+			BeforeEach(func() {
+				deprovisionDetails = brokerapi.DeprovisionDetails{
+					ServiceID: "Queue-Service",
+					PlanID:    "Queue-Plan",
+				}
+
+				err := store.SaveInstance(brokerstore.Instance{
+					InstanceID: instanceID,
+					ServiceID:  "Queue-Service",
+					PlanID:     "Queue-Plan",
+					Subscriptions: map[string]brokerstore.Subscription{
+						topicInstanceID: brokerstore.Subscription{
+							TopicArn:        topicArn,
+							SubscriptionArn: "subscription-arn",
+						},
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("deletes the Subscriptions", func() {
+				_, err := sqsBroker.Deprovision(instanceID, deprovisionDetails, false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(queue.DeleteCalled).To(BeTrue())
+				Expect(topic.UnsubscribeCalled).To(BeTrue())
+				Expect(topic.UnsubscribeSubscriptionArn).To(Equal("subscription-arn"))
+			})
+		})
+	})
+
+	Describe("GetInstance", func() {
+		BeforeEach(func() {
+			err := store.SaveInstance(brokerstore.Instance{
+				InstanceID: instanceID,
+				ServiceID:  "Topic-Service",
+				PlanID:     "Topic-Plan",
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns the Topic attributes", func() {
+			instanceResponse, err := sqsBroker.GetInstance(instanceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(topic.DescribeTopicName).To(Equal(topicName))
+			Expect(instanceResponse.Attributes).To(Equal(TopicAttributes{
+				TopicArn:    topicArn,
+				DisplayName: "Topic 1",
+			}))
+			Expect(instanceResponse.Stats).To(Equal(TopicStats{
+				SubscriptionsConfirmed: "1",
+			}))
+		})
+	})
+
+	Describe("Bind", func() {
+		var bindDetails brokerapi.BindDetails
+
+		BeforeEach(func() {
+			bindDetails = brokerapi.BindDetails{
+				ServiceID:  "Topic-Service",
+				PlanID:     "Topic-Plan",
+				AppGUID:    "Application-1",
+				Parameters: map[string]interface{}{},
+			}
+		})
+
+		It("grants access to the Topic", func() {
+			bindingResponse, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+			Expect(err).ToNot(HaveOccurred())
+			credentials := bindingResponse.Credentials.(*brokerapi.CredentialsHash)
+			Expect(credentials.URI).To(Equal(topicArn))
+			Expect(user.CreatePolicyAction).To(Equal("sns:*"))
+			Expect(user.CreatePolicyResource).To(Equal(topicArn))
+			Expect(queue.DescribeCalled).To(BeFalse())
+		})
+
+		It("refuses to subscribe a Topic to a Topic", func() {
+			bindDetails.Parameters = map[string]interface{}{"subscribe_to_topic": topicInstanceID}
+
+			_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Only queues can be subscribed to a topic"))
+			Expect(user.CreateCalled).To(BeFalse())
+		})
+
+		Context("when has SubscribeToTopic", func() {
+			BeforeEach(func() {
+				bindDetails = brokerapi.BindDetails{
+					ServiceID: "Queue-Service",
+					PlanID:    "Queue-Plan",
+					AppGUID:   "Application-1",
+					Parameters: map[string]interface{}{
+						"subscribe_to_topic": topicInstanceID,
+						"filter_policy":      `{"event":["created"]}`,
+					},
+				}
+
+				err := store.SaveInstance(brokerstore.Instance{
+					InstanceID:       instanceID,
+					ServiceID:        "Queue-Service",
+					PlanID:           "Queue-Plan",
+					OrganizationGUID: "organization-id",
+					SpaceGUID:        "space-id",
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				err = store.SaveInstance(brokerstore.Instance{
+					InstanceID:       topicInstanceID,
+					ServiceID:        "Topic-Service",
+					PlanID:           "Topic-Plan",
+					OrganizationGUID: "organization-id",
+					SpaceGUID:        "space-id",
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("subscribes the Queue to the Topic", func() {
+				bindingResponse, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+				Expect(err).ToNot(HaveOccurred())
+				credentials := bindingResponse.Credentials.(*brokerapi.CredentialsHash)
+				Expect(credentials.URI).To(Equal("queue-url"))
+				Expect(user.CreatePolicyAction).To(Equal("sqs:*"))
+				Expect(queue.SetPolicyPolicy).To(ContainSubstring(`"Sid":"cf-binding-id"`))
+				Expect(topic.SubscribeEndpoint).To(Equal(queueArn))
+				Expect(topic.SubscribeSubscriptionDetails).To(Equal(awssns.SubscriptionDetails{
+					FilterPolicy: `{"event":["created"]}`,
+				}))
+
+				binding, err := store.GetBinding(bindingID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(binding.TopicArn).To(Equal(topicArn))
+				Expect(binding.SubscriptionArn).To(Equal("subscription-arn"))
+			})
+
+			Context("when subscribing fails", func() {
+				BeforeEach(func() {
+					topic.SubscribeError = errors.New("operation failed")
+				})
+
+				It("deletes the User", func() {
+					_, err := sqsBroker.Bind(instanceID, bindingID, bindDetails)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("operation failed"))
+					Expect(user.DeleteCalled).To(BeTrue())
+
+					_, err = store.GetBinding(bindingID)
+					Expect(err).To(Equal(brokerstore.ErrBindingDoesNotExist))
+				})
+			})
+		})
+	})
+
+	Describe("Unbind", func() {
+		BeforeEach(func() {
+			queue.DescribeQueueDetails.Policy = `{"Version":"2012-10-17","Statement":[{"Sid":"custom"},{"Sid":"cf-binding-id"}]}`
+
+			err := store.SaveBinding(brokerstore.Binding{
+				BindingID:       bindingID,
+				InstanceID:      instanceID,
+				ServiceID:       "Queue-Service",
+				PlanID:          "Queue-Plan",
+				TopicInstanceID: topicInstanceID,
+				TopicArn:        topicArn,
+				SubscriptionArn: "subscription-arn",
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("unsubscribes the Queue from the Topic", func() {
+			err := sqsBroker.Unbind(instanceID, bindingID, brokerapi.UnbindDetails{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(topic.UnsubscribeCalled).To(BeTrue())
+			Expect(topic.UnsubscribeSubscriptionArn).To(Equal("subscription-arn"))
+			Expect(queue.SetPolicyPolicy).To(MatchJSON(`{"Version":"2012-10-17","Statement":[{"Sid":"custom"}]}`))
+			Expect(user.DeleteCalled).To(BeTrue())
+		})
+
+		Context("when the Topic lives in another Account", func() {
+			BeforeEach(func() {
+				err := store.SaveInstance(brokerstore.Instance{
+					InstanceID: topicInstanceID,
+					ServiceID:  "Topic-Service",
+					PlanID:     "Topic-Plan",
+					RoleArn:    "topic-role-arn",
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("unsubscribes assuming the Topic Role", func() {
+				err := sqsBroker.Unbind(instanceID, bindingID, brokerapi.UnbindDetails{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(accountRoles).To(ContainElement(AccountRole{RoleArn: "topic-role-arn"}))
+				Expect(topic.UnsubscribeCalled).To(BeTrue())
+			})
+		})
+
+		Context("when unsubscribing fails", func() {
+			BeforeEach(func() {
+				topic.UnsubscribeError = errors.New("operation failed")
+			})
+
+			It("keeps the Binding", func() {
+				err := sqsBroker.Unbind(instanceID, bindingID, brokerapi.UnbindDetails{})
+				Expect(err).To(HaveOccurred())
+				Expect(user.DeleteCalled).To(BeFalse())
+
+				_, err = store.GetBinding(bindingID)
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+	})
+})